@@ -0,0 +1,667 @@
+// Package cg is the CG (Conjugate Gradient) kernel: it estimates the smallest
+// eigenvalue of a large sparse symmetric positive definite matrix.
+//
+// As in NPB's MPI version the ranks form a grid of process rows and columns,
+// as square as the number of ranks allows with the columns a multiple of the
+// rows. Every rank holds the block of the matrix at its row block and column
+// block, and the column block of every vector. A matrix vector product
+// multiplies the rank's block, sums the partial products across the process
+// row and sends each sum to the rank that holds that part of the product as
+// a column block, its transpose in a square grid. The dot products are summed
+// across the process row.
+package cg
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/iyisakuma/NPB-GO/NPB-CHANNEL/CG/params"
+	"github.com/iyisakuma/NPB-GO/NPB-CHANNEL/comm"
+	"github.com/iyisakuma/NPB-GO/NPB-CHANNEL/common"
+)
+
+// Constants
+const (
+	CGITMAX = 25 // conjugate gradient iterations of each outer iteration
+)
+
+// Config selects the problem a Run solves
+type Config struct {
+	Params  params.Params // problem size, from params.Lookup or params.Custom
+	Workers int           // ranks; 0 means $GO_NUM_THREADS, or one per CPU; ignored under comm.Launch
+	Out     io.Writer     // progress messages and the NPB banner, discarded when nil
+}
+
+// Result is the outcome of a CG run with the zeta it is verified on
+type Result struct {
+	common.Result
+	Zeta float64
+}
+
+// Run generates the matrix of cfg.Params, runs the benchmark on it and returns
+// its result. When ctx is done between two iterations the run stops there and
+// Run returns the partial result with a *common.IncompleteError.
+func Run(ctx context.Context, cfg Config) (Result, error) {
+	if err := ctx.Err(); err != nil {
+		return Result{}, err
+	}
+	workers, err := common.ResolveWorkers(cfg.Workers)
+	if err != nil {
+		return Result{}, err
+	}
+	out := cfg.Out
+	if out == nil {
+		out = io.Discard
+	}
+	cg := NewCGBenchmark(cfg.Params, workers, out)
+	results := make(chan Result, 1)
+	errs := make(chan error, 1)
+	err = comm.Spawn(cg.numRanks, func(c *comm.Comm) {
+		result, err := cg.newRank(c).run(ctx)
+		if c.Leader() {
+			results <- result
+			errs <- err
+		}
+	})
+	if err != nil {
+		return Result{}, err
+	}
+	return <-results, <-errs
+}
+
+// CGBenchmark holds what the ranks of one CG run share before they start
+type CGBenchmark struct {
+	// Problem size parameters
+	NA     int
+	NITER  int
+	SHIFT  float64
+	NONZER int
+
+	numRanks int
+
+	// Verification
+	zetaVerifyValue float64
+	classNPB        string
+
+	out io.Writer
+}
+
+// NewCGBenchmark creates a CG benchmark instance for one problem size on
+// numRanks ranks, or on the ranks started by comm.Launch, but never more
+// ranks than rows
+func NewCGBenchmark(prob params.Params, numRanks int, out io.Writer) *CGBenchmark {
+	if n, ok := comm.Launched(); ok {
+		numRanks = n
+	}
+	return &CGBenchmark{
+		NA:              prob.NA,
+		NITER:           prob.NITER,
+		SHIFT:           prob.SHIFT,
+		NONZER:          prob.NONZER,
+		numRanks:        min(numRanks, prob.NA),
+		zetaVerifyValue: prob.ZETA_VERIFY_VALUE,
+		classNPB:        prob.CLASS,
+		out:             out,
+	}
+}
+
+// cgRank is the state of one rank: its block of the matrix, with column
+// indices relative to firstcol, and its column block of every vector
+type cgRank struct {
+	*CGBenchmark
+	c   *comm.Comm
+	out io.Writer // cg.out on rank 0, discarded on the others
+
+	// The rank is at (procRow, procCol) in a grid of nprows x npcols, rank
+	// procRow*npcols + procCol. The columns are split into npcols blocks,
+	// block j starting at bounds[j]; a row block is npcols/nprows
+	// consecutive column blocks.
+	nprows, npcols   int
+	procRow, procCol int
+	bounds           []int
+
+	firstrow, lastrow int
+	firstcol, lastcol int
+
+	a      []float64
+	colidx []int
+	rowstr []int
+
+	x, z, p, q, r []float64
+	part          []float64 // the products of the rank's block, one per row
+	sum           []float64 // the part of the product the rank sums over its process row
+}
+
+// grid returns the process rows and columns of size ranks: the most rows
+// whose square is at most size and that divide the columns, which for a
+// power of two gives NPB's grid
+func grid(size int) (rows, cols int) {
+	rows = 1
+	for d := 2; d*d <= size; d++ {
+		if size%d == 0 && size/d%d == 0 {
+			rows = d
+		}
+	}
+	return rows, size / rows
+}
+
+func (cg *CGBenchmark) newRank(c *comm.Comm) *cgRank {
+	n := cg.NA
+	nprows, npcols := grid(c.Size())
+	k := npcols / nprows
+	r := &cgRank{
+		CGBenchmark: cg,
+		c:           c,
+		out:         io.Discard,
+		nprows:      nprows,
+		npcols:      npcols,
+		procRow:     c.Rank() / npcols,
+		procCol:     c.Rank() % npcols,
+		bounds:      make([]int, npcols+1),
+	}
+
+	// Column blocks whose sizes differ by at most one
+	for j := range r.bounds {
+		r.bounds[j] = j*(n/npcols) + min(j, n%npcols)
+	}
+	r.firstrow, r.lastrow = r.bounds[r.procRow*k], r.bounds[(r.procRow+1)*k]-1
+	r.firstcol, r.lastcol = r.bounds[r.procCol], r.bounds[r.procCol+1]-1
+
+	ncols := r.lastcol - r.firstcol + 1
+	r.x = make([]float64, ncols)
+	r.z = make([]float64, ncols)
+	r.p = make([]float64, ncols)
+	r.q = make([]float64, ncols)
+	r.r = make([]float64, ncols)
+	r.part = make([]float64, r.lastrow-r.firstrow+1)
+	lo, hi := r.segment(r.procCol)
+	r.sum = make([]float64, hi-lo)
+	if c.Rank() == 0 {
+		r.out = cg.out
+	}
+	return r
+}
+
+// segment returns the rows, relative to firstrow, of the part of the row
+// block that the rank in column pc of the process row sums: the column block
+// of the row block numbered pc/nprows
+func (r *cgRank) segment(pc int) (lo, hi int) {
+	j := r.procRow*(r.npcols/r.nprows) + pc/r.nprows
+	return r.bounds[j] - r.firstrow, r.bounds[j+1] - r.firstrow
+}
+
+// rowPeer returns the rank in column pc of the rank's process row
+func (r *cgRank) rowPeer(pc int) int {
+	return r.procRow*r.npcols + pc
+}
+
+// icnvrt scales a double precision number x in (0,1) by a power of 2 and chops it
+func icnvrt(x float64, ipwr2 int) int {
+	return int(float64(ipwr2) * x)
+}
+
+// sprnvc generates a sparse n-vector (v, iv) having nzv nonzeros
+func sprnvc(n, nz, nn1 int, v []float64, iv []int, tran *float64) {
+	nzv := 0
+	amult := 1220703125.0
+
+	for nzv < nz {
+		vecelt := common.Randlc(tran, amult)
+		vecloc := common.Randlc(tran, amult)
+		i := icnvrt(vecloc, nn1) + 1
+		if i > n {
+			continue
+		}
+
+		// Check if this integer was already generated
+		wasGen := false
+		for ii := 0; ii < nzv; ii++ {
+			if iv[ii] == i {
+				wasGen = true
+				break
+			}
+		}
+		if wasGen {
+			continue
+		}
+		v[nzv] = vecelt
+		iv[nzv] = i
+		nzv++
+	}
+}
+
+// vecset sets ith element of sparse vector (v, iv) with nzv nonzeros to val
+func vecset(n int, v []float64, iv []int, nzv *int, i int, val float64) {
+	set := false
+	for k := 0; k < *nzv; k++ {
+		if iv[k] == i {
+			v[k] = val
+			set = true
+		}
+	}
+	if !set {
+		v[*nzv] = val
+		iv[*nzv] = i
+		*nzv++
+	}
+}
+
+// SparseInternalError reports an element of row Row that sparse found no
+// slot for
+type SparseInternalError struct {
+	Row int
+}
+
+func (e *SparseInternalError) Error() string {
+	return fmt.Sprintf("internal error in sparse: i=%d", e.Row)
+}
+
+// sparse builds the block of the matrix at rows firstrow to lastrow and
+// columns firstcol to lastcol from the outer products of the generated
+// sparse vectors, summing duplicates in the same order as for the whole
+// matrix so that every rank's block is bit-identical to that part of the
+// matrix of a single rank
+func (r *cgRank) sparse(n int, arow []int, acol [][]int, aelt [][]float64, rcond, shift float64) error {
+	nrows := r.lastrow - r.firstrow + 1
+	r.rowstr = make([]int, nrows+1)
+	nzloc := make([]int, nrows)
+
+	// Count the number of triples in each row
+	incols := make([]int, n)
+	for i := 0; i < n; i++ {
+		for _, jcol := range acol[i][:arow[i]] {
+			if r.firstcol <= jcol && jcol <= r.lastcol {
+				incols[i]++
+			}
+		}
+		for nza := 0; nza < arow[i]; nza++ {
+			if j := acol[i][nza] - r.firstrow; 0 <= j && j < nrows {
+				r.rowstr[j+1] += incols[i]
+			}
+		}
+	}
+	for j := 1; j < nrows+1; j++ {
+		r.rowstr[j] += r.rowstr[j-1]
+	}
+	a := make([]float64, r.rowstr[nrows])
+	colidx := make([]int, r.rowstr[nrows])
+	for k := range colidx {
+		colidx[k] = -1
+	}
+
+	// Generate actual values by summing duplicates
+	size := 1.0
+	ratio := math.Pow(rcond, 1.0/float64(n))
+	for i := 0; i < n; i++ {
+		for nza := 0; nza < arow[i]; nza++ {
+			row := acol[i][nza]
+			j := row - r.firstrow
+			if j < 0 || j >= nrows {
+				continue
+			}
+			scale := size * aelt[i][nza]
+			for nzrow := 0; nzrow < arow[i]; nzrow++ {
+				jcol := acol[i][nzrow]
+				if jcol < r.firstcol || jcol > r.lastcol {
+					continue
+				}
+				va := aelt[i][nzrow] * scale
+
+				// Add the identity * rcond to the generated matrix
+				if jcol == row && row == i {
+					va = va + rcond - shift
+				}
+
+				found := false
+				k := 0
+				for k = r.rowstr[j]; k < r.rowstr[j+1]; k++ {
+					if colidx[k] > jcol {
+						// Insert colidx here orderly
+						for kk := r.rowstr[j+1] - 2; kk >= k; kk-- {
+							if colidx[kk] > -1 {
+								a[kk+1] = a[kk]
+								colidx[kk+1] = colidx[kk]
+							}
+						}
+						colidx[k] = jcol
+						a[k] = 0.0
+						found = true
+						break
+					} else if colidx[k] == -1 {
+						colidx[k] = jcol
+						found = true
+						break
+					} else if colidx[k] == jcol {
+						// Mark the duplicated entry
+						nzloc[j]++
+						found = true
+						break
+					}
+				}
+				if !found {
+					return &SparseInternalError{Row: i}
+				}
+				a[k] += va
+			}
+		}
+		size *= ratio
+	}
+
+	// Remove empty entries and generate final results
+	for j := 1; j < nrows; j++ {
+		nzloc[j] += nzloc[j-1]
+	}
+	for j := 0; j < nrows; j++ {
+		j1 := 0
+		if j > 0 {
+			j1 = r.rowstr[j] - nzloc[j-1]
+		}
+		j2 := r.rowstr[j+1] - nzloc[j]
+		nza := r.rowstr[j]
+		for k := j1; k < j2; k++ {
+			a[k] = a[nza]
+			colidx[k] = colidx[nza]
+			nza++
+		}
+	}
+	for j := 1; j < nrows+1; j++ {
+		r.rowstr[j] -= nzloc[j-1]
+	}
+	r.a = a[:r.rowstr[nrows]]
+	r.colidx = colidx[:r.rowstr[nrows]]
+	for k := range r.colidx {
+		r.colidx[k] -= r.firstcol
+	}
+	return nil
+}
+
+// makea generates the sparse vectors of the whole matrix, which every rank
+// draws from the same random sequence, and keeps the rank's block
+func (r *cgRank) makea() error {
+	n := r.NA
+	tran := 314159265.0
+	amult := 1220703125.0
+	common.Randlc(&tran, amult)
+
+	arow := make([]int, n)
+	acol := make([][]int, n)
+	aelt := make([][]float64, n)
+
+	// nn1 is the smallest power of two not less than n
+	nn1 := 1
+	for nn1 < n {
+		nn1 *= 2
+	}
+
+	// Generate nonzero positions and save for the use in sparse
+	ivc := make([]int, r.NONZER+1)
+	vc := make([]float64, r.NONZER+1)
+	for iouter := 0; iouter < n; iouter++ {
+		nzv := r.NONZER
+		sprnvc(n, nzv, nn1, vc, ivc, &tran)
+		vecset(n, vc, ivc, &nzv, iouter+1, 0.5)
+		arow[iouter] = nzv
+		acol[iouter] = make([]int, nzv)
+		aelt[iouter] = make([]float64, nzv)
+		for ivelt := 0; ivelt < nzv; ivelt++ {
+			acol[iouter][ivelt] = ivc[ivelt] - 1
+			aelt[iouter][ivelt] = vc[ivelt]
+		}
+	}
+
+	return r.sparse(n, arow, acol, aelt, 0.1, r.SHIFT)
+}
+
+// matvec computes w = A v, where v and w are the rank's column blocks of
+// whole vectors
+func (r *cgRank) matvec(v, w []float64) {
+	for j := range r.part {
+		sum := 0.0
+		for k := r.rowstr[j]; k < r.rowstr[j+1]; k++ {
+			sum += r.a[k] * v[r.colidx[k]]
+		}
+		r.part[j] = sum
+	}
+
+	// Every rank of the process row sums one part of the row block, adding
+	// the products of the row in column order so that the ranks that sum
+	// the same part get the same bits
+	for pc := 0; pc < r.npcols; pc++ {
+		if pc != r.procCol {
+			lo, hi := r.segment(pc)
+			comm.Send(r.c, r.rowPeer(pc), r.part[lo:hi])
+		}
+	}
+	clear(r.sum)
+	for pc := 0; pc < r.npcols; pc++ {
+		var part []float64
+		if pc == r.procCol {
+			lo, hi := r.segment(pc)
+			part = r.part[lo:hi]
+		} else {
+			part = comm.Recv[float64](r.c, r.rowPeer(pc))
+		}
+		for j, x := range part {
+			r.sum[j] += x
+		}
+	}
+
+	// The sum is column block j of the product, which goes to the rank of
+	// column j in process row procCol%nprows; this rank's column block comes
+	// from the rank of its process row that sums it
+	k := r.npcols / r.nprows
+	j := r.procRow*k + r.procCol/r.nprows
+	to := r.procCol%r.nprows*r.npcols + j
+	from := r.procCol/k*r.npcols + r.procCol%k*r.nprows + r.procRow
+	if to == r.c.Rank() {
+		copy(w, r.sum)
+		return
+	}
+	comm.Send(r.c, to, r.sum)
+	copy(w, comm.Recv[float64](r.c, from))
+}
+
+// dot returns the dot products of the pairs of whole vectors in xy, given
+// by their column blocks, summed across the process row in one exchange
+func (r *cgRank) dot(xy ...[]float64) []float64 {
+	local := make([]float64, len(xy)/2)
+	for i := range local {
+		x, y := xy[2*i], xy[2*i+1]
+		for j := range x {
+			local[i] += x[j] * y[j]
+		}
+	}
+	for pc := 0; pc < r.npcols; pc++ {
+		if pc != r.procCol {
+			comm.Send(r.c, r.rowPeer(pc), local)
+		}
+	}
+	sums := make([]float64, len(local))
+	for pc := 0; pc < r.npcols; pc++ {
+		part := local
+		if pc != r.procCol {
+			part = comm.Recv[float64](r.c, r.rowPeer(pc))
+		}
+		for i, x := range part {
+			sums[i] += x
+		}
+	}
+	return sums
+}
+
+// conjGrad solves A z = x approximately with CGITMAX conjugate gradient
+// iterations and returns the norm of the residual x - A z
+func (r *cgRank) conjGrad() float64 {
+	x, z, p, q, res := r.x, r.z, r.p, r.q, r.r
+	for j := range x {
+		q[j] = 0.0
+		z[j] = 0.0
+		res[j] = x[j]
+		p[j] = res[j]
+	}
+	rho := r.dot(res, res)[0]
+
+	for cgit := 1; cgit <= CGITMAX; cgit++ {
+		rho0 := rho
+
+		// q = A.p
+		r.matvec(p, q)
+
+		alpha := 0.0
+		if d := r.dot(p, q)[0]; d != 0.0 {
+			alpha = rho0 / d
+		}
+
+		// z = z + alpha*p and r = r - alpha*q
+		for j := range z {
+			z[j] += alpha * p[j]
+			res[j] -= alpha * q[j]
+		}
+		rho = r.dot(res, res)[0]
+
+		beta := 0.0
+		if rho0 != 0.0 {
+			beta = rho / rho0
+		}
+
+		// p = r + beta*p
+		for j := range p {
+			p[j] = res[j] + beta*p[j]
+		}
+	}
+
+	// The residual norm ||x - A.z||
+	r.matvec(z, res)
+	for j := range res {
+		res[j] = x[j] - res[j]
+	}
+	return math.Sqrt(r.dot(res, res)[0])
+}
+
+// iterate performs one outer iteration: it solves for z and normalizes it
+// into x, and returns the residual norm and zeta
+func (r *cgRank) iterate() (rnorm, zeta float64) {
+	rnorm = r.conjGrad()
+	norms := r.dot(r.x, r.z, r.z, r.z)
+	zeta = r.SHIFT + 1.0/norms[0]
+	scale := 1.0 / math.Sqrt(norms[1])
+	for j := range r.x {
+		r.x[j] = scale * r.z[j]
+	}
+	return rnorm, zeta
+}
+
+// run is the program of one rank; only rank 0 prints and its result is the
+// one Run returns
+func (r *cgRank) run(ctx context.Context) (Result, error) {
+	fmt.Fprintf(r.out, "\n\n NAS Parallel Benchmarks 4.1 Go Channel version - CG Benchmark\n\n")
+	fmt.Fprintf(r.out, " Size: %11d\n", r.NA)
+	fmt.Fprintf(r.out, " Iterations:                  %5d\n", r.NITER)
+	fmt.Fprintf(r.out, " Number of ranks: %d\n", r.c.Size())
+	fmt.Fprintf(r.out, " Process grid: %d x %d\n", r.nprows, r.npcols)
+
+	// All ranks give up if any of them failed to build its block
+	err := r.makea()
+	failed := []int{0}
+	if err != nil {
+		failed[0] = 1
+	}
+	comm.Allreduce(r.c, failed, comm.Max)
+	if failed[0] != 0 {
+		return Result{}, cmp.Or(err, errors.New("another rank failed to generate the matrix"))
+	}
+
+	// Do one iteration untimed to init all code and data page tables, then
+	// start again from (1, 1, ..., 1)
+	for j := range r.x {
+		r.x[j] = 1.0
+	}
+	r.iterate()
+	for j := range r.x {
+		r.x[j] = 1.0
+	}
+
+	r.c.Barrier()
+	startTime := time.Now()
+
+	// Rank 0 decides whether the next iteration runs, so that all ranks
+	// stop after the same one
+	zeta := 0.0
+	iterations := r.NITER
+	stop := []int{0}
+	for it := 1; it <= r.NITER; it++ {
+		if r.c.Rank() == 0 && ctx.Err() != nil {
+			stop[0] = 1
+		}
+		comm.Bcast(r.c, 0, stop)
+		if stop[0] != 0 {
+			iterations = it - 1
+			break
+		}
+
+		var rnorm float64
+		rnorm, zeta = r.iterate()
+		if it == 1 {
+			fmt.Fprintf(r.out, "\n   iteration           ||r||                 zeta\n")
+		}
+		fmt.Fprintf(r.out, "    %5d       %20.14e%20.13e\n", it, rnorm, zeta)
+	}
+
+	elapsed := time.Since(startTime).Seconds()
+	mops := float64(2*iterations*r.NA) * (3.0 + float64(r.NONZER*(r.NONZER+1)) + 25.0*(5.0+float64(r.NONZER*(r.NONZER+1))) + 3.0) / elapsed / 1e6
+
+	verified := false
+	incomplete := iterations < r.NITER
+	if incomplete {
+		fmt.Fprintf(r.out, "\n Benchmark stopped after %d of %d iterations\n", iterations, r.NITER)
+		fmt.Fprintf(r.out, " NO VERIFICATION PERFORMED\n")
+		fmt.Fprintf(r.out, " Zeta is    %20.13e\n", zeta)
+	} else if r.classNPB == params.UserClass {
+		fmt.Fprintf(r.out, "\n Benchmark completed\n")
+		fmt.Fprintf(r.out, " Problem size unknown\n")
+		fmt.Fprintf(r.out, " NO VERIFICATION PERFORMED\n")
+		fmt.Fprintf(r.out, " Zeta is    %20.13e\n", zeta)
+	} else {
+		fmt.Fprintf(r.out, "\n Benchmark completed\n")
+		verified = math.Abs(zeta-r.zetaVerifyValue) < 1e-10
+		err := math.Abs(zeta-r.zetaVerifyValue) / r.zetaVerifyValue
+		if verified {
+			fmt.Fprintf(r.out, " VERIFICATION SUCCESSFUL\n")
+			fmt.Fprintf(r.out, " Zeta is    %20.13e\n", zeta)
+			fmt.Fprintf(r.out, " Error is   %20.13e\n", err)
+		} else {
+			fmt.Fprintf(r.out, " VERIFICATION FAILED\n")
+			fmt.Fprintf(r.out, " Zeta                %20.13e\n", zeta)
+			fmt.Fprintf(r.out, " The correct zeta is %20.13e\n", r.zetaVerifyValue)
+		}
+	}
+
+	result := common.Result{
+		Kernel:      "CG",
+		Class:       r.classNPB,
+		Size:        [3]int{r.NA, 0, 0},
+		Iterations:  iterations,
+		Time:        elapsed,
+		Mops:        mops,
+		OpType:      "conjugate gradient",
+		Verified:    verified,
+		Incomplete:  incomplete,
+		Workers:     r.c.Size(),
+		NPBVersion:  "4.1",
+		CompileTime: "Unknown",
+		Compiler:    "Go",
+	}
+	if r.c.Rank() != 0 {
+		return Result{result, zeta}, nil
+	}
+	common.Finish(&result, r.out)
+	if incomplete {
+		return Result{result, zeta}, &common.IncompleteError{Completed: iterations, Planned: r.NITER, Err: ctx.Err()}
+	}
+	return Result{result, zeta}, nil
+}
@@ -0,0 +1,130 @@
+package cg
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"math"
+	"os"
+	"testing"
+
+	"github.com/iyisakuma/NPB-GO/NPB-CHANNEL/CG/params"
+	"github.com/iyisakuma/NPB-GO/NPB-CHANNEL/comm"
+	"github.com/iyisakuma/NPB-GO/NPB-CHANNEL/common"
+	"github.com/iyisakuma/NPB-GO/NPB-CHANNEL/common/npbtest"
+)
+
+var long = flag.Bool("long", false, "also verify class A")
+
+// testClasses lists the classes the verification tests run
+func testClasses() []string {
+	if *long {
+		return []string{"S", "W", "A"}
+	}
+	return []string{"S", "W"}
+}
+
+func BenchmarkCG(b *testing.B) {
+	for _, class := range []string{"S", "W"} {
+		b.Run(class, func(b *testing.B) {
+			p, _ := params.Lookup(class)
+			mops := 0.0
+			for i := 0; i < b.N; i++ {
+				result, err := Run(context.Background(), Config{Params: p})
+				if err != nil {
+					b.Fatal(err)
+				}
+				if result.Failed() {
+					b.Fatalf("class %s failed verification", class)
+				}
+				mops += result.Mops
+			}
+			b.ReportMetric(mops/float64(b.N), "Mop/s")
+		})
+	}
+}
+
+func TestVerification(t *testing.T) {
+	for _, class := range testClasses() {
+		t.Run(class, func(t *testing.T) {
+			prob, _ := params.Lookup(class)
+			result, err := Run(context.Background(), Config{Params: prob})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if math.Abs(result.Zeta-prob.ZETA_VERIFY_VALUE) >= 1e-10 {
+				t.Errorf("zeta = %.13e, want %.13e", result.Zeta, prob.ZETA_VERIFY_VALUE)
+			}
+			if !result.Verified {
+				t.Error("kernel reported a failed verification")
+			}
+		})
+	}
+}
+
+// TestGrid checks the process grids: NPB's for powers of two, and for other
+// counts the squarest whose columns are a multiple of its rows
+func TestGrid(t *testing.T) {
+	for _, tc := range []struct{ size, rows, cols int }{
+		{1, 1, 1}, {2, 1, 2}, {4, 2, 2}, {8, 2, 4}, {16, 4, 4}, {32, 4, 8}, {64, 8, 8},
+		{3, 1, 3}, {6, 1, 6}, {12, 2, 6}, {18, 3, 6}, {36, 6, 6},
+	} {
+		if rows, cols := grid(tc.size); rows != tc.rows || cols != tc.cols {
+			t.Errorf("grid(%d) = %d x %d, want %d x %d", tc.size, rows, cols, tc.rows, tc.cols)
+		}
+	}
+}
+
+// TestRanks checks that zeta does not depend on how the matrix and vectors
+// are split across the process grid, square or not
+func TestRanks(t *testing.T) {
+	prob, _ := params.Lookup("S")
+	for _, ranks := range []int{1, 3, 4, 7, 8, 12, 16} {
+		result, err := Run(context.Background(), Config{Params: prob, Workers: ranks})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Workers != ranks || !result.Verified {
+			t.Errorf("%d ranks: got %d ranks, zeta %.13e, verified %v", ranks, result.Workers, result.Zeta, result.Verified)
+		}
+	}
+}
+
+// TestLaunch runs class S as four processes, a 2 x 2 grid, that talk over
+// TCP: the test binary starts itself once per rank
+func TestLaunch(t *testing.T) {
+	prob, _ := params.Lookup("S")
+	if _, ok := comm.Launched(); ok {
+		result, err := Run(context.Background(), Config{Params: prob})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if comm.Root() && (result.Workers != 4 || !result.Verified) {
+			t.Errorf("got %d ranks, zeta %.13e, verified %v", result.Workers, result.Zeta, result.Verified)
+		}
+		return
+	}
+	var out bytes.Buffer
+	if err := comm.Launch(context.Background(), 4, os.Args[0], []string{"-test.run=^TestLaunch$"}, &out, &out); err != nil {
+		t.Fatalf("%v\n%s", err, out.String())
+	}
+}
+
+// runClass runs the kernel at class for the checks every kernel shares
+func runClass(ctx context.Context, class string) (common.Result, error) {
+	p, _ := params.Lookup(class)
+	result, err := Run(ctx, Config{Params: p})
+	return result.Result, err
+}
+
+// TestConcurrentRuns checks that runs of two classes in one process do not
+// share state
+func TestConcurrentRuns(t *testing.T) {
+	npbtest.ConcurrentRuns(t, runClass, "S", "W")
+}
+
+// TestStoppedRun checks a run stopped by its context. Run checks once before
+// starting and then before every iteration
+func TestStoppedRun(t *testing.T) {
+	npbtest.StoppedRun(t, runClass, "S", "iterations")
+}
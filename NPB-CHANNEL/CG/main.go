@@ -0,0 +1,78 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/iyisakuma/NPB-GO/NPB-CHANNEL/CG/cg"
+	"github.com/iyisakuma/NPB-GO/NPB-CHANNEL/CG/params"
+	"github.com/iyisakuma/NPB-GO/NPB-CHANNEL/comm"
+	"github.com/iyisakuma/NPB-GO/NPB-CHANNEL/common"
+)
+
+func main() {
+	class := flag.String("class", "S", "problem class (S, W, A, B, C, D, E or U)")
+	na := flag.Int("na", 1400, "class U: order of the sparse matrix")
+	nonzer := flag.Int("nonzer", 7, "class U: nonzeros per generating vector")
+	niter := flag.Int("niter", 15, "class U: number of iterations")
+	shift := flag.Float64("shift", 10.0, "class U: shift of the main diagonal")
+	format := flag.String("format", "text", "result format: text or json")
+	results := flag.String("results", "", "append the result to this .csv or .jsonl file")
+	workers := flag.Int("workers", 0, "ranks (0 means $GO_NUM_THREADS, or one per CPU; ignored under npbrun)")
+	maxProcs := flag.Int("gomaxprocs", 0, "goroutines running at once (0 means $GOMAXPROCS, or one per CPU)")
+	timeout := flag.Duration("timeout", 0, "stop after this long and report the iterations completed (0 for no limit)")
+	flag.Parse()
+
+	if err := common.SetFormat(*format); err != nil {
+		fmt.Fprintf(os.Stderr, "cg: %v\n", err)
+		os.Exit(1)
+	}
+	out := common.Progress()
+	if err := common.SetResultsFile(*results); err != nil {
+		fmt.Fprintf(os.Stderr, "cg: %v\n", err)
+		os.Exit(1)
+	}
+	if err := common.SetMaxProcs(*maxProcs); err != nil {
+		fmt.Fprintf(os.Stderr, "cg: %v\n", err)
+		os.Exit(1)
+	}
+
+	var prob params.Params
+	if strings.EqualFold(*class, params.UserClass) {
+		var err error
+		prob, err = params.Custom(*na, *nonzer, *niter, *shift)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "cg: class U: %v\n", err)
+			os.Exit(1)
+		}
+	} else {
+		var ok bool
+		prob, ok = params.Lookup(*class)
+		if !ok {
+			common.PrintClassUsage(out, "cg", *class, append(params.Classes, params.UserClass))
+			os.Exit(1)
+		}
+	}
+
+	ctx, stop := common.RunContext(*timeout)
+	defer stop()
+	result, err := cg.Run(ctx, cg.Config{Params: prob, Workers: *workers, Out: out})
+	if err != nil && !result.Incomplete {
+		fmt.Fprintf(os.Stderr, "cg: %v\n", err)
+		os.Exit(1)
+	}
+	if !comm.Root() {
+		// Rank 0 reports the run of the ranks started by npbrun
+		return
+	}
+	common.Report(&result.Result)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cg: %v\n", err)
+		os.Exit(1)
+	}
+	if result.Failed() {
+		os.Exit(1)
+	}
+}
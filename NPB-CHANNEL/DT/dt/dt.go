@@ -0,0 +1,427 @@
+// Package dt is the DT (Data Traffic) benchmark: features, arrays of samples,
+// flow through a graph from the sources that generate them, through the
+// comparators that filter and merge them, to the sinks that reduce them to a
+// checksum. It measures how fast the features move between the nodes, the
+// arithmetic being light. The graph is a black hole (BH: many sources narrow
+// down to one sink), a white hole (WH: one source widens out to many sinks)
+// or a shuffle (SH: a butterfly from as many sources as sinks).
+//
+// As in NPB's MPI version every node is a rank of its own, and every arc a
+// message from one rank to another: a node waits for the features of its
+// predecessors, and sends its own to its successors as soon as it is ready,
+// so the nodes of a layer run concurrently.
+package dt
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/iyisakuma/NPB-GO/NPB-CHANNEL/DT/params"
+	"github.com/iyisakuma/NPB-GO/NPB-CHANNEL/comm"
+	"github.com/iyisakuma/NPB-GO/NPB-CHANNEL/common"
+)
+
+// FIELD_DIM is the number of values of every sample of a feature
+const FIELD_DIM = 4
+
+// Timers of the timer.flag section breakdown
+const (
+	T_TOTAL = iota + 1
+	T_SOURCES
+	T_COMPARATORS
+	T_SINKS
+	T_LAST = T_SINKS
+)
+
+// Config selects the problem a Run solves. The run takes one rank per node of
+// the graph, and as many processes under comm.Launch.
+type Config struct {
+	Params  params.Params // problem size, from params.Lookup
+	Graph   string        // BH, WH or SH, see params.Graphs
+	Workers int           // nodes processed at once; 0 means $GO_NUM_THREADS, or one per CPU; ignored under comm.Launch
+	Out     io.Writer     // progress messages and the NPB banner, discarded when nil
+}
+
+// Result is the outcome of a DT run with the checksum it is verified on
+type Result struct {
+	common.Result
+	Graph    string
+	Checksum float64 // sum of the values of the sinks
+}
+
+// Run runs the benchmark on the graph and class of cfg and returns its
+// result. A node that finds ctx done when its inputs have arrived does not
+// process them; the run then ends with the nodes that depend on it left out,
+// and Run returns the partial result with a *common.IncompleteError.
+func Run(ctx context.Context, cfg Config) (Result, error) {
+	if err := ctx.Err(); err != nil {
+		return Result{}, err
+	}
+	out := cfg.Out
+	if out == nil {
+		out = io.Discard
+	}
+	b, err := NewDTBenchmark(cfg.Params, cfg.Graph, out)
+	if err != nil {
+		return Result{}, err
+	}
+	if _, err := os.Stat("timer.flag"); err == nil {
+		b.timersEnabled = true
+	}
+	if _, ok := comm.Launched(); !ok {
+		workers, err := common.ResolveWorkers(cfg.Workers)
+		if err != nil {
+			return Result{}, err
+		}
+		b.slots = make(chan struct{}, workers)
+	}
+	results := make(chan Result, 1)
+	errs := make(chan error, 1)
+	err = comm.Spawn(len(b.graph.nodes), func(c *comm.Comm) {
+		result, err := b.rank(ctx, c)
+		if c.Leader() {
+			results <- result
+			errs <- err
+		}
+	})
+	if err != nil {
+		return Result{}, err
+	}
+	return <-results, <-errs
+}
+
+// DTBenchmark holds what the ranks of one DT run share before they start
+type DTBenchmark struct {
+	params params.Params
+	kind   string
+	graph  *graph
+
+	// slots bounds the nodes that hold their working features at once, so
+	// that the ranks of one process do not all do so: every rank would have
+	// a process of its own with MPI
+	slots chan struct{}
+
+	timersEnabled bool
+	out           io.Writer
+}
+
+// NewDTBenchmark creates a DT benchmark for the given class parameters on
+// the graph of the given kind
+func NewDTBenchmark(p params.Params, kind string, out io.Writer) (*DTBenchmark, error) {
+	kind = strings.ToUpper(kind)
+	g, err := newGraph(kind, p.CLASS, p.NUM_SOURCES)
+	if err != nil {
+		return nil, err
+	}
+	return &DTBenchmark{params: p, kind: kind, graph: g, out: out}, nil
+}
+
+// ipowMod returns a to the power n modulo md. It computes in 32-bit integers
+// that wrap around on overflow, as NPB's does, since the lengths of the
+// features depend on it.
+func ipowMod(a int32, n int64, md int32) int32 {
+	if n < 0 {
+		n = -n
+	}
+	if md <= 0 || n == 0 {
+		return 1
+	}
+	q, r := a, int32(1)
+	for n > 1 {
+		if n%2 == 0 {
+			q = q * q % md
+			n /= 2
+		} else {
+			r = r * q % md
+			n--
+		}
+	}
+	return r * q % md
+}
+
+// featureNum returns the number of samples of the feature of source id
+func (b *DTBenchmark) featureNum(id int) int {
+	tran := 314159265.0
+	denom := common.Randlc(&tran, float64(2*id+1))
+	stdev := b.params.STD_DEVIATION
+	rtfs := ipowMod(int32(1/denom)*int32('S'), int64(2*id+1), int32(2*stdev))
+	if rtfs < 0 {
+		rtfs = -rtfs
+	}
+	return b.params.NUM_SAMPLES - stdev + int(rtfs)
+}
+
+// randomFeatures generates the feature of source id: the samples follow
+// four multiplicative congruential sequences
+func (b *DTBenchmark) randomFeatures(id int) []float64 {
+	const (
+		nxg, nyg, nzg, nfg = 2, 2, 2, 5
+		nx, ny, nz, nf     = 421, 419, 1427, 3527
+	)
+	feat := make([]float64, b.featureNum(id)*FIELD_DIM)
+	expon := int64(len(feat)*(id+1)) % 3141592
+	seedx := ipowMod(nxg, expon, nx)
+	seedy := ipowMod(nyg, expon, ny)
+	seedz := ipowMod(nzg, expon, nz)
+	seedf := ipowMod(nfg, expon, nf)
+	for i := 0; i < len(feat); i += FIELD_DIM {
+		seedx = seedx * nxg % nx
+		seedy = seedy * nyg % ny
+		seedz = seedz * nzg % nz
+		seedf = seedf * nfg % nf
+		feat[i] = float64(seedx)
+		feat[i+1] = float64(seedy)
+		feat[i+2] = float64(seedz)
+		feat[i+3] = float64(seedf)
+	}
+	return feat
+}
+
+// resample stretches a to blen values, spreading every inner value evenly
+// over the values it stretches to
+func resample(a []float64, blen int) []float64 {
+	nval := make([]float64, blen)
+	ratio := float64(blen / len(a))
+	for i := 1; i < len(a)-1; i++ {
+		jlo := int(0.5 * float64(2*i-1) * ratio)
+		jhi := int(0.5 * float64(2*i+1) * ratio)
+		avval := a[i] / float64(jhi-jlo+1)
+		for j := jlo; j <= jhi; j++ {
+			nval[j] += avval
+		}
+	}
+	nval[0] = a[0]
+	nval[blen-1] = a[len(a)-1]
+	return nval
+}
+
+// windowFilter brings a and b to the same length and replaces every inner
+// sample of a with a weighted sample of b, the one of the window of three
+// around it that is closest to a's, and returns the new a
+func windowFilter(a, b []float64, w int) []float64 {
+	weight := float64(w+1) / float64(w+2)
+	if len(a) < len(b) {
+		a = resample(a, len(b))
+	}
+	if len(a) > len(b) {
+		b = resample(b, len(a))
+	}
+	dist := func(i, j int) float64 {
+		d0, d1, d2, d3 := a[i]-b[j], a[i+1]-b[j+1], a[i+2]-b[j+2], a[i+3]-b[j+3]
+		return d0*d0 + d1*d1 + d2*d2 + d3*d3
+	}
+	for i := FIELD_DIM; i < len(a)-FIELD_DIM; i += FIELD_DIM {
+		rms0 := dist(i, i)
+		rms1 := dist(i+FIELD_DIM, i+FIELD_DIM)
+		rmsm1 := dist(i-FIELD_DIM, i-FIELD_DIM)
+		j := i
+		if rms1 < rms0 {
+			j = i + FIELD_DIM
+			rms0 = rms1
+		}
+		if rmsm1 < rms0 {
+			j = i - FIELD_DIM
+		}
+		a[i] = weight * b[j]
+		a[i+1] = weight * b[j+1]
+		a[i+2] = weight * b[j+2]
+		a[i+3] = weight * b[j+3]
+	}
+	return a
+}
+
+// combine filters the features of the inputs of comparator nd, in the order
+// of its arcs, into a new feature. in returns the feature of an arc, which
+// combine may change.
+func (b *DTBenchmark) combine(nd *node, in func(ar *arc) []float64) []float64 {
+	feat := make([]float64, b.params.NUM_SAMPLES*FIELD_DIM)
+	for _, ar := range nd.in {
+		feat = windowFilter(feat, in(ar), nd.id)
+	}
+	for i, v := range feat {
+		feat[i] = float64(int(v) / len(nd.in))
+	}
+	return feat
+}
+
+// checkVal returns the mean square of the values of a
+func checkVal(a []float64) float64 {
+	csum := 0.0
+	for _, v := range a {
+		csum += v * v / float64(len(a))
+	}
+	return csum
+}
+
+// reduce returns the value of sink nd: the mean, truncated, of the weighted
+// mean squares of its inputs, weighted again. in returns the feature of an
+// arc.
+func (b *DTBenchmark) reduce(nd *node, in func(ar *arc) []float64) float64 {
+	w := nd.id + 1
+	csum := 0.0
+	for _, ar := range nd.in {
+		csum += float64(int(float64(w) * checkVal(in(ar))))
+	}
+	if len(nd.in) > 0 {
+		csum = float64(int64(csum) / int64(len(nd.in)))
+	}
+	return float64(w) * csum
+}
+
+// verify compares the checksum with the reference value of the class
+func (b *DTBenchmark) verify(checksum float64) bool {
+	ref, _ := b.params.VerifyValue(b.kind)
+	fmt.Fprintf(b.out, " Verification being performed for %s\n", b.graph.name)
+	verified := math.Abs(checksum-ref)/ref <= 1.0e-8
+	if verified {
+		fmt.Fprintf(b.out, "          checksum %22.1f%22.1f\n", checksum, ref)
+		fmt.Fprintf(b.out, " Verification Successful\n")
+	} else {
+		fmt.Fprintf(b.out, " FAILURE: checksum %22.1f%22.1f\n", checksum, ref)
+		fmt.Fprintf(b.out, " Verification failed\n")
+	}
+	return verified
+}
+
+// Entries of the tally the ranks sum at the end of a run
+const (
+	TALLY_CHECKSUM = iota // value of the sink
+	TALLY_NODES           // 1 when the node was processed
+	TALLY_SECONDS         // seconds of the node, at TALLY_SECONDS + its kind
+	TALLY_LEN      = TALLY_SECONDS + SINK + 1
+)
+
+// rank is the program of the rank of node c.Rank(); only rank 0 prints and
+// its result is the one Run returns. A node that was not processed sends
+// empty features, which tell its successors to stop as well.
+func (b *DTBenchmark) rank(ctx context.Context, c *comm.Comm) (Result, error) {
+	g := b.graph
+	nd := g.nodes[c.Rank()]
+	featnum := b.params.NUM_SAMPLES * FIELD_DIM
+	out := io.Discard
+	if c.Rank() == 0 {
+		out = b.out
+	}
+	var timers common.Timers
+
+	fmt.Fprintf(out, "\n\n NAS Parallel Benchmarks 4.1 Go Channel version - DT Benchmark\n\n")
+	fmt.Fprintf(out, " Graph:            %12s\n", g.name)
+	fmt.Fprintf(out, " Nodes:            %12d\n", len(g.nodes))
+	fmt.Fprintf(out, " Arcs:             %12d\n", len(g.arcs))
+	fmt.Fprintf(out, " Feature size:     %12d\n", featnum)
+	fmt.Fprintf(out, " Number of ranks:  %12d\n\n", c.Size())
+
+	c.Barrier()
+	for i := 1; i <= T_LAST; i++ {
+		timers.Clear(i)
+	}
+	timers.Start(T_TOTAL)
+
+	inputs := make(map[*arc][]float64, len(nd.in))
+	stopped := false
+	for _, ar := range nd.in {
+		inputs[ar] = comm.Recv[float64](c, ar.tail.id)
+		stopped = stopped || len(inputs[ar]) == 0
+	}
+	in := func(ar *arc) []float64 {
+		return inputs[ar]
+	}
+
+	tally := make([]float64, TALLY_LEN)
+	var feat []float64
+	if b.slots != nil {
+		b.slots <- struct{}{}
+	}
+	if !stopped && ctx.Err() == nil {
+		timers.Start(T_SOURCES + nd.kind)
+		switch nd.kind {
+		case SOURCE:
+			feat = b.randomFeatures(nd.id)
+		case COMPARATOR:
+			feat = b.combine(nd, in)
+		case SINK:
+			tally[TALLY_CHECKSUM] = b.reduce(nd, in)
+		}
+		timers.Stop(T_SOURCES + nd.kind)
+		tally[TALLY_NODES] = 1
+		tally[TALLY_SECONDS+nd.kind] = timers.Read(T_SOURCES + nd.kind)
+	}
+	for _, ar := range nd.out {
+		comm.Send(c, ar.head.id, feat)
+	}
+	if b.slots != nil {
+		<-b.slots
+	}
+	comm.Allreduce(c, tally, comm.Sum)
+
+	timers.Stop(T_TOTAL)
+	tsec := timers.Read(T_TOTAL)
+	checksum := tally[TALLY_CHECKSUM]
+	completed := int(tally[TALLY_NODES])
+
+	verified := false
+	incomplete := completed < len(g.nodes)
+	if c.Rank() == 0 {
+		if incomplete {
+			fmt.Fprintf(out, " Benchmark stopped after %d of %d nodes\n", completed, len(g.nodes))
+			fmt.Fprintf(out, " NO VERIFICATION PERFORMED\n")
+		} else {
+			verified = b.verify(checksum)
+		}
+	} else {
+		ref, _ := b.params.VerifyValue(b.kind)
+		verified = !incomplete && math.Abs(checksum-ref)/ref <= 1.0e-8
+	}
+
+	// The rate NPB reports: millions of feature values sent along the arcs
+	// per second, counted in units of 2^20
+	mops := 0.0
+	if tsec != 0.0 {
+		mops = float64(featnum) * float64(len(g.arcs)) / 1048576 / tsec
+	}
+
+	result := common.Result{
+		Kernel:      "DT",
+		Class:       b.params.CLASS,
+		Size:        [3]int{featnum, 0, 0},
+		Iterations:  completed,
+		Time:        tsec,
+		Mops:        mops,
+		OpType:      "bytes transmitted",
+		Verified:    verified,
+		Incomplete:  incomplete,
+		Workers:     c.Size(),
+		NPBVersion:  "4.1",
+		CompileTime: "Unknown",
+		Compiler:    "Go",
+	}
+	if b.timersEnabled {
+		result.Timers = []common.Timer{
+			{Name: "total", Seconds: tsec},
+			{Name: "sources", Seconds: tally[TALLY_SECONDS+SOURCE]},
+			{Name: "comparators", Seconds: tally[TALLY_SECONDS+COMPARATOR]},
+			{Name: "sinks", Seconds: tally[TALLY_SECONDS+SINK]},
+		}
+	}
+	res := Result{result, b.kind, checksum}
+	if c.Rank() != 0 {
+		return res, nil
+	}
+	common.Finish(&result, out)
+
+	if b.timersEnabled {
+		fmt.Fprintln(out, "  SECTION      Time (secs), summed over the nodes")
+		for _, t := range result.Timers {
+			fmt.Fprintf(out, "  %-11s:%9.3f\n", t.Name, t.Seconds)
+		}
+	}
+	res.Result = result
+	if incomplete {
+		return res, &common.IncompleteError{Completed: completed, Planned: len(g.nodes), Err: ctx.Err()}
+	}
+	return res, nil
+}
@@ -0,0 +1,175 @@
+package dt
+
+import "fmt"
+
+// Kinds of the nodes of a graph
+const (
+	SOURCE = iota
+	COMPARATOR
+	SINK
+)
+
+// MAX_IN_DEGREE is the fan-in of the comparators of the BH graph, and the
+// fan-out of those of WH
+const MAX_IN_DEGREE = 4
+
+// node is a vertex of a communication graph. Its arcs are kept in the order
+// they were attached, which is the order in which a comparator filters its
+// inputs.
+type node struct {
+	id      int
+	kind    int
+	name    string
+	in, out []*arc
+}
+
+// arc carries the feature of its tail to its head
+type arc struct {
+	id         int
+	tail, head *node
+}
+
+// graph is a communication graph whose nodes are topologically sorted: the
+// tail of every arc comes before its head
+type graph struct {
+	name  string
+	nodes []*node
+	arcs  []*arc
+}
+
+func (g *graph) addNode(kind int, name string) *node {
+	nd := &node{id: len(g.nodes), kind: kind, name: name}
+	g.nodes = append(g.nodes, nd)
+	return nd
+}
+
+func (g *graph) addArc(tail, head *node) {
+	ar := &arc{id: len(g.arcs), tail: tail, head: head}
+	g.arcs = append(g.arcs, ar)
+	tail.out = append(tail.out, ar)
+	head.in = append(head.in, ar)
+}
+
+// newGraph builds the graph of the given kind ("BH", "WH" or "SH") with
+// numSources sources or sinks
+func newGraph(kind, class string, numSources int) (*graph, error) {
+	g := &graph{name: fmt.Sprintf("DT_%s.%s", kind, class)}
+	switch kind {
+	case "BH":
+		g.buildBH(numSources)
+	case "WH":
+		g.buildWH(numSources)
+	case "SH":
+		g.buildSH(numSources)
+	default:
+		return nil, fmt.Errorf("unknown graph %q: want BH, WH or SH", kind)
+	}
+	return g, nil
+}
+
+// buildBH builds the black hole: the sources feed layers of comparators of
+// up to MAX_IN_DEGREE inputs each, which narrow down to a single sink
+func (g *graph) buildBH(numSources int) {
+	for i := 0; i < numSources; i++ {
+		g.addNode(SOURCE, fmt.Sprintf("Source.%d", i))
+	}
+	numLayerNodes, numPrevLayerNodes := numSources, numSources
+	firstLayerNode, totComparators := 0, 0
+	for numLayerNodes > MAX_IN_DEGREE {
+		numLayerNodes = numLayerNodes / MAX_IN_DEGREE
+		if numLayerNodes*MAX_IN_DEGREE < numPrevLayerNodes {
+			numLayerNodes++
+		}
+		for i := 0; i < numLayerNodes; i++ {
+			nd := g.addNode(COMPARATOR, fmt.Sprintf("Comparator.%d", totComparators))
+			totComparators++
+			for j := 0; j < MAX_IN_DEGREE; j++ {
+				sid := i*MAX_IN_DEGREE + j
+				if sid >= numPrevLayerNodes {
+					break
+				}
+				g.addArc(g.nodes[firstLayerNode+sid], nd)
+			}
+		}
+		firstLayerNode += numPrevLayerNodes
+		numPrevLayerNodes = numLayerNodes
+	}
+	sink := g.addNode(SINK, "Sink")
+	for i := 0; i < numPrevLayerNodes; i++ {
+		g.addArc(g.nodes[firstLayerNode+i], sink)
+	}
+}
+
+// buildWH builds the white hole, the black hole reversed: a single source
+// feeds layers of comparators of up to MAX_IN_DEGREE outputs each, which
+// widen out to numSinks sinks
+func (g *graph) buildWH(numSinks int) {
+	for i := 0; i < numSinks; i++ {
+		g.addNode(SINK, fmt.Sprintf("Sink.%d", i))
+	}
+	numLayerNodes, numPrevLayerNodes := numSinks, numSinks
+	firstLayerNode, totComparators := 0, 0
+	for numLayerNodes > MAX_IN_DEGREE {
+		numLayerNodes = numLayerNodes / MAX_IN_DEGREE
+		if numLayerNodes*MAX_IN_DEGREE < numPrevLayerNodes {
+			numLayerNodes++
+		}
+		for i := 0; i < numLayerNodes; i++ {
+			nd := g.addNode(COMPARATOR, fmt.Sprintf("Comparator.%d", totComparators))
+			totComparators++
+			for j := 0; j < MAX_IN_DEGREE; j++ {
+				sid := i*MAX_IN_DEGREE + j
+				if sid >= numPrevLayerNodes {
+					break
+				}
+				g.addArc(nd, g.nodes[firstLayerNode+sid])
+			}
+		}
+		firstLayerNode += numPrevLayerNodes
+		numPrevLayerNodes = numLayerNodes
+	}
+	source := g.addNode(SOURCE, "Source")
+	for i := 0; i < numPrevLayerNodes; i++ {
+		g.addArc(source, g.nodes[firstLayerNode+i])
+	}
+
+	// The graph was built from the sinks up: reverse it to sort it
+	for i, j := 0, len(g.nodes)-1; i < j; i, j = i+1, j-1 {
+		g.nodes[i], g.nodes[j] = g.nodes[j], g.nodes[i]
+	}
+	for i, nd := range g.nodes {
+		nd.id = i
+	}
+}
+
+// buildSH builds the shuffle: numSources sources, then layers of numSources
+// comparators that each combine two nodes of the layer before whose
+// positions differ in one bit, as in a butterfly, then numSources sinks.
+// numSources must be a power of two.
+func (g *graph) buildSH(numSources int) {
+	numOfLayers := 0
+	for tmpS := numSources >> 1; tmpS > 1; tmpS >>= 1 {
+		numOfLayers++
+	}
+	for i := 0; i < numSources; i++ {
+		g.addNode(SOURCE, fmt.Sprintf("Source.%d", i))
+	}
+	firstLayerNode := 0
+	for j := 0; j < numOfLayers; j++ {
+		mask := 1 << j
+		for i := 0; i < numSources; i++ {
+			nd := g.addNode(COMPARATOR, fmt.Sprintf("Comparator.%d", i+j*firstLayerNode))
+			ndoff := i &^ mask
+			g.addArc(g.nodes[firstLayerNode+ndoff], nd)
+			g.addArc(g.nodes[firstLayerNode+ndoff+mask], nd)
+		}
+		firstLayerNode += numSources
+	}
+	mask := 1 << numOfLayers
+	for i := 0; i < numSources; i++ {
+		nd := g.addNode(SINK, fmt.Sprintf("Sink.%d", i))
+		ndoff := i &^ mask
+		g.addArc(g.nodes[firstLayerNode+ndoff], nd)
+		g.addArc(g.nodes[firstLayerNode+ndoff+mask], nd)
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/iyisakuma/NPB-GO/NPB-CHANNEL/DT/dt"
+	"github.com/iyisakuma/NPB-GO/NPB-CHANNEL/DT/params"
+	"github.com/iyisakuma/NPB-GO/NPB-CHANNEL/comm"
+	"github.com/iyisakuma/NPB-GO/NPB-CHANNEL/common"
+)
+
+func main() {
+	class := flag.String("class", "S", "problem class (S, W, A or B)")
+	format := flag.String("format", "text", "result format: text or json")
+	results := flag.String("results", "", "append the result to this .csv or .jsonl file")
+	workers := flag.Int("workers", 0, "ranks (0 means $GO_NUM_THREADS, or one per CPU; ignored under npbrun)")
+	maxProcs := flag.Int("gomaxprocs", 0, "goroutines running at once (0 means $GOMAXPROCS, or one per CPU)")
+	graph := flag.String("graph", "BH", "communication graph: BH (black hole), WH (white hole) or SH (shuffle)")
+	timeout := flag.Duration("timeout", 0, "stop after this long and report the nodes completed (0 for no limit)")
+	flag.Parse()
+
+	if err := common.SetFormat(*format); err != nil {
+		fmt.Fprintf(os.Stderr, "dt: %v\n", err)
+		os.Exit(1)
+	}
+	out := common.Progress()
+	if err := common.SetResultsFile(*results); err != nil {
+		fmt.Fprintf(os.Stderr, "dt: %v\n", err)
+		os.Exit(1)
+	}
+	if err := common.SetMaxProcs(*maxProcs); err != nil {
+		fmt.Fprintf(os.Stderr, "dt: %v\n", err)
+		os.Exit(1)
+	}
+
+	p, ok := params.Lookup(*class)
+	if !ok {
+		common.PrintClassUsage(out, "dt", *class, params.Classes)
+		os.Exit(1)
+	}
+
+	ctx, stop := common.RunContext(*timeout)
+	defer stop()
+	result, err := dt.Run(ctx, dt.Config{Params: p, Workers: *workers, Graph: *graph, Out: out})
+	if err != nil && !result.Incomplete {
+		fmt.Fprintf(os.Stderr, "dt: %v\n", err)
+		os.Exit(1)
+	}
+	if !comm.Root() {
+		// Rank 0 reports the run of the ranks started by npbrun
+		return
+	}
+	common.Report(&result.Result)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "dt: %v\n", err)
+		os.Exit(1)
+	}
+	if result.Failed() {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,276 @@
+// Package ep is the EP (Embarrassingly Parallel) kernel: it tallies Gaussian
+// deviates generated from a stream of pseudorandom numbers. Every rank
+// generates its own range of batches, and the tallies are summed with one
+// reduction at the end.
+package ep
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/iyisakuma/NPB-GO/NPB-CHANNEL/EP/params"
+	"github.com/iyisakuma/NPB-GO/NPB-CHANNEL/comm"
+	"github.com/iyisakuma/NPB-GO/NPB-CHANNEL/common"
+)
+
+const (
+	MK      = 16
+	NK      = 1 << MK
+	NQ      = 10
+	EPSILON = 1.0e-8
+	A       = 1220703125.0
+	S       = 271828183.0
+	NK_PLUS = (2*NK + 1)
+)
+
+// Timers of the timer.flag section breakdown
+const (
+	T_TOTAL = iota
+	T_GPAIRS
+	T_RANDN
+	T_LAST = T_RANDN
+)
+
+// Config selects the problem a Run solves
+type Config struct {
+	Params  params.Params // problem size, from params.Lookup
+	Workers int           // ranks; 0 means $GO_NUM_THREADS, or one per CPU; ignored under comm.Launch
+	Out     io.Writer     // progress messages and the NPB banner, discarded when nil
+}
+
+// Result is the outcome of an EP run with the sums it is verified on
+type Result struct {
+	common.Result
+	SX, SY float64
+}
+
+// Run runs the EP benchmark and returns its result. When ctx is done between
+// two batches of random numbers the run stops there and Run returns the
+// partial result with a *common.IncompleteError.
+func Run(ctx context.Context, cfg Config) (Result, error) {
+	if err := ctx.Err(); err != nil {
+		return Result{}, err
+	}
+	out := cfg.Out
+	if out == nil {
+		out = io.Discard
+	}
+	numRanks, err := common.ResolveWorkers(cfg.Workers)
+	if err != nil {
+		return Result{}, err
+	}
+	if n, ok := comm.Launched(); ok {
+		numRanks = n
+	}
+
+	b := &EPBenchmark{params: cfg.Params, numRanks: numRanks, out: out}
+	if _, err := os.Stat("timer.flag"); err == nil {
+		b.timersEnabled = true
+	}
+	results := make(chan Result, 1)
+	errs := make(chan error, 1)
+	err = comm.Spawn(numRanks, func(c *comm.Comm) {
+		result, err := b.rank(ctx, c)
+		if c.Leader() {
+			results <- result
+			errs <- err
+		}
+	})
+	if err != nil {
+		return Result{}, err
+	}
+	return <-results, <-errs
+}
+
+// EPBenchmark holds what the ranks of one EP run share before they start
+type EPBenchmark struct {
+	params        params.Params
+	numRanks      int
+	timersEnabled bool
+	out           io.Writer
+}
+
+// batches returns the first batch of rank and the one after its last: the
+// batches are split in blocks, the first ranks getting one more when they
+// do not divide evenly
+func (b *EPBenchmark) batches(rank, np int) (k1, k2 int) {
+	k1 = rank*(np/b.numRanks) + min(rank, np%b.numRanks)
+	k2 = k1 + np/b.numRanks
+	if rank < np%b.numRanks {
+		k2++
+	}
+	return k1, k2
+}
+
+// rank is the program of one rank; only rank 0 prints and its result is
+// the one Run returns
+func (b *EPBenchmark) rank(ctx context.Context, c *comm.Comm) (Result, error) {
+	p := b.params
+	out := io.Discard
+	if c.Rank() == 0 {
+		out = b.out
+	}
+	var timers common.Timers
+	x := make([]float64, NK_PLUS)
+
+	size := strings.TrimRight(fmt.Sprintf("%15.0f", math.Pow(2.0, float64(p.M+1))), ".")
+	fmt.Fprintf(out, "\n\n NAS Parallel Benchmarks 4.1 Go Channel version - EP Benchmark\n\n")
+	fmt.Fprintf(out, " Number of random numbers generated: %15s\n", size)
+	fmt.Fprintf(out, " Number of ranks: %d\n", c.Size())
+
+	// The number of batches of random number pairs generated
+	np := 1 << (p.M - MK)
+
+	// Call the random number generator functions and initialize the x array
+	// to reduce the effects of paging on the timings
+	dum := []float64{1.0, 1.0, 1.0}
+	common.Vranlc(0, &dum[0], dum[1], dum)
+	dum[0] = common.Randlc(&dum[1], dum[2])
+	for i := range x {
+		x[i] = -1.0e99
+	}
+
+	c.Barrier()
+	for i := T_TOTAL; i <= T_LAST; i++ {
+		timers.Clear(i)
+	}
+	timers.Start(T_TOTAL)
+
+	t1 := A
+	common.Vranlc(0, &t1, A, x)
+	for i := 0; i < MK+1; i++ {
+		common.Randlc(&t1, t1)
+	}
+	an := t1
+
+	// Tallies of the annuli, then sx, sy and the batches generated
+	tally := make([]float64, NQ+3)
+	q := tally[:NQ]
+	k1, k2 := b.batches(c.Rank(), np)
+	for k := k1; k < k2; k++ {
+		if ctx.Err() != nil {
+			break
+		}
+		kk := k
+		t1 = S
+		t2 := an
+
+		// Find the starting seed t1 for this kk
+		for i := 1; i <= 100; i++ {
+			ik := kk / 2
+			if 2*ik != kk {
+				common.Randlc(&t1, t2)
+			}
+			if ik == 0 {
+				break
+			}
+			common.Randlc(&t2, t2)
+			kk = ik
+		}
+
+		// Compute uniform pseudorandom numbers
+		if b.timersEnabled {
+			timers.Start(T_RANDN)
+		}
+		common.Vranlc(2*NK, &t1, A, x)
+		if b.timersEnabled {
+			timers.Stop(T_RANDN)
+		}
+
+		// Compute Gaussian deviates by the acceptance-rejection method and
+		// tally counts in concentric square annuli
+		if b.timersEnabled {
+			timers.Start(T_GPAIRS)
+		}
+		for i := 0; i < NK; i++ {
+			x1 := 2.0*x[2*i] - 1.0
+			x2 := 2.0*x[2*i+1] - 1.0
+			t1 = x1*x1 + x2*x2
+			if t1 <= 1.0 {
+				t2 = math.Sqrt(-2.0 * math.Log(t1) / t1)
+				t3 := x1 * t2
+				t4 := x2 * t2
+				l := int(math.Max(math.Abs(t3), math.Abs(t4)))
+				q[l] += 1.0
+				tally[NQ] += t3
+				tally[NQ+1] += t4
+			}
+		}
+		if b.timersEnabled {
+			timers.Stop(T_GPAIRS)
+		}
+		tally[NQ+2]++
+	}
+	comm.Allreduce(c, tally, comm.Sum)
+	timers.Stop(T_TOTAL)
+	tm := timers.Read(T_TOTAL)
+
+	sx, sy := tally[NQ], tally[NQ+1]
+	batches := int(tally[NQ+2])
+	gc := 0.0
+	for i := 0; i < NQ; i++ {
+		gc += q[i]
+	}
+
+	incomplete := batches < np
+	sxErr := math.Abs((sx - p.SX_VERIFY_VALUE) / p.SX_VERIFY_VALUE)
+	syErr := math.Abs((sy - p.SY_VERIFY_VALUE) / p.SY_VERIFY_VALUE)
+	verified := !incomplete && sxErr <= EPSILON && syErr <= EPSILON
+	mops := math.Pow(2.0, float64(p.M+1)) * float64(batches) / float64(np) / tm / 1000000.0
+
+	if incomplete {
+		fmt.Fprintf(out, "\n Benchmark stopped after %d of %d batches\n", batches, np)
+	}
+	fmt.Fprintf(out, "\n EP Benchmark Results:\n\n")
+	fmt.Fprintf(out, " CPU Time =%10.4f\n", tm)
+	fmt.Fprintf(out, " N = 2^%5d\n", p.M)
+	fmt.Fprintf(out, " No. Gaussian Pairs = %15.0f\n", gc)
+	fmt.Fprintf(out, " Sums = %25.15e %25.15e\n", sx, sy)
+	fmt.Fprintln(out, " Counts:")
+	for i := 0; i < NQ-1; i++ {
+		fmt.Fprintf(out, "%3d%15.0f\n", i, q[i])
+	}
+
+	result := common.Result{
+		Kernel:      "EP",
+		Class:       p.CLASS,
+		Size:        [3]int{p.M + 1, 0, 0},
+		Iterations:  0,
+		Time:        tm,
+		Mops:        mops,
+		OpType:      "Random numbers generated",
+		Verified:    verified,
+		Incomplete:  incomplete,
+		Workers:     c.Size(),
+		NPBVersion:  "4.1",
+		CompileTime: "Unknown",
+		Compiler:    "Go",
+		Rand:        "randdp",
+	}
+	if b.timersEnabled {
+		result.Timers = []common.Timer{
+			{Name: "total", Seconds: timers.Read(T_TOTAL)},
+			{Name: "gaussian pairs", Seconds: timers.Read(T_GPAIRS)},
+			{Name: "random numbers", Seconds: timers.Read(T_RANDN)},
+		}
+	}
+	if c.Rank() != 0 {
+		return Result{result, sx, sy}, nil
+	}
+	common.Finish(&result, out)
+	if b.timersEnabled {
+		tm = cmp.Or(tm, 1.0)
+		fmt.Fprintf(out, "\nTotal time:     %9.3f (%6.2f)\n", timers.Read(T_TOTAL), timers.Read(T_TOTAL)*100.0/tm)
+		fmt.Fprintf(out, "Gaussian pairs: %9.3f (%6.2f)\n", timers.Read(T_GPAIRS), timers.Read(T_GPAIRS)*100.0/tm)
+		fmt.Fprintf(out, "Random numbers: %9.3f (%6.2f)\n", timers.Read(T_RANDN), timers.Read(T_RANDN)*100.0/tm)
+	}
+	if incomplete {
+		return Result{result, sx, sy}, &common.IncompleteError{Completed: batches, Planned: np, Err: ctx.Err()}
+	}
+	return Result{result, sx, sy}, nil
+}
@@ -0,0 +1,460 @@
+// Package is is the IS (Integer Sort) kernel: it ranks a large sequence of
+// integer keys with a bucket sort. Every rank generates a block of the keys
+// and counts them per bucket; the bucket counts are summed over the ranks,
+// each rank takes a run of consecutive buckets holding about the same
+// number of keys, and the keys are exchanged so that every rank receives
+// those of its buckets and ranks them.
+package is
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/iyisakuma/NPB-GO/NPB-CHANNEL/IS/params"
+	"github.com/iyisakuma/NPB-GO/NPB-CHANNEL/IS/types"
+	"github.com/iyisakuma/NPB-GO/NPB-CHANNEL/comm"
+	"github.com/iyisakuma/NPB-GO/NPB-CHANNEL/common"
+)
+
+const (
+	T_BENCHMARKING    = 0
+	T_INITIALIZATION  = 1
+	T_SORTING         = 2
+	T_TOTAL_EXECUTION = 3
+	MAX_ITERATIONS    = 10
+	TEST_ARRAY_SIZE   = 5
+)
+
+// Config selects the problem a Run solves
+type Config struct {
+	Params  params.Params // problem size, from params.Lookup or params.Custom
+	Workers int           // ranks; 0 means $GO_NUM_THREADS, or one per CPU; ignored under comm.Launch
+	Out     io.Writer     // progress messages and the NPB banner, discarded when nil
+}
+
+// Result is the outcome of an IS run with its verification counts
+type Result struct {
+	common.Result
+	PartialPassed int  // partial verifications passed over the timed iterations
+	FullVerified  bool // whether the final key sequence was sorted
+}
+
+// Run ranks the keys of cfg.Params and returns the result. When ctx is done
+// between two iterations the run stops there and Run returns the partial result
+// with a *common.IncompleteError.
+func Run(ctx context.Context, cfg Config) (Result, error) {
+	if err := ctx.Err(); err != nil {
+		return Result{}, err
+	}
+	workers, err := common.ResolveWorkers(cfg.Workers)
+	if err != nil {
+		return Result{}, err
+	}
+	b := NewISBenchmark(cfg.Params, workers)
+	if cfg.Out != nil {
+		b.out = cfg.Out
+	}
+	results := make(chan Result, 1)
+	errs := make(chan error, 1)
+	err = comm.Spawn(b.numRanks, func(c *comm.Comm) {
+		result, err := b.newRank(c).run(ctx)
+		if c.Leader() {
+			results <- result
+			errs <- err
+		}
+	})
+	if err != nil {
+		return Result{}, err
+	}
+	return <-results, <-errs
+}
+
+// ISBenchmark holds what the ranks of one IS run share before they start
+type ISBenchmark struct {
+	params     params.Params
+	totalKeys  int
+	maxKey     int
+	numBuckets int
+	shift      int // bucket of a key is key >> shift
+
+	numRanks int
+	out      io.Writer
+}
+
+// NewISBenchmark creates a new IS benchmark instance running on numRanks
+// ranks, or on the ranks started by comm.Launch. There are never so many
+// ranks that the keys planted by each iteration leave rank 0.
+func NewISBenchmark(p params.Params, numRanks int) *ISBenchmark {
+	if n, ok := comm.Launched(); ok {
+		numRanks = n
+	}
+	totalKeys := 1 << p.TOTAL_KEYS_LOG_2
+	numRanks = max(min(numRanks, totalKeys/(2*MAX_ITERATIONS+1)), 1)
+
+	return &ISBenchmark{
+		params:     p,
+		totalKeys:  totalKeys,
+		maxKey:     1 << p.MAX_KEY_LOG_2,
+		numBuckets: 1 << p.NUM_BUCKETS_LOG_2,
+		shift:      p.MAX_KEY_LOG_2 - p.NUM_BUCKETS_LOG_2,
+		numRanks:   numRanks,
+		out:        io.Discard,
+	}
+}
+
+// isRank is the state of one rank
+type isRank struct {
+	*ISBenchmark
+	c   *comm.Comm
+	out io.Writer // b.out on rank 0, discarded on the others
+
+	firstKey int              // global index of keys[0]
+	keys     []types.INT_TYPE // this rank's block of the key sequence
+	counts   []types.INT_TYPE // keys per bucket, followed by the test keys
+	send     [][]types.INT_TYPE
+	recv     [][]types.INT_TYPE // keys of this rank's buckets, by sender
+	ranks    []types.INT_TYPE   // keys up to each value of this rank's range
+	keyLo    types.INT_TYPE     // smallest key value of this rank's buckets
+	keyHi    types.INT_TYPE     // one past the largest
+	lesser   types.INT_TYPE     // keys below keyLo over all ranks
+	passed   int
+	timers   common.Timers
+	timersOn bool
+}
+
+func (b *ISBenchmark) newRank(c *comm.Comm) *isRank {
+	mq := (b.totalKeys + c.Size() - 1) / c.Size()
+	k1 := min(mq*c.Rank(), b.totalKeys)
+	k2 := min(k1+mq, b.totalKeys)
+	r := &isRank{
+		ISBenchmark: b,
+		c:           c,
+		out:         io.Discard,
+		firstKey:    k1,
+		keys:        make([]types.INT_TYPE, k2-k1),
+		counts:      make([]types.INT_TYPE, b.numBuckets+TEST_ARRAY_SIZE),
+		send:        make([][]types.INT_TYPE, c.Size()),
+	}
+	if c.Rank() == 0 {
+		r.out = b.out
+	}
+	if _, err := os.Stat("timer.flag"); err == nil {
+		r.timersOn = true
+	}
+	return r
+}
+
+// findMySeed returns the seed of the random number sequence after the
+// numbers of the first kn of np blocks of nn numbers
+func findMySeed(kn int, np int, nn int64, s float64, a float64) float64 {
+	if kn == 0 {
+		return s
+	}
+
+	mq := (nn/4 + int64(np) - 1) / int64(np)
+	nq := mq * 4 * int64(kn) // number of rans to be skipped
+
+	t1 := s
+	t2 := a
+	kk := nq
+	for kk > 1 {
+		ik := kk / 2
+		if 2*ik == kk {
+			common.Randlc(&t2, t2)
+			kk = ik
+		} else {
+			common.Randlc(&t1, t2)
+			kk = kk - 1
+		}
+	}
+	common.Randlc(&t1, t2)
+	return t1
+}
+
+// createSeq generates this rank's block of the keys, the same keys a single
+// rank would generate at those positions
+func (r *isRank) createSeq(seed float64, a float64) {
+	s := findMySeed(r.c.Rank(), r.c.Size(), int64(4*r.totalKeys), seed, a)
+	k := float64(r.maxKey / 4)
+	for i := range r.keys {
+		x := common.Randlc(&s, a)
+		x += common.Randlc(&s, a)
+		x += common.Randlc(&s, a)
+		x += common.Randlc(&s, a)
+		r.keys[i] = types.INT_TYPE(k * x)
+	}
+}
+
+// rank ranks the keys of one iteration
+func (r *isRank) rank(iteration types.INT_TYPE) {
+	size := r.c.Size()
+
+	// Set test values, which lie in the block of rank 0
+	if r.c.Rank() == 0 {
+		r.keys[iteration] = iteration
+		r.keys[iteration+MAX_ITERATIONS] = types.INT_TYPE(r.maxKey) - iteration
+	}
+
+	// Count the keys of each bucket, and hide the partial verification test
+	// keys of this rank's block after the counts so that the reduction hands
+	// them to every rank
+	clear(r.counts)
+	for _, k := range r.keys {
+		r.counts[k>>r.shift]++
+	}
+	local := append([]types.INT_TYPE(nil), r.counts[:r.numBuckets]...)
+	for i, index := range r.params.TEST_INDEX_ARRAY {
+		if j := int(index) - r.firstKey; 0 <= j && j < len(r.keys) {
+			r.counts[r.numBuckets+i] = r.keys[j]
+		}
+	}
+	comm.Allreduce(r.c, r.counts, comm.Sum)
+
+	// Give every rank consecutive buckets until it holds its share of keys;
+	// first[d] is the first bucket of rank d
+	first := make([]int, size+1)
+	first[size] = r.numBuckets
+	d, total := 0, 0
+	for i := 0; i < r.numBuckets; i++ {
+		total += int(r.counts[i])
+		for d < size-1 && total*size >= (d+1)*r.totalKeys {
+			d++
+			first[d] = i + 1
+		}
+	}
+	for d++; d < size; d++ {
+		first[d] = r.numBuckets
+	}
+
+	// Send every key to the rank of its bucket
+	owner := make([]int, r.numBuckets)
+	for d := 0; d < size; d++ {
+		n := types.INT_TYPE(0)
+		for i := first[d]; i < first[d+1]; i++ {
+			owner[i] = d
+			n += local[i]
+		}
+		r.send[d] = r.send[d][:0]
+		if cap(r.send[d]) < int(n) {
+			r.send[d] = make([]types.INT_TYPE, 0, n)
+		}
+	}
+	for _, k := range r.keys {
+		d := owner[k>>r.shift]
+		r.send[d] = append(r.send[d], k)
+	}
+	r.recv = comm.Alltoall(r.c, r.send)
+
+	// Rank the keys received: count the population of each key value and
+	// accumulate it, not forgetting the lesser keys on the ranks before
+	rank := r.c.Rank()
+	r.keyLo = types.INT_TYPE(first[rank]) << r.shift
+	r.keyHi = types.INT_TYPE(first[rank+1]) << r.shift
+	r.lesser = 0
+	for i := 0; i < first[rank]; i++ {
+		r.lesser += r.counts[i]
+	}
+	if len(r.ranks) < int(r.keyHi-r.keyLo) {
+		r.ranks = make([]types.INT_TYPE, r.keyHi-r.keyLo)
+	}
+	r.ranks = r.ranks[:r.keyHi-r.keyLo]
+	clear(r.ranks)
+	for _, block := range r.recv {
+		for _, k := range block {
+			r.ranks[k-r.keyLo]++
+		}
+	}
+	for i := 1; i < len(r.ranks); i++ {
+		r.ranks[i] += r.ranks[i-1]
+	}
+
+	// Partial verification, by the rank whose keys include the one below
+	// each test key
+	for i := 0; i < TEST_ARRAY_SIZE; i++ {
+		k := r.counts[r.numBuckets+i]
+		if 0 < k && k <= types.INT_TYPE(r.totalKeys-1) && r.keyLo <= k-1 && k-1 < r.keyHi {
+			keyRank := r.ranks[k-1-r.keyLo] + r.lesser
+			if r.params.Verifier.Do(i, iteration, keyRank, r.params.TEXT_RANK_ARRAY[:], &r.passed) {
+				fmt.Fprintf(r.out, "Failed partial verification: iteration %d, test key %d\n", iteration, i)
+			}
+		}
+	}
+}
+
+// fullVerify sorts the keys of the last iteration with the ranks computed
+// for them and returns the number of keys out of order: within each rank,
+// and between the last key of a rank and the first of the next one with keys
+func (r *isRank) fullVerify() types.INT_TYPE {
+	n := 0
+	for _, block := range r.recv {
+		n += len(block)
+	}
+	sorted := make([]types.INT_TYPE, n)
+	for _, block := range r.recv {
+		for _, k := range block {
+			r.ranks[k-r.keyLo]--
+			sorted[r.ranks[k-r.keyLo]] = k
+		}
+	}
+
+	outOfSort := types.INT_TYPE(0)
+	for i := 1; i < n; i++ {
+		if sorted[i-1] > sorted[i] {
+			outOfSort++
+		}
+	}
+
+	// Number of keys, first and last key of every rank
+	ends := []types.INT_TYPE{types.INT_TYPE(n), 0, 0}
+	if n > 0 {
+		ends[1], ends[2] = sorted[0], sorted[n-1]
+	}
+	all := make([]types.INT_TYPE, 3*r.c.Size())
+	comm.Allgather(r.c, ends, all)
+	last := types.INT_TYPE(-1)
+	for d := 0; d < r.c.Size(); d++ {
+		if all[3*d] == 0 {
+			continue
+		}
+		if all[3*d+1] < last {
+			outOfSort++
+		}
+		last = all[3*d+2]
+	}
+	return outOfSort
+}
+
+// run is the program of one rank; only rank 0 prints and its result is the
+// one Run returns
+func (r *isRank) run(ctx context.Context) (Result, error) {
+	r.timers.Clear(T_BENCHMARKING)
+	if r.timersOn {
+		r.timers.Clear(T_INITIALIZATION)
+		r.timers.Clear(T_SORTING)
+		r.timers.Clear(T_TOTAL_EXECUTION)
+		r.timers.Start(T_TOTAL_EXECUTION)
+	}
+
+	fmt.Fprintf(r.out, "\n\n NAS Parallel Benchmarks 4.1 Go Channel version - IS Benchmark\n\n")
+	fmt.Fprintf(r.out, " Size:  %d  (class %s)\n", r.totalKeys, r.params.CLASS)
+	fmt.Fprintf(r.out, " Iterations:   %d\n", MAX_ITERATIONS)
+	fmt.Fprintf(r.out, " Number of ranks: %d\n", r.c.Size())
+	fmt.Fprintf(r.out, "\n")
+
+	if r.timersOn {
+		r.timers.Start(T_INITIALIZATION)
+	}
+	r.createSeq(314159265.00, 1220703125.00)
+	if r.timersOn {
+		r.timers.Stop(T_INITIALIZATION)
+	}
+
+	// Do one iteration for free (i.e., untimed) to guarantee initialization
+	r.rank(1)
+	r.passed = 0
+
+	if r.params.CLASS != "S" {
+		fmt.Fprintln(r.out, "\n   iteration")
+	}
+
+	r.c.Barrier()
+	r.timers.Start(T_BENCHMARKING)
+
+	// Rank 0 decides whether the next iteration runs, so that all ranks
+	// stop after the same one
+	iterations := MAX_ITERATIONS
+	stop := []int{0}
+	for iteration := types.INT_TYPE(1); iteration <= MAX_ITERATIONS; iteration++ {
+		if r.c.Rank() == 0 && ctx.Err() != nil {
+			stop[0] = 1
+		}
+		comm.Bcast(r.c, 0, stop)
+		if stop[0] != 0 {
+			iterations = int(iteration) - 1
+			break
+		}
+		if r.params.CLASS != "S" {
+			fmt.Fprintf(r.out, "        %d\n", iteration)
+		}
+		r.rank(iteration)
+	}
+
+	r.timers.Stop(T_BENCHMARKING)
+	timecounter := r.timers.Read(T_BENCHMARKING)
+	incomplete := iterations < MAX_ITERATIONS
+	if incomplete {
+		fmt.Fprintf(r.out, "\n Benchmark stopped after %d of %d iterations\n", iterations, MAX_ITERATIONS)
+	}
+
+	// This tests that keys are in sequence: sorting of last ranked key seq
+	if r.timersOn {
+		r.timers.Start(T_SORTING)
+	}
+	verify := []types.INT_TYPE{types.INT_TYPE(r.passed), 0}
+	if !incomplete {
+		verify[1] = r.fullVerify()
+	}
+	comm.Allreduce(r.c, verify[:1], comm.Sum)
+	partialPassed := int(verify[0])
+	fullVerified := !incomplete && verify[1] == 0
+	if r.timersOn {
+		r.timers.Stop(T_SORTING)
+		r.timers.Stop(T_TOTAL_EXECUTION)
+	}
+	if !incomplete && verify[1] != 0 {
+		fmt.Fprintf(r.out, "Full_verify: number of keys out of sort: %d\n", verify[1])
+	}
+	if want := TEST_ARRAY_SIZE * iterations; partialPassed != want && r.params.CLASS != params.UserClass {
+		fmt.Fprintf(r.out, " Partial verification: %d of %d tests passed\n", partialPassed, want)
+	}
+
+	mops := 0.0
+	if timecounter > 0 {
+		mops = float64(iterations*r.totalKeys) / timecounter / 1000000.0
+	}
+	result := common.Result{
+		Kernel:      "IS",
+		Class:       r.params.CLASS,
+		Size:        [3]int{r.totalKeys, 0, 0},
+		Iterations:  iterations,
+		Time:        timecounter,
+		Mops:        mops,
+		OpType:      "keys ranked",
+		Verified:    fullVerified && partialPassed == TEST_ARRAY_SIZE*MAX_ITERATIONS,
+		Incomplete:  incomplete,
+		Workers:     r.c.Size(),
+		NPBVersion:  "4.1",
+		CompileTime: "Unknown",
+		Compiler:    "Go",
+	}
+	if r.timersOn {
+		result.Timers = []common.Timer{
+			{Name: "total execution", Seconds: r.timers.Read(T_TOTAL_EXECUTION)},
+			{Name: "initialization", Seconds: r.timers.Read(T_INITIALIZATION)},
+			{Name: "benchmarking", Seconds: r.timers.Read(T_BENCHMARKING)},
+			{Name: "sorting", Seconds: r.timers.Read(T_SORTING)},
+		}
+	}
+	if r.c.Rank() != 0 {
+		return Result{result, partialPassed, fullVerified}, nil
+	}
+	common.Finish(&result, r.out)
+
+	if r.timersOn {
+		tTotal := r.timers.Read(T_TOTAL_EXECUTION)
+		fmt.Fprintf(r.out, "\nAdditional timers -\n")
+		fmt.Fprintf(r.out, " Total execution: %8.3f\n", tTotal)
+		if tTotal == 0.0 {
+			tTotal = 1.0
+		}
+		for _, t := range result.Timers[1:] {
+			fmt.Fprintf(r.out, " %-15s: %8.3f (%5.2f%%)\n", t.Name, t.Seconds, t.Seconds/tTotal*100.0)
+		}
+	}
+	if incomplete {
+		return Result{result, partialPassed, fullVerified}, &common.IncompleteError{Completed: iterations, Planned: MAX_ITERATIONS, Err: ctx.Err()}
+	}
+	return Result{result, partialPassed, fullVerified}, nil
+}
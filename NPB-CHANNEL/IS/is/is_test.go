@@ -0,0 +1,138 @@
+package is
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"os"
+	"testing"
+
+	"github.com/iyisakuma/NPB-GO/NPB-CHANNEL/IS/params"
+	"github.com/iyisakuma/NPB-GO/NPB-CHANNEL/comm"
+	"github.com/iyisakuma/NPB-GO/NPB-CHANNEL/common"
+	"github.com/iyisakuma/NPB-GO/NPB-CHANNEL/common/npbtest"
+)
+
+var long = flag.Bool("long", false, "also verify class A")
+
+// testClasses lists the classes the verification tests run
+func testClasses() []string {
+	if *long {
+		return []string{"S", "W", "A"}
+	}
+	return []string{"S", "W"}
+}
+
+func BenchmarkIS(b *testing.B) {
+	for _, class := range []string{"S", "W"} {
+		b.Run(class, func(b *testing.B) {
+			p, _ := params.Lookup(class)
+			mops := 0.0
+			for i := 0; i < b.N; i++ {
+				result, err := Run(context.Background(), Config{Params: p})
+				if err != nil {
+					b.Fatal(err)
+				}
+				if result.Failed() {
+					b.Fatalf("class %s failed verification", class)
+				}
+				mops += result.Mops
+			}
+			b.ReportMetric(mops/float64(b.N), "Mop/s")
+		})
+	}
+}
+
+func TestVerification(t *testing.T) {
+	for _, class := range testClasses() {
+		t.Run(class, func(t *testing.T) {
+			p, _ := params.Lookup(class)
+			result, err := Run(context.Background(), Config{Params: p})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := TEST_ARRAY_SIZE * MAX_ITERATIONS; result.PartialPassed != want {
+				t.Errorf("%d partial verifications passed, want %d", result.PartialPassed, want)
+			}
+			if !result.FullVerified {
+				t.Error("keys are not sorted after the last iteration")
+			}
+			if !result.Verified {
+				t.Error("kernel reported a failed verification")
+			}
+		})
+	}
+}
+
+// TestRanks checks the ranking with bucket ranges split across several ranks
+func TestRanks(t *testing.T) {
+	p, _ := params.Lookup("S")
+	for _, ranks := range []int{1, 3, 8} {
+		result, err := Run(context.Background(), Config{Params: p, Workers: ranks})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Workers != ranks || result.PartialPassed != TEST_ARRAY_SIZE*MAX_ITERATIONS || !result.FullVerified || !result.Verified {
+			t.Errorf("%d ranks: ran on %d, %d partial verifications passed, full %v, verified %v",
+				ranks, result.Workers, result.PartialPassed, result.FullVerified, result.Verified)
+		}
+	}
+}
+
+// TestLaunch runs class S as three processes that talk over TCP: the test
+// binary starts itself once per rank
+func TestLaunch(t *testing.T) {
+	p, _ := params.Lookup("S")
+	if _, ok := comm.Launched(); ok {
+		result, err := Run(context.Background(), Config{Params: p})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if comm.Root() && (result.Workers != 3 || !result.Verified) {
+			t.Errorf("got %d ranks, %d partial verifications passed, fully verified %v", result.Workers, result.PartialPassed, result.FullVerified)
+		}
+		return
+	}
+	var out bytes.Buffer
+	if err := comm.Launch(context.Background(), 3, os.Args[0], []string{"-test.run=^TestLaunch$"}, &out, &out); err != nil {
+		t.Fatalf("%v\n%s", err, out.String())
+	}
+}
+
+// TestFewKeysPerRank checks that a run asked for more ranks than its keys
+// allow uses fewer ranks and still sorts the keys
+func TestFewKeysPerRank(t *testing.T) {
+	p, err := params.Custom(8, 11, 9)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := Run(context.Background(), Config{Params: p, Workers: 64})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (1 << 8) / (2*MAX_ITERATIONS + 1); result.Workers != want {
+		t.Errorf("ran on %d ranks, want %d", result.Workers, want)
+	}
+	if !result.FullVerified {
+		t.Error("keys are not sorted after the last iteration")
+	}
+}
+
+// runClass runs the kernel at class for the checks every kernel shares
+func runClass(ctx context.Context, class string) (common.Result, error) {
+	p, _ := params.Lookup(class)
+	result, err := Run(ctx, Config{Params: p})
+	return result.Result, err
+}
+
+// TestConcurrentRuns checks that runs of two classes in one process do not
+// share state
+func TestConcurrentRuns(t *testing.T) {
+	npbtest.ConcurrentRuns(t, runClass, "S", "W")
+}
+
+// TestStoppedRun checks a run stopped by its context. Run checks once before
+// starting and then before every iteration
+func TestStoppedRun(t *testing.T) {
+	npbtest.StoppedRun(t, runClass, "S", "iterations")
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/iyisakuma/NPB-GO/NPB-CHANNEL/IS/is"
+	"github.com/iyisakuma/NPB-GO/NPB-CHANNEL/IS/params"
+	"github.com/iyisakuma/NPB-GO/NPB-CHANNEL/comm"
+	"github.com/iyisakuma/NPB-GO/NPB-CHANNEL/common"
+)
+
+func main() {
+	class := flag.String("class", "S", "problem class (S, W, A, B, C, D or U)")
+	keysLog2 := flag.Int("keys-log2", 16, "class U: log2 of the number of keys")
+	maxKeyLog2 := flag.Int("max-key-log2", 11, "class U: log2 of the key range")
+	bucketsLog2 := flag.Int("buckets-log2", 9, "class U: log2 of the number of buckets")
+	format := flag.String("format", "text", "result format: text or json")
+	results := flag.String("results", "", "append the result to this .csv or .jsonl file")
+	workers := flag.Int("workers", 0, "ranks (0 means $GO_NUM_THREADS, or one per CPU; ignored under npbrun)")
+	maxProcs := flag.Int("gomaxprocs", 0, "goroutines running at once (0 means $GOMAXPROCS, or one per CPU)")
+	timeout := flag.Duration("timeout", 0, "stop after this long and report the iterations completed (0 for no limit)")
+	flag.Parse()
+
+	if err := common.SetFormat(*format); err != nil {
+		fmt.Fprintf(os.Stderr, "is: %v\n", err)
+		os.Exit(1)
+	}
+	out := common.Progress()
+	if err := common.SetResultsFile(*results); err != nil {
+		fmt.Fprintf(os.Stderr, "is: %v\n", err)
+		os.Exit(1)
+	}
+	if err := common.SetMaxProcs(*maxProcs); err != nil {
+		fmt.Fprintf(os.Stderr, "is: %v\n", err)
+		os.Exit(1)
+	}
+
+	var p params.Params
+	if strings.EqualFold(*class, params.UserClass) {
+		var err error
+		p, err = params.Custom(*keysLog2, *maxKeyLog2, *bucketsLog2)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "is: class U: %v\n", err)
+			os.Exit(1)
+		}
+	} else {
+		var ok bool
+		p, ok = params.Lookup(*class)
+		if !ok {
+			common.PrintClassUsage(out, "is", *class, append(params.Classes, params.UserClass))
+			os.Exit(1)
+		}
+	}
+
+	ctx, stop := common.RunContext(*timeout)
+	defer stop()
+	result, err := is.Run(ctx, is.Config{Params: p, Workers: *workers, Out: out})
+	if err != nil && !result.Incomplete {
+		fmt.Fprintf(os.Stderr, "is: %v\n", err)
+		os.Exit(1)
+	}
+	if !comm.Root() {
+		// Rank 0 reports the run of the ranks started by npbrun
+		return
+	}
+	common.Report(&result.Result)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "is: %v\n", err)
+		os.Exit(1)
+	}
+	if result.Failed() {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,112 @@
+package params
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/iyisakuma/NPB-GO/NPB-CHANNEL/IS/types"
+	"github.com/iyisakuma/NPB-GO/NPB-CHANNEL/IS/verifier"
+)
+
+// Params holds the key counts and partial verification data of one IS class
+type Params struct {
+	CLASS             string
+	TOTAL_KEYS_LOG_2  int
+	MAX_KEY_LOG_2     int
+	NUM_BUCKETS_LOG_2 int
+	TEST_INDEX_ARRAY  [5]types.INT_TYPE
+	TEXT_RANK_ARRAY   [5]types.INT_TYPE
+	Verifier          verifier.PartialVerifier
+}
+
+// Classes lists the standard problem classes in increasing size
+var Classes = []string{"S", "W", "A", "B", "C", "D"}
+
+var table = map[string]Params{
+	"S": {
+		CLASS:             "S",
+		TOTAL_KEYS_LOG_2:  16,
+		MAX_KEY_LOG_2:     11,
+		NUM_BUCKETS_LOG_2: 9,
+		TEST_INDEX_ARRAY:  [5]types.INT_TYPE{48427, 17148, 23627, 62548, 4431},
+		TEXT_RANK_ARRAY:   [5]types.INT_TYPE{0, 18, 346, 64917, 65463},
+		Verifier:          &verifier.ClassSVerifier{},
+	},
+	"W": {
+		CLASS:             "W",
+		TOTAL_KEYS_LOG_2:  20,
+		MAX_KEY_LOG_2:     16,
+		NUM_BUCKETS_LOG_2: 10,
+		TEST_INDEX_ARRAY:  [5]types.INT_TYPE{357773, 934767, 875723, 898999, 404505},
+		TEXT_RANK_ARRAY:   [5]types.INT_TYPE{1249, 11698, 1039987, 1043896, 1048018},
+		Verifier:          &verifier.ClassWVerifier{},
+	},
+	"A": {
+		CLASS:             "A",
+		TOTAL_KEYS_LOG_2:  23,
+		MAX_KEY_LOG_2:     19,
+		NUM_BUCKETS_LOG_2: 10,
+		TEST_INDEX_ARRAY:  [5]types.INT_TYPE{2112377, 662041, 5336171, 3642833, 4250760},
+		TEXT_RANK_ARRAY:   [5]types.INT_TYPE{104, 17523, 123928, 8288932, 8388264},
+		Verifier:          &verifier.ClassAVerifier{},
+	},
+	"B": {
+		CLASS:             "B",
+		TOTAL_KEYS_LOG_2:  25,
+		MAX_KEY_LOG_2:     21,
+		NUM_BUCKETS_LOG_2: 10,
+		TEST_INDEX_ARRAY:  [5]types.INT_TYPE{41869, 812306, 5102857, 18232239, 26860214},
+		TEXT_RANK_ARRAY:   [5]types.INT_TYPE{33422937, 10244, 59149, 33135281, 99},
+		Verifier:          &verifier.ClassBVerifier{},
+	},
+	"C": {
+		CLASS:             "C",
+		TOTAL_KEYS_LOG_2:  27,
+		MAX_KEY_LOG_2:     23,
+		NUM_BUCKETS_LOG_2: 10,
+		TEST_INDEX_ARRAY:  [5]types.INT_TYPE{44172927, 72999161, 74326391, 129606274, 21736814},
+		TEXT_RANK_ARRAY:   [5]types.INT_TYPE{61147, 882988, 266290, 133997595, 133525895},
+		Verifier:          &verifier.ClassCVerifier{},
+	},
+	"D": {
+		CLASS:             "D",
+		TOTAL_KEYS_LOG_2:  31,
+		MAX_KEY_LOG_2:     27,
+		NUM_BUCKETS_LOG_2: 10,
+		TEST_INDEX_ARRAY:  [5]types.INT_TYPE{1317351170, 995930646, 1157283250, 1503301535, 1453734525},
+		TEXT_RANK_ARRAY:   [5]types.INT_TYPE{1, 36538729, 1978098519, 2145192618, 2147425337},
+		Verifier:          &verifier.ClassDVerifier{},
+	},
+}
+
+// UserClass selects a problem size supplied on the command line
+const UserClass = "U"
+
+// Lookup returns the parameters of the given class
+func Lookup(class string) (Params, bool) {
+	p, ok := table[strings.ToUpper(class)]
+	return p, ok
+}
+
+// Custom returns class U parameters for 2^totalKeysLog2 keys in the range
+// [0, 2^maxKeyLog2). The partial verification is skipped since there are
+// no reference ranks for such a size.
+func Custom(totalKeysLog2, maxKeyLog2, numBucketsLog2 int) (Params, error) {
+	// rank() plants keys 1..10 and maxKey-10..maxKey-1 at the first 20
+	// positions on every iteration, which bounds the smallest sizes.
+	switch {
+	case totalKeysLog2 < 5 || totalKeysLog2 > 31:
+		return Params{}, fmt.Errorf("total keys log2 must be between 5 and 31, got %d", totalKeysLog2)
+	case maxKeyLog2 < 4 || maxKeyLog2 > 31:
+		return Params{}, fmt.Errorf("max key log2 must be between 4 and 31, got %d", maxKeyLog2)
+	case numBucketsLog2 < 0 || numBucketsLog2 > maxKeyLog2:
+		return Params{}, fmt.Errorf("buckets log2 must be between 0 and the max key log2, got %d", numBucketsLog2)
+	}
+	return Params{
+		CLASS:             UserClass,
+		TOTAL_KEYS_LOG_2:  totalKeysLog2,
+		MAX_KEY_LOG_2:     maxKeyLog2,
+		NUM_BUCKETS_LOG_2: numBucketsLog2,
+		Verifier:          &verifier.EmptyVerifier{},
+	}, nil
+}
@@ -0,0 +1,3 @@
+package types
+
+type INT_TYPE int64
@@ -0,0 +1,24 @@
+package verifier
+
+import (
+	"github.com/iyisakuma/NPB-GO/NPB-CHANNEL/IS/types"
+)
+
+type ClassWVerifier struct{}
+
+func (m *ClassWVerifier) Do(index int, iteration types.INT_TYPE, keyRank types.INT_TYPE, testRankArray []types.INT_TYPE, passedVerification *int) (failed bool) {
+	if index < 2 {
+		if keyRank != testRankArray[index]+(iteration-2) {
+			failed = true
+		} else {
+			*passedVerification++
+		}
+	} else {
+		if keyRank != testRankArray[index]-iteration {
+			failed = true
+		} else {
+			*passedVerification++
+		}
+	}
+	return
+}
@@ -0,0 +1,228 @@
+// Package comm lets the ranks of a kernel exchange messages over channels, in
+// the manner of MPI: ranks share no slices, and everything one rank needs
+// from another is sent to it. Every ordered pair of ranks has a channel of
+// its own, so messages from one rank to another arrive in the order they
+// were sent.
+//
+// The ranks are goroutines of one process, unless the process was started by
+// Launch: then it runs a single rank, and the messages between ranks travel
+// over TCP connections on the loopback interface.
+//
+// The collective operations must be called by every rank of the group, in
+// the same order. They combine and hand out values in rank order, so all
+// ranks get bit-identical results and runs are reproducible.
+package comm
+
+import "fmt"
+
+// depth is the number of messages from one rank to another that may wait to
+// be received before Send blocks
+const depth = 16
+
+// Comm is the endpoint of one rank in a group of ranks
+type Comm struct {
+	rank, size int
+	in         []chan any // in[from] carries the messages from rank from
+	out        []chan any // out[to] carries the messages to rank to
+	group      *group     // the connections to the other ranks, nil in-process
+}
+
+// Spawn runs body on size ranks, each in its own goroutine with its own
+// Comm, and returns when all of them have returned. In a process started by
+// Launch it runs body only for the rank of the process, connected to the
+// other processes, and size must be the number of ranks launched.
+func Spawn(size int, body func(c *Comm)) error {
+	if launched, ok := Launched(); ok {
+		if size != launched {
+			return fmt.Errorf("comm: the run takes %d ranks, but %d were launched", size, launched)
+		}
+		c, err := join()
+		if err != nil {
+			return err
+		}
+		body(c)
+		c.leave()
+		return nil
+	}
+
+	links := make([][]chan any, size) // links[from][to]
+	for from := range links {
+		links[from] = make([]chan any, size)
+		for to := range links[from] {
+			links[from][to] = make(chan any, depth)
+		}
+	}
+
+	done := make(chan struct{})
+	for rank := 0; rank < size; rank++ {
+		c := &Comm{rank: rank, size: size, in: make([]chan any, size), out: links[rank]}
+		for from := range c.in {
+			c.in[from] = links[from][rank]
+		}
+		go func() {
+			defer func() { done <- struct{}{} }()
+			body(c)
+		}()
+	}
+	for range size {
+		<-done
+	}
+	return nil
+}
+
+// Rank returns the number of this rank, from 0 to Size()-1
+func (c *Comm) Rank() int {
+	return c.rank
+}
+
+// Size returns the number of ranks in the group
+func (c *Comm) Size() int {
+	return c.size
+}
+
+// Leader reports whether c is the first rank that runs in this process: rank
+// 0 of a group spawned in-process, the only rank of a process started by
+// Launch. The kernels return the result of their leader.
+func (c *Comm) Leader() bool {
+	return c.group != nil || c.rank == 0
+}
+
+// Number is the element type of the slices that can be reduced
+type Number interface {
+	~int | ~int32 | ~int64 | ~float64
+}
+
+// Op combines the values of two ranks in a reduction
+type Op int
+
+const (
+	Sum Op = iota
+	Max
+	Min
+)
+
+func combine[T Number](op Op, acc, x []T) {
+	for i, v := range x {
+		switch op {
+		case Sum:
+			acc[i] += v
+		case Max:
+			acc[i] = max(acc[i], v)
+		case Min:
+			acc[i] = min(acc[i], v)
+		}
+	}
+}
+
+// Send sends a copy of data to rank to, so the caller may reuse data as
+// soon as Send returns
+func Send[T any](c *Comm, to int, data []T) {
+	if c.group != nil {
+		c.out[to] <- encode(c, data)
+		return
+	}
+	msg := make([]T, len(data))
+	copy(msg, data)
+	c.out[to] <- msg
+}
+
+// Recv returns the next message from rank from, which must have been sent
+// with the same element type
+func Recv[T any](c *Comm, from int) []T {
+	msg := c.recv(from)
+	if f, ok := msg.(frame); ok {
+		return decode[T](c, f)
+	}
+	return msg.([]T)
+}
+
+// recv returns the next message from rank from
+func (c *Comm) recv(from int) any {
+	msg, ok := <-c.in[from]
+	if !ok {
+		c.abort("rank %d left the group before sending", from)
+	}
+	return msg
+}
+
+// Barrier returns once every rank has called it
+func (c *Comm) Barrier() {
+	if c.rank == 0 {
+		for from := 1; from < c.size; from++ {
+			c.recv(from)
+		}
+		for to := 1; to < c.size; to++ {
+			c.out[to] <- struct{}{}
+		}
+		return
+	}
+	c.out[0] <- struct{}{}
+	c.recv(0)
+}
+
+// Allreduce combines data element by element across the ranks with op and
+// leaves the result in data on every rank
+func Allreduce[T Number](c *Comm, data []T, op Op) {
+	if c.rank != 0 {
+		Send(c, 0, data)
+		copy(data, Recv[T](c, 0))
+		return
+	}
+	for from := 1; from < c.size; from++ {
+		combine(op, data, Recv[T](c, from))
+	}
+	for to := 1; to < c.size; to++ {
+		Send(c, to, data)
+	}
+}
+
+// Bcast copies data of rank root into data on every other rank
+func Bcast[T any](c *Comm, root int, data []T) {
+	if c.rank != root {
+		copy(data, Recv[T](c, root))
+		return
+	}
+	for to := 0; to < c.size; to++ {
+		if to != root {
+			Send(c, to, data)
+		}
+	}
+}
+
+// Allgather concatenates the data of all ranks, in rank order, into all on
+// every rank. The ranks may contribute different lengths, but all must hold
+// the total.
+func Allgather[T any](c *Comm, data, all []T) {
+	for to := 0; to < c.size; to++ {
+		if to != c.rank {
+			Send(c, to, data)
+		}
+	}
+	offset := 0
+	for from := 0; from < c.size; from++ {
+		if from == c.rank {
+			offset += copy(all[offset:], data)
+		} else {
+			offset += copy(all[offset:], Recv[T](c, from))
+		}
+	}
+}
+
+// Alltoall sends a copy of send[to] to every rank to, this one included, and
+// returns the blocks received, the one from rank from at index from
+func Alltoall[T any](c *Comm, send [][]T) [][]T {
+	for to := 0; to < c.size; to++ {
+		if to != c.rank {
+			Send(c, to, send[to])
+		}
+	}
+	recv := make([][]T, c.size)
+	for from := 0; from < c.size; from++ {
+		if from == c.rank {
+			recv[from] = append([]T(nil), send[from]...)
+		} else {
+			recv[from] = Recv[T](c, from)
+		}
+	}
+	return recv
+}
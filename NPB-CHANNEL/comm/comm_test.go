@@ -0,0 +1,199 @@
+package comm
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// exercise runs every operation of the package on c and reports what went
+// wrong with errorf, which may be called from any rank
+func exercise(c *Comm, errorf func(format string, args ...any)) {
+	rank, size := c.Rank(), c.Size()
+
+	// Messages from one rank to another arrive in order, as copies of what
+	// was sent, whatever their element type
+	next := (rank + 1) % size
+	prev := (rank + size - 1) % size
+	data := []float64{float64(rank), 0.5}
+	for i := 0; i < 3*depth; i++ {
+		data[1] = float64(i)
+		Send(c, next, data)
+		data[1] = -1 // the message already holds a copy
+		got := Recv[float64](c, prev)
+		if want := []float64{float64(prev), float64(i)}; !slices.Equal(got, want) {
+			errorf("rank %d: message %d from rank %d = %v, want %v", rank, i, prev, got, want)
+		}
+	}
+	Send(c, next, []string{fmt.Sprint("from ", rank)})
+	if got, want := Recv[string](c, prev), fmt.Sprint("from ", prev); len(got) != 1 || got[0] != want {
+		errorf("rank %d: got %q, want [%q]", rank, got, want)
+	}
+	Send(c, next, []int32{})
+	if got := Recv[int32](c, prev); len(got) != 0 {
+		errorf("rank %d: got %v, want an empty slice", rank, got)
+	}
+
+	// Reductions of every op, on integers and floats
+	for _, tc := range []struct {
+		op         Op
+		ints, want []int64
+	}{
+		{Sum, []int64{int64(rank), 1}, []int64{int64(size * (size - 1) / 2), int64(size)}},
+		{Max, []int64{int64(rank), -int64(rank)}, []int64{int64(size - 1), 0}},
+		{Min, []int64{int64(rank), -int64(rank)}, []int64{0, -int64(size - 1)}},
+	} {
+		Allreduce(c, tc.ints, tc.op)
+		if !slices.Equal(tc.ints, tc.want) {
+			errorf("rank %d: Allreduce op %d = %v, want %v", rank, tc.op, tc.ints, tc.want)
+		}
+	}
+	floats := []float64{1.0 / float64(rank+1)}
+	Allreduce(c, floats, Sum)
+	sum := 0.0
+	for from := 0; from < size; from++ {
+		sum += 1.0 / float64(from+1)
+	}
+	if floats[0] != sum {
+		errorf("rank %d: Allreduce of floats = %v, want %v, added in rank order", rank, floats[0], sum)
+	}
+
+	// Bcast from every root
+	for root := 0; root < size; root++ {
+		v := []int{rank, rank}
+		Bcast(c, root, v)
+		if v[0] != root || v[1] != root {
+			errorf("rank %d: Bcast from %d gave %v", rank, root, v)
+		}
+	}
+
+	// Allgather of a block of rank+1 elements per rank
+	mine := make([]int, rank+1)
+	for i := range mine {
+		mine[i] = rank
+	}
+	all := make([]int, size*(size+1)/2)
+	Allgather(c, mine, all)
+	var want []int
+	for from := 0; from < size; from++ {
+		for range from + 1 {
+			want = append(want, from)
+		}
+	}
+	if !slices.Equal(all, want) {
+		errorf("rank %d: Allgather = %v, want %v", rank, all, want)
+	}
+
+	// Alltoall of a block of length to+1 holding the sender and the receiver
+	send := make([][]int, size)
+	for to := range send {
+		send[to] = slices.Repeat([]int{rank*100 + to}, to+1)
+	}
+	recv := Alltoall(c, send)
+	for from, block := range recv {
+		if want := slices.Repeat([]int{from*100 + rank}, rank+1); !slices.Equal(block, want) {
+			errorf("rank %d: Alltoall block from %d = %v, want %v", rank, from, block, want)
+		}
+	}
+	send[rank][0] = -1
+	if recv[rank][0] == -1 {
+		errorf("rank %d: Alltoall handed back its own block instead of a copy", rank)
+	}
+}
+
+// TestSpawn runs every operation on groups of goroutines
+func TestSpawn(t *testing.T) {
+	for _, size := range []int{1, 2, 3, 8} {
+		err := Spawn(size, func(c *Comm) {
+			if c.Size() != size || c.Leader() != (c.Rank() == 0) {
+				t.Errorf("rank %d of %d: size %d, leader %v", c.Rank(), size, c.Size(), c.Leader())
+			}
+			exercise(c, t.Errorf)
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// TestBarrier checks that no rank gets past a barrier before all have
+// reached it, phase after phase
+func TestBarrier(t *testing.T) {
+	const size, phases = 6, 200
+	var arrived [phases]atomic.Int32
+	var failures atomic.Int32
+	err := Spawn(size, func(c *Comm) {
+		for p := range arrived {
+			arrived[p].Add(1)
+			c.Barrier()
+			if arrived[p].Load() != size {
+				failures.Add(1)
+			}
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f := failures.Load(); f != 0 {
+		t.Errorf("%d ranks got past a barrier before all had arrived", f)
+	}
+}
+
+// TestLaunch runs every operation on four processes that talk over TCP: the
+// test binary starts itself once per rank
+func TestLaunch(t *testing.T) {
+	if size, ok := Launched(); ok {
+		var mu sync.Mutex
+		var failures []string
+		errorf := func(format string, args ...any) {
+			mu.Lock()
+			failures = append(failures, fmt.Sprintf(format, args...))
+			mu.Unlock()
+		}
+		err := Spawn(size, func(c *Comm) {
+			exercise(c, errorf)
+			for range 10 {
+				c.Barrier()
+			}
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, f := range failures {
+			t.Error(f)
+		}
+		return
+	}
+	var out bytes.Buffer
+	if err := Launch(context.Background(), 4, os.Args[0], []string{"-test.run=^TestLaunch$"}, &out, &out); err != nil {
+		t.Fatalf("%v\n%s", err, out.String())
+	}
+}
+
+// TestSpawnSize checks that a launched process refuses to run a group of
+// another size than was launched
+func TestSpawnSize(t *testing.T) {
+	t.Setenv(envSize, "4")
+	t.Setenv(envLauncher, "127.0.0.1:1")
+	if err := Spawn(3, func(c *Comm) { t.Error("body ran") }); err == nil {
+		t.Error("Spawn(3) in a process launched as one of 4 ranks succeeded")
+	}
+}
+
+// TestHeader checks that frame headers keep the length of messages of 4 GiB
+// and more, which no longer fits in 32 bits
+func TestHeader(t *testing.T) {
+	for _, n := range []int{0, 1, math.MaxUint32, math.MaxUint32 + 1, 5<<32 + 3, math.MaxInt} {
+		var h [headerSize]byte
+		putHeader(&h, kindEncoded, n)
+		if kind, got := readHeader(&h); kind != kindEncoded || got != uint64(n) {
+			t.Errorf("header of %d bytes read back as kind %d, %d bytes", n, kind, got)
+		}
+	}
+}
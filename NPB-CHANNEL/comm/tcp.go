@@ -0,0 +1,424 @@
+package comm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"os"
+	"os/exec"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"unsafe"
+)
+
+// Environment of the processes started by Launch
+const (
+	envRank     = "NPB_RANK"
+	envSize     = "NPB_SIZE"
+	envLauncher = "NPB_LAUNCHER" // address where the ranks exchange their own
+)
+
+// Kinds of the frames sent over TCP
+const (
+	kindToken   byte = iota // a Barrier token, with no payload
+	kindRaw                 // the memory of a slice of numbers
+	kindEncoded             // a gob encoded slice
+)
+
+// frame is a message received over TCP, decoded by Recv once the element
+// type is known
+type frame struct {
+	kind byte
+	data []byte
+}
+
+// headerSize is the length of the header that precedes every frame on the
+// wire: its kind, then the length of its data as 8 bytes, so that messages
+// of 4 GiB and more keep their length
+const headerSize = 9
+
+// putHeader writes to h the header of a frame of the given kind with n bytes
+// of data
+func putHeader(h *[headerSize]byte, kind byte, n int) {
+	h[0] = kind
+	binary.LittleEndian.PutUint64(h[1:], uint64(n))
+}
+
+// readHeader returns the kind and the data length of the header h
+func readHeader(h *[headerSize]byte) (kind byte, n uint64) {
+	return h[0], binary.LittleEndian.Uint64(h[1:])
+}
+
+// Launched reports whether this process is one of the ranks started by
+// Launch, and the number of ranks in its group
+func Launched() (size int, ok bool) {
+	size, err := strconv.Atoi(os.Getenv(envSize))
+	return size, err == nil && os.Getenv(envLauncher) != ""
+}
+
+// Root reports whether this process runs rank 0, the one that prints the
+// banner and reports the result. It is false only in the other processes
+// started by Launch.
+func Root() bool {
+	_, launched := Launched()
+	return !launched || os.Getenv(envRank) == "0"
+}
+
+// Launch runs the command name with args as size processes, the ranks of one
+// group, and waits for all of them to exit. The ranks find each other through
+// a listener of Launch on the loopback interface, then every pair of ranks
+// talks over a TCP connection of its own. When a rank fails the others are
+// killed, and the error of the first one is returned. When ctx is done the
+// ranks are sent SIGINT, so that they stop and report their partial result.
+func Launch(ctx context.Context, size int, name string, args []string, stdout, stderr io.Writer) error {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return err
+	}
+	defer ln.Close()
+
+	var mu sync.Mutex
+	stdout, stderr = serialize(stdout, &mu), serialize(stderr, &mu)
+	cmds := make([]*exec.Cmd, size)
+	for rank := range cmds {
+		cmd := exec.Command(name, args...)
+		cmd.Stdout = stdout
+		cmd.Stderr = stderr
+		cmd.Env = append(os.Environ(),
+			envRank+"="+strconv.Itoa(rank),
+			envSize+"="+strconv.Itoa(size),
+			envLauncher+"="+ln.Addr().String())
+		if err := cmd.Start(); err != nil {
+			for _, started := range cmds[:rank] {
+				started.Process.Kill()
+				started.Wait()
+			}
+			return err
+		}
+		cmds[rank] = cmd
+	}
+	go rendezvous(ln, size)
+
+	type exit struct {
+		rank int
+		err  error
+	}
+	exits := make(chan exit, size)
+	for rank, cmd := range cmds {
+		go func() {
+			exits <- exit{rank, cmd.Wait()}
+		}()
+	}
+	var first error
+	interrupted := ctx.Done()
+	for running := size; running > 0; {
+		select {
+		case e := <-exits:
+			running--
+			if e.err != nil && first == nil {
+				first = fmt.Errorf("rank %d: %w", e.rank, e.err)
+				for _, cmd := range cmds {
+					cmd.Process.Kill()
+				}
+			}
+		case <-interrupted:
+			interrupted = nil
+			for _, cmd := range cmds {
+				cmd.Process.Signal(os.Interrupt)
+			}
+		}
+	}
+	return first
+}
+
+// lockedWriter serializes the writes to w
+type lockedWriter struct {
+	mu *sync.Mutex
+	w  io.Writer
+}
+
+func (l lockedWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.Write(p)
+}
+
+// serialize returns w guarded by mu, unless w is a file or nil. exec copies
+// the output of each process to any other writer in a goroutine of its own,
+// so the copies for the ranks of a group would otherwise write at once.
+func serialize(w io.Writer, mu *sync.Mutex) io.Writer {
+	if _, ok := w.(*os.File); ok || w == nil {
+		return w
+	}
+	return lockedWriter{mu, w}
+}
+
+// rendezvous collects the address each rank listens on and sends the list to
+// all of them once every rank has checked in
+func rendezvous(ln net.Listener, size int) {
+	conns := make([]net.Conn, size)
+	addrs := make([]string, size)
+	for joined := 0; joined < size; {
+		conn, err := ln.Accept()
+		if err != nil {
+			return // Launch returned: a rank failed before joining
+		}
+		var rank int
+		var addr string
+		if _, err := fmt.Fscan(conn, &rank, &addr); err != nil || rank < 0 || rank >= size || conns[rank] != nil {
+			conn.Close()
+			continue
+		}
+		conns[rank], addrs[rank] = conn, addr
+		joined++
+	}
+	for _, conn := range conns {
+		fmt.Fprintln(conn, strings.Join(addrs, " "))
+		conn.Close()
+	}
+}
+
+// group is the TCP side of the Comm of a process started by Launch
+type group struct {
+	ln      net.Listener
+	conns   []net.Conn // conns[rank] is nil for this process's own rank
+	writers sync.WaitGroup
+	readers sync.WaitGroup
+}
+
+// join connects this process to the other ranks of its group and returns its
+// Comm
+func join() (*Comm, error) {
+	rank, err := strconv.Atoi(os.Getenv(envRank))
+	if err != nil {
+		return nil, fmt.Errorf("comm: bad %s: %v", envRank, err)
+	}
+	size, _ := Launched()
+	if rank < 0 || rank >= size {
+		return nil, fmt.Errorf("comm: rank %d out of a group of %d", rank, size)
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	g := &group{ln: ln, conns: make([]net.Conn, size)}
+	addrs, err := g.checkIn(rank, size)
+	if err == nil {
+		err = g.connect(rank, addrs)
+	}
+	if err != nil {
+		g.close()
+		return nil, fmt.Errorf("comm: rank %d joining its group: %v", rank, err)
+	}
+
+	c := &Comm{rank: rank, size: size, in: make([]chan any, size), out: make([]chan any, size), group: g}
+	for peer, conn := range g.conns {
+		c.in[peer] = make(chan any, depth)
+		if conn == nil {
+			c.out[peer] = c.in[peer]
+			continue
+		}
+		c.out[peer] = make(chan any, depth)
+		g.writers.Add(1)
+		go c.write(peer, conn)
+		g.readers.Add(1)
+		go c.read(peer, conn)
+	}
+	return c, nil
+}
+
+// checkIn gives the launcher the address of this rank and returns those of
+// all ranks
+func (g *group) checkIn(rank, size int) ([]string, error) {
+	conn, err := net.Dial("tcp", os.Getenv(envLauncher))
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if _, err := fmt.Fprintf(conn, "%d %s\n", rank, g.ln.Addr()); err != nil {
+		return nil, err
+	}
+	addrs := make([]string, size)
+	r := bufio.NewReader(conn)
+	for i := range addrs {
+		if _, err := fmt.Fscan(r, &addrs[i]); err != nil {
+			return nil, err
+		}
+	}
+	return addrs, nil
+}
+
+// connect opens the connections of this rank: it dials the ranks below it,
+// which have their listener ready whether or not they accept yet, then
+// accepts those of the ranks above it
+func (g *group) connect(rank int, addrs []string) error {
+	for peer := 0; peer < rank; peer++ {
+		conn, err := net.Dial("tcp", addrs[peer])
+		if err != nil {
+			return err
+		}
+		g.conns[peer] = conn
+		if err := binary.Write(conn, binary.LittleEndian, uint32(rank)); err != nil {
+			return err
+		}
+	}
+	for range len(addrs) - rank - 1 {
+		conn, err := g.ln.Accept()
+		if err != nil {
+			return err
+		}
+		var peer uint32
+		if err := binary.Read(conn, binary.LittleEndian, &peer); err != nil {
+			conn.Close()
+			return err
+		}
+		if int(peer) <= rank || int(peer) >= len(addrs) || g.conns[peer] != nil {
+			conn.Close()
+			return fmt.Errorf("unexpected connection from rank %d", peer)
+		}
+		g.conns[peer] = conn
+	}
+	return nil
+}
+
+func (g *group) close() {
+	g.ln.Close()
+	for _, conn := range g.conns {
+		if conn != nil {
+			conn.Close()
+		}
+	}
+}
+
+// leave flushes the messages still queued to the other ranks, waits until
+// they have sent all of theirs, and closes the connections
+func (c *Comm) leave() {
+	for peer, out := range c.out {
+		if peer != c.rank {
+			close(out)
+		}
+	}
+	c.group.writers.Wait()
+	c.group.readers.Wait()
+	c.group.close()
+}
+
+// write sends the messages queued for rank peer, flushing whenever the queue
+// is empty, and half-closes the connection when Comm.leave closes the queue
+func (c *Comm) write(peer int, conn net.Conn) {
+	defer c.group.writers.Done()
+	w := bufio.NewWriter(conn)
+	var header [headerSize]byte
+	for msg := range c.out[peer] {
+		f, ok := msg.(frame)
+		if !ok {
+			f = frame{kind: kindToken}
+		}
+		putHeader(&header, f.kind, len(f.data))
+		if _, err := w.Write(header[:]); err != nil {
+			c.abort("sending to rank %d: %v", peer, err)
+		}
+		if _, err := w.Write(f.data); err != nil {
+			c.abort("sending to rank %d: %v", peer, err)
+		}
+		if len(c.out[peer]) == 0 {
+			if err := w.Flush(); err != nil {
+				c.abort("sending to rank %d: %v", peer, err)
+			}
+		}
+	}
+	if err := w.Flush(); err != nil {
+		c.abort("sending to rank %d: %v", peer, err)
+	}
+	conn.(*net.TCPConn).CloseWrite()
+}
+
+// read queues the messages of rank peer until it closes its side of the
+// connection, then closes the queue
+func (c *Comm) read(peer int, conn net.Conn) {
+	defer c.group.readers.Done()
+	defer close(c.in[peer])
+	r := bufio.NewReader(conn)
+	var header [headerSize]byte
+	for {
+		if _, err := io.ReadFull(r, header[:]); err != nil {
+			if err != io.EOF {
+				c.abort("receiving from rank %d: %v", peer, err)
+			}
+			return
+		}
+		kind, n := readHeader(&header)
+		if kind == kindToken {
+			c.in[peer] <- struct{}{}
+			continue
+		}
+		if n > math.MaxInt {
+			c.abort("receiving from rank %d: message of %d bytes", peer, n)
+		}
+		f := frame{kind: kind, data: make([]byte, n)}
+		if _, err := io.ReadFull(r, f.data); err != nil {
+			c.abort("receiving from rank %d: %v", peer, err)
+		}
+		c.in[peer] <- f
+	}
+}
+
+// abort ends the process, and with it the group, as MPI_Abort does: the
+// other ranks could only wait forever for what this one cannot deliver
+func (c *Comm) abort(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, "comm: rank %d: %s\n", c.rank, fmt.Sprintf(format, args...))
+	os.Exit(1)
+}
+
+// plain reports whether slices of T can be sent as their memory, which the
+// ranks of one machine all lay out the same way
+func plain[T any]() bool {
+	switch reflect.TypeFor[T]().Kind() {
+	case reflect.Int, reflect.Int32, reflect.Int64, reflect.Uint8, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// memory returns the bytes of the elements of s
+func memory[T any](s []T) []byte {
+	var zero T
+	return unsafe.Slice((*byte)(unsafe.Pointer(unsafe.SliceData(s))), len(s)*int(unsafe.Sizeof(zero)))
+}
+
+// encode copies data into a frame
+func encode[T any](c *Comm, data []T) frame {
+	if plain[T]() {
+		return frame{kind: kindRaw, data: bytes.Clone(memory(data))}
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(data); err != nil {
+		c.abort("encoding %T: %v", data, err)
+	}
+	return frame{kind: kindEncoded, data: buf.Bytes()}
+}
+
+// decode returns the slice f was encoded from
+func decode[T any](c *Comm, f frame) []T {
+	if f.kind == kindRaw {
+		var zero T
+		msg := make([]T, len(f.data)/int(unsafe.Sizeof(zero)))
+		copy(memory(msg), f.data)
+		return msg
+	}
+	var msg []T
+	if err := gob.NewDecoder(bytes.NewReader(f.data)).Decode(&msg); err != nil && !errors.Is(err, io.EOF) {
+		c.abort("decoding %T: %v", msg, err)
+	}
+	return msg
+}
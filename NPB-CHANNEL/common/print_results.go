@@ -0,0 +1,61 @@
+package common
+
+import (
+	"fmt"
+	"io"
+	"math"
+)
+
+func PrintResults(w io.Writer, name, classNPB string, n1, n2, n3, niter, workers, gomaxprocs int, t, mops float64, optype, verification, npbversion, compiletime, compilerversion, rand string) {
+	fmt.Fprintf(w, "\n\n %s Benchmark Completed\n", name)
+	fmt.Fprintf(w, " class_npb       =                        %s\n", classNPB)
+
+	if len(name) >= 2 && name[:2] == "IS" {
+		if n3 == 0 {
+			nn := int64(n1)
+			if n2 != 0 {
+				nn *= int64(n2)
+			}
+			fmt.Fprintf(w, " Size            =             %12d\n", nn)
+		} else {
+			fmt.Fprintf(w, " Size            =             %4dx%4dx%4d\n", n1, n2, n3)
+		}
+	} else {
+		if n2 == 0 && n3 == 0 {
+			if len(name) >= 2 && name[:2] == "EP" {
+				size := fmt.Sprintf("%15.0f", math.Pow(2.0, float64(n1)))
+				// remove ponto final se presente
+				if size[len(size)-1] == '.' {
+					size = size[:len(size)-1]
+				}
+				fmt.Fprintf(w, " Size            =          %15s\n", size)
+			} else {
+				fmt.Fprintf(w, " Size            =             %12d\n", n1)
+			}
+		} else {
+			fmt.Fprintf(w, " Size            =           %4dx%4dx%4d\n", n1, n2, n3)
+		}
+	}
+
+	fmt.Fprintf(w, " Iterations      =             %12d\n", niter)
+	fmt.Fprintf(w, " Time in seconds =             %12.2f\n", t)
+	fmt.Fprintf(w, " Total workers   =             %12d\n", workers)
+	fmt.Fprintf(w, " GOMAXPROCS      =             %12d\n", gomaxprocs)
+	fmt.Fprintf(w, " Mop/s total     =             %12.2f\n", mops)
+	fmt.Fprintf(w, " Operation type  = %24s\n", optype)
+
+	fmt.Fprintf(w, " Verification    = %24s\n", verification)
+
+	fmt.Fprintf(w, " Version         =             %12s\n", npbversion)
+	fmt.Fprintf(w, " Compiler ver    =             %12s\n", compilerversion)
+	fmt.Fprintf(w, " Compile date    =             %12s\n", compiletime)
+
+	fmt.Fprintln(w, "\n Compile options:")
+	fmt.Fprintf(w, "    RAND         = %s\n", rand)
+	fmt.Fprintln(w, "\n\n----------------------------------------------------------------------")
+	fmt.Fprintln(w, "    NPB-GO is developed by: ")
+	fmt.Fprintln(w, "        Igor Yuji Ishihara Sakuma")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "----------------------------------------------------------------------")
+	fmt.Fprintln(w)
+}
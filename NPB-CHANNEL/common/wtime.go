@@ -0,0 +1,34 @@
+package common
+
+import (
+	"time"
+)
+
+// Timers holds the stopwatches of one benchmark run, indexed by the kernel's
+// timer numbers
+type Timers struct {
+	start   [64]float64
+	elapsed [64]float64
+}
+
+func elapsedTime() float64 {
+	return float64(time.Now().UnixNano()) / 1e9
+}
+
+func (t *Timers) Clear(n int) {
+	t.elapsed[n] = 0.0
+}
+
+func (t *Timers) Start(n int) {
+	t.start[n] = elapsedTime()
+}
+
+func (t *Timers) Stop(n int) {
+	now := elapsedTime()
+	e := now - t.start[n]
+	t.elapsed[n] += e
+}
+
+func (t *Timers) Read(n int) float64 {
+	return t.elapsed[n]
+}
@@ -0,0 +1,3 @@
+module github.com/iyisakuma/NPB-GO/NPB-CHANNEL
+
+go 1.24
@@ -0,0 +1,53 @@
+// Command npbrun runs a kernel of NPB-CHANNEL as separate processes, one per
+// rank, that communicate over TCP on the loopback interface, as mpirun runs
+// the MPI version of NPB.
+//
+//	npbrun -np 4 ./bin/CG -class=B
+//
+// Only rank 0 prints. The exit status is that of the first rank to fail, 0
+// when all of them succeed.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+
+	"github.com/iyisakuma/NPB-GO/NPB-CHANNEL/comm"
+	"github.com/iyisakuma/NPB-GO/NPB-CHANNEL/common"
+)
+
+func main() {
+	np := flag.Int("np", 0, "number of ranks, 0 means $GO_NUM_THREADS, or one per CPU")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: npbrun [-np N] <kernel binary> [kernel flags]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 || *np < 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	ranks, err := common.ResolveWorkers(*np)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "npbrun: %v\n", err)
+		os.Exit(2)
+	}
+
+	// SIGINT stops the ranks, which still report their partial result
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	err = comm.Launch(ctx, ranks, flag.Arg(0), flag.Args()[1:], os.Stdout, os.Stderr)
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() > 0 {
+			os.Exit(exitErr.ExitCode())
+		}
+		fmt.Fprintf(os.Stderr, "npbrun: %v\n", err)
+		os.Exit(1)
+	}
+}
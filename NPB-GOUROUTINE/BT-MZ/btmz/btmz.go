@@ -0,0 +1,60 @@
+// Package btmz is BT-MZ, the multi-zone version of BT: the grid is split in
+// x and y into zones that are advanced with the block-tridiagonal ADI solver
+// of BT and exchange their faces before every time step. The zones grow
+// geometrically in x and y so that the widest is 4.5 times the narrowest,
+// which makes the zone groups differ in work unless they are balanced.
+package btmz
+
+import (
+	"context"
+	"io"
+
+	"github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/BT-MZ/params"
+	"github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/BT/bt"
+	"github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/common"
+	"github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/multizone"
+)
+
+// Config selects the problem class and the goroutines of a run
+type Config struct {
+	Params     params.Params   // problem size, from params.Lookup
+	Workers    int             // goroutines; 0 means $GO_NUM_THREADS, or one per CPU
+	ZoneGroups int             // goroutines the zones are distributed over; 0 means $GO_ZONE_GROUPS, or as many as the zones and workers allow
+	Schedule   common.Schedule // of the loops of the zones; none means $GO_SCHEDULE
+	Bind       common.Binding  // of the workers to cores; none means $GO_PROC_BIND
+	Out        io.Writer       // progress messages and the NPB banner, discarded when nil
+}
+
+// Result is the outcome of a BT-MZ run with the norms it is verified on
+type Result = multizone.Result
+
+// Run runs the benchmark on the zones of cfg.Params and returns its result.
+// When ctx is done between two time steps the run stops there and Run returns
+// the partial result with a *common.IncompleteError.
+func Run(ctx context.Context, cfg Config) (Result, error) {
+	p := cfg.Params
+	problem := multizone.Problem{
+		Kernel: "BT-MZ",
+		Class:  p.CLASS,
+		XZones: p.X_ZONES,
+		YZones: p.Y_ZONES,
+		GX:     p.GX_SIZE,
+		GY:     p.GY_SIZE,
+		GZ:     p.GZ_SIZE,
+		Ratio:  4.5,
+		Niter:  p.NITER,
+		DT:     p.DT,
+		XCRRef: p.XCR_REF,
+		XCERef: p.XCE_REF,
+		NewSolver: func(nx, ny, x0, y0 int, team *common.Team) multizone.Solver {
+			return bt.NewZone(nx, ny, p.GZ_SIZE, x0, y0, p.GX_SIZE, p.GY_SIZE, p.DT, team)
+		},
+	}
+	return multizone.Run(ctx, problem, multizone.Config{
+		Workers:    cfg.Workers,
+		ZoneGroups: cfg.ZoneGroups,
+		Schedule:   cfg.Schedule,
+		Bind:       cfg.Bind,
+		Out:        cfg.Out,
+	})
+}
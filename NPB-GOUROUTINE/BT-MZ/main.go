@@ -0,0 +1,58 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/BT-MZ/btmz"
+	"github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/BT-MZ/params"
+	"github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/common"
+)
+
+func main() {
+	class := flag.String("class", "S", "problem class (S, W, A or B)")
+	groups := flag.Int("groups", 0, "goroutines the zones are distributed over (0 for $GO_ZONE_GROUPS, or as many as the zones and workers allow)")
+	format := flag.String("format", "text", "result format: text or json")
+	results := flag.String("results", "", "append the result to this .csv or .jsonl file")
+	workers := flag.Int("workers", 0, "goroutines (0 means $GO_NUM_THREADS, or one per CPU)")
+	maxProcs := flag.Int("gomaxprocs", 0, "goroutines running at once (0 means $GOMAXPROCS, or one per CPU)")
+	timeout := flag.Duration("timeout", 0, "stop after this long and report the time steps completed (0 for no limit)")
+	flag.Parse()
+
+	if err := common.SetFormat(*format); err != nil {
+		fmt.Fprintf(os.Stderr, "btmz: %v\n", err)
+		os.Exit(1)
+	}
+	out := common.Progress()
+	if err := common.SetResultsFile(*results); err != nil {
+		fmt.Fprintf(os.Stderr, "btmz: %v\n", err)
+		os.Exit(1)
+	}
+	if err := common.SetMaxProcs(*maxProcs); err != nil {
+		fmt.Fprintf(os.Stderr, "btmz: %v\n", err)
+		os.Exit(1)
+	}
+
+	p, ok := params.Lookup(*class)
+	if !ok {
+		common.PrintClassUsage(out, "btmz", *class, params.Classes)
+		os.Exit(1)
+	}
+
+	ctx, stop := common.RunContext(*timeout)
+	defer stop()
+	result, err := btmz.Run(ctx, btmz.Config{Params: p, Workers: *workers, ZoneGroups: *groups, Out: out})
+	if err != nil && !result.Incomplete {
+		fmt.Fprintf(os.Stderr, "btmz: %v\n", err)
+		os.Exit(1)
+	}
+	common.Report(&result.Result)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "btmz: %v\n", err)
+		os.Exit(1)
+	}
+	if result.Failed() {
+		os.Exit(1)
+	}
+}
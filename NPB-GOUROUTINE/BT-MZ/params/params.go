@@ -0,0 +1,41 @@
+package params
+
+import "strings"
+
+// Params holds the zones, grid size, time step and reference values of one
+// BT-MZ class
+type Params struct {
+	CLASS                     string
+	X_ZONES, Y_ZONES          int // zones in x and y
+	GX_SIZE, GY_SIZE, GZ_SIZE int // grid points of the whole problem
+	NITER                     int // time steps
+	DT                        float64
+	XCR_REF                   [5]float64 // RMS norms of the residual
+	XCE_REF                   [5]float64 // RMS norms of the solution error
+}
+
+// Classes lists the problem classes in increasing size. The zone layouts and
+// grid sizes are those of NPB-MZ; the reference norms were computed with this
+// implementation, and the classes from C up are not provided.
+var Classes = []string{"S", "W", "A", "B"}
+
+var table = map[string]Params{
+	"S": {CLASS: "S", X_ZONES: 2, Y_ZONES: 2, GX_SIZE: 24, GY_SIZE: 24, GZ_SIZE: 6, NITER: 60, DT: 0.010,
+		XCR_REF: [5]float64{1.0104435471737e+03, 8.7518979424770e+01, 2.3886551671639e+02, 1.9675970790272e+02, 1.7316980426238e+03},
+		XCE_REF: [5]float64{5.5007036811793e+01, 4.2467262130747e+00, 1.2838569518571e+01, 1.0268025228410e+01, 1.0300075502642e+02}},
+	"W": {CLASS: "W", X_ZONES: 4, Y_ZONES: 4, GX_SIZE: 64, GY_SIZE: 64, GZ_SIZE: 8, NITER: 200, DT: 0.0008,
+		XCR_REF: [5]float64{1.0322019927029e+03, 7.8631265616894e+01, 2.5169189889399e+02, 2.1950171281958e+02, 2.1372642593546e+03},
+		XCE_REF: [5]float64{1.2898872169546e+02, 8.4257299573367e+00, 3.0947529120063e+01, 2.6465689031776e+01, 2.7178513635606e+02}},
+	"A": {CLASS: "A", X_ZONES: 4, Y_ZONES: 4, GX_SIZE: 128, GY_SIZE: 128, GZ_SIZE: 16, NITER: 200, DT: 0.0008,
+		XCR_REF: [5]float64{4.7274461999086e+03, 4.4235120380922e+02, 1.2144679852583e+03, 9.9790435569893e+02, 8.6565544614164e+03},
+		XCE_REF: [5]float64{1.6086049442342e+02, 1.0764058896299e+01, 3.8884759153605e+01, 3.3679262578452e+01, 3.3979183814153e+02}},
+	"B": {CLASS: "B", X_ZONES: 8, Y_ZONES: 8, GX_SIZE: 304, GY_SIZE: 208, GZ_SIZE: 17, NITER: 200, DT: 0.0003,
+		XCR_REF: [5]float64{2.4622241998352e+04, 2.6154240803353e+03, 6.7042274350634e+03, 5.4093399199802e+03, 3.7279347372327e+04},
+		XCE_REF: [5]float64{2.7628456141784e+02, 2.0120434557864e+01, 6.8235015789243e+01, 6.0051191499987e+01, 5.7138633253408e+02}},
+}
+
+// Lookup returns the parameters of the given class
+func Lookup(class string) (Params, bool) {
+	p, ok := table[strings.ToUpper(class)]
+	return p, ok
+}
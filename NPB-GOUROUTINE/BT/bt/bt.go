@@ -0,0 +1,1251 @@
+// Package bt is the BT (Block Tri-diagonal) pseudo-application: it solves the
+// 3D compressible Navier-Stokes equations with an ADI scheme whose lines are
+// block tridiagonal systems of 5x5 blocks.
+package bt
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"os"
+
+	"github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/BT/params"
+	"github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/common"
+)
+
+// Timers of the timer.flag section breakdown
+const (
+	T_TOTAL = iota + 1
+	T_RHS
+	T_XSOLVE
+	T_YSOLVE
+	T_ZSOLVE
+	T_ADD
+	T_LAST = T_ADD
+)
+
+// Blocks of one row of the block tridiagonal system
+const (
+	AA = iota
+	BB
+	CC
+)
+
+// Config selects the problem a Run solves
+type Config struct {
+	Params   params.Params   // problem size, from params.Lookup
+	Workers  int             // goroutines; 0 means $GO_NUM_THREADS, or one per CPU
+	Schedule common.Schedule // of the loops over grid planes; none means $GO_SCHEDULE
+	Bind     common.Binding  // of the workers to cores; none means $GO_PROC_BIND
+	Out      io.Writer       // progress messages and the NPB banner, discarded when nil
+}
+
+// Result is the outcome of a BT run with the norms it is verified on
+type Result struct {
+	common.Result
+	XCR [5]float64 // RMS norms of the residual
+	XCE [5]float64 // RMS norms of the solution error
+}
+
+// Run runs the benchmark on the grid of cfg.Params and returns its result.
+// When ctx is done between two time steps the run stops there and Run returns
+// the partial result with a *common.IncompleteError.
+func Run(ctx context.Context, cfg Config) (Result, error) {
+	if err := ctx.Err(); err != nil {
+		return Result{}, err
+	}
+	workers, err := common.ResolveWorkers(cfg.Workers)
+	if err != nil {
+		return Result{}, err
+	}
+	out := cfg.Out
+	if out == nil {
+		out = io.Discard
+	}
+	sched, err := common.ResolveSchedule(cfg.Schedule)
+	if err != nil {
+		return Result{}, err
+	}
+	bind, err := common.ResolveBinding(cfg.Bind)
+	if err != nil {
+		return Result{}, err
+	}
+	bt := NewBTBenchmark(cfg.Params, workers, out)
+	bt.schedule = sched
+	bt.bind = bind
+	return bt.run(ctx)
+}
+
+// BTBenchmark represents the BT benchmark
+type BTBenchmark struct {
+	nx, ny, nz int
+	niter      int
+	dt         float64
+	class      string
+	xcrRef     [5]float64
+	xceRef     [5]float64
+
+	// Size of the whole grid in x and y and position of this grid in it,
+	// which differ from nx, ny and 0 only for the zones of a multi-zone
+	// problem
+	gx, gy int
+	x0, y0 int
+
+	// Conserved variables, right hand side and forcing term at every point
+	u, rhs, forcing []([5]float64)
+	// Quantities derived from u by computeRHS and used by the solvers
+	us, vs, ws, qs, rhoI, square []float64
+
+	// Line solver scratch space of each worker
+	solvers []lineSolver
+
+	// Coefficients of the exact solution
+	ce [5][13]float64
+
+	c1, c2, c3, c4, c5        float64
+	dnxm1, dnym1, dnzm1       float64
+	c1c2, c1c5, c3c4, c1345   float64
+	conz1, con43, con16       float64
+	tx1, tx2, tx3             float64
+	ty1, ty2, ty3             float64
+	tz1, tz2, tz3             float64
+	dx, dy, dz                [5]float64
+	dxtx1, dyty1, dztz1       [5]float64
+	dssp                      float64
+	c3c4tx3, c3c4ty3, c3c4tz3 float64
+	xxcon1, xxcon2, xxcon3    float64
+	xxcon4, xxcon5            float64
+	yycon1, yycon2, yycon3    float64
+	yycon4, yycon5            float64
+	zzcon1, zzcon2, zzcon3    float64
+	zzcon4, zzcon5            float64
+
+	numWorkers    int
+	schedule      common.Schedule
+	bind          common.Binding
+	team          *common.Team // started by run, or given to NewZone
+	timersEnabled bool
+	timers        common.Timers
+	out           io.Writer
+}
+
+// lineSolver holds the Jacobians and block tridiagonal system of the grid
+// line a worker is solving
+type lineSolver struct {
+	fjac, njac [][5][5]float64
+	lhs        [][3][5][5]float64
+}
+
+// NewBTBenchmark creates a BT benchmark for the given class parameters
+// running on numWorkers goroutines
+func NewBTBenchmark(p params.Params, numWorkers int, out io.Writer) *BTBenchmark {
+	n := p.PROBLEM_SIZE
+	bt := &BTBenchmark{
+		nx:         n,
+		ny:         n,
+		nz:         n,
+		gx:         n,
+		gy:         n,
+		niter:      p.NITER,
+		dt:         p.DT,
+		class:      p.CLASS,
+		xcrRef:     p.XCR_REF,
+		xceRef:     p.XCE_REF,
+		numWorkers: numWorkers,
+		out:        out,
+	}
+	bt.setConstants()
+	return bt
+}
+
+// idx returns the position of grid point (i, j, k) in the flat arrays
+func (bt *BTBenchmark) idx(k, j, i int) int {
+	return (k*bt.ny+j)*bt.nx + i
+}
+
+// setConstants computes the coefficients of the exact solution and the
+// constants of the discretization
+func (bt *BTBenchmark) setConstants() {
+	bt.ce = [5][13]float64{
+		{2.0, 0.0, 0.0, 4.0, 5.0, 3.0, 0.5, 0.02, 0.01, 0.03, 0.5, 0.4, 0.3},
+		{1.0, 0.0, 0.0, 0.0, 1.0, 2.0, 3.0, 0.01, 0.03, 0.02, 0.4, 0.3, 0.5},
+		{2.0, 2.0, 0.0, 0.0, 0.0, 2.0, 3.0, 0.04, 0.03, 0.05, 0.3, 0.5, 0.4},
+		{2.0, 2.0, 0.0, 0.0, 0.0, 2.0, 3.0, 0.03, 0.05, 0.04, 0.2, 0.1, 0.3},
+		{5.0, 4.0, 3.0, 2.0, 0.1, 0.4, 0.3, 0.05, 0.04, 0.03, 0.1, 0.3, 0.2},
+	}
+
+	bt.c1 = 1.4
+	bt.c2 = 0.4
+	bt.c3 = 0.1
+	bt.c4 = 1.0
+	bt.c5 = 1.4
+
+	bt.dnxm1 = 1.0 / float64(bt.gx-1)
+	bt.dnym1 = 1.0 / float64(bt.gy-1)
+	bt.dnzm1 = 1.0 / float64(bt.nz-1)
+
+	bt.c1c2 = bt.c1 * bt.c2
+	bt.c1c5 = bt.c1 * bt.c5
+	bt.c3c4 = bt.c3 * bt.c4
+	bt.c1345 = bt.c1c5 * bt.c3c4
+
+	bt.conz1 = 1.0 - bt.c1c5
+
+	bt.tx1 = 1.0 / (bt.dnxm1 * bt.dnxm1)
+	bt.tx2 = 1.0 / (2.0 * bt.dnxm1)
+	bt.tx3 = 1.0 / bt.dnxm1
+
+	bt.ty1 = 1.0 / (bt.dnym1 * bt.dnym1)
+	bt.ty2 = 1.0 / (2.0 * bt.dnym1)
+	bt.ty3 = 1.0 / bt.dnym1
+
+	bt.tz1 = 1.0 / (bt.dnzm1 * bt.dnzm1)
+	bt.tz2 = 1.0 / (2.0 * bt.dnzm1)
+	bt.tz3 = 1.0 / bt.dnzm1
+
+	bt.dx = [5]float64{0.75, 0.75, 0.75, 0.75, 0.75}
+	bt.dy = [5]float64{0.75, 0.75, 0.75, 0.75, 0.75}
+	bt.dz = [5]float64{1.0, 1.0, 1.0, 1.0, 1.0}
+
+	bt.dssp = 0.25 * math.Max(bt.dx[0], math.Max(bt.dy[0], bt.dz[0]))
+
+	bt.c3c4tx3 = bt.c3c4 * bt.tx3
+	bt.c3c4ty3 = bt.c3c4 * bt.ty3
+	bt.c3c4tz3 = bt.c3c4 * bt.tz3
+
+	for m := 0; m < 5; m++ {
+		bt.dxtx1[m] = bt.dx[m] * bt.tx1
+		bt.dyty1[m] = bt.dy[m] * bt.ty1
+		bt.dztz1[m] = bt.dz[m] * bt.tz1
+	}
+
+	bt.con43 = 4.0 / 3.0
+	bt.con16 = 1.0 / 6.0
+
+	bt.xxcon1 = bt.c3c4tx3 * bt.con43 * bt.tx3
+	bt.xxcon2 = bt.c3c4tx3 * bt.tx3
+	bt.xxcon3 = bt.c3c4tx3 * bt.conz1 * bt.tx3
+	bt.xxcon4 = bt.c3c4tx3 * bt.con16 * bt.tx3
+	bt.xxcon5 = bt.c3c4tx3 * bt.c1c5 * bt.tx3
+
+	bt.yycon1 = bt.c3c4ty3 * bt.con43 * bt.ty3
+	bt.yycon2 = bt.c3c4ty3 * bt.ty3
+	bt.yycon3 = bt.c3c4ty3 * bt.conz1 * bt.ty3
+	bt.yycon4 = bt.c3c4ty3 * bt.con16 * bt.ty3
+	bt.yycon5 = bt.c3c4ty3 * bt.c1c5 * bt.ty3
+
+	bt.zzcon1 = bt.c3c4tz3 * bt.con43 * bt.tz3
+	bt.zzcon2 = bt.c3c4tz3 * bt.tz3
+	bt.zzcon3 = bt.c3c4tz3 * bt.conz1 * bt.tz3
+	bt.zzcon4 = bt.c3c4tz3 * bt.con16 * bt.tz3
+	bt.zzcon5 = bt.c3c4tz3 * bt.c1c5 * bt.tz3
+}
+
+// exactSolution returns the exact solution at point (xi, eta, zeta)
+func (bt *BTBenchmark) exactSolution(xi, eta, zeta float64) [5]float64 {
+	var dtemp [5]float64
+	for m := 0; m < 5; m++ {
+		ce := &bt.ce[m]
+		dtemp[m] = ce[0] +
+			xi*(ce[1]+xi*(ce[4]+xi*(ce[7]+xi*ce[10]))) +
+			eta*(ce[2]+eta*(ce[5]+eta*(ce[8]+eta*ce[11]))) +
+			zeta*(ce[3]+zeta*(ce[6]+zeta*(ce[9]+zeta*ce[12])))
+	}
+	return dtemp
+}
+
+// initialize sets u to a transfinite interpolation of the boundary values of
+// the exact solution, and u to the exact solution on the boundaries
+func (bt *BTBenchmark) initialize() {
+	for p := range bt.u {
+		bt.u[p] = [5]float64{1.0, 1.0, 1.0, 1.0, 1.0}
+	}
+
+	var pface [2][3][5]float64
+	for k := 0; k < bt.nz; k++ {
+		zeta := float64(k) * bt.dnzm1
+		for j := 0; j < bt.ny; j++ {
+			eta := float64(bt.y0+j) * bt.dnym1
+			for i := 0; i < bt.nx; i++ {
+				xi := float64(bt.x0+i) * bt.dnxm1
+				for ix := 0; ix < 2; ix++ {
+					pface[ix][0] = bt.exactSolution(float64(ix), eta, zeta)
+				}
+				for iy := 0; iy < 2; iy++ {
+					pface[iy][1] = bt.exactSolution(xi, float64(iy), zeta)
+				}
+				for iz := 0; iz < 2; iz++ {
+					pface[iz][2] = bt.exactSolution(xi, eta, float64(iz))
+				}
+				u := &bt.u[bt.idx(k, j, i)]
+				for m := 0; m < 5; m++ {
+					pxi := xi*pface[1][0][m] + (1.0-xi)*pface[0][0][m]
+					peta := eta*pface[1][1][m] + (1.0-eta)*pface[0][1][m]
+					pzeta := zeta*pface[1][2][m] + (1.0-zeta)*pface[0][2][m]
+					u[m] = pxi + peta + pzeta - pxi*peta - pxi*pzeta - peta*pzeta + pxi*peta*pzeta
+				}
+			}
+		}
+	}
+
+	// West and east faces
+	for k := 0; k < bt.nz; k++ {
+		zeta := float64(k) * bt.dnzm1
+		for j := 0; j < bt.ny; j++ {
+			eta := float64(bt.y0+j) * bt.dnym1
+			bt.u[bt.idx(k, j, 0)] = bt.exactSolution(0.0, eta, zeta)
+			bt.u[bt.idx(k, j, bt.nx-1)] = bt.exactSolution(1.0, eta, zeta)
+		}
+	}
+
+	// South and north faces
+	for k := 0; k < bt.nz; k++ {
+		zeta := float64(k) * bt.dnzm1
+		for i := 0; i < bt.nx; i++ {
+			xi := float64(bt.x0+i) * bt.dnxm1
+			bt.u[bt.idx(k, 0, i)] = bt.exactSolution(xi, 0.0, zeta)
+			bt.u[bt.idx(k, bt.ny-1, i)] = bt.exactSolution(xi, 1.0, zeta)
+		}
+	}
+
+	// Bottom and top faces
+	for j := 0; j < bt.ny; j++ {
+		eta := float64(bt.y0+j) * bt.dnym1
+		for i := 0; i < bt.nx; i++ {
+			xi := float64(bt.x0+i) * bt.dnxm1
+			bt.u[bt.idx(0, j, i)] = bt.exactSolution(xi, eta, 0.0)
+			bt.u[bt.idx(bt.nz-1, j, i)] = bt.exactSolution(xi, eta, 1.0)
+		}
+	}
+}
+
+// dissipation subtracts the fourth-order dissipation of the n points of a
+// grid line of v, starting at v0 with stride vs, from the interior points of
+// the same line of dst, starting at d0 with stride ds
+func dissipation(dst [][5]float64, d0, ds int, v [][5]float64, v0, vs, n int, dssp float64) {
+	for m := 0; m < 5; m++ {
+		d := d0 + ds
+		i := v0 + vs
+		dst[d][m] -= dssp * (5.0*v[i][m] - 4.0*v[i+vs][m] + v[i+2*vs][m])
+		d += ds
+		i += vs
+		dst[d][m] -= dssp * (-4.0*v[i-vs][m] + 6.0*v[i][m] - 4.0*v[i+vs][m] + v[i+2*vs][m])
+	}
+	for l := 3; l < n-3; l++ {
+		d := d0 + l*ds
+		i := v0 + l*vs
+		for m := 0; m < 5; m++ {
+			dst[d][m] -= dssp * (v[i-2*vs][m] - 4.0*v[i-vs][m] + 6.0*v[i][m] - 4.0*v[i+vs][m] + v[i+2*vs][m])
+		}
+	}
+	for m := 0; m < 5; m++ {
+		d := d0 + (n-3)*ds
+		i := v0 + (n-3)*vs
+		dst[d][m] -= dssp * (v[i-2*vs][m] - 4.0*v[i-vs][m] + 6.0*v[i][m] - 4.0*v[i+vs][m])
+		d += ds
+		i += vs
+		dst[d][m] -= dssp * (v[i-2*vs][m] - 4.0*v[i-vs][m] + 5.0*v[i][m])
+	}
+}
+
+// exactRHS computes the forcing term that makes the exact solution a
+// solution of the discretized equations
+func (bt *BTBenchmark) exactRHS() {
+	maxdim := max(bt.nx, bt.ny, bt.nz)
+	ue := make([][5]float64, maxdim)
+	buf := make([][5]float64, maxdim)
+	cuf := make([]float64, maxdim)
+	q := make([]float64, maxdim)
+
+	for p := range bt.forcing {
+		bt.forcing[p] = [5]float64{}
+	}
+
+	// line fills ue, buf, cuf and q along one grid line, with dir the
+	// direction whose velocity goes to cuf
+	line := func(n, dir int, at func(l int) (float64, float64, float64)) {
+		for l := 0; l < n; l++ {
+			ue[l] = bt.exactSolution(at(l))
+			dtpp := 1.0 / ue[l][0]
+			for m := 1; m < 5; m++ {
+				buf[l][m] = dtpp * ue[l][m]
+			}
+			cuf[l] = buf[l][dir] * buf[l][dir]
+			buf[l][0] = buf[l][1]*buf[l][1] + buf[l][2]*buf[l][2] + buf[l][3]*buf[l][3]
+			q[l] = 0.5 * (buf[l][1]*ue[l][1] + buf[l][2]*ue[l][2] + buf[l][3]*ue[l][3])
+		}
+	}
+
+	// xi-direction flux differences
+	for k := 1; k < bt.nz-1; k++ {
+		zeta := float64(k) * bt.dnzm1
+		for j := 1; j < bt.ny-1; j++ {
+			eta := float64(bt.y0+j) * bt.dnym1
+			line(bt.nx, 1, func(i int) (float64, float64, float64) { return float64(bt.x0+i) * bt.dnxm1, eta, zeta })
+			for i := 1; i < bt.nx-1; i++ {
+				im1, ip1 := i-1, i+1
+				f := &bt.forcing[bt.idx(k, j, i)]
+				f[0] = f[0] - bt.tx2*(ue[ip1][1]-ue[im1][1]) +
+					bt.dxtx1[0]*(ue[ip1][0]-2.0*ue[i][0]+ue[im1][0])
+				f[1] = f[1] - bt.tx2*((ue[ip1][1]*buf[ip1][1]+bt.c2*(ue[ip1][4]-q[ip1]))-
+					(ue[im1][1]*buf[im1][1]+bt.c2*(ue[im1][4]-q[im1]))) +
+					bt.xxcon1*(buf[ip1][1]-2.0*buf[i][1]+buf[im1][1]) +
+					bt.dxtx1[1]*(ue[ip1][1]-2.0*ue[i][1]+ue[im1][1])
+				f[2] = f[2] - bt.tx2*(ue[ip1][2]*buf[ip1][1]-ue[im1][2]*buf[im1][1]) +
+					bt.xxcon2*(buf[ip1][2]-2.0*buf[i][2]+buf[im1][2]) +
+					bt.dxtx1[2]*(ue[ip1][2]-2.0*ue[i][2]+ue[im1][2])
+				f[3] = f[3] - bt.tx2*(ue[ip1][3]*buf[ip1][1]-ue[im1][3]*buf[im1][1]) +
+					bt.xxcon2*(buf[ip1][3]-2.0*buf[i][3]+buf[im1][3]) +
+					bt.dxtx1[3]*(ue[ip1][3]-2.0*ue[i][3]+ue[im1][3])
+				f[4] = f[4] - bt.tx2*(buf[ip1][1]*(bt.c1*ue[ip1][4]-bt.c2*q[ip1])-
+					buf[im1][1]*(bt.c1*ue[im1][4]-bt.c2*q[im1])) +
+					0.5*bt.xxcon3*(buf[ip1][0]-2.0*buf[i][0]+buf[im1][0]) +
+					bt.xxcon4*(cuf[ip1]-2.0*cuf[i]+cuf[im1]) +
+					bt.xxcon5*(buf[ip1][4]-2.0*buf[i][4]+buf[im1][4]) +
+					bt.dxtx1[4]*(ue[ip1][4]-2.0*ue[i][4]+ue[im1][4])
+			}
+			dissipation(bt.forcing, bt.idx(k, j, 0), 1, ue, 0, 1, bt.nx, bt.dssp)
+		}
+	}
+
+	// eta-direction flux differences
+	for k := 1; k < bt.nz-1; k++ {
+		zeta := float64(k) * bt.dnzm1
+		for i := 1; i < bt.nx-1; i++ {
+			xi := float64(bt.x0+i) * bt.dnxm1
+			line(bt.ny, 2, func(j int) (float64, float64, float64) { return xi, float64(bt.y0+j) * bt.dnym1, zeta })
+			for j := 1; j < bt.ny-1; j++ {
+				jm1, jp1 := j-1, j+1
+				f := &bt.forcing[bt.idx(k, j, i)]
+				f[0] = f[0] - bt.ty2*(ue[jp1][2]-ue[jm1][2]) +
+					bt.dyty1[0]*(ue[jp1][0]-2.0*ue[j][0]+ue[jm1][0])
+				f[1] = f[1] - bt.ty2*(ue[jp1][1]*buf[jp1][2]-ue[jm1][1]*buf[jm1][2]) +
+					bt.yycon2*(buf[jp1][1]-2.0*buf[j][1]+buf[jm1][1]) +
+					bt.dyty1[1]*(ue[jp1][1]-2.0*ue[j][1]+ue[jm1][1])
+				f[2] = f[2] - bt.ty2*((ue[jp1][2]*buf[jp1][2]+bt.c2*(ue[jp1][4]-q[jp1]))-
+					(ue[jm1][2]*buf[jm1][2]+bt.c2*(ue[jm1][4]-q[jm1]))) +
+					bt.yycon1*(buf[jp1][2]-2.0*buf[j][2]+buf[jm1][2]) +
+					bt.dyty1[2]*(ue[jp1][2]-2.0*ue[j][2]+ue[jm1][2])
+				f[3] = f[3] - bt.ty2*(ue[jp1][3]*buf[jp1][2]-ue[jm1][3]*buf[jm1][2]) +
+					bt.yycon2*(buf[jp1][3]-2.0*buf[j][3]+buf[jm1][3]) +
+					bt.dyty1[3]*(ue[jp1][3]-2.0*ue[j][3]+ue[jm1][3])
+				f[4] = f[4] - bt.ty2*(buf[jp1][2]*(bt.c1*ue[jp1][4]-bt.c2*q[jp1])-
+					buf[jm1][2]*(bt.c1*ue[jm1][4]-bt.c2*q[jm1])) +
+					0.5*bt.yycon3*(buf[jp1][0]-2.0*buf[j][0]+buf[jm1][0]) +
+					bt.yycon4*(cuf[jp1]-2.0*cuf[j]+cuf[jm1]) +
+					bt.yycon5*(buf[jp1][4]-2.0*buf[j][4]+buf[jm1][4]) +
+					bt.dyty1[4]*(ue[jp1][4]-2.0*ue[j][4]+ue[jm1][4])
+			}
+			dissipation(bt.forcing, bt.idx(k, 0, i), bt.nx, ue, 0, 1, bt.ny, bt.dssp)
+		}
+	}
+
+	// zeta-direction flux differences
+	for j := 1; j < bt.ny-1; j++ {
+		eta := float64(bt.y0+j) * bt.dnym1
+		for i := 1; i < bt.nx-1; i++ {
+			xi := float64(bt.x0+i) * bt.dnxm1
+			line(bt.nz, 3, func(k int) (float64, float64, float64) { return xi, eta, float64(k) * bt.dnzm1 })
+			for k := 1; k < bt.nz-1; k++ {
+				km1, kp1 := k-1, k+1
+				f := &bt.forcing[bt.idx(k, j, i)]
+				f[0] = f[0] - bt.tz2*(ue[kp1][3]-ue[km1][3]) +
+					bt.dztz1[0]*(ue[kp1][0]-2.0*ue[k][0]+ue[km1][0])
+				f[1] = f[1] - bt.tz2*(ue[kp1][1]*buf[kp1][3]-ue[km1][1]*buf[km1][3]) +
+					bt.zzcon2*(buf[kp1][1]-2.0*buf[k][1]+buf[km1][1]) +
+					bt.dztz1[1]*(ue[kp1][1]-2.0*ue[k][1]+ue[km1][1])
+				f[2] = f[2] - bt.tz2*(ue[kp1][2]*buf[kp1][3]-ue[km1][2]*buf[km1][3]) +
+					bt.zzcon2*(buf[kp1][2]-2.0*buf[k][2]+buf[km1][2]) +
+					bt.dztz1[2]*(ue[kp1][2]-2.0*ue[k][2]+ue[km1][2])
+				f[3] = f[3] - bt.tz2*((ue[kp1][3]*buf[kp1][3]+bt.c2*(ue[kp1][4]-q[kp1]))-
+					(ue[km1][3]*buf[km1][3]+bt.c2*(ue[km1][4]-q[km1]))) +
+					bt.zzcon1*(buf[kp1][3]-2.0*buf[k][3]+buf[km1][3]) +
+					bt.dztz1[3]*(ue[kp1][3]-2.0*ue[k][3]+ue[km1][3])
+				f[4] = f[4] - bt.tz2*(buf[kp1][3]*(bt.c1*ue[kp1][4]-bt.c2*q[kp1])-
+					buf[km1][3]*(bt.c1*ue[km1][4]-bt.c2*q[km1])) +
+					0.5*bt.zzcon3*(buf[kp1][0]-2.0*buf[k][0]+buf[km1][0]) +
+					bt.zzcon4*(cuf[kp1]-2.0*cuf[k]+cuf[km1]) +
+					bt.zzcon5*(buf[kp1][4]-2.0*buf[k][4]+buf[km1][4]) +
+					bt.dztz1[4]*(ue[kp1][4]-2.0*ue[k][4]+ue[km1][4])
+			}
+			dissipation(bt.forcing, bt.idx(0, j, i), bt.nx*bt.ny, ue, 0, 1, bt.nz, bt.dssp)
+		}
+	}
+
+	// Now change the sign of the forcing function
+	for k := 1; k < bt.nz-1; k++ {
+		for j := 1; j < bt.ny-1; j++ {
+			for i := 1; i < bt.nx-1; i++ {
+				f := &bt.forcing[bt.idx(k, j, i)]
+				for m := 0; m < 5; m++ {
+					f[m] = -1.0 * f[m]
+				}
+			}
+		}
+	}
+}
+
+// computeRHS computes the right hand side of the equations from u
+func (bt *BTBenchmark) computeRHS() {
+	if bt.timersEnabled {
+		bt.timers.Start(T_RHS)
+	}
+	u, rhs := bt.u, bt.rhs
+	us, vs, ws, qs, rhoI, square := bt.us, bt.vs, bt.ws, bt.qs, bt.rhoI, bt.square
+
+	// Compute the reciprocal of density, and the kinetic energy and the
+	// speed of sound
+	plane := bt.nx * bt.ny
+	bt.team.For(0, bt.nz, func(k0, k1, _ int) {
+		for p := k0 * plane; p < k1*plane; p++ {
+			rhoInv := 1.0 / u[p][0]
+			rhoI[p] = rhoInv
+			us[p] = u[p][1] * rhoInv
+			vs[p] = u[p][2] * rhoInv
+			ws[p] = u[p][3] * rhoInv
+			square[p] = 0.5 * (u[p][1]*u[p][1] + u[p][2]*u[p][2] + u[p][3]*u[p][3]) * rhoInv
+			qs[p] = square[p] * rhoInv
+		}
+
+		// Copy the exact forcing term to the right hand side; the
+		// boundaries keep it as is
+		copy(rhs[k0*plane:k1*plane], bt.forcing[k0*plane:k1*plane])
+	})
+
+	// xi-direction fluxes
+	bt.team.For(1, bt.nz-1, func(k0, k1, _ int) { bt.xFluxes(k0, k1) })
+	// eta-direction fluxes
+	bt.team.For(1, bt.nz-1, func(k0, k1, _ int) { bt.yFluxes(k0, k1) })
+	// zeta-direction fluxes
+	bt.team.For(1, bt.nz-1, func(k0, k1, _ int) { bt.zFluxes(k0, k1) })
+	bt.team.For(1, bt.ny-1, func(j0, j1, _ int) { bt.zDissipation(j0, j1) })
+
+	bt.team.For(1, bt.nz-1, func(k0, k1, _ int) {
+		for k := k0; k < k1; k++ {
+			for j := 1; j < bt.ny-1; j++ {
+				for i := 1; i < bt.nx-1; i++ {
+					r := &rhs[bt.idx(k, j, i)]
+					for m := 0; m < 5; m++ {
+						r[m] = r[m] * bt.dt
+					}
+				}
+			}
+		}
+	})
+	if bt.timersEnabled {
+		bt.timers.Stop(T_RHS)
+	}
+}
+
+// xFluxes adds the xi-direction fluxes and dissipation to rhs in the planes
+// k0..k1-1
+func (bt *BTBenchmark) xFluxes(k0, k1 int) {
+	u, rhs := bt.u, bt.rhs
+	us, vs, ws, qs, rhoI, square := bt.us, bt.vs, bt.ws, bt.qs, bt.rhoI, bt.square
+	c1, c2, dssp := bt.c1, bt.c2, bt.dssp
+	for k := k0; k < k1; k++ {
+		for j := 1; j < bt.ny-1; j++ {
+			for i := 1; i < bt.nx-1; i++ {
+				p := bt.idx(k, j, i)
+				pp, pm := p+1, p-1
+				uijk, up1, um1 := us[p], us[pp], us[pm]
+				r := &rhs[p]
+				r[0] = r[0] + bt.dxtx1[0]*(u[pp][0]-2.0*u[p][0]+u[pm][0]) -
+					bt.tx2*(u[pp][1]-u[pm][1])
+				r[1] = r[1] + bt.dxtx1[1]*(u[pp][1]-2.0*u[p][1]+u[pm][1]) +
+					bt.xxcon2*bt.con43*(up1-2.0*uijk+um1) -
+					bt.tx2*(u[pp][1]*up1-u[pm][1]*um1+(u[pp][4]-square[pp]-u[pm][4]+square[pm])*c2)
+				r[2] = r[2] + bt.dxtx1[2]*(u[pp][2]-2.0*u[p][2]+u[pm][2]) +
+					bt.xxcon2*(vs[pp]-2.0*vs[p]+vs[pm]) -
+					bt.tx2*(u[pp][2]*up1-u[pm][2]*um1)
+				r[3] = r[3] + bt.dxtx1[3]*(u[pp][3]-2.0*u[p][3]+u[pm][3]) +
+					bt.xxcon2*(ws[pp]-2.0*ws[p]+ws[pm]) -
+					bt.tx2*(u[pp][3]*up1-u[pm][3]*um1)
+				r[4] = r[4] + bt.dxtx1[4]*(u[pp][4]-2.0*u[p][4]+u[pm][4]) +
+					bt.xxcon3*(qs[pp]-2.0*qs[p]+qs[pm]) +
+					bt.xxcon4*(up1*up1-2.0*uijk*uijk+um1*um1) +
+					bt.xxcon5*(u[pp][4]*rhoI[pp]-2.0*u[p][4]*rhoI[p]+u[pm][4]*rhoI[pm]) -
+					bt.tx2*((c1*u[pp][4]-c2*square[pp])*up1-(c1*u[pm][4]-c2*square[pm])*um1)
+			}
+			p0 := bt.idx(k, j, 0)
+			dissipation(rhs, p0, 1, u, p0, 1, bt.nx, dssp)
+		}
+	}
+}
+
+// yFluxes adds the eta-direction fluxes and dissipation to rhs in the
+// planes k0..k1-1
+func (bt *BTBenchmark) yFluxes(k0, k1 int) {
+	u, rhs := bt.u, bt.rhs
+	us, vs, ws, qs, rhoI, square := bt.us, bt.vs, bt.ws, bt.qs, bt.rhoI, bt.square
+	c1, c2, dssp := bt.c1, bt.c2, bt.dssp
+	s := bt.nx
+	for k := k0; k < k1; k++ {
+		for j := 1; j < bt.ny-1; j++ {
+			for i := 1; i < bt.nx-1; i++ {
+				p := bt.idx(k, j, i)
+				pp, pm := p+s, p-s
+				vijk, vp1, vm1 := vs[p], vs[pp], vs[pm]
+				r := &rhs[p]
+				r[0] = r[0] + bt.dyty1[0]*(u[pp][0]-2.0*u[p][0]+u[pm][0]) -
+					bt.ty2*(u[pp][2]-u[pm][2])
+				r[1] = r[1] + bt.dyty1[1]*(u[pp][1]-2.0*u[p][1]+u[pm][1]) +
+					bt.yycon2*(us[pp]-2.0*us[p]+us[pm]) -
+					bt.ty2*(u[pp][1]*vp1-u[pm][1]*vm1)
+				r[2] = r[2] + bt.dyty1[2]*(u[pp][2]-2.0*u[p][2]+u[pm][2]) +
+					bt.yycon2*bt.con43*(vp1-2.0*vijk+vm1) -
+					bt.ty2*(u[pp][2]*vp1-u[pm][2]*vm1+(u[pp][4]-square[pp]-u[pm][4]+square[pm])*c2)
+				r[3] = r[3] + bt.dyty1[3]*(u[pp][3]-2.0*u[p][3]+u[pm][3]) +
+					bt.yycon2*(ws[pp]-2.0*ws[p]+ws[pm]) -
+					bt.ty2*(u[pp][3]*vp1-u[pm][3]*vm1)
+				r[4] = r[4] + bt.dyty1[4]*(u[pp][4]-2.0*u[p][4]+u[pm][4]) +
+					bt.yycon3*(qs[pp]-2.0*qs[p]+qs[pm]) +
+					bt.yycon4*(vp1*vp1-2.0*vijk*vijk+vm1*vm1) +
+					bt.yycon5*(u[pp][4]*rhoI[pp]-2.0*u[p][4]*rhoI[p]+u[pm][4]*rhoI[pm]) -
+					bt.ty2*((c1*u[pp][4]-c2*square[pp])*vp1-(c1*u[pm][4]-c2*square[pm])*vm1)
+			}
+		}
+		for i := 1; i < bt.nx-1; i++ {
+			p0 := bt.idx(k, 0, i)
+			dissipation(rhs, p0, s, u, p0, s, bt.ny, dssp)
+		}
+	}
+
+}
+
+// zFluxes adds the zeta-direction fluxes and dissipation to rhs in the
+// planes k0..k1-1. The dissipation spans whole zeta lines and is added by
+// zDissipation.
+func (bt *BTBenchmark) zFluxes(k0, k1 int) {
+	u, rhs := bt.u, bt.rhs
+	us, vs, ws, qs, rhoI, square := bt.us, bt.vs, bt.ws, bt.qs, bt.rhoI, bt.square
+	c1, c2 := bt.c1, bt.c2
+	s := bt.nx * bt.ny
+	for k := k0; k < k1; k++ {
+		for j := 1; j < bt.ny-1; j++ {
+			for i := 1; i < bt.nx-1; i++ {
+				p := bt.idx(k, j, i)
+				pp, pm := p+s, p-s
+				wijk, wp1, wm1 := ws[p], ws[pp], ws[pm]
+				r := &rhs[p]
+				r[0] = r[0] + bt.dztz1[0]*(u[pp][0]-2.0*u[p][0]+u[pm][0]) -
+					bt.tz2*(u[pp][3]-u[pm][3])
+				r[1] = r[1] + bt.dztz1[1]*(u[pp][1]-2.0*u[p][1]+u[pm][1]) +
+					bt.zzcon2*(us[pp]-2.0*us[p]+us[pm]) -
+					bt.tz2*(u[pp][1]*wp1-u[pm][1]*wm1)
+				r[2] = r[2] + bt.dztz1[2]*(u[pp][2]-2.0*u[p][2]+u[pm][2]) +
+					bt.zzcon2*(vs[pp]-2.0*vs[p]+vs[pm]) -
+					bt.tz2*(u[pp][2]*wp1-u[pm][2]*wm1)
+				r[3] = r[3] + bt.dztz1[3]*(u[pp][3]-2.0*u[p][3]+u[pm][3]) +
+					bt.zzcon2*bt.con43*(wp1-2.0*wijk+wm1) -
+					bt.tz2*(u[pp][3]*wp1-u[pm][3]*wm1+(u[pp][4]-square[pp]-u[pm][4]+square[pm])*c2)
+				r[4] = r[4] + bt.dztz1[4]*(u[pp][4]-2.0*u[p][4]+u[pm][4]) +
+					bt.zzcon3*(qs[pp]-2.0*qs[p]+qs[pm]) +
+					bt.zzcon4*(wp1*wp1-2.0*wijk*wijk+wm1*wm1) +
+					bt.zzcon5*(u[pp][4]*rhoI[pp]-2.0*u[p][4]*rhoI[p]+u[pm][4]*rhoI[pm]) -
+					bt.tz2*((c1*u[pp][4]-c2*square[pp])*wp1-(c1*u[pm][4]-c2*square[pm])*wm1)
+			}
+		}
+	}
+}
+
+// zDissipation adds the zeta-direction dissipation to rhs along the lines
+// j0..j1-1, which cross every plane
+func (bt *BTBenchmark) zDissipation(j0, j1 int) {
+	s := bt.nx * bt.ny
+	for j := j0; j < j1; j++ {
+		for i := 1; i < bt.nx-1; i++ {
+			p0 := bt.idx(0, j, i)
+			dissipation(bt.rhs, p0, s, bt.u, p0, s, bt.nz, bt.dssp)
+		}
+	}
+}
+
+// xJacobians sets the flux and viscous Jacobians of point p for the
+// xi-direction
+func (bt *BTBenchmark) xJacobians(p int, fjac, njac *[5][5]float64) {
+	u := &bt.u[p]
+	c1, c2, c3c4, c1345, con43 := bt.c1, bt.c2, bt.c3c4, bt.c1345, bt.con43
+	tmp1 := bt.rhoI[p]
+	tmp2 := tmp1 * tmp1
+	tmp3 := tmp1 * tmp2
+
+	*fjac = [5][5]float64{}
+	fjac[1][0] = 1.0
+	fjac[0][1] = -(u[1] * tmp2 * u[1]) + c2*bt.qs[p]
+	fjac[1][1] = (2.0 - c2) * (u[1] / u[0])
+	fjac[2][1] = -c2 * (u[2] * tmp1)
+	fjac[3][1] = -c2 * (u[3] * tmp1)
+	fjac[4][1] = c2
+	fjac[0][2] = -(u[1] * u[2]) * tmp2
+	fjac[1][2] = u[2] * tmp1
+	fjac[2][2] = u[1] * tmp1
+	fjac[0][3] = -(u[1] * u[3]) * tmp2
+	fjac[1][3] = u[3] * tmp1
+	fjac[3][3] = u[1] * tmp1
+	fjac[0][4] = (c2*2.0*bt.square[p] - c1*u[4]) * (u[1] * tmp2)
+	fjac[1][4] = c1*u[4]*tmp1 - c2*(u[1]*u[1]*tmp2+bt.qs[p])
+	fjac[2][4] = -c2 * (u[2] * u[1]) * tmp2
+	fjac[3][4] = -c2 * (u[3] * u[1]) * tmp2
+	fjac[4][4] = c1 * (u[1] * tmp1)
+
+	*njac = [5][5]float64{}
+	njac[0][1] = -con43 * c3c4 * tmp2 * u[1]
+	njac[1][1] = con43 * c3c4 * tmp1
+	njac[0][2] = -c3c4 * tmp2 * u[2]
+	njac[2][2] = c3c4 * tmp1
+	njac[0][3] = -c3c4 * tmp2 * u[3]
+	njac[3][3] = c3c4 * tmp1
+	njac[0][4] = -(con43*c3c4-c1345)*tmp3*(u[1]*u[1]) -
+		(c3c4-c1345)*tmp3*(u[2]*u[2]) -
+		(c3c4-c1345)*tmp3*(u[3]*u[3]) -
+		c1345*tmp2*u[4]
+	njac[1][4] = (con43*c3c4 - c1345) * tmp2 * u[1]
+	njac[2][4] = (c3c4 - c1345) * tmp2 * u[2]
+	njac[3][4] = (c3c4 - c1345) * tmp2 * u[3]
+	njac[4][4] = c1345 * tmp1
+}
+
+// yJacobians sets the flux and viscous Jacobians of point p for the
+// eta-direction
+func (bt *BTBenchmark) yJacobians(p int, fjac, njac *[5][5]float64) {
+	u := &bt.u[p]
+	c1, c2, c3c4, c1345, con43 := bt.c1, bt.c2, bt.c3c4, bt.c1345, bt.con43
+	tmp1 := bt.rhoI[p]
+	tmp2 := tmp1 * tmp1
+	tmp3 := tmp1 * tmp2
+
+	*fjac = [5][5]float64{}
+	fjac[2][0] = 1.0
+	fjac[0][1] = -(u[1] * u[2]) * tmp2
+	fjac[1][1] = u[2] * tmp1
+	fjac[2][1] = u[1] * tmp1
+	fjac[0][2] = -(u[2] * u[2] * tmp2) + c2*bt.qs[p]
+	fjac[1][2] = -c2 * u[1] * tmp1
+	fjac[2][2] = (2.0 - c2) * u[2] * tmp1
+	fjac[3][2] = -c2 * u[3] * tmp1
+	fjac[4][2] = c2
+	fjac[0][3] = -(u[2] * u[3]) * tmp2
+	fjac[2][3] = u[3] * tmp1
+	fjac[3][3] = u[2] * tmp1
+	fjac[0][4] = (c2*2.0*bt.square[p] - c1*u[4]) * u[2] * tmp2
+	fjac[1][4] = -c2 * u[1] * u[2] * tmp2
+	fjac[2][4] = c1*u[4]*tmp1 - c2*(bt.qs[p]+u[2]*u[2]*tmp2)
+	fjac[3][4] = -c2 * (u[2] * u[3]) * tmp2
+	fjac[4][4] = c1 * u[2] * tmp1
+
+	*njac = [5][5]float64{}
+	njac[0][1] = -c3c4 * tmp2 * u[1]
+	njac[1][1] = c3c4 * tmp1
+	njac[0][2] = -con43 * c3c4 * tmp2 * u[2]
+	njac[2][2] = con43 * c3c4 * tmp1
+	njac[0][3] = -c3c4 * tmp2 * u[3]
+	njac[3][3] = c3c4 * tmp1
+	njac[0][4] = -(c3c4-c1345)*tmp3*(u[1]*u[1]) -
+		(con43*c3c4-c1345)*tmp3*(u[2]*u[2]) -
+		(c3c4-c1345)*tmp3*(u[3]*u[3]) -
+		c1345*tmp2*u[4]
+	njac[1][4] = (c3c4 - c1345) * tmp2 * u[1]
+	njac[2][4] = (con43*c3c4 - c1345) * tmp2 * u[2]
+	njac[3][4] = (c3c4 - c1345) * tmp2 * u[3]
+	njac[4][4] = c1345 * tmp1
+}
+
+// zJacobians sets the flux and viscous Jacobians of point p for the
+// zeta-direction
+func (bt *BTBenchmark) zJacobians(p int, fjac, njac *[5][5]float64) {
+	u := &bt.u[p]
+	c1, c2, c3c4, c1345, con43 := bt.c1, bt.c2, bt.c3c4, bt.c1345, bt.con43
+	tmp1 := bt.rhoI[p]
+	tmp2 := tmp1 * tmp1
+	tmp3 := tmp1 * tmp2
+
+	*fjac = [5][5]float64{}
+	fjac[3][0] = 1.0
+	fjac[0][1] = -(u[1] * u[3]) * tmp2
+	fjac[1][1] = u[3] * tmp1
+	fjac[3][1] = u[1] * tmp1
+	fjac[0][2] = -(u[2] * u[3]) * tmp2
+	fjac[2][2] = u[3] * tmp1
+	fjac[3][2] = u[2] * tmp1
+	fjac[0][3] = -(u[3] * u[3] * tmp2) + c2*bt.qs[p]
+	fjac[1][3] = -c2 * u[1] * tmp1
+	fjac[2][3] = -c2 * u[2] * tmp1
+	fjac[3][3] = (2.0 - c2) * u[3] * tmp1
+	fjac[4][3] = c2
+	fjac[0][4] = (c2*2.0*bt.square[p] - c1*u[4]) * u[3] * tmp2
+	fjac[1][4] = -c2 * (u[1] * u[3]) * tmp2
+	fjac[2][4] = -c2 * (u[2] * u[3]) * tmp2
+	fjac[3][4] = c1*(u[4]*tmp1) - c2*(bt.qs[p]+u[3]*u[3]*tmp2)
+	fjac[4][4] = c1 * u[3] * tmp1
+
+	*njac = [5][5]float64{}
+	njac[0][1] = -c3c4 * tmp2 * u[1]
+	njac[1][1] = c3c4 * tmp1
+	njac[0][2] = -c3c4 * tmp2 * u[2]
+	njac[2][2] = c3c4 * tmp1
+	njac[0][3] = -con43 * c3c4 * tmp2 * u[3]
+	njac[3][3] = con43 * c3c4 * tmp1
+	njac[0][4] = -(c3c4-c1345)*tmp3*(u[1]*u[1]) -
+		(c3c4-c1345)*tmp3*(u[2]*u[2]) -
+		(con43*c3c4-c1345)*tmp3*(u[3]*u[3]) -
+		c1345*tmp2*u[4]
+	njac[1][4] = (c3c4 - c1345) * tmp2 * u[1]
+	njac[2][4] = (c3c4 - c1345) * tmp2 * u[2]
+	njac[3][4] = (con43*c3c4 - c1345) * tmp2 * u[3]
+	njac[4][4] = c1345 * tmp1
+}
+
+// solveLine forms the block tridiagonal system of the grid line of size+1
+// points starting at p0 with stride s from the Jacobians in ls, and solves it
+// in place of rhs. tmp1 and tmp2 are dt times the line's t*1 and t*2
+// constants, d its dissipation coefficients.
+func (bt *BTBenchmark) solveLine(ls *lineSolver, p0, s, size int, tmp1, tmp2 float64, d *[5]float64) {
+	fjac, njac, lhs, rhs := ls.fjac, ls.njac, ls.lhs, bt.rhs
+
+	lhsinit(lhs, size)
+	for i := 1; i < size; i++ {
+		for n := 0; n < 5; n++ {
+			for m := 0; m < 5; m++ {
+				lhs[i][AA][n][m] = -tmp2*fjac[i-1][n][m] - tmp1*njac[i-1][n][m]
+				lhs[i][BB][n][m] = tmp1 * 2.0 * njac[i][n][m]
+				lhs[i][CC][n][m] = tmp2*fjac[i+1][n][m] - tmp1*njac[i+1][n][m]
+			}
+		}
+		for m := 0; m < 5; m++ {
+			lhs[i][AA][m][m] -= tmp1 * d[m]
+			lhs[i][BB][m][m] = 1.0 + lhs[i][BB][m][m] + tmp1*2.0*d[m]
+			lhs[i][CC][m][m] -= tmp1 * d[m]
+		}
+	}
+
+	// Gaussian elimination: multiply the first block row by the inverse of
+	// its diagonal block, then eliminate the lower blocks row by row
+	binvcrhs(&lhs[0][BB], &lhs[0][CC], &rhs[p0])
+	for i := 1; i < size; i++ {
+		p := p0 + i*s
+		matvecSub(&lhs[i][AA], &rhs[p-s], &rhs[p])
+		matmulSub(&lhs[i][AA], &lhs[i-1][CC], &lhs[i][BB])
+		binvcrhs(&lhs[i][BB], &lhs[i][CC], &rhs[p])
+	}
+	p := p0 + size*s
+	matvecSub(&lhs[size][AA], &rhs[p-s], &rhs[p])
+	matmulSub(&lhs[size][AA], &lhs[size-1][CC], &lhs[size][BB])
+	binvrhs(&lhs[size][BB], &rhs[p])
+
+	// Back substitution
+	for i := size - 1; i >= 0; i-- {
+		p := p0 + i*s
+		for m := 0; m < 5; m++ {
+			for n := 0; n < 5; n++ {
+				rhs[p][m] -= lhs[i][CC][n][m] * rhs[p+s][n]
+			}
+		}
+	}
+}
+
+// xSolve solves the block tridiagonal systems of the xi-direction lines
+func (bt *BTBenchmark) xSolve() {
+	if bt.timersEnabled {
+		bt.timers.Start(T_XSOLVE)
+	}
+	isize := bt.nx - 1
+	bt.team.For(1, bt.nz-1, func(k0, k1, id int) {
+		ls := &bt.solvers[id]
+		for k := k0; k < k1; k++ {
+			for j := 1; j < bt.ny-1; j++ {
+				p0 := bt.idx(k, j, 0)
+				for i := 0; i <= isize; i++ {
+					bt.xJacobians(p0+i, &ls.fjac[i], &ls.njac[i])
+				}
+				bt.solveLine(ls, p0, 1, isize, bt.dt*bt.tx1, bt.dt*bt.tx2, &bt.dx)
+			}
+		}
+	})
+	if bt.timersEnabled {
+		bt.timers.Stop(T_XSOLVE)
+	}
+}
+
+// ySolve solves the block tridiagonal systems of the eta-direction lines
+func (bt *BTBenchmark) ySolve() {
+	if bt.timersEnabled {
+		bt.timers.Start(T_YSOLVE)
+	}
+	jsize := bt.ny - 1
+	bt.team.For(1, bt.nz-1, func(k0, k1, id int) {
+		ls := &bt.solvers[id]
+		for k := k0; k < k1; k++ {
+			for i := 1; i < bt.nx-1; i++ {
+				p0 := bt.idx(k, 0, i)
+				for j := 0; j <= jsize; j++ {
+					bt.yJacobians(p0+j*bt.nx, &ls.fjac[j], &ls.njac[j])
+				}
+				bt.solveLine(ls, p0, bt.nx, jsize, bt.dt*bt.ty1, bt.dt*bt.ty2, &bt.dy)
+			}
+		}
+	})
+	if bt.timersEnabled {
+		bt.timers.Stop(T_YSOLVE)
+	}
+}
+
+// zSolve solves the block tridiagonal systems of the zeta-direction lines
+func (bt *BTBenchmark) zSolve() {
+	if bt.timersEnabled {
+		bt.timers.Start(T_ZSOLVE)
+	}
+	ksize := bt.nz - 1
+	s := bt.nx * bt.ny
+	bt.team.For(1, bt.ny-1, func(j0, j1, id int) {
+		ls := &bt.solvers[id]
+		for j := j0; j < j1; j++ {
+			for i := 1; i < bt.nx-1; i++ {
+				p0 := bt.idx(0, j, i)
+				for k := 0; k <= ksize; k++ {
+					bt.zJacobians(p0+k*s, &ls.fjac[k], &ls.njac[k])
+				}
+				bt.solveLine(ls, p0, s, ksize, bt.dt*bt.tz1, bt.dt*bt.tz2, &bt.dz)
+			}
+		}
+	})
+	if bt.timersEnabled {
+		bt.timers.Stop(T_ZSOLVE)
+	}
+}
+
+// add adds the solved increments in rhs to u
+func (bt *BTBenchmark) add() {
+	if bt.timersEnabled {
+		bt.timers.Start(T_ADD)
+	}
+	bt.team.For(1, bt.nz-1, func(k0, k1, _ int) {
+		for k := k0; k < k1; k++ {
+			for j := 1; j < bt.ny-1; j++ {
+				for i := 1; i < bt.nx-1; i++ {
+					p := bt.idx(k, j, i)
+					for m := 0; m < 5; m++ {
+						bt.u[p][m] += bt.rhs[p][m]
+					}
+				}
+			}
+		}
+	})
+	if bt.timersEnabled {
+		bt.timers.Stop(T_ADD)
+	}
+}
+
+// adi performs one time step
+func (bt *BTBenchmark) adi() {
+	bt.computeRHS()
+	bt.xSolve()
+	bt.ySolve()
+	bt.zSolve()
+	bt.add()
+}
+
+// errorNorm returns the RMS norms of the difference between u and the exact
+// solution
+func (bt *BTBenchmark) errorNorm() [5]float64 {
+	var rms [5]float64
+	for k := 0; k < bt.nz; k++ {
+		zeta := float64(k) * bt.dnzm1
+		for j := 0; j < bt.ny; j++ {
+			eta := float64(bt.y0+j) * bt.dnym1
+			for i := 0; i < bt.nx; i++ {
+				xi := float64(bt.x0+i) * bt.dnxm1
+				uExact := bt.exactSolution(xi, eta, zeta)
+				u := &bt.u[bt.idx(k, j, i)]
+				for m := 0; m < 5; m++ {
+					add := u[m] - uExact[m]
+					rms[m] += add * add
+				}
+			}
+		}
+	}
+	return bt.normalize(rms)
+}
+
+// rhsNorm returns the RMS norms of the interior of rhs
+func (bt *BTBenchmark) rhsNorm() [5]float64 {
+	var rms [5]float64
+	for k := 1; k < bt.nz-1; k++ {
+		for j := 1; j < bt.ny-1; j++ {
+			for i := 1; i < bt.nx-1; i++ {
+				r := &bt.rhs[bt.idx(k, j, i)]
+				for m := 0; m < 5; m++ {
+					rms[m] += r[m] * r[m]
+				}
+			}
+		}
+	}
+	return bt.normalize(rms)
+}
+
+// normalize turns sums of squares over the grid into RMS norms
+func (bt *BTBenchmark) normalize(rms [5]float64) [5]float64 {
+	for m := 0; m < 5; m++ {
+		for _, n := range []int{bt.nx, bt.ny, bt.nz} {
+			rms[m] /= float64(n - 2)
+		}
+		rms[m] = math.Sqrt(rms[m])
+	}
+	return rms
+}
+
+// verify computes the residual and error norms of the solution and compares
+// them with the reference values of the class
+func (bt *BTBenchmark) verify() (xcr, xce [5]float64, verified bool) {
+	const epsilon = 1.0e-8
+
+	xce = bt.errorNorm()
+	bt.computeRHS()
+	xcr = bt.rhsNorm()
+	for m := 0; m < 5; m++ {
+		xcr[m] = xcr[m] / bt.dt
+	}
+
+	fmt.Fprintf(bt.out, " Verification being performed for class %s\n", bt.class)
+	fmt.Fprintf(bt.out, " accuracy setting for epsilon = %20.13E\n", epsilon)
+
+	verified = true
+	fmt.Fprintf(bt.out, " Comparison of RMS-norms of residual\n")
+	for m := 0; m < 5; m++ {
+		dif := math.Abs((xcr[m] - bt.xcrRef[m]) / bt.xcrRef[m])
+		if dif <= epsilon {
+			fmt.Fprintf(bt.out, "          %2d%20.13E%20.13E%20.13E\n", m+1, xcr[m], bt.xcrRef[m], dif)
+		} else {
+			verified = false
+			fmt.Fprintf(bt.out, " FAILURE: %2d%20.13E%20.13E%20.13E\n", m+1, xcr[m], bt.xcrRef[m], dif)
+		}
+	}
+	fmt.Fprintf(bt.out, " Comparison of RMS-norms of solution error\n")
+	for m := 0; m < 5; m++ {
+		dif := math.Abs((xce[m] - bt.xceRef[m]) / bt.xceRef[m])
+		if dif <= epsilon {
+			fmt.Fprintf(bt.out, "          %2d%20.13E%20.13E%20.13E\n", m+1, xce[m], bt.xceRef[m], dif)
+		} else {
+			verified = false
+			fmt.Fprintf(bt.out, " FAILURE: %2d%20.13E%20.13E%20.13E\n", m+1, xce[m], bt.xceRef[m], dif)
+		}
+	}
+
+	if verified {
+		fmt.Fprintf(bt.out, " Verification Successful\n")
+	} else {
+		fmt.Fprintf(bt.out, " Verification failed\n")
+	}
+	return xcr, xce, verified
+}
+
+// allocate creates the arrays of the grid and the scratch space of the
+// workers
+func (bt *BTBenchmark) allocate() {
+	points := bt.nx * bt.ny * bt.nz
+	bt.u = make([][5]float64, points)
+	bt.rhs = make([][5]float64, points)
+	bt.forcing = make([][5]float64, points)
+	bt.us = make([]float64, points)
+	bt.vs = make([]float64, points)
+	bt.ws = make([]float64, points)
+	bt.qs = make([]float64, points)
+	bt.rhoI = make([]float64, points)
+	bt.square = make([]float64, points)
+	maxdim := max(bt.nx, bt.ny, bt.nz)
+	bt.solvers = make([]lineSolver, bt.numWorkers)
+	for w := range bt.solvers {
+		bt.solvers[w] = lineSolver{
+			fjac: make([][5][5]float64, maxdim),
+			njac: make([][5][5]float64, maxdim),
+			lhs:  make([][3][5][5]float64, maxdim),
+		}
+	}
+}
+
+// run performs the BT benchmark and returns its result, with an error when
+// ctx stopped its time steps early
+func (bt *BTBenchmark) run(ctx context.Context) (Result, error) {
+	if _, err := os.Stat("timer.flag"); err == nil {
+		bt.timersEnabled = true
+	}
+
+	team, err := common.StartTeam(bt.numWorkers, bt.schedule, bt.bind)
+	if err != nil {
+		return Result{}, err
+	}
+	bt.team = team
+	defer bt.team.Close()
+
+	bt.allocate()
+
+	fmt.Fprintf(bt.out, "\n\n NAS Parallel Benchmarks 4.1 Go Goroutine version - BT Benchmark\n\n")
+	fmt.Fprintf(bt.out, " Size: %4dx%4dx%4d\n", bt.nx, bt.ny, bt.nz)
+	fmt.Fprintf(bt.out, " Iterations: %4d    dt: %10.6f\n", bt.niter, bt.dt)
+	fmt.Fprintf(bt.out, " Number of available workers: %d\n\n", bt.numWorkers)
+
+	for i := 1; i <= T_LAST; i++ {
+		bt.timers.Clear(i)
+	}
+
+	bt.initialize()
+	bt.exactRHS()
+
+	// Do one time step to touch all code, and reinitialize
+	bt.adi()
+	bt.initialize()
+
+	for i := 1; i <= T_LAST; i++ {
+		bt.timers.Clear(i)
+	}
+	bt.timers.Start(T_TOTAL)
+
+	iterations := bt.niter
+	for step := 1; step <= bt.niter; step++ {
+		if ctx.Err() != nil {
+			iterations = step - 1
+			break
+		}
+		if step%20 == 0 || step == 1 {
+			fmt.Fprintf(bt.out, " Time step %4d\n", step)
+		}
+		bt.adi()
+	}
+
+	bt.timers.Stop(T_TOTAL)
+	tmax := bt.timers.Read(T_TOTAL)
+
+	var xcr, xce [5]float64
+	verified := false
+	incomplete := iterations < bt.niter
+	if incomplete {
+		fmt.Fprintf(bt.out, "\n Benchmark stopped after %d of %d time steps\n", iterations, bt.niter)
+		fmt.Fprintf(bt.out, " NO VERIFICATION PERFORMED\n")
+	} else {
+		xcr, xce, verified = bt.verify()
+	}
+
+	mflops := 0.0
+	if tmax != 0.0 {
+		n3 := float64(bt.nx * bt.ny * bt.nz)
+		navg := float64(bt.nx+bt.ny+bt.nz) / 3.0
+		mflops = 1.0e-6 * float64(iterations) *
+			(3478.8*n3 - 17655.7*navg*navg + 28023.7*navg) / tmax
+	}
+
+	result := common.Result{
+		Kernel:      "BT",
+		Class:       bt.class,
+		Size:        [3]int{bt.nx, bt.ny, bt.nz},
+		Iterations:  iterations,
+		Time:        tmax,
+		Mops:        mflops,
+		OpType:      "floating point",
+		Verified:    verified,
+		Incomplete:  incomplete,
+		Workers:     bt.numWorkers,
+		NPBVersion:  "4.1",
+		CompileTime: "Unknown",
+		Compiler:    "Go",
+	}
+	bt.team.Describe(&result)
+	if bt.timersEnabled {
+		names := []string{"", "total", "rhs", "xsolve", "ysolve", "zsolve", "add"}
+		for i := 1; i <= T_LAST; i++ {
+			result.Timers = append(result.Timers, common.Timer{Name: names[i], Seconds: bt.timers.Read(i)})
+		}
+	}
+	common.Finish(&result, bt.out)
+
+	if bt.timersEnabled {
+		fmt.Fprintln(bt.out, "  SECTION   Time (secs)")
+		for _, t := range result.Timers {
+			fmt.Fprintf(bt.out, "  %-8s:%9.3f  (%6.2f%%)\n", t.Name, t.Seconds, t.Seconds*100.0/tmax)
+		}
+	}
+	if incomplete {
+		return Result{result, xcr, xce}, &common.IncompleteError{Completed: iterations, Planned: bt.niter, Err: ctx.Err()}
+	}
+	return Result{result, xcr, xce}, nil
+}
+
+// lhsinit sets the first and last block rows of the system to identity rows,
+// which keeps the boundary values of rhs
+func lhsinit(lhs [][3][5][5]float64, size int) {
+	for _, i := range []int{0, size} {
+		lhs[i] = [3][5][5]float64{}
+		for m := 0; m < 5; m++ {
+			lhs[i][BB][m][m] = 1.0
+		}
+	}
+}
+
+// The 5x5 blocks below are stored by column: block[c][r] is row r, column c.
+
+// matvecSub subtracts a*v from b
+func matvecSub(a *[5][5]float64, v, b *[5]float64) {
+	for r := 0; r < 5; r++ {
+		b[r] = b[r] - a[0][r]*v[0] - a[1][r]*v[1] - a[2][r]*v[2] - a[3][r]*v[3] - a[4][r]*v[4]
+	}
+}
+
+// matmulSub subtracts a*b from c
+func matmulSub(a, b, c *[5][5]float64) {
+	for col := 0; col < 5; col++ {
+		for r := 0; r < 5; r++ {
+			c[col][r] = c[col][r] - a[0][r]*b[col][0] - a[1][r]*b[col][1] - a[2][r]*b[col][2] - a[3][r]*b[col][3] - a[4][r]*b[col][4]
+		}
+	}
+}
+
+// binvcrhs overwrites c with lhs⁻¹c and r with lhs⁻¹r by Gauss-Jordan
+// elimination without pivoting, destroying lhs
+func binvcrhs(lhs, c *[5][5]float64, r *[5]float64) {
+	for p := 0; p < 5; p++ {
+		pivot := 1.00 / lhs[p][p]
+		for col := p + 1; col < 5; col++ {
+			lhs[col][p] = lhs[col][p] * pivot
+		}
+		for col := 0; col < 5; col++ {
+			c[col][p] = c[col][p] * pivot
+		}
+		r[p] = r[p] * pivot
+
+		for q := 0; q < 5; q++ {
+			if q == p {
+				continue
+			}
+			coeff := lhs[p][q]
+			for col := p + 1; col < 5; col++ {
+				lhs[col][q] = lhs[col][q] - coeff*lhs[col][p]
+			}
+			for col := 0; col < 5; col++ {
+				c[col][q] = c[col][q] - coeff*c[col][p]
+			}
+			r[q] = r[q] - coeff*r[p]
+		}
+	}
+}
+
+// binvrhs overwrites r with lhs⁻¹r, destroying lhs
+func binvrhs(lhs *[5][5]float64, r *[5]float64) {
+	for p := 0; p < 5; p++ {
+		pivot := 1.00 / lhs[p][p]
+		for col := p + 1; col < 5; col++ {
+			lhs[col][p] = lhs[col][p] * pivot
+		}
+		r[p] = r[p] * pivot
+
+		for q := 0; q < 5; q++ {
+			if q == p {
+				continue
+			}
+			coeff := lhs[p][q]
+			for col := p + 1; col < 5; col++ {
+				lhs[col][q] = lhs[col][q] - coeff*lhs[col][p]
+			}
+			r[q] = r[q] - coeff*r[p]
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package bt
+
+import (
+	"io"
+
+	"github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/common"
+)
+
+// Zone is one zone of a multi-zone BT problem (BT-MZ): a block of a larger
+// grid that is solved like the single-zone grid, except that its x and y
+// faces hold copies of the points next to them in the neighbouring zones
+type Zone struct {
+	bt *BTBenchmark
+}
+
+// NewZone creates the zone of nx x ny x nz points whose first point is at
+// (x0, y0, 0) in a grid of gx x gy x nz points, advanced with time step dt
+// and with its loops run on the workers of team
+func NewZone(nx, ny, nz, x0, y0, gx, gy int, dt float64, team *common.Team) *Zone {
+	bt := &BTBenchmark{
+		nx:         nx,
+		ny:         ny,
+		nz:         nz,
+		gx:         gx,
+		gy:         gy,
+		x0:         x0,
+		y0:         y0,
+		dt:         dt,
+		numWorkers: team.Workers(),
+		team:       team,
+		out:        io.Discard,
+	}
+	bt.setConstants()
+	bt.allocate()
+	bt.initialize()
+	bt.exactRHS()
+	return &Zone{bt}
+}
+
+// Initialize sets u back to its initial values
+func (z *Zone) Initialize() {
+	z.bt.initialize()
+}
+
+// Step advances the zone by one time step
+func (z *Zone) Step() {
+	z.bt.adi()
+}
+
+// U returns the conserved variables at every point of the zone, point
+// (i, j, k) at (k*ny+j)*nx+i
+func (z *Zone) U() [][5]float64 {
+	return z.bt.u
+}
+
+// Norms returns the sums over the interior points of the squared residual,
+// divided by dt, and of the squared difference from the exact solution
+func (z *Zone) Norms() (rhs, err [5]float64) {
+	bt := z.bt
+	bt.computeRHS()
+	for k := 1; k < bt.nz-1; k++ {
+		zeta := float64(k) * bt.dnzm1
+		for j := 1; j < bt.ny-1; j++ {
+			eta := float64(bt.y0+j) * bt.dnym1
+			for i := 1; i < bt.nx-1; i++ {
+				xi := float64(bt.x0+i) * bt.dnxm1
+				uExact := bt.exactSolution(xi, eta, zeta)
+				p := bt.idx(k, j, i)
+				for m := 0; m < 5; m++ {
+					r := bt.rhs[p][m] / bt.dt
+					rhs[m] += r * r
+					e := bt.u[p][m] - uExact[m]
+					err[m] += e * e
+				}
+			}
+		}
+	}
+	return rhs, err
+}
+
+// Ops returns the floating point operations of one time step
+func (z *Zone) Ops() float64 {
+	n3 := float64(z.bt.nx * z.bt.ny * z.bt.nz)
+	navg := float64(z.bt.nx+z.bt.ny+z.bt.nz) / 3.0
+	return 3478.8*n3 - 17655.7*navg*navg + 28023.7*navg
+}
@@ -0,0 +1,46 @@
+package params
+
+import "strings"
+
+// Params holds the grid size, time step and reference norms of one BT class
+type Params struct {
+	CLASS        string
+	PROBLEM_SIZE int // grid points in each direction
+	NITER        int
+	DT           float64
+	XCR_REF      [5]float64 // RMS norms of the residual
+	XCE_REF      [5]float64 // RMS norms of the solution error
+}
+
+// Classes lists the standard problem classes in increasing size
+var Classes = []string{"S", "W", "A", "B", "C", "D", "E"}
+
+var table = map[string]Params{
+	"S": {CLASS: "S", PROBLEM_SIZE: 12, NITER: 60, DT: 0.010,
+		XCR_REF: [5]float64{1.7034283709541311e-01, 1.2975252070034097e-02, 3.2527926989486055e-02, 2.6436421275166801e-02, 1.9211784131744430e-01},
+		XCE_REF: [5]float64{4.9976913345811579e-04, 4.5195666782961927e-05, 7.3973765172921357e-05, 7.3821238632439731e-05, 8.9269630987491446e-04}},
+	"W": {CLASS: "W", PROBLEM_SIZE: 24, NITER: 200, DT: 0.0008,
+		XCR_REF: [5]float64{0.1125590409344e+03, 0.1180007595731e+02, 0.2710329767846e+02, 0.2469174937669e+02, 0.2638427874317e+03},
+		XCE_REF: [5]float64{0.4419655736008e+01, 0.4638531260002e+00, 0.1011551749967e+01, 0.9235878729944e+00, 0.1018045837718e+02}},
+	"A": {CLASS: "A", PROBLEM_SIZE: 64, NITER: 200, DT: 0.0008,
+		XCR_REF: [5]float64{1.0806346714637264e+02, 1.1319730901220813e+01, 2.5974354511582465e+01, 2.3665622544678910e+01, 2.5278963211748344e+02},
+		XCE_REF: [5]float64{4.2348416040525025e+00, 4.4390282496995698e-01, 9.6692480136345650e-01, 8.8302063039765474e-01, 9.7379901770829278e+00}},
+	"B": {CLASS: "B", PROBLEM_SIZE: 102, NITER: 200, DT: 0.0003,
+		XCR_REF: [5]float64{1.4233597229287254e+03, 9.9330522590150238e+01, 3.5646025644535285e+02, 3.2485447959084092e+02, 3.2707541254659363e+03},
+		XCE_REF: [5]float64{5.2969847140936856e+01, 4.4632896115670668e+00, 1.3122573342210174e+01, 1.2006925323559144e+01, 1.2459576151035986e+02}},
+	"C": {CLASS: "C", PROBLEM_SIZE: 162, NITER: 200, DT: 0.0001,
+		XCR_REF: [5]float64{0.62398116551764615e+04, 0.50793239190423964e+03, 0.15423530093013596e+04, 0.13302387929291190e+04, 0.11604087428436455e+05},
+		XCE_REF: [5]float64{0.16462008369091265e+03, 0.11497107903824313e+02, 0.41207446207461508e+02, 0.37087651059694167e+02, 0.36211053051841265e+03}},
+	"D": {CLASS: "D", PROBLEM_SIZE: 408, NITER: 250, DT: 0.00002,
+		XCR_REF: [5]float64{0.2533188551738e+05, 0.2346393716980e+04, 0.6294554366904e+04, 0.5352565376030e+04, 0.3905864038618e+05},
+		XCE_REF: [5]float64{0.3100009377557e+03, 0.2424086324913e+02, 0.7782212022645e+02, 0.6835623860116e+02, 0.6065737200368e+03}},
+	"E": {CLASS: "E", PROBLEM_SIZE: 1020, NITER: 250, DT: 0.4e-5,
+		XCR_REF: [5]float64{0.9795372484517e+05, 0.9739814511521e+04, 0.2467606342965e+05, 0.2092419572860e+05, 0.1392138856939e+06},
+		XCE_REF: [5]float64{0.4565775155815e+03, 0.3472903059720e+02, 0.1122208001706e+03, 0.9932089090880e+02, 0.8909706809540e+03}},
+}
+
+// Lookup returns the parameters of the given class
+func Lookup(class string) (Params, bool) {
+	p, ok := table[strings.ToUpper(class)]
+	return p, ok
+}
@@ -0,0 +1,646 @@
+// Package cg is the CG (Conjugate Gradient) kernel: it estimates the smallest
+// eigenvalue of a large sparse symmetric positive definite matrix.
+package cg
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/CG/params"
+	"github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/common"
+)
+
+// Constants
+const (
+	MAX_ITERATIONS = 100
+	MAX_NA         = 1500000
+	MAX_NZ         = 27 * MAX_NA
+	MAX_NONZER     = 26
+)
+
+// Config selects the problem a Run solves
+type Config struct {
+	Params   params.Params   // problem size, from params.Lookup or params.Custom
+	Workers  int             // goroutines; 0 means $GO_NUM_THREADS, or one per CPU
+	Schedule common.Schedule // of the loops over the matrix rows; none means $GO_SCHEDULE
+	Bind     common.Binding  // of the workers to cores; none means $GO_PROC_BIND
+	Out      io.Writer       // progress messages and the NPB banner, discarded when nil
+
+	// Input, from an upstream task of a workflow, perturbs the starting
+	// vector of the timed iterations, which is otherwise all ones. The run
+	// is then of class U, with nothing to verify.
+	Input  *common.Field
+	Output bool // return the final x in Result.Output
+}
+
+// Result is the outcome of a CG run with the zeta it is verified on
+type Result struct {
+	common.Result
+	Zeta   float64
+	Output *common.Field // the normalized eigenvector estimate x, when Config.Output is set
+}
+
+// Run generates the matrix of cfg.Params, runs the benchmark on it and returns
+// its result. When ctx is done between two iterations the run stops there and
+// Run returns the partial result with a *common.IncompleteError.
+func Run(ctx context.Context, cfg Config) (Result, error) {
+	if err := ctx.Err(); err != nil {
+		return Result{}, err
+	}
+	workers, err := common.ResolveWorkers(cfg.Workers)
+	if err != nil {
+		return Result{}, err
+	}
+	out := cfg.Out
+	if out == nil {
+		out = io.Discard
+	}
+	sched, err := common.ResolveSchedule(cfg.Schedule)
+	if err != nil {
+		return Result{}, err
+	}
+	bind, err := common.ResolveBinding(cfg.Bind)
+	if err != nil {
+		return Result{}, err
+	}
+	p := cfg.Params
+	if cfg.Input != nil {
+		p.CLASS = params.UserClass
+	}
+	cg := NewCGBenchmark(p, workers, out)
+	cg.schedule = sched
+	cg.bind = bind
+	cg.input = cfg.Input
+	cg.output = cfg.Output
+	return cg.run(ctx)
+}
+
+// CGBenchmark represents the CG benchmark
+type CGBenchmark struct {
+	// Problem size parameters
+	NA     int
+	NZ     int
+	NITER  int
+	SHIFT  float64
+	NONZER int
+
+	naa        int
+	nzz        int
+	firstrow   int
+	lastrow    int
+	firstcol   int
+	lastcol    int
+	numWorkers int
+	schedule   common.Schedule
+	bind       common.Binding
+
+	// Verification
+	zetaVerifyValue float64
+	classNPB        string
+
+	input  *common.Field // perturbation of the starting vector, nil for none
+	output bool
+
+	out io.Writer
+}
+
+// NewCGBenchmark creates a CG benchmark instance for one problem size,
+// running on numWorkers goroutines
+func NewCGBenchmark(prob params.Params, numWorkers int, out io.Writer) *CGBenchmark {
+	nz := prob.NA * (prob.NONZER + 1) * (prob.NONZER + 1)
+	return &CGBenchmark{
+		NA:              prob.NA,
+		NZ:              nz,
+		NITER:           prob.NITER,
+		SHIFT:           prob.SHIFT,
+		NONZER:          prob.NONZER,
+		naa:             prob.NA,
+		nzz:             nz,
+		firstrow:        0,
+		lastrow:         prob.NA - 1,
+		firstcol:        0,
+		lastcol:         prob.NA - 1,
+		numWorkers:      numWorkers,
+		zetaVerifyValue: prob.ZETA_VERIFY_VALUE,
+		classNPB:        prob.CLASS,
+		out:             out,
+	}
+}
+
+// icnvrt scales a double precision number x in (0,1) by a power of 2 and chops it
+func icnvrt(x float64, ipwr2 int) int {
+	return int(float64(ipwr2) * x)
+}
+
+// sprnvc generates a sparse n-vector (v, iv) having nzv nonzeros
+func sprnvc(n, nz, nn1 int, v []float64, iv []int, tran *float64) {
+	nzv := 0
+	amult := 1220703125.0
+
+	for nzv < nz {
+		vecelt := common.Randlc(tran, amult)
+		vecloc := common.Randlc(tran, amult)
+		i := icnvrt(vecloc, nn1) + 1
+		if i > n {
+			continue
+		}
+
+		// Check if this integer was already generated
+		wasGen := false
+		for ii := 0; ii < nzv; ii++ {
+			if iv[ii] == i {
+				wasGen = true
+				break
+			}
+		}
+		if wasGen {
+			continue
+		}
+		v[nzv] = vecelt
+		iv[nzv] = i
+		nzv++
+	}
+}
+
+// vecset sets ith element of sparse vector (v, iv) with nzv nonzeros to val
+func vecset(n int, v []float64, iv []int, nzv *int, i int, val float64) {
+	set := false
+	for k := 0; k < *nzv; k++ {
+		if iv[k] == i {
+			v[k] = val
+			set = true
+		}
+	}
+	if !set {
+		v[*nzv] = val
+		iv[*nzv] = i
+		*nzv++
+	}
+}
+
+// SparseSpaceError reports that the matrix generated by sparse needs more
+// nonzeros than were allocated for it
+type SparseSpaceError struct {
+	NZA, NZMax int
+}
+
+func (e *SparseSpaceError) Error() string {
+	return fmt.Sprintf("space for matrix elements exceeded in sparse: nza, nzmax = %d, %d", e.NZA, e.NZMax)
+}
+
+// SparseInternalError reports an element of row Row that sparse found no
+// slot for
+type SparseInternalError struct {
+	Row int
+}
+
+func (e *SparseInternalError) Error() string {
+	return fmt.Sprintf("internal error in sparse: i=%d", e.Row)
+}
+
+// sparse generates a sparse matrix from a list of [col, row, element] triples
+func sparse(a []float64, colidx []int, rowstr []int, n int, nz int, nozer int,
+	arow []int, acol [][]int, aelt [][]float64, firstrow, lastrow int, nzloc []int, rcond, shift float64) error {
+
+	nrows := lastrow - firstrow + 1
+
+	// Count the number of triples in each row
+	for j := 0; j < nrows+1; j++ {
+		rowstr[j] = 0
+	}
+	for i := 0; i < n; i++ {
+		for nza := 0; nza < arow[i]; nza++ {
+			j := acol[i][nza] + 1
+			rowstr[j] += arow[i]
+		}
+	}
+	rowstr[0] = 0
+	for j := 1; j < nrows+1; j++ {
+		rowstr[j] += rowstr[j-1]
+	}
+	nza := rowstr[nrows] - 1
+
+	if nza > nz {
+		return &SparseSpaceError{NZA: nza, NZMax: nz}
+	}
+
+	// Preload data pages
+	for j := 0; j < nrows; j++ {
+		for k := rowstr[j]; k < rowstr[j+1]; k++ {
+			a[k] = 0.0
+			colidx[k] = -1
+		}
+		nzloc[j] = 0
+	}
+
+	// Generate actual values by summing duplicates
+	size := 1.0
+	ratio := math.Pow(rcond, 1.0/float64(n))
+	for i := 0; i < n; i++ {
+		for nza := 0; nza < arow[i]; nza++ {
+			j := acol[i][nza]
+			scale := size * aelt[i][nza]
+			for nzrow := 0; nzrow < arow[i]; nzrow++ {
+				jcol := acol[i][nzrow]
+				va := aelt[i][nzrow] * scale
+
+				// Add the identity * rcond to the generated matrix
+				if jcol == j && j == i {
+					va = va + rcond - shift
+				}
+
+				goto40 := false
+				k := 0
+				for k = rowstr[j]; k < rowstr[j+1]; k++ {
+					if colidx[k] > jcol {
+						// Insert colidx here orderly
+						for kk := rowstr[j+1] - 2; kk >= k; kk-- {
+							if colidx[kk] > -1 {
+								a[kk+1] = a[kk]
+								colidx[kk+1] = colidx[kk]
+							}
+						}
+						colidx[k] = jcol
+						a[k] = 0.0
+						goto40 = true
+						break
+					} else if colidx[k] == -1 {
+						colidx[k] = jcol
+						goto40 = true
+						break
+					} else if colidx[k] == jcol {
+						// Mark the duplicated entry
+						nzloc[j]++
+						goto40 = true
+						break
+					}
+				}
+				if !goto40 {
+					return &SparseInternalError{Row: i}
+				}
+				a[k] += va
+			}
+		}
+		size *= ratio
+	}
+
+	// Remove empty entries and generate final results
+	for j := 1; j < nrows; j++ {
+		nzloc[j] += nzloc[j-1]
+	}
+
+	for j := 0; j < nrows; j++ {
+		j1 := 0
+		if j > 0 {
+			j1 = rowstr[j] - nzloc[j-1]
+		}
+		j2 := rowstr[j+1] - nzloc[j]
+		nza := rowstr[j]
+		for k := j1; k < j2; k++ {
+			a[k] = a[nza]
+			colidx[k] = colidx[nza]
+			nza++
+		}
+	}
+	for j := 1; j < nrows+1; j++ {
+		rowstr[j] -= nzloc[j-1]
+	}
+	return nil
+}
+
+// makea generates the sparse matrix A - complete implementation
+func (cg *CGBenchmark) makea(naa, nzz int, a []float64, colidx []int, rowstr []int,
+	firstrow, lastrow, firstcol, lastcol int) error {
+
+	// Initialize random number generator
+	tran := 314159265.0
+	amult := 1220703125.0
+	common.Randlc(&tran, amult)
+
+	// Allocate workspace arrays
+	arow := make([]int, naa)
+	acol := make([][]int, naa)
+	aelt := make([][]float64, naa)
+	for i := 0; i < naa; i++ {
+		acol[i] = make([]int, cg.NONZER+1)
+		aelt[i] = make([]float64, cg.NONZER+1)
+	}
+	nzloc := make([]int, lastrow-firstrow+1)
+
+	// nn1 is the smallest power of two not less than n
+	nn1 := 1
+	for nn1 < naa {
+		nn1 *= 2
+	}
+
+	// Generate nonzero positions and save for the use in sparse
+	for iouter := 0; iouter < naa; iouter++ {
+		nzv := cg.NONZER
+		ivc := make([]int, cg.NONZER+1)
+		vc := make([]float64, cg.NONZER+1)
+		sprnvc(naa, nzv, nn1, vc, ivc, &tran)
+		vecset(naa, vc, ivc, &nzv, iouter+1, 0.5)
+		arow[iouter] = nzv
+		for ivelt := 0; ivelt < nzv; ivelt++ {
+			acol[iouter][ivelt] = ivc[ivelt] - 1
+			aelt[iouter][ivelt] = vc[ivelt]
+		}
+	}
+
+	// Make the sparse matrix from list of elements with duplicates
+	return sparse(a, colidx, rowstr, naa, nzz, cg.NONZER, arow, acol, aelt, firstrow, lastrow, nzloc, 0.1, cg.SHIFT)
+}
+
+// conj_grad performs the conjugate gradient algorithm on the workers of team,
+// in one parallel region: the workers keep their blocks of the vectors over
+// the iterations and meet at a barrier before every step that reads the
+// blocks of the others. The products with A, whose rows differ in length,
+// are shared out by the schedule of the team. It returns the residual norm
+// ||x - A.z||.
+func (cg *CGBenchmark) conj_grad(team *common.Team, colidx []int, rowstr []int, x []float64, z []float64, a []float64,
+	p []float64, q []float64, r []float64) float64 {
+
+	cgitmax := 25
+	ncols := cg.lastcol - cg.firstcol + 1
+	nrows := cg.lastrow - cg.firstrow + 1
+
+	var rnorm float64
+	team.Run(func(w *common.Worker) {
+		// Initialize the CG algorithm
+		lo, hi := w.Range(0, cg.NA+1)
+		for j := lo; j < hi; j++ {
+			q[j] = 0.0
+			z[j] = 0.0
+			r[j] = x[j]
+			p[j] = r[j]
+		}
+		w.Barrier()
+
+		// rho = r.r
+		clo, chi := w.Range(0, ncols)
+		localRho := 0.0
+		for j := clo; j < chi; j++ {
+			localRho += r[j] * r[j]
+		}
+		rho := w.Sum(localRho)
+
+		// Main loop of the conjugate gradient
+		for cgit := 1; cgit <= cgitmax; cgit++ {
+			rho0 := rho
+
+			// q = A.p
+			w.For(0, nrows, func(lo, hi int) {
+				for j := lo; j < hi; j++ {
+					suml := 0.0
+					for k := rowstr[j]; k < rowstr[j+1]; k++ {
+						suml += a[k] * p[colidx[k]]
+					}
+					q[j] = suml
+				}
+			})
+
+			// d = p.q
+			localD := 0.0
+			for j := clo; j < chi; j++ {
+				localD += p[j] * q[j]
+			}
+			d := w.Sum(localD)
+
+			alpha := 0.0
+			if d != 0.0 {
+				alpha = rho0 / d
+			}
+
+			// z = z + alpha*p, r = r - alpha*q and rho = r.r
+			localRho = 0.0
+			for j := clo; j < chi; j++ {
+				z[j] += alpha * p[j]
+				r[j] -= alpha * q[j]
+				localRho += r[j] * r[j]
+			}
+			rho = w.Sum(localRho)
+
+			beta := 0.0
+			if rho0 != 0.0 {
+				beta = rho / rho0
+			}
+
+			// p = r + beta*p
+			for j := clo; j < chi; j++ {
+				p[j] = r[j] + beta*p[j]
+			}
+			w.Barrier()
+		}
+
+		// Residual norm ||x - A.z||, with A.z in r
+		w.For(0, nrows, func(lo, hi int) {
+			for j := lo; j < hi; j++ {
+				suml := 0.0
+				for k := rowstr[j]; k < rowstr[j+1]; k++ {
+					suml += a[k] * z[colidx[k]]
+				}
+				r[j] = suml
+			}
+		})
+		localSum := 0.0
+		for j := clo; j < chi; j++ {
+			diff := x[j] - r[j]
+			localSum += diff * diff
+		}
+		sum := w.Sum(localSum)
+		if w.ID == 0 {
+			rnorm = math.Sqrt(sum)
+		}
+	})
+	return rnorm
+}
+
+// normalize computes norm_temp1 = x.z and norm_temp2 = 1/||z||, sets x to
+// z*norm_temp2 and returns norm_temp1
+func (cg *CGBenchmark) normalize(team *common.Team, x, z []float64) float64 {
+	ncols := cg.lastcol - cg.firstcol + 1
+	var normTemp1 float64
+	team.Run(func(w *common.Worker) {
+		lo, hi := w.Range(0, ncols)
+		var localNorm1, localNorm2 float64
+		for j := lo; j < hi; j++ {
+			localNorm1 += x[j] * z[j]
+			localNorm2 += z[j] * z[j]
+		}
+		norm1 := w.Sum(localNorm1)
+		norm2 := 1.0 / math.Sqrt(w.Sum(localNorm2))
+		for j := lo; j < hi; j++ {
+			x[j] = norm2 * z[j]
+		}
+		if w.ID == 0 {
+			normTemp1 = norm1
+		}
+	})
+	return normTemp1
+}
+
+// run performs the CG benchmark and returns its result, or the error that
+// kept the matrix from being generated or stopped the iterations early
+func (cg *CGBenchmark) run(ctx context.Context) (Result, error) {
+	// Allocate arrays
+	a := make([]float64, cg.NZ)
+	colidx := make([]int, cg.NZ)
+	rowstr := make([]int, cg.NA+1)
+	x := make([]float64, cg.NA+1)
+	z := make([]float64, cg.NA+1)
+	p := make([]float64, cg.NA+1)
+	q := make([]float64, cg.NA+1)
+	r := make([]float64, cg.NA+1)
+
+	// Initialize arrays
+	naa := cg.naa
+	nzz := cg.nzz
+
+	// Initialize random number generator
+	tran := 314159265.0
+	amult := 1220703125.0
+	common.Randlc(&tran, amult)
+
+	// Generate matrix
+	if err := cg.makea(naa, nzz, a, colidx, rowstr, cg.firstrow, cg.lastrow, cg.firstcol, cg.lastcol); err != nil {
+		return Result{}, err
+	}
+
+	team, err := common.StartTeam(cg.numWorkers, cg.schedule, cg.bind)
+	if err != nil {
+		return Result{}, err
+	}
+	defer team.Close()
+
+	// Shift column indices
+	nrows := cg.lastrow - cg.firstrow + 1
+	team.For(0, nrows, func(lo, hi, _ int) {
+		for j := lo; j < hi; j++ {
+			for k := rowstr[j]; k < rowstr[j+1]; k++ {
+				colidx[k] = colidx[k] - cg.firstcol
+			}
+		}
+	})
+
+	// Set starting vector to (1, 1, ..., 1)
+	setOnes := func(lo, hi, _ int) {
+		for i := lo; i < hi; i++ {
+			x[i] = 1.0
+		}
+	}
+	team.For(0, cg.NA+1, setOnes)
+
+	// Initialize vectors
+	team.For(0, cg.NA, func(lo, hi, _ int) {
+		for j := lo; j < hi; j++ {
+			q[j] = 0.0
+			z[j] = 0.0
+			r[j] = 0.0
+			p[j] = 0.0
+		}
+	})
+
+	zeta := 0.0
+	verified := false
+
+	// Do one iteration untimed to init all code and data page tables
+	for it := 1; it <= 1; it++ {
+		cg.conj_grad(team, colidx, rowstr, x, z, a, p, q, r)
+		cg.normalize(team, x, z)
+	}
+
+	// Set starting vector to (1, 1, ..., 1) again
+	team.For(0, cg.NA+1, setOnes)
+	if cg.input != nil {
+		for i, v := range cg.input.Sample(cg.NA) {
+			x[i] = 1.0 + 0.5*v
+		}
+	}
+	zeta = 0.0
+
+	// Main CG loop
+	startTime := time.Now()
+
+	iterations := cg.NITER
+	for it := 1; it <= cg.NITER; it++ {
+		if ctx.Err() != nil {
+			iterations = it - 1
+			break
+		}
+
+		rnorm := cg.conj_grad(team, colidx, rowstr, x, z, a, p, q, r)
+
+		// zeta from norm_temp1 = x.z, before z is normalized into x
+		normTemp1 := cg.normalize(team, x, z)
+		zeta = cg.SHIFT + 1.0/normTemp1
+
+		if it == 1 {
+			fmt.Fprintf(cg.out, "\n   iteration           ||r||                 zeta\n")
+		}
+		fmt.Fprintf(cg.out, "    %5d       %20.14e%20.13e\n", it, rnorm, zeta)
+	}
+
+	endTime := time.Now()
+	elapsed := endTime.Sub(startTime).Seconds()
+
+	// Calculate Mop/s using the same formula as C++
+	mops := float64(2*iterations*cg.NA) * (3.0 + float64(cg.NONZER*(cg.NONZER+1)) + 25.0*(5.0+float64(cg.NONZER*(cg.NONZER+1))) + 3.0) / elapsed / 1e6
+
+	// Verify result
+	incomplete := iterations < cg.NITER
+	if incomplete {
+		fmt.Fprintf(cg.out, "\n Benchmark stopped after %d of %d iterations\n", iterations, cg.NITER)
+		fmt.Fprintf(cg.out, " NO VERIFICATION PERFORMED\n")
+		fmt.Fprintf(cg.out, " Zeta is    %20.13e\n", zeta)
+	} else if cg.classNPB == "U" {
+		fmt.Fprintf(cg.out, "\n Benchmark completed\n")
+		fmt.Fprintf(cg.out, " Problem size unknown\n")
+		fmt.Fprintf(cg.out, " NO VERIFICATION PERFORMED\n")
+		fmt.Fprintf(cg.out, " Zeta is    %20.13e\n", zeta)
+	} else {
+		fmt.Fprintf(cg.out, "\n Benchmark completed\n")
+		verified = math.Abs(zeta-cg.zetaVerifyValue) < 1e-10
+		err := math.Abs(zeta-cg.zetaVerifyValue) / cg.zetaVerifyValue
+
+		// Print detailed verification results
+		if verified {
+			fmt.Fprintf(cg.out, " VERIFICATION SUCCESSFUL\n")
+			fmt.Fprintf(cg.out, " Zeta is    %20.13e\n", zeta)
+			fmt.Fprintf(cg.out, " Error is   %20.13e\n", err)
+		} else {
+			fmt.Fprintf(cg.out, " VERIFICATION FAILED\n")
+			fmt.Fprintf(cg.out, " Zeta                %20.13e\n", zeta)
+			fmt.Fprintf(cg.out, " The correct zeta is %20.13e\n", cg.zetaVerifyValue)
+		}
+	}
+
+	// Print results
+	result := common.Result{
+		Kernel:      "CG",
+		Class:       cg.classNPB,
+		Size:        [3]int{cg.NA, 0, 0},
+		Iterations:  iterations,
+		Time:        elapsed,
+		Mops:        mops,
+		OpType:      "conjugate gradient",
+		Verified:    verified,
+		Incomplete:  incomplete,
+		Workers:     cg.numWorkers,
+		NPBVersion:  "4.1",
+		CompileTime: "Unknown",
+		Compiler:    "Go",
+	}
+	team.Describe(&result)
+	common.Finish(&result, cg.out)
+	res := Result{Result: result, Zeta: zeta}
+	if cg.output {
+		res.Output = &common.Field{NX: cg.NA, NY: 1, NZ: 1, Data: append([]float64(nil), x[:cg.NA]...)}
+	}
+	if incomplete {
+		return res, &common.IncompleteError{Completed: iterations, Planned: cg.NITER, Err: ctx.Err()}
+	}
+	return res, nil
+}
@@ -0,0 +1,144 @@
+package cg
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"math"
+	"testing"
+
+	"github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/CG/params"
+	"github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/common"
+	"github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/common/npbtest"
+)
+
+var long = flag.Bool("long", false, "also verify class A")
+
+// testClasses lists the classes the verification tests run
+func testClasses() []string {
+	if *long {
+		return []string{"S", "W", "A"}
+	}
+	return []string{"S", "W"}
+}
+
+func BenchmarkCG(b *testing.B) {
+	for _, class := range []string{"S", "W"} {
+		b.Run(class, func(b *testing.B) {
+			p, _ := params.Lookup(class)
+			mops := 0.0
+			for i := 0; i < b.N; i++ {
+				result, err := Run(context.Background(), Config{Params: p})
+				if err != nil {
+					b.Fatal(err)
+				}
+				if result.Failed() {
+					b.Fatalf("class %s failed verification", class)
+				}
+				mops += result.Mops
+			}
+			b.ReportMetric(mops/float64(b.N), "Mop/s")
+		})
+	}
+}
+
+func TestVerification(t *testing.T) {
+	for _, class := range testClasses() {
+		t.Run(class, func(t *testing.T) {
+			prob, _ := params.Lookup(class)
+			result, err := Run(context.Background(), Config{Params: prob})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if math.Abs(result.Zeta-prob.ZETA_VERIFY_VALUE) >= 1e-10 {
+				t.Errorf("zeta = %.13e, want %.13e", result.Zeta, prob.ZETA_VERIFY_VALUE)
+			}
+			if !result.Verified {
+				t.Error("kernel reported a failed verification")
+			}
+		})
+	}
+}
+
+func TestSparseSpaceExceeded(t *testing.T) {
+	arow := []int{2, 2}
+	acol := [][]int{{0, 1}, {0, 1}}
+	aelt := [][]float64{{1, 1}, {1, 1}}
+	err := sparse(nil, nil, make([]int, 3), 2, 1, 1, arow, acol, aelt, 0, 1, nil, 0.1, 0)
+	var spaceErr *SparseSpaceError
+	if !errors.As(err, &spaceErr) {
+		t.Fatalf("sparse returned %v, want a *SparseSpaceError", err)
+	}
+	if spaceErr.NZMax != 1 {
+		t.Errorf("NZMax = %d, want 1", spaceErr.NZMax)
+	}
+}
+
+// TestWorkers checks that the kernel verifies on teams of any size, including
+// one with more workers than the loops have iterations to share
+func TestWorkers(t *testing.T) {
+	p, _ := params.Lookup("S")
+	for _, workers := range []int{1, 3, 64} {
+		result, err := Run(context.Background(), Config{Params: p, Workers: workers})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Workers != workers || !result.Verified {
+			t.Errorf("%d workers: got %d workers, verified %v", workers, result.Workers, result.Verified)
+		}
+	}
+}
+
+// TestSchedules checks that the kernel verifies under every kind of schedule
+func TestSchedules(t *testing.T) {
+	p, _ := params.Lookup("S")
+	for _, text := range []string{"static", "static,3", "dynamic", "dynamic,7", "guided", "guided,2"} {
+		sched, err := common.ParseSchedule(text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result, err := Run(context.Background(), Config{Params: p, Workers: 3, Schedule: sched})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Schedule != text || !result.Verified {
+			t.Errorf("%s: got schedule %q, verified %v", text, result.Schedule, result.Verified)
+		}
+	}
+}
+
+func TestScheduleFromEnvironment(t *testing.T) {
+	p, _ := params.Lookup("S")
+	t.Setenv("GO_SCHEDULE", "Dynamic, 4")
+	result, err := Run(context.Background(), Config{Params: p, Workers: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Schedule != "dynamic,4" {
+		t.Errorf("schedule = %q, want dynamic,4", result.Schedule)
+	}
+
+	t.Setenv("GO_SCHEDULE", "cyclic")
+	if _, err := Run(context.Background(), Config{Params: p}); err == nil {
+		t.Error("GO_SCHEDULE=cyclic was accepted")
+	}
+}
+
+// runClass runs the kernel at class for the checks every kernel shares
+func runClass(ctx context.Context, class string) (common.Result, error) {
+	p, _ := params.Lookup(class)
+	result, err := Run(ctx, Config{Params: p})
+	return result.Result, err
+}
+
+// TestConcurrentRuns checks that runs of two classes in one process do not
+// share state
+func TestConcurrentRuns(t *testing.T) {
+	npbtest.ConcurrentRuns(t, runClass, "S", "W")
+}
+
+// TestStoppedRun checks a run stopped by its context. Run checks once before
+// starting and then before every iteration
+func TestStoppedRun(t *testing.T) {
+	npbtest.StoppedRun(t, runClass, "S", "iterations")
+}
@@ -1,14 +0,0 @@
-//go:build B
-
-package params
-
-const (
-	CLASS           = "B"
-	NA              = 75000
-	NITER           = 75
-	SHIFT           = 60.0
-	NONZER          = 13
-	EmptyTag        = false
-	ZETA_VERIFY_VALUE = 22.712745482631
-)
-
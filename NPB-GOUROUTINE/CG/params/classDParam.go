@@ -1,14 +0,0 @@
-//go:build D
-
-package params
-
-const (
-	CLASS           = "D"
-	NA              = 1500000
-	NITER           = 100
-	SHIFT           = 500.0
-	NONZER          = 21
-	EmptyTag        = false
-	ZETA_VERIFY_VALUE = 52.514532105794
-)
-
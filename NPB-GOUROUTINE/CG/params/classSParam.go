@@ -1,13 +0,0 @@
-//go:build S
-
-package params
-
-const (
-	CLASS             = "S"
-	NA                = 1400
-	NITER             = 15
-	SHIFT             = 10.0
-	NONZER            = 7
-	EmptyTag          = false
-	ZETA_VERIFY_VALUE = 8.5971775078648
-)
@@ -0,0 +1,572 @@
+// Package dc is the DC (Data Cube) benchmark: it generates tuples of
+// dimension attributes and a measure and computes every view of their data
+// cube, the sums of the measure grouped by each subset of the attributes.
+//
+// Like NPB's DC it is dominated by data movement rather than arithmetic:
+// every view is aggregated from the smallest view already computed that has
+// one attribute more, and in the on-disk mode the input tuples and every
+// view go through files in a local directory. Unlike NPB's DC the attributes
+// take few values, so views are aggregated in dense arrays instead of being
+// sorted, the classes are smaller and named after their input tuples rather
+// than NPB's letters, and the reference values in params are those of this
+// implementation.
+package dc
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/DC/params"
+	"github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/common"
+)
+
+// Timers of the timer.flag section breakdown
+const (
+	T_TOTAL = iota + 1
+	T_GENERATE
+	T_VIEWS
+	T_LAST = T_VIEWS
+)
+
+// MAX_MEASURE bounds the measure of the generated tuples
+const MAX_MEASURE = 1000
+
+// Config selects the problem a Run solves
+type Config struct {
+	Params   params.Params   // problem size, from params.Lookup
+	Dir      string          // directory of the tuple and view files; views stay in memory when empty
+	Workers  int             // goroutines; 0 means $GO_NUM_THREADS, or one per CPU
+	Schedule common.Schedule // of the views of one number of attributes; none means $GO_SCHEDULE
+	Bind     common.Binding  // of the workers to cores; none means $GO_PROC_BIND
+	Out      io.Writer       // progress messages and the NPB banner, discarded when nil
+}
+
+// Result is the outcome of a DC run with the values it is verified on
+type Result struct {
+	common.Result
+	Rows     int64  // tuples of all views together
+	Checksum uint64 // checksum of all views
+}
+
+// Run runs the benchmark with the class of cfg.Params and returns its result.
+// When ctx is done between two views the run stops there and Run returns the
+// partial result with a *common.IncompleteError.
+func Run(ctx context.Context, cfg Config) (Result, error) {
+	if err := ctx.Err(); err != nil {
+		return Result{}, err
+	}
+	workers, err := common.ResolveWorkers(cfg.Workers)
+	if err != nil {
+		return Result{}, err
+	}
+	out := cfg.Out
+	if out == nil {
+		out = io.Discard
+	}
+	sched, err := common.ResolveSchedule(cfg.Schedule)
+	if err != nil {
+		return Result{}, err
+	}
+	bind, err := common.ResolveBinding(cfg.Bind)
+	if err != nil {
+		return Result{}, err
+	}
+	dc := NewDCBenchmark(cfg.Params, workers, cfg.Dir, out)
+	dc.schedule = sched
+	dc.bind = bind
+	return dc.run(ctx)
+}
+
+// row is one tuple of a view: the cell of its attribute values and the sum
+// of the measure over the input tuples that fall in that cell
+type row struct {
+	cell, sum int64
+}
+
+// rowSize is the size of a row in a view file
+const rowSize = 16
+
+// view is the group-by of the attributes in mask
+type view struct {
+	mask     int
+	rows     int64  // tuples of the view
+	checksum uint64 // contribution of the view to the checksum
+	read     int64  // tuples read to build the view
+	data     []row  // tuples in cell order, in memory mode
+	path     string // file holding the tuples, in on-disk mode
+}
+
+// DCBenchmark represents the DC benchmark
+type DCBenchmark struct {
+	numTuples   int
+	numAttrs    int
+	class       string
+	rowsRef     int64
+	checksumRef uint64
+	dir         string
+
+	views []*view // indexed by mask
+
+	ops float64 // tuples read to build the views
+
+	// Aggregation buffers, one per worker
+	buffers []*buffer
+
+	numWorkers    int
+	schedule      common.Schedule
+	bind          common.Binding
+	team          *common.Team // started by run
+	timersEnabled bool
+	timers        common.Timers
+	out           io.Writer
+}
+
+// buffer is a dense aggregation buffer, one entry per cell of the view being
+// built
+type buffer struct {
+	sums    []int64
+	present []bool
+}
+
+// NewDCBenchmark creates a DC benchmark for the given class parameters
+// running on numWorkers goroutines, that keeps its tuples and views in files
+// in dir, or in memory when dir is empty
+func NewDCBenchmark(p params.Params, numWorkers int, dir string, out io.Writer) *DCBenchmark {
+	return &DCBenchmark{
+		numTuples:   p.NUM_TUPLES,
+		numAttrs:    p.NUM_ATTRS,
+		class:       p.CLASS,
+		rowsRef:     p.ROWS_REF,
+		checksumRef: p.CHECKSUM_REF,
+		dir:         dir,
+		numWorkers:  numWorkers,
+		out:         out,
+	}
+}
+
+// card returns the number of values attribute j takes
+func card(j int) int {
+	return 2 + j%4
+}
+
+// cells returns the number of cells of the view of the attributes in mask
+func cells(mask int) int {
+	n := 1
+	for j := 0; mask>>j != 0; j++ {
+		if mask>>j&1 == 1 {
+			n *= card(j)
+		}
+	}
+	return n
+}
+
+// stride returns the distance between the cells of consecutive values of
+// attribute j in the view of the attributes in mask: the product of the
+// cardinalities of the attributes of mask below j
+func stride(mask, j int) int {
+	return cells(mask & (1<<j - 1))
+}
+
+// clearCells empties the first n cells of b
+func (b *buffer) clearCells(n int) {
+	clear(b.sums[:n])
+	clear(b.present[:n])
+}
+
+// add adds a measure to a cell of b
+func (b *buffer) add(cell int, measure int64) {
+	b.sums[cell] += measure
+	b.present[cell] = true
+}
+
+// generate draws the input tuples and aggregates them into the view of all
+// attributes. In on-disk mode the tuples are written to a file first and
+// read back from it.
+func (dc *DCBenchmark) generate(v *view, b *buffer) error {
+	strides := make([]int, dc.numAttrs)
+	for j := range strides {
+		strides[j] = stride(v.mask, j)
+	}
+	b.clearCells(cells(v.mask))
+
+	seed := 314159265.0
+	const a = 1220703125.0
+	rec := make([]byte, dc.numAttrs+8)
+	tuple := func() {
+		for j := 0; j < dc.numAttrs; j++ {
+			rec[j] = byte(common.Randlc(&seed, a) * float64(card(j)))
+		}
+		measure := 1 + int64(common.Randlc(&seed, a)*MAX_MEASURE)
+		binary.LittleEndian.PutUint64(rec[dc.numAttrs:], uint64(measure))
+	}
+	aggregate := func() {
+		cell := 0
+		for j, s := range strides {
+			cell += int(rec[j]) * s
+		}
+		b.add(cell, int64(binary.LittleEndian.Uint64(rec[dc.numAttrs:])))
+	}
+
+	if dc.dir == "" {
+		for i := 0; i < dc.numTuples; i++ {
+			tuple()
+			aggregate()
+		}
+	} else {
+		path := filepath.Join(dc.dir, fmt.Sprintf("dc.%s.tuples", dc.class))
+		err := writeFile(path, func(w io.Writer) error {
+			for i := 0; i < dc.numTuples; i++ {
+				tuple()
+				if _, err := w.Write(rec); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if err := readFile(path, rec, aggregate); err != nil {
+			return err
+		}
+	}
+	v.read = int64(dc.numTuples)
+	return dc.store(v, b)
+}
+
+// compute aggregates the view v from its smallest parent, the computed view
+// with one attribute more, in b
+func (dc *DCBenchmark) compute(v *view, b *buffer) error {
+	var parent *view
+	attr := 0
+	for j := 0; j < dc.numAttrs; j++ {
+		if v.mask>>j&1 == 1 {
+			continue
+		}
+		if p := dc.views[v.mask|1<<j]; parent == nil || p.rows < parent.rows {
+			parent, attr = p, j
+		}
+	}
+
+	// A parent cell is hi*high + value*low + lo for the value of attr; the
+	// cell of v drops that value
+	low := stride(parent.mask, attr)
+	high := low * card(attr)
+	b.clearCells(cells(v.mask))
+	err := dc.scan(parent, func(r row) {
+		c := int(r.cell)
+		b.add(c/high*low+c%low, r.sum)
+	})
+	if err != nil {
+		return err
+	}
+	v.read = parent.rows
+	return dc.store(v, b)
+}
+
+// store turns the filled cells of b into the tuples of v, in memory or in
+// its file, and computes its checksum
+func (dc *DCBenchmark) store(v *view, b *buffer) error {
+	n := cells(v.mask)
+	add := func(c int) row {
+		r := row{int64(c), b.sums[c]}
+		v.rows++
+		v.checksum += uint64(v.mask+1) * uint64(r.cell+1) * uint64(r.sum)
+		return r
+	}
+
+	if dc.dir == "" {
+		for c := 0; c < n; c++ {
+			if b.present[c] {
+				v.data = append(v.data, add(c))
+			}
+		}
+		return nil
+	}
+
+	v.path = filepath.Join(dc.dir, fmt.Sprintf("dc.%s.view.%04x", dc.class, v.mask))
+	return writeFile(v.path, func(w io.Writer) error {
+		var rec [rowSize]byte
+		for c := 0; c < n; c++ {
+			if !b.present[c] {
+				continue
+			}
+			r := add(c)
+			binary.LittleEndian.PutUint64(rec[:8], uint64(r.cell))
+			binary.LittleEndian.PutUint64(rec[8:], uint64(r.sum))
+			if _, err := w.Write(rec[:]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// scan calls fn with every tuple of v in cell order
+func (dc *DCBenchmark) scan(v *view, fn func(r row)) error {
+	if v.path == "" {
+		for _, r := range v.data {
+			fn(r)
+		}
+		return nil
+	}
+	var rec [rowSize]byte
+	return readFile(v.path, rec[:], func() {
+		fn(row{
+			int64(binary.LittleEndian.Uint64(rec[:8])),
+			int64(binary.LittleEndian.Uint64(rec[8:])),
+		})
+	})
+}
+
+// writeFile creates the file at path and writes it through a buffer with
+// write
+func writeFile(path string, write func(w io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	if err := write(w); err != nil {
+		f.Close()
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// readFile reads the file at path in records of len(rec) bytes, calling fn
+// after each one is read into rec
+func readFile(path string, rec []byte, fn func()) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+	for {
+		if _, err := io.ReadFull(r, rec); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		fn()
+	}
+}
+
+// order returns the masks of all views, by decreasing number of attributes
+// so that the parents of a view come before it
+func (dc *DCBenchmark) order() []int {
+	masks := make([]int, 0, 1<<dc.numAttrs)
+	for k := dc.numAttrs; k >= 0; k-- {
+		for mask := 0; mask < 1<<dc.numAttrs; mask++ {
+			if bits.OnesCount(uint(mask)) == k {
+				masks = append(masks, mask)
+			}
+		}
+	}
+	return masks
+}
+
+// verify compares the number of tuples and the checksum of all views with
+// the reference values of the class
+func (dc *DCBenchmark) verify(rows int64, checksum uint64) bool {
+	fmt.Fprintf(dc.out, " Verification being performed for class %s\n", dc.class)
+	verified := rows == dc.rowsRef && checksum == dc.checksumRef
+	if verified {
+		fmt.Fprintf(dc.out, "          rows     %22d%22d\n", rows, dc.rowsRef)
+		fmt.Fprintf(dc.out, "          checksum %22d%22d\n", checksum, dc.checksumRef)
+		fmt.Fprintf(dc.out, " Verification Successful\n")
+	} else {
+		fmt.Fprintf(dc.out, " FAILURE: rows     %22d%22d\n", rows, dc.rowsRef)
+		fmt.Fprintf(dc.out, " FAILURE: checksum %22d%22d\n", checksum, dc.checksumRef)
+		fmt.Fprintf(dc.out, " Verification failed\n")
+	}
+	return verified
+}
+
+// run performs the DC benchmark and returns its result, with an error when
+// ctx stopped it before all views were computed or a file could not be
+// written or read
+func (dc *DCBenchmark) run(ctx context.Context) (Result, error) {
+	if _, err := os.Stat("timer.flag"); err == nil {
+		dc.timersEnabled = true
+	}
+
+	team, err := common.StartTeam(dc.numWorkers, dc.schedule, dc.bind)
+	if err != nil {
+		return Result{}, err
+	}
+	dc.team = team
+	defer dc.team.Close()
+
+	full := 1<<dc.numAttrs - 1
+	fmt.Fprintf(dc.out, "\n\n NAS Parallel Benchmarks 4.1 Go Goroutine version - DC Benchmark\n\n")
+	fmt.Fprintf(dc.out, " Input tuples:     %12d\n", dc.numTuples)
+	fmt.Fprintf(dc.out, " Attributes:       %12d\n", dc.numAttrs)
+	fmt.Fprintf(dc.out, " Views:            %12d\n", full+1)
+	if dc.dir == "" {
+		fmt.Fprintf(dc.out, " Storage:          in memory\n")
+	} else {
+		fmt.Fprintf(dc.out, " Storage:          %s\n", dc.dir)
+	}
+	fmt.Fprintf(dc.out, " Number of available workers: %d\n\n", dc.numWorkers)
+
+	for i := 1; i <= T_LAST; i++ {
+		dc.timers.Clear(i)
+	}
+	dc.timers.Start(T_TOTAL)
+
+	dc.views = make([]*view, full+1)
+	dc.buffers = make([]*buffer, dc.numWorkers)
+	for w := range dc.buffers {
+		dc.buffers[w] = &buffer{make([]int64, cells(full)), make([]bool, cells(full))}
+	}
+
+	// The views of one number of attributes are built in one loop of the
+	// team, each by a worker in its own buffer, once all their parents are
+	// done
+	var mu sync.Mutex
+	var viewErr error
+	var rows int64
+	var checksum uint64
+	var pending []*view
+	finish := func(report bool) {
+		if len(pending) == 0 {
+			return
+		}
+		if pending[0].mask != full {
+			dc.team.For(0, len(pending), func(lo, hi, id int) {
+				for _, v := range pending[lo:hi] {
+					if err := dc.compute(v, dc.buffers[id]); err != nil {
+						mu.Lock()
+						viewErr = cmp.Or(viewErr, err)
+						mu.Unlock()
+					}
+				}
+			})
+		}
+		level, levelRows := bits.OnesCount(uint(pending[0].mask)), int64(0)
+		for _, v := range pending {
+			rows += v.rows
+			checksum += v.checksum
+			dc.ops += float64(v.read)
+			levelRows += v.rows
+		}
+		pending = pending[:0]
+		if report {
+			fmt.Fprintf(dc.out, " Views of %2d attributes: %12d tuples\n", level, levelRows)
+		}
+		// Views two levels up are no parent of the ones still to come
+		for m, v := range dc.views {
+			if bits.OnesCount(uint(m)) == level+2 {
+				v.data = nil
+			}
+		}
+	}
+
+	completed := 0
+	for _, mask := range dc.order() {
+		if ctx.Err() != nil {
+			break
+		}
+		if len(pending) > 0 && bits.OnesCount(uint(mask)) != bits.OnesCount(uint(pending[0].mask)) {
+			finish(true)
+			if viewErr != nil {
+				break
+			}
+		}
+
+		v := &view{mask: mask}
+		dc.views[mask] = v
+		pending = append(pending, v)
+		completed++
+		if mask != full {
+			continue
+		}
+		if dc.timersEnabled {
+			dc.timers.Start(T_GENERATE)
+		}
+		err := dc.generate(v, dc.buffers[0])
+		if dc.timersEnabled {
+			dc.timers.Stop(T_GENERATE)
+			dc.timers.Start(T_VIEWS)
+		}
+		if err != nil {
+			return Result{}, err
+		}
+	}
+	finish(completed == full+1)
+	if viewErr != nil {
+		return Result{}, viewErr
+	}
+	if dc.timersEnabled && completed > 0 {
+		dc.timers.Stop(T_VIEWS)
+	}
+
+	dc.timers.Stop(T_TOTAL)
+	tmax := dc.timers.Read(T_TOTAL)
+
+	verified := false
+	incomplete := completed < full+1
+	if incomplete {
+		fmt.Fprintf(dc.out, "\n Benchmark stopped after %d of %d views\n", completed, full+1)
+		fmt.Fprintf(dc.out, " NO VERIFICATION PERFORMED\n")
+	} else {
+		verified = dc.verify(rows, checksum)
+	}
+
+	mops := 0.0
+	if tmax != 0.0 {
+		mops = 1.0e-6 * dc.ops / tmax
+	}
+
+	result := common.Result{
+		Kernel:      "DC",
+		Class:       dc.class,
+		Size:        [3]int{dc.numTuples, 0, 0},
+		Iterations:  completed,
+		Time:        tmax,
+		Mops:        mops,
+		OpType:      "tuples read",
+		Verified:    verified,
+		Incomplete:  incomplete,
+		Workers:     dc.numWorkers,
+		NPBVersion:  "4.1",
+		CompileTime: "Unknown",
+		Compiler:    "Go",
+	}
+	dc.team.Describe(&result)
+	if dc.timersEnabled {
+		names := []string{"", "total", "generate", "views"}
+		for i := 1; i <= T_LAST; i++ {
+			result.Timers = append(result.Timers, common.Timer{Name: names[i], Seconds: dc.timers.Read(i)})
+		}
+	}
+	common.Finish(&result, dc.out)
+
+	if dc.timersEnabled {
+		fmt.Fprintln(dc.out, "  SECTION   Time (secs)")
+		for _, t := range result.Timers {
+			fmt.Fprintf(dc.out, "  %-8s:%9.3f  (%6.2f%%)\n", t.Name, t.Seconds, t.Seconds*100.0/tmax)
+		}
+	}
+	res := Result{result, rows, checksum}
+	if incomplete {
+		return res, &common.IncompleteError{Completed: completed, Planned: full + 1, Err: ctx.Err()}
+	}
+	return res, nil
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/DC/dc"
+	"github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/DC/params"
+	"github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/common"
+)
+
+func main() {
+	class := flag.String("class", "1K", "problem class (1K, 100K, 1M or 10M input tuples)")
+	format := flag.String("format", "text", "result format: text or json")
+	results := flag.String("results", "", "append the result to this .csv or .jsonl file")
+	workers := flag.Int("workers", 0, "goroutines (0 means $GO_NUM_THREADS, or one per CPU)")
+	maxProcs := flag.Int("gomaxprocs", 0, "goroutines running at once (0 means $GOMAXPROCS, or one per CPU)")
+	dir := flag.String("dir", "", "write the tuples and views to files in this directory instead of keeping them in memory")
+	timeout := flag.Duration("timeout", 0, "stop after this long and report the views completed (0 for no limit)")
+	flag.Parse()
+
+	if err := common.SetFormat(*format); err != nil {
+		fmt.Fprintf(os.Stderr, "dc: %v\n", err)
+		os.Exit(1)
+	}
+	out := common.Progress()
+	if err := common.SetResultsFile(*results); err != nil {
+		fmt.Fprintf(os.Stderr, "dc: %v\n", err)
+		os.Exit(1)
+	}
+	if err := common.SetMaxProcs(*maxProcs); err != nil {
+		fmt.Fprintf(os.Stderr, "dc: %v\n", err)
+		os.Exit(1)
+	}
+
+	p, ok := params.Lookup(*class)
+	if !ok {
+		common.PrintClassUsage(out, "dc", *class, params.Classes)
+		os.Exit(1)
+	}
+
+	ctx, stop := common.RunContext(*timeout)
+	defer stop()
+	result, err := dc.Run(ctx, dc.Config{Params: p, Workers: *workers, Dir: *dir, Out: out})
+	if err != nil && !result.Incomplete {
+		fmt.Fprintf(os.Stderr, "dc: %v\n", err)
+		os.Exit(1)
+	}
+	common.Report(&result.Result)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "dc: %v\n", err)
+		os.Exit(1)
+	}
+	if result.Failed() {
+		os.Exit(1)
+	}
+}
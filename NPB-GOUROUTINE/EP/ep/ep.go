@@ -0,0 +1,359 @@
+// Package ep is the EP (Embarrassingly Parallel) kernel: it tallies Gaussian
+// deviates generated from a stream of pseudorandom numbers.
+package ep
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/EP/params"
+	"github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/common"
+)
+
+const (
+	MK      = 16
+	NK      = 1 << MK
+	NQ      = 10
+	EPSILON = 1.0e-8
+	A       = 1220703125.0
+	S       = 271828183.0
+	NK_PLUS = (2*NK + 1)
+)
+
+type WorkerResults struct {
+	QPartial  []float64
+	SXPartial float64
+	SYPartial float64
+	Batches   int // batches generated before ctx stopped the worker
+}
+
+// epWorker generates the batches startK to endK-1 and adds their counts and
+// sums to res, the results of the worker goID. x is its scratch array.
+func epWorker(
+	ctx context.Context,
+	startK int,
+	endK int,
+	an float64,
+	seed float64,
+	res *WorkerResults,
+	x []float64,
+	timers *common.Timers,
+	timersEnabled bool,
+	goID int,
+) {
+	var t1, t2, t3, t4, x1, x2 float64
+	var kk, i, ik, l int
+	kOffset := -1
+
+	qq := res.QPartial
+	sx, sy := res.SXPartial, res.SYPartial
+	batches := res.Batches
+	for k := startK; k < endK; k++ {
+		if ctx.Err() != nil {
+			break
+		}
+		kk = kOffset + k
+
+		t1 = seed
+		t2 = an
+
+		/* find starting seed t1 for this kk */
+		for i = 1; i <= 100; i++ {
+			ik = kk / 2
+			if (2 * ik) != kk {
+				t3 = common.Randlc(&t1, t2)
+			}
+			if ik == 0 {
+				break
+			}
+			t3 = common.Randlc(&t2, t2)
+			kk = ik
+		}
+
+		/* compute uniform pseudorandom numbers */
+		if timersEnabled && goID == 0 {
+			timers.Start(2)
+		}
+		common.Vranlc(2*NK, &t1, A, x)
+		if timersEnabled && goID == 0 {
+			timers.Stop(2)
+		}
+
+		/*
+		 * compute gaussian deviates by acceptance-rejection method and
+		 * tally counts in concentric square annuli. this loop is not
+		 * vectorizable.
+		 */
+		if timersEnabled && goID == 0 {
+			timers.Start(1)
+		}
+		for i = 0; i < NK; i++ {
+			x1 = 2.0*x[2*i] - 1.0
+			x2 = 2.0*x[2*i+1] - 1.0
+			t1 = x1*x1 + x2*x2
+			if t1 <= 1.0 {
+				t2 = math.Sqrt(-2.0 * math.Log(t1) / t1)
+				t3 = (x1 * t2)
+				t4 = (x2 * t2)
+				l = int(math.Max(math.Abs(t3), math.Abs(t4)))
+				qq[l] += 1.0
+				sx += t3
+				sy += t4
+			}
+		}
+		if timersEnabled && goID == 0 {
+			timers.Stop(1)
+		}
+		batches++
+	}
+
+	res.SXPartial, res.SYPartial = sx, sy
+	res.Batches = batches
+}
+
+// Config selects the problem a Run solves
+type Config struct {
+	Params   params.Params   // problem size, from params.Lookup
+	Workers  int             // goroutines; 0 means $GO_NUM_THREADS, or one per CPU
+	Schedule common.Schedule // of the batches; none means $GO_SCHEDULE
+	Bind     common.Binding  // of the workers to cores; none means $GO_PROC_BIND
+	Out      io.Writer       // progress messages and the NPB banner, discarded when nil
+
+	// Input, from an upstream task of a workflow, seeds the random numbers
+	// in place of S. The run is then of class U, with nothing to verify.
+	Input  *common.Field
+	Output bool // return the counts and sums in Result.Output
+}
+
+// Result is the outcome of an EP run with the sums it is verified on
+type Result struct {
+	common.Result
+	SX, SY float64
+	Output *common.Field // the NQ counts then SX and SY, when Config.Output is set
+}
+
+// inputSeed derives an odd seed below 2^46 from the norm of f, rounded to ten
+// significant digits so that the last bits of an upstream reduction, which
+// depend on its number of workers, do not change the seed
+func inputSeed(f *common.Field) float64 {
+	frac, exp := math.Frexp(f.Norm())
+	key := uint64(math.Round(frac*1e10)) + uint64(exp+1100)<<40
+	return float64(2*(key*2654435761%(1<<45)) + 1)
+}
+
+// Run runs the EP benchmark and returns its result. When ctx is done between
+// two batches of random numbers the run stops there and Run returns the
+// partial result with a *common.IncompleteError.
+func Run(ctx context.Context, cfg Config) (Result, error) {
+	if err := ctx.Err(); err != nil {
+		return Result{}, err
+	}
+	numCPUs, err := common.ResolveWorkers(cfg.Workers)
+	if err != nil {
+		return Result{}, err
+	}
+	sched, err := common.ResolveSchedule(cfg.Schedule)
+	if err != nil {
+		return Result{}, err
+	}
+	bind, err := common.ResolveBinding(cfg.Bind)
+	if err != nil {
+		return Result{}, err
+	}
+	p := cfg.Params
+	class := p.CLASS
+	seed := S
+	if cfg.Input != nil {
+		class = params.UserClass
+		seed = inputSeed(cfg.Input)
+	}
+	out := cfg.Out
+	if out == nil {
+		out = io.Discard
+	}
+	x := make([]float64, NK_PLUS)
+	q := make([]float64, NQ)
+	var timers common.Timers
+
+	var Mops, t1 float64
+	var sx, sy, tm, an, tt, gc float64
+	var sxErr, syErr float64
+	var np int
+	var i, nit int
+	var verified, timersEnabled bool
+	var dum = []float64{1.0, 1.0, 1.0}
+	var size string
+
+	timersEnabled = checkTimeFlag()
+	size = fmt.Sprintf("%15.0f", math.Pow(2.0, float64(p.M+1)))
+
+	size = strings.TrimRight(size, ".")
+
+	fmt.Fprintf(out, "\n\n NAS Parallel Benchmarks 4.1 Serial Go version - EP Benchmark\n\n")
+	fmt.Fprintf(out, " Number of random numbers generated: %15s\n", size)
+	verified = false
+
+	/*
+	 * --------------------------------------------------------------------
+	 * compute the number of "batches" of random number pairs generated
+	 * per processor. Adjust if the number of processors does not evenly
+	 * divide the total number
+	 * --------------------------------------------------------------------
+	 */
+	np = 1 << (p.M - MK)
+
+	/*
+	 * call the random number generator functions and initialize
+	 * the x-array to reduce the effects of paging on the timings.
+	 * also, call all mathematical functions that are used. make
+	 * sure these initializations cannot be eliminated as dead code.
+	 */
+	common.Vranlc(0, &dum[0], dum[1], dum)
+	dum[0] = common.Randlc(&dum[1], dum[2])
+
+	for i = 0; i < NK_PLUS; i++ {
+		x[i] = -1.0e99
+	}
+
+	Mops = math.Log(math.Sqrt(math.Abs(math.Max(1.0, 1.0))))
+
+	timers.Clear(0)
+	timers.Clear(1)
+	timers.Clear(2)
+	timers.Start(0)
+
+	t1 = A
+	common.Vranlc(0, &t1, A, x)
+
+	for i = 0; i < MK+1; i++ {
+		common.Randlc(&t1, t1)
+	}
+
+	an = t1
+	tt = S
+	gc = 0.0
+	sx = 0.0
+	sy = 0.0
+
+	for i = 0; i <= NQ-1; i++ {
+		q[i] = 0.0
+	}
+
+	team, err := common.StartTeam(numCPUs, sched, bind)
+	if err != nil {
+		return Result{}, err
+	}
+	defer team.Close()
+
+	// The batches are dealt by the schedule; each worker tallies its own and
+	// the tallies are added in worker order
+	partialResults := make([]WorkerResults, numCPUs)
+	team.Run(func(w *common.Worker) {
+		res := &partialResults[w.ID]
+		res.QPartial = make([]float64, NQ)
+		x := make([]float64, NK_PLUS)
+		w.For(1, np+1, func(startK, endK int) {
+			epWorker(ctx, startK, endK, an, seed, res, x, &timers, timersEnabled, w.ID)
+		})
+	})
+
+	batches := 0
+	for _, workerRes := range partialResults {
+		// Agrega q (contagens de anéis)
+		for i := 0; i < NQ; i++ {
+			q[i] += workerRes.QPartial[i]
+		}
+		sx += workerRes.SXPartial
+		sy += workerRes.SYPartial
+		batches += workerRes.Batches
+	}
+
+	for i = 0; i <= NQ-1; i++ {
+		gc = gc + q[i]
+	}
+	incomplete := batches < np
+	timers.Stop(0)
+	tm = timers.Read(0)
+
+	nit = 0
+
+	sxErr = math.Abs((sx - p.SX_VERIFY_VALUE) / p.SX_VERIFY_VALUE)
+	syErr = math.Abs((sy - p.SY_VERIFY_VALUE) / p.SY_VERIFY_VALUE)
+	verified = !incomplete && class != params.UserClass && (sxErr <= EPSILON) && (syErr <= EPSILON)
+
+	Mops = math.Pow(2.0, float64(p.M+1)) * float64(batches) / float64(np) / tm / 1000000.0
+
+	if incomplete {
+		fmt.Fprintf(out, "\n Benchmark stopped after %d of %d batches\n", batches, np)
+	}
+	fmt.Fprintf(out, "\n EP Benchmark Results:\n\n")
+	fmt.Fprintf(out, " CPU Time =%10.4f\n", tm)
+	fmt.Fprintf(out, " N = 2^%5d\n", p.M)
+	fmt.Fprintf(out, " No. Gaussian Pairs = %15.0f\n", gc)
+	fmt.Fprintf(out, " Sums = %25.15e %25.15e\n", sx, sy)
+	fmt.Fprintf(out, " No. Goroutines: %5d\n", numCPUs)
+	fmt.Fprintf(out, " Schedule: %s\n", sched)
+	fmt.Fprintf(out, " Placement: %s\n", common.FormatPlacement(team.Placement()))
+	fmt.Fprintln(out, " Counts:")
+
+	for i = 0; i < NQ-1; i++ {
+		fmt.Fprintf(out, "%3d%15.0f\n", i, q[i])
+	}
+
+	result := common.Result{
+		Kernel:      "EP",
+		Class:       class,
+		Size:        [3]int{p.M + 1, 0, 0},
+		Iterations:  nit,
+		Time:        tm,
+		Mops:        Mops,
+		OpType:      "Random numbers generated",
+		Verified:    verified,
+		Incomplete:  incomplete,
+		Workers:     numCPUs,
+		NPBVersion:  "4.1",
+		CompileTime: time.Now().Format("03 Jun 2006"),
+		Compiler:    "go1.24.2 linux/amd64",
+		Rand:        "randdp",
+	}
+	if timersEnabled {
+		result.Timers = []common.Timer{
+			{Name: "total", Seconds: timers.Read(0)},
+			{Name: "gaussian pairs", Seconds: timers.Read(1)},
+			{Name: "random numbers", Seconds: timers.Read(2)},
+		}
+	}
+	team.Describe(&result)
+	common.Finish(&result, out)
+	if timersEnabled {
+		if tm <= 0.0 {
+			tm = 1.0
+		}
+		tt = timers.Read(0)
+		fmt.Fprintf(out, "\nTotal time:     %9.3f (%6.2f)\n", tt, tt*100.0/tm)
+
+		tt = timers.Read(1)
+		fmt.Fprintf(out, "Gaussian pairs: %9.3f (%6.2f)\n", tt, tt*100.0/tm)
+
+		tt = timers.Read(2)
+		fmt.Fprintf(out, "Random numbers: %9.3f (%6.2f)\n", tt, tt*100.0/tm)
+	}
+	res := Result{Result: result, SX: sx, SY: sy}
+	if cfg.Output {
+		res.Output = &common.Field{NX: NQ + 2, NY: 1, NZ: 1, Data: append(append([]float64(nil), q...), sx, sy)}
+	}
+	if incomplete {
+		return res, &common.IncompleteError{Completed: batches, Planned: np, Err: ctx.Err()}
+	}
+	return res, nil
+}
+
+func checkTimeFlag() bool {
+	_, err := os.Stat("timer.flag")
+	return os.IsNotExist(err)
+}
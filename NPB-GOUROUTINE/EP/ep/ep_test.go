@@ -0,0 +1,100 @@
+package ep
+
+import (
+	"context"
+	"flag"
+	"math"
+	"testing"
+
+	"github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/EP/params"
+	"github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/common"
+	"github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/common/npbtest"
+)
+
+var long = flag.Bool("long", false, "also verify class A")
+
+// testClasses lists the classes the verification tests run
+func testClasses() []string {
+	if *long {
+		return []string{"S", "W", "A"}
+	}
+	return []string{"S", "W"}
+}
+
+func BenchmarkEP(b *testing.B) {
+	for _, class := range []string{"S", "W"} {
+		b.Run(class, func(b *testing.B) {
+			p, _ := params.Lookup(class)
+			mops := 0.0
+			for i := 0; i < b.N; i++ {
+				result, err := Run(context.Background(), Config{Params: p})
+				if err != nil {
+					b.Fatal(err)
+				}
+				if result.Failed() {
+					b.Fatalf("class %s failed verification", class)
+				}
+				mops += result.Mops
+			}
+			b.ReportMetric(mops/float64(b.N), "Mop/s")
+		})
+	}
+}
+
+func TestVerification(t *testing.T) {
+	for _, class := range testClasses() {
+		t.Run(class, func(t *testing.T) {
+			p, _ := params.Lookup(class)
+			result, err := Run(context.Background(), Config{Params: p})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := math.Abs((result.SX - p.SX_VERIFY_VALUE) / p.SX_VERIFY_VALUE); err > EPSILON {
+				t.Errorf("sx = %.15e, want %.15e", result.SX, p.SX_VERIFY_VALUE)
+			}
+			if err := math.Abs((result.SY - p.SY_VERIFY_VALUE) / p.SY_VERIFY_VALUE); err > EPSILON {
+				t.Errorf("sy = %.15e, want %.15e", result.SY, p.SY_VERIFY_VALUE)
+			}
+			if !result.Verified {
+				t.Error("kernel reported a failed verification")
+			}
+		})
+	}
+}
+
+// TestSchedules checks that the kernel verifies under every kind of schedule
+func TestSchedules(t *testing.T) {
+	p, _ := params.Lookup("S")
+	for _, text := range []string{"static,5", "dynamic", "guided"} {
+		sched, err := common.ParseSchedule(text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result, err := Run(context.Background(), Config{Params: p, Workers: 3, Schedule: sched})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Schedule != text || !result.Verified {
+			t.Errorf("%s: got schedule %q, verified %v", text, result.Schedule, result.Verified)
+		}
+	}
+}
+
+// runClass runs the kernel at class for the checks every kernel shares
+func runClass(ctx context.Context, class string) (common.Result, error) {
+	p, _ := params.Lookup(class)
+	result, err := Run(ctx, Config{Params: p, Workers: 1})
+	return result.Result, err
+}
+
+// TestConcurrentRuns checks that runs of two classes in one process do not
+// share state
+func TestConcurrentRuns(t *testing.T) {
+	npbtest.ConcurrentRuns(t, runClass, "S", "W")
+}
+
+// TestStoppedRun checks a run stopped by its context. Run checks once before
+// starting and then before every batch, which one worker generates in order
+func TestStoppedRun(t *testing.T) {
+	npbtest.StoppedRun(t, runClass, "S", "batches")
+}
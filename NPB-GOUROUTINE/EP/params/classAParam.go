@@ -1,11 +0,0 @@
-//go:build A
-
-package params
-
-const (
-	CLASS           = "A"
-	M               = 28
-	EmptyTag        = false
-	SX_VERIFY_VALUE = -4.295875165629892e+3
-	SY_VERIFY_VALUE = -1.580732573678431e+4
-)
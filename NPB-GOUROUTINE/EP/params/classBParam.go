@@ -1,11 +0,0 @@
-//go:build B
-
-package params
-
-const (
-	CLASS           = "B"
-	M               = 30
-	EmptyTag        = false
-	SX_VERIFY_VALUE = 4.033815542441498e+4
-	SY_VERIFY_VALUE = -2.660669192809235e+4
-)
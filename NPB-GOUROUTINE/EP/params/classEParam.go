@@ -1,11 +0,0 @@
-//go:build E
-
-package params
-
-const (
-	CLASS           = "E"
-	M               = 40
-	EmptyTag        = false
-	SX_VERIFY_VALUE = -5.319717441530e+05
-	SY_VERIFY_VALUE = -3.688834557731e+05
-)
@@ -1,11 +0,0 @@
-//go:build S
-
-package params
-
-const (
-	CLASS           = "S"
-	M               = 24
-	EmptyTag        = false
-	SX_VERIFY_VALUE = -3.247834652034740e+3
-	SY_VERIFY_VALUE = -6.958407078382297e+3
-)
@@ -1,11 +0,0 @@
-//go:build !S && !W && !A && !B && !C && !D && !E
-
-package params
-
-const (
-	CLASS           = ""
-	M               = 20
-	EmptyTag        = true
-	SX_VERIFY_VALUE = 1.0
-	SY_VERIFY_VALUE = 1.0
-)
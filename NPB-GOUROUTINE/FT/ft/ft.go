@@ -0,0 +1,802 @@
+// Package ft is the FT (Fourier Transform) kernel: it solves a 3D partial
+// differential equation with forward and inverse FFTs.
+package ft
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"math/cmplx"
+	"os"
+
+	"github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/FT/params"
+	"github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/common"
+)
+
+// Constants
+const (
+	// Cache blocking params
+	FFTBLOCK    = 16
+	FFTBLOCKPAD = 18
+
+	SEED    = 314159265.0
+	A       = 1220703125.0
+	PI      = 3.141592653589793238
+	ALPHA   = 1.0e-6
+	EPSILON = 1.0e-12
+
+	// Timers
+	T_TOTAL    = 1
+	T_SETUP    = 2
+	T_FFT      = 3
+	T_EVOLVE   = 4
+	T_CHECKSUM = 5
+	T_FFTX     = 6
+	T_FFTY     = 7
+	T_FFTZ     = 8
+	T_MAX      = 8
+)
+
+// Use Go's native complex128 type
+type Dcomplex = complex128
+
+// Config selects the problem a Run solves
+type Config struct {
+	Params   params.Params   // problem size, from params.Lookup or params.Custom
+	Workers  int             // goroutines; 0 means $GO_NUM_THREADS, or one per CPU
+	Schedule common.Schedule // of the loops over grid planes; none means $GO_SCHEDULE
+	Bind     common.Binding  // of the workers to cores; none means $GO_PROC_BIND
+	Out      io.Writer       // progress messages and the NPB banner, discarded when nil
+
+	// FirstTouch has each worker zero the planes of u0, u1 and twiddle it
+	// computes on, so that their pages land on its NUMA node; false means
+	// $GO_FIRST_TOUCH
+	FirstTouch bool
+
+	// Input, from an upstream task of a workflow, is the real part of the
+	// initial conditions in place of the random ones. The run is then of
+	// class U, with nothing to verify.
+	Input  *common.Field
+	Output bool // return the real part of the final grid in Result.Output
+}
+
+// Result is the outcome of an FT run with the checksums it is verified on,
+// indexed by iteration from 1
+type Result struct {
+	common.Result
+	Sums   []Dcomplex
+	Output *common.Field // the real part of u after the last iteration, when Config.Output is set
+}
+
+// Run runs the benchmark on the grid of cfg.Params and returns its result. When
+// ctx is done between two iterations the run stops there and Run returns the
+// partial result with a *common.IncompleteError.
+func Run(ctx context.Context, cfg Config) (Result, error) {
+	if err := ctx.Err(); err != nil {
+		return Result{}, err
+	}
+	workers, err := common.ResolveWorkers(cfg.Workers)
+	if err != nil {
+		return Result{}, err
+	}
+	sched, err := common.ResolveSchedule(cfg.Schedule)
+	if err != nil {
+		return Result{}, err
+	}
+	bind, err := common.ResolveBinding(cfg.Bind)
+	if err != nil {
+		return Result{}, err
+	}
+	firstTouch, err := common.ResolveFirstTouch(cfg.FirstTouch)
+	if err != nil {
+		return Result{}, err
+	}
+	ft := NewFTBenchmark(cfg.Params, workers, cfg.Out)
+	ft.schedule = sched
+	ft.bind = bind
+	ft.firstTouch = firstTouch
+	ft.input = cfg.Input
+	ft.output = cfg.Output
+	return ft.run(ctx)
+}
+
+// FTBenchmark holds the problem size and state of one FT run
+type FTBenchmark struct {
+	// Problem size parameters
+	NX, NY, NZ int
+	NITER      int
+	MAXDIM     int
+	NTOTAL     int
+	CLASS      string
+
+	// Arrays (allocated on heap)
+	u0      []Dcomplex
+	u1      []Dcomplex
+	twiddle []Dcomplex
+	sums    []Dcomplex   // sums[NITER_DEFAULT+1]
+	u       []Dcomplex   // u[MAXDIM] used in fft_init/cfftz
+	y1, y2  [][]Dcomplex // scratch blocks of the 1-D FFTs of each worker, MAXDIM*FFTBLOCKPAD
+
+	// State variables
+	dims          [3]int
+	timersEnabled bool
+	debug         bool
+	timers        common.Timers
+
+	numWorkers int
+	schedule   common.Schedule
+	bind       common.Binding
+	firstTouch bool
+	team       *common.Team // started by run
+	timerOn    bool
+
+	input  *common.Field // initial conditions, nil for the random ones
+	output bool
+
+	out io.Writer
+}
+
+// NewFTBenchmark creates an FT benchmark instance for one problem size,
+// running on numWorkers goroutines
+func NewFTBenchmark(p params.Params, numWorkers int, out io.Writer) *FTBenchmark {
+	if out == nil {
+		out = io.Discard
+	}
+
+	timerOn := false
+	if _, err := os.Stat("timer.flag"); err == nil {
+		timerOn = true
+	}
+
+	return &FTBenchmark{
+		NX:         p.NX,
+		NY:         p.NY,
+		NZ:         p.NZ,
+		NITER:      p.NITER,
+		MAXDIM:     p.MAXDIM,
+		CLASS:      p.CLASS,
+		numWorkers: numWorkers,
+		timerOn:    timerOn,
+		out:        out,
+	}
+}
+
+// ilog2 calculates integer log2 of n
+func ilog2(n int) int {
+	if n <= 0 {
+		return 0
+	}
+	return int(math.Log2(float64(n)))
+}
+
+// compute_indexmap computes the index map for time evolution (parallelized)
+func (ft *FTBenchmark) compute_indexmap(twiddle []Dcomplex, d1, d2, d3 int) {
+	ap := -4.0 * ALPHA * PI * PI
+
+	ft.team.For(0, d3, func(start, end, _ int) {
+		for k := start; k < end; k++ {
+			kk := ((k + ft.NZ/2) % ft.NZ) - ft.NZ/2
+			kk2 := float64(kk * kk)
+			for j := 0; j < d2; j++ {
+				jj := ((j + ft.NY/2) % ft.NY) - ft.NY/2
+				kj2 := float64(jj*jj) + kk2
+				for i := 0; i < d1; i++ {
+					ii := ((i + ft.NX/2) % ft.NX) - ft.NX/2
+					exponent := ap * (float64(ii*ii) + kj2)
+					idx := k*d2*d1 + j*d1 + i
+					twiddle[idx] = complex(math.Exp(exponent), 0.0)
+				}
+			}
+		}
+	})
+}
+
+// ipow46 computes a^exponent mod 2^46
+func (ft *FTBenchmark) ipow46(a float64, exponent int) float64 {
+	var q, r float64
+	var n, n2 int
+
+	result := 1.0
+	if exponent == 0 {
+		return result
+	}
+
+	q = a
+	r = 1.0
+	n = exponent
+
+	for n > 1 {
+		n2 = n / 2
+		if n2*2 == n {
+			common.Randlc(&q, q)
+			n = n2
+		} else {
+			common.Randlc(&r, q)
+			n = n - 1
+		}
+	}
+	common.Randlc(&r, q)
+	result = r
+	return result
+}
+
+// compute_initial_conditions fills u0 with random data (parallelized)
+func (ft *FTBenchmark) compute_initial_conditions(u0 []Dcomplex, d1, d2, d3 int) {
+	var start, an float64
+	starts := make([]float64, ft.NZ)
+	start = SEED
+
+	an = ft.ipow46(A, 0)
+	common.Randlc(&start, an)
+	an = ft.ipow46(A, 2*ft.NX*ft.NY)
+
+	starts[0] = start
+	for k := 1; k < ft.dims[2]; k++ {
+		common.Randlc(&start, an)
+		starts[k] = start
+	}
+
+	// Parallelize loop k
+	ft.team.For(0, ft.dims[2], func(start, end, _ int) {
+		for k := start; k < end; k++ {
+			x0 := starts[k]
+			for j := 0; j < ft.dims[1]; j++ {
+				tempFloat := make([]float64, 2*ft.NX)
+				common.Vranlc(2*ft.NX, &x0, A, tempFloat)
+
+				baseIdx := k*d2*d1 + j*d1
+				for i := 0; i < d1; i++ {
+					u0[baseIdx+i] = complex(tempFloat[2*i], tempFloat[2*i+1])
+				}
+			}
+		}
+	})
+}
+
+// fft_init initializes roots of unity
+func (ft *FTBenchmark) fft_init(n int) {
+	m := ilog2(n)
+	ft.u[0] = complex(float64(m), 0.0)
+
+	ku := 2
+	ln := 1
+
+	for j := 1; j <= m; j++ {
+		t := PI / float64(ln)
+
+		for i := 0; i <= ln-1; i++ {
+			ti := float64(i) * t
+			ft.u[i+ku-1] = complex(math.Cos(ti), math.Sin(ti))
+		}
+
+		ku = ku + ln
+		ln = 2 * ln
+	}
+}
+
+// CfftzError reports parameters cfftz cannot transform with: a direction
+// other than 1 or -1, or a log2 size M outside 1..MaxM
+type CfftzError struct {
+	Is, M, MaxM int
+}
+
+func (e *CfftzError) Error() string {
+	return fmt.Sprintf("CFFTZ: invalid parameters is=%d, m=%d (max %d)", e.Is, e.M, e.MaxM)
+}
+
+// cfftz performs Stockham FFT
+func (ft *FTBenchmark) cfftz(is, m, n int, x, y []Dcomplex) error {
+	mx := int(real(ft.u[0]))
+	if (is != 1 && is != -1) || m < 1 || m > mx {
+		return &CfftzError{Is: is, M: m, MaxM: mx}
+	}
+
+	for l := 1; l <= m; l += 2 {
+		ft.fftz2(is, l, m, n, FFTBLOCK, FFTBLOCKPAD, ft.u, x, y)
+		if l == m {
+			for j := 0; j < n; j++ {
+				for i := 0; i < FFTBLOCK; i++ {
+					x[j*FFTBLOCKPAD+i] = y[j*FFTBLOCKPAD+i]
+				}
+			}
+			break
+		}
+		ft.fftz2(is, l+1, m, n, FFTBLOCK, FFTBLOCKPAD, ft.u, y, x)
+	}
+	return nil
+}
+
+func (ft *FTBenchmark) fftz2(is, l, m, n, ny, ny1 int, u, x, y []Dcomplex) {
+	n1 := n / 2
+	lk := 1 << (l - 1)
+	li := 1 << (m - l)
+	lj := 2 * lk
+	ku := li
+
+	for i := 0; i <= li-1; i++ {
+		i11 := i * lk
+		i12 := i11 + n1
+		i21 := i * lj
+		i22 := i21 + lk
+
+		var u1 Dcomplex
+		if is >= 1 {
+			u1 = u[ku+i]
+		} else {
+			u1 = cmplx.Conj(u[ku+i])
+		}
+
+		for k := 0; k <= lk-1; k++ {
+			for j := 0; j < ny; j++ {
+				x11 := x[(i11+k)*ny1+j]
+				x21 := x[(i12+k)*ny1+j]
+
+				y[(i21+k)*ny1+j] = x11 + x21
+				y[(i22+k)*ny1+j] = u1 * (x11 - x21)
+			}
+		}
+	}
+}
+
+// cffts1 performs FFT in 1st dimension (parallelized)
+func (ft *FTBenchmark) cffts1(is, d1, d2, d3 int, x, xout []Dcomplex) error {
+	logd1 := ilog2(d1)
+
+	if ft.timerOn {
+		ft.timers.Start(T_FFTX)
+	}
+
+	errs := make([]error, ft.team.Workers())
+	ft.team.For(0, d3, func(start, end, id int) {
+		y1, y2 := ft.y1[id][:d1*FFTBLOCKPAD], ft.y2[id][:d1*FFTBLOCKPAD]
+
+		for k := start; k < end; k++ {
+			for jj := 0; jj <= d2-FFTBLOCK; jj += FFTBLOCK {
+				// Load into blocks
+				for j := 0; j < FFTBLOCK; j++ {
+					for i := 0; i < d1; i++ {
+						y1[i*FFTBLOCKPAD+j] = x[k*d2*d1+(j+jj)*d1+i]
+					}
+				}
+
+				if err := ft.cfftz(is, logd1, d1, y1, y2); err != nil {
+					errs[id] = err
+					return
+				}
+
+				// Store back
+				for j := 0; j < FFTBLOCK; j++ {
+					for i := 0; i < d1; i++ {
+						xout[k*d2*d1+(j+jj)*d1+i] = y1[i*FFTBLOCKPAD+j]
+					}
+				}
+			}
+		}
+	})
+
+	if ft.timerOn {
+		ft.timers.Stop(T_FFTX)
+	}
+	return firstError(errs)
+}
+
+// cffts2 performs FFT in 2nd dimension (parallelized)
+func (ft *FTBenchmark) cffts2(is, d1, d2, d3 int, x, xout []Dcomplex) error {
+	logd2 := ilog2(d2)
+
+	if ft.timerOn {
+		ft.timers.Start(T_FFTY)
+	}
+
+	errs := make([]error, ft.team.Workers())
+	ft.team.For(0, d3, func(start, end, id int) {
+		y1, y2 := ft.y1[id][:d2*FFTBLOCKPAD], ft.y2[id][:d2*FFTBLOCKPAD]
+
+		for k := start; k < end; k++ {
+			for ii := 0; ii <= d1-FFTBLOCK; ii += FFTBLOCK {
+				for j := 0; j < d2; j++ {
+					for i := 0; i < FFTBLOCK; i++ {
+						y1[j*FFTBLOCKPAD+i] = x[k*d2*d1+j*d1+(i+ii)]
+					}
+				}
+
+				if err := ft.cfftz(is, logd2, d2, y1, y2); err != nil {
+					errs[id] = err
+					return
+				}
+
+				for j := 0; j < d2; j++ {
+					for i := 0; i < FFTBLOCK; i++ {
+						xout[k*d2*d1+j*d1+(i+ii)] = y1[j*FFTBLOCKPAD+i]
+					}
+				}
+			}
+		}
+	})
+
+	if ft.timerOn {
+		ft.timers.Stop(T_FFTY)
+	}
+	return firstError(errs)
+}
+
+// cffts3 performs FFT in 3rd dimension (parallelized)
+func (ft *FTBenchmark) cffts3(is, d1, d2, d3 int, x, xout []Dcomplex) error {
+	logd3 := ilog2(d3)
+
+	if ft.timerOn {
+		ft.timers.Start(T_FFTZ)
+	}
+
+	errs := make([]error, ft.team.Workers())
+	ft.team.For(0, d2, func(start, end, id int) {
+		y1, y2 := ft.y1[id][:d3*FFTBLOCKPAD], ft.y2[id][:d3*FFTBLOCKPAD]
+
+		for j := start; j < end; j++ {
+			for ii := 0; ii <= d1-FFTBLOCK; ii += FFTBLOCK {
+				for k := 0; k < d3; k++ {
+					for i := 0; i < FFTBLOCK; i++ {
+						y1[k*FFTBLOCKPAD+i] = x[k*d2*d1+j*d1+(i+ii)]
+					}
+				}
+
+				if err := ft.cfftz(is, logd3, d3, y1, y2); err != nil {
+					errs[id] = err
+					return
+				}
+
+				for k := 0; k < d3; k++ {
+					for i := 0; i < FFTBLOCK; i++ {
+						xout[k*d2*d1+j*d1+(i+ii)] = y1[k*FFTBLOCKPAD+i]
+					}
+				}
+			}
+		}
+	})
+
+	if ft.timerOn {
+		ft.timers.Stop(T_FFTZ)
+	}
+	return firstError(errs)
+}
+
+// firstError returns the first non-nil error reported by the workers
+func firstError(errs []error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fft performs the main FFT operation sequence
+func (ft *FTBenchmark) fft(dir int, x1, x2 []Dcomplex) error {
+	if dir == 1 {
+		if err := ft.cffts1(1, ft.dims[0], ft.dims[1], ft.dims[2], x1, x1); err != nil {
+			return err
+		}
+		if err := ft.cffts2(1, ft.dims[0], ft.dims[1], ft.dims[2], x1, x1); err != nil {
+			return err
+		}
+		return ft.cffts3(1, ft.dims[0], ft.dims[1], ft.dims[2], x1, x2)
+	}
+	if err := ft.cffts3(-1, ft.dims[0], ft.dims[1], ft.dims[2], x1, x1); err != nil {
+		return err
+	}
+	if err := ft.cffts2(-1, ft.dims[0], ft.dims[1], ft.dims[2], x1, x1); err != nil {
+		return err
+	}
+	return ft.cffts1(-1, ft.dims[0], ft.dims[1], ft.dims[2], x1, x2)
+}
+
+// evolve performs the evolution step (parallelized)
+func (ft *FTBenchmark) evolve(u0, u1, twiddle []Dcomplex, d1, d2, d3 int) {
+	ft.team.For(0, d3, func(start, end, _ int) {
+		for k := start; k < end; k++ {
+			for j := 0; j < d2; j++ {
+				for i := 0; i < d1; i++ {
+					idx := k*d2*d1 + j*d1 + i
+					u0[idx] = u0[idx] * twiddle[idx]
+					u1[idx] = u0[idx]
+				}
+			}
+		}
+	})
+}
+
+// checksum computes the checksum (parallelized with reduction)
+func (ft *FTBenchmark) checksum(i int, u1 []Dcomplex, d1, d2, d3 int) {
+	// Reduce the points j = 1..1024, real and imaginary parts apart
+	var chk Dcomplex
+	ft.team.Run(func(w *common.Worker) {
+		chk_worker := complex(0.0, 0.0)
+		w.For(1, 1025, func(start, end int) {
+			for j := start; j < end; j++ {
+				q := j % ft.NX
+				r := (3 * j) % ft.NY
+				s := (5 * j) % ft.NZ
+				idx := s*d2*d1 + r*d1 + q
+				chk_worker += u1[idx]
+			}
+		})
+		re, im := w.Sum(real(chk_worker)), w.Sum(imag(chk_worker))
+		if w.ID == 0 {
+			chk = complex(re, im)
+		}
+	})
+
+	chk = chk / complex(float64(ft.NTOTAL), 0.0)
+	fmt.Fprintf(ft.out, " T =%5d     Checksum =%22.12e%22.12e\n", i, real(chk), imag(chk))
+	ft.sums[i] = chk
+}
+
+// checksumReference returns the class whose grid and iteration count match
+// the arguments, with its reference checksums indexed by iteration (zero
+// where NPB gives none). The class is "U" when no class matches.
+func checksumReference(d1, d2, d3, nt int) (string, []Dcomplex) {
+	csum_ref := make([]Dcomplex, 26)
+	class := "U"
+
+	if d1 == 64 && d2 == 64 && d3 == 64 && nt == 6 {
+		class = "S"
+		csum_ref[1] = complex(5.546087004964e+02, 4.845363331978e+02)
+		csum_ref[2] = complex(5.546385409189e+02, 4.865304269511e+02)
+		csum_ref[3] = complex(5.546148406171e+02, 4.883910722336e+02)
+		csum_ref[4] = complex(5.545423607415e+02, 4.901273169046e+02)
+		csum_ref[5] = complex(5.544255039624e+02, 4.917475857993e+02)
+		csum_ref[6] = complex(5.542683411902e+02, 4.932597244941e+02)
+	} else if d1 == 128 && d2 == 128 && d3 == 32 && nt == 6 {
+		class = "W"
+		csum_ref[1] = complex(5.673612178944e+02, 5.293246849175e+02)
+		csum_ref[6] = complex(5.504159734538e+02, 5.239212247086e+02)
+	} else if d1 == 256 && d2 == 256 && d3 == 128 && nt == 6 {
+		class = "A"
+		csum_ref[6] = complex(5.091487099959e+02, 5.107917842803e+02)
+	} else if d1 == 512 && d2 == 256 && d3 == 256 && nt == 20 {
+		class = "B"
+		csum_ref[20] = complex(5.124146770029e+02, 5.115744692211e+02)
+	} else if d1 == 512 && d2 == 512 && d3 == 512 && nt == 20 {
+		class = "C"
+		csum_ref[20] = complex(5.129714421109e+02, 5.123465164008e+02)
+	} else if d1 == 2048 && d2 == 1024 && d3 == 1024 && nt == 25 {
+		class = "D"
+		csum_ref[25] = complex(5.118822370068e+02, 5.119794338060e+02)
+	}
+	return class, csum_ref
+}
+
+// verify performs verification against reference values
+func (ft *FTBenchmark) verify(d1, d2, d3, nt int, verified *bool, class_npb *string) {
+	*verified = false
+	epsilon := 1.0e-12
+	var csum_ref []Dcomplex
+	*class_npb, csum_ref = checksumReference(d1, d2, d3, nt)
+
+	if *class_npb != "U" {
+		*verified = true
+		for i := 1; i <= nt; i++ {
+			if csum_ref[i] == 0 {
+				continue
+			}
+
+			ref := csum_ref[i]
+			sum := ft.sums[i]
+
+			diff := sum - ref
+			modDiff := math.Sqrt(real(diff)*real(diff) + imag(diff)*imag(diff))
+			modRef := math.Sqrt(real(ref)*real(ref) + imag(ref)*imag(ref))
+			err := modDiff / modRef
+
+			if err > epsilon {
+				*verified = false
+				break
+			}
+		}
+	}
+}
+
+// run performs the FT benchmark and returns its result, or the error that
+// stopped one of its transforms or its iterations
+func (ft *FTBenchmark) run(ctx context.Context) (Result, error) {
+	team, err := common.StartTeam(ft.numWorkers, ft.schedule, ft.bind)
+	if err != nil {
+		return Result{}, err
+	}
+	ft.team = team
+	defer ft.team.Close()
+	ft.timersEnabled = ft.timerOn
+
+	for i := 0; i < T_MAX+1; i++ {
+		ft.timers.Clear(i)
+	}
+
+	// Setup global ft.dims and arrays
+	ft.dims[0], ft.dims[1], ft.dims[2] = ft.NX, ft.NY, ft.NZ
+	ft.NTOTAL = ft.NX * ft.NY * ft.NZ
+
+	// Allocation
+	ft.u0 = make([]Dcomplex, ft.NTOTAL)
+	ft.u1 = make([]Dcomplex, ft.NTOTAL)
+	ft.twiddle = make([]Dcomplex, ft.NTOTAL)
+	ft.sums = make([]Dcomplex, ft.NITER+1)
+	ft.u = make([]Dcomplex, ft.MAXDIM)
+	ft.y1 = make([][]Dcomplex, ft.team.Workers())
+	ft.y2 = make([][]Dcomplex, ft.team.Workers())
+	ft.team.Run(func(w *common.Worker) {
+		ft.y1[w.ID] = make([]Dcomplex, ft.MAXDIM*FFTBLOCKPAD)
+		ft.y2[w.ID] = make([]Dcomplex, ft.MAXDIM*FFTBLOCKPAD)
+	})
+	if ft.firstTouch {
+		// evolve, cffts1 and cffts2 split the grid by planes of d1*d2
+		plane := ft.dims[0] * ft.dims[1]
+		for _, a := range [][]Dcomplex{ft.u0, ft.u1, ft.twiddle} {
+			common.Touch(ft.team, a, 0, plane, ft.dims[2], 0, ft.dims[2])
+		}
+	}
+
+	fmt.Fprintf(ft.out, "\n\n NAS Parallel Benchmarks 4.1 Go Goroutine version - FT Benchmark\n\n")
+	fmt.Fprintf(ft.out, " Size                : %4dx%4dx%4d\n", ft.NX, ft.NY, ft.NZ)
+	fmt.Fprintf(ft.out, " Iterations                  :%7d\n", ft.NITER)
+	fmt.Fprintf(ft.out, " Number of workers           :%7d\n", ft.numWorkers)
+	fmt.Fprintf(ft.out, " Schedule                    :%7s\n", ft.schedule)
+	fmt.Fprintf(ft.out, " Placement                   : %s\n", common.FormatPlacement(ft.team.Placement()))
+	fmt.Fprintf(ft.out, " First touch                 :%7t\n\n", ft.firstTouch)
+
+	// 1. Warmup Run
+	ft.compute_indexmap(ft.twiddle, ft.dims[0], ft.dims[1], ft.dims[2])
+	ft.compute_initial_conditions(ft.u1, ft.dims[0], ft.dims[1], ft.dims[2])
+	ft.fft_init(ft.MAXDIM)
+	if err := ft.fft(1, ft.u1, ft.u0); err != nil {
+		return Result{}, err
+	}
+
+	// 2. Timed Run
+	for i := 0; i < T_MAX+1; i++ {
+		ft.timers.Clear(i)
+	}
+
+	ft.timers.Start(T_TOTAL)
+	if ft.timerOn {
+		ft.timers.Start(T_SETUP)
+	}
+
+	ft.compute_indexmap(ft.twiddle, ft.dims[0], ft.dims[1], ft.dims[2])
+	if ft.input != nil {
+		for i, v := range ft.input.Sample(ft.NTOTAL) {
+			ft.u1[i] = complex(v, 0)
+		}
+	} else {
+		ft.compute_initial_conditions(ft.u1, ft.dims[0], ft.dims[1], ft.dims[2])
+	}
+	ft.fft_init(ft.MAXDIM)
+
+	if ft.timerOn {
+		ft.timers.Stop(T_SETUP)
+	}
+	if ft.timerOn {
+		ft.timers.Start(T_FFT)
+	}
+
+	if err := ft.fft(1, ft.u1, ft.u0); err != nil {
+		return Result{}, err
+	}
+
+	if ft.timerOn {
+		ft.timers.Stop(T_FFT)
+	}
+
+	iterations := ft.NITER
+	for iter := 1; iter <= ft.NITER; iter++ {
+		if ctx.Err() != nil {
+			iterations = iter - 1
+			break
+		}
+
+		if ft.timerOn {
+			ft.timers.Start(T_EVOLVE)
+		}
+		ft.evolve(ft.u0, ft.u1, ft.twiddle, ft.dims[0], ft.dims[1], ft.dims[2])
+		if ft.timerOn {
+			ft.timers.Stop(T_EVOLVE)
+		}
+
+		if ft.timerOn {
+			ft.timers.Start(T_FFT)
+		}
+		if err := ft.fft(-1, ft.u1, ft.u1); err != nil {
+			return Result{}, err
+		}
+		if ft.timerOn {
+			ft.timers.Stop(T_FFT)
+		}
+
+		if ft.timerOn {
+			ft.timers.Start(T_CHECKSUM)
+		}
+		ft.checksum(iter, ft.u1, ft.dims[0], ft.dims[1], ft.dims[2])
+		if ft.timerOn {
+			ft.timers.Stop(T_CHECKSUM)
+		}
+	}
+
+	var verified bool
+	var class_npb string
+	ft.verify(ft.NX, ft.NY, ft.NZ, ft.NITER, &verified, &class_npb)
+	if ft.input != nil {
+		class_npb, verified = params.UserClass, false
+	}
+	incomplete := iterations < ft.NITER
+	if incomplete {
+		verified = false
+	}
+
+	ft.timers.Stop(T_TOTAL)
+	totalTime := ft.timers.Read(T_TOTAL)
+	numa := common.NUMABytes(common.SpanOf(ft.u0), common.SpanOf(ft.u1), common.SpanOf(ft.twiddle))
+	fmt.Fprintf(ft.out, " NUMA pages                  : %s\n", common.FormatNUMABytes(numa))
+
+	mflops := 0.0
+	if totalTime != 0.0 {
+		ntVal := float64(ft.NTOTAL)
+		mflops = 1.0e-6 * ntVal *
+			(14.8157 + 7.19641*math.Log(ntVal) +
+				(5.23518+7.21113*math.Log(ntVal))*float64(iterations)) / totalTime
+	}
+
+	verificationStr := "FAILED"
+	if incomplete {
+		verificationStr = fmt.Sprintf("NOT PERFORMED (stopped after %d of %d iterations)", iterations, ft.NITER)
+	} else if class_npb == "U" {
+		verificationStr = "NOT PERFORMED"
+	} else if verified {
+		verificationStr = "SUCCESSFUL"
+	}
+	fmt.Fprintf(ft.out, " Result verification %s\n", verificationStr)
+	fmt.Fprintf(ft.out, " class_npb = %s\n", class_npb)
+
+	result := common.Result{
+		Kernel:      "FT",
+		Class:       class_npb,
+		Size:        [3]int{ft.NX, ft.NY, ft.NZ},
+		Iterations:  iterations,
+		Time:        totalTime,
+		Mops:        mflops,
+		OpType:      "floating point",
+		Verified:    verified,
+		Incomplete:  incomplete,
+		Workers:     ft.numWorkers,
+		NPBVersion:  "4.1",
+		CompileTime: "Unknown",
+		Compiler:    "Go",
+	}
+	if ft.timerOn {
+		tstrings := []string{"", "total", "setup", "fft", "evolve", "checksum", "fftx", "ffty", "fftz"}
+		for i := 1; i <= T_MAX; i++ {
+			result.Timers = append(result.Timers, common.Timer{Name: tstrings[i], Seconds: ft.timers.Read(i)})
+		}
+	}
+	ft.team.Describe(&result)
+	result.FirstTouch = ft.firstTouch
+	result.NUMABytes = numa
+	common.Finish(&result, ft.out)
+
+	if ft.timerOn {
+		fmt.Fprintln(ft.out, "  SECTION   Time (secs)")
+		for _, t := range result.Timers {
+			fmt.Fprintf(ft.out, "  %-8s:%9.3f  (%6.2f%%)\n", t.Name, t.Seconds, t.Seconds*100.0/totalTime)
+		}
+	}
+	res := Result{Result: result, Sums: ft.sums}
+	if ft.output {
+		res.Output = common.NewField(ft.NX, ft.NY, ft.NZ)
+		for i, v := range ft.u1 {
+			res.Output.Data[i] = real(v)
+		}
+	}
+	if incomplete {
+		return res, &common.IncompleteError{Completed: iterations, Planned: ft.NITER, Err: ctx.Err()}
+	}
+	return res, nil
+}
@@ -0,0 +1,153 @@
+package ft
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"math/cmplx"
+	"testing"
+
+	"github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/FT/params"
+	"github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/common"
+	"github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/common/npbtest"
+)
+
+var long = flag.Bool("long", false, "also verify class A")
+
+// testClasses lists the classes the verification tests run
+func testClasses() []string {
+	if *long {
+		return []string{"S", "W", "A"}
+	}
+	return []string{"S", "W"}
+}
+
+func BenchmarkFT(b *testing.B) {
+	for _, class := range []string{"S", "W"} {
+		b.Run(class, func(b *testing.B) {
+			p, _ := params.Lookup(class)
+			mops := 0.0
+			for i := 0; i < b.N; i++ {
+				result, err := Run(context.Background(), Config{Params: p})
+				if err != nil {
+					b.Fatal(err)
+				}
+				if result.Failed() {
+					b.Fatalf("class %s failed verification", class)
+				}
+				mops += result.Mops
+			}
+			b.ReportMetric(mops/float64(b.N), "Mop/s")
+		})
+	}
+}
+
+func TestVerification(t *testing.T) {
+	for _, class := range testClasses() {
+		t.Run(class, func(t *testing.T) {
+			p, _ := params.Lookup(class)
+			result, err := Run(context.Background(), Config{Params: p})
+			if err != nil {
+				t.Fatal(err)
+			}
+			refClass, ref := checksumReference(p.NX, p.NY, p.NZ, p.NITER)
+			if refClass != class {
+				t.Fatalf("sizes of class %s match reference class %s", class, refClass)
+			}
+			for i := 1; i <= p.NITER; i++ {
+				if ref[i] == 0 {
+					continue
+				}
+				if err := cmplx.Abs(result.Sums[i]-ref[i]) / cmplx.Abs(ref[i]); err > EPSILON {
+					t.Errorf("checksum %d = %.12e, want %.12e", i, result.Sums[i], ref[i])
+				}
+			}
+			if !result.Verified {
+				t.Error("kernel reported a failed verification")
+			}
+		})
+	}
+}
+
+func TestCfftzInvalidParameters(t *testing.T) {
+	ft := NewFTBenchmark(params.Params{}, 1, nil)
+	ft.u = []Dcomplex{4}
+	for _, tc := range []struct{ is, m int }{{0, 2}, {1, 0}, {-1, 5}} {
+		var cfftzErr *CfftzError
+		if err := ft.cfftz(tc.is, tc.m, 1<<tc.m, nil, nil); !errors.As(err, &cfftzErr) {
+			t.Errorf("cfftz(is=%d, m=%d) returned %v, want a *CfftzError", tc.is, tc.m, err)
+		}
+	}
+}
+
+// TestWorkers checks that the kernel verifies on teams of any size, including
+// one with more workers than the loops have iterations to share
+func TestWorkers(t *testing.T) {
+	p, _ := params.Lookup("S")
+	for _, workers := range []int{1, 3, 64} {
+		result, err := Run(context.Background(), Config{Params: p, Workers: workers})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Workers != workers || !result.Verified {
+			t.Errorf("%d workers: got %d workers, verified %v", workers, result.Workers, result.Verified)
+		}
+	}
+}
+
+// TestSchedules checks that the kernel verifies under every kind of schedule
+func TestSchedules(t *testing.T) {
+	p, _ := params.Lookup("S")
+	for _, text := range []string{"static", "static,3", "dynamic", "dynamic,7", "guided", "guided,2"} {
+		sched, err := common.ParseSchedule(text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result, err := Run(context.Background(), Config{Params: p, Workers: 3, Schedule: sched})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Schedule != text || !result.Verified {
+			t.Errorf("%s: got schedule %q, verified %v", text, result.Schedule, result.Verified)
+		}
+	}
+}
+
+// TestFirstTouch checks that grids zeroed by the workers verify, and that a
+// GO_FIRST_TOUCH that is not a boolean is an error
+func TestFirstTouch(t *testing.T) {
+	p, _ := params.Lookup("S")
+	for _, workers := range []int{1, 3, 64} {
+		result, err := Run(context.Background(), Config{Params: p, Workers: workers, FirstTouch: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !result.FirstTouch || !result.Verified {
+			t.Errorf("%d workers: got first touch %v, verified %v", workers, result.FirstTouch, result.Verified)
+		}
+	}
+
+	t.Setenv("GO_FIRST_TOUCH", "workers")
+	if _, err := Run(context.Background(), Config{Params: p, Workers: 2}); err == nil {
+		t.Error("GO_FIRST_TOUCH=workers was accepted")
+	}
+}
+
+// runClass runs the kernel at class for the checks every kernel shares
+func runClass(ctx context.Context, class string) (common.Result, error) {
+	p, _ := params.Lookup(class)
+	result, err := Run(ctx, Config{Params: p})
+	return result.Result, err
+}
+
+// TestConcurrentRuns checks that runs of two classes in one process do not
+// share state
+func TestConcurrentRuns(t *testing.T) {
+	npbtest.ConcurrentRuns(t, runClass, "S", "W")
+}
+
+// TestStoppedRun checks a run stopped by its context. Run checks once before
+// starting and then before every iteration
+func TestStoppedRun(t *testing.T) {
+	npbtest.StoppedRun(t, runClass, "S", "iterations")
+}
@@ -1,14 +0,0 @@
-//go:build A
-
-package params
-
-const (
-	CLASS     = "A"
-	NX        = 256
-	NY        = 256
-	NZ        = 128
-	NITER     = 6
-	MAXDIM    = 256
-	EmptyTag  = false
-)
-
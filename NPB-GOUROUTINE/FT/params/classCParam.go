@@ -1,14 +0,0 @@
-//go:build C
-
-package params
-
-const (
-	CLASS     = "C"
-	NX        = 512
-	NY        = 512
-	NZ        = 512
-	NITER     = 20
-	MAXDIM    = 512
-	EmptyTag  = false
-)
-
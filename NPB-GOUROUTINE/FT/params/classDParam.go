@@ -1,14 +0,0 @@
-//go:build D
-
-package params
-
-const (
-	CLASS     = "D"
-	NX        = 2048
-	NY        = 1024
-	NZ        = 1024
-	NITER     = 25
-	MAXDIM    = 2048
-	EmptyTag  = false
-)
-
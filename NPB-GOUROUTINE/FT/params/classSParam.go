@@ -1,14 +0,0 @@
-//go:build S
-
-package params
-
-const (
-	CLASS     = "S"
-	NX        = 64
-	NY        = 64
-	NZ        = 64
-	NITER     = 6
-	MAXDIM    = 64
-	EmptyTag  = false
-)
-
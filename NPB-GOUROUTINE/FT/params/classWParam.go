@@ -1,14 +0,0 @@
-//go:build W
-
-package params
-
-const (
-	CLASS     = "W"
-	NX        = 128
-	NY        = 128
-	NZ        = 32
-	NITER     = 6
-	MAXDIM    = 128
-	EmptyTag  = false
-)
-
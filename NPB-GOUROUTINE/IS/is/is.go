@@ -0,0 +1,660 @@
+// Package is is the IS (Integer Sort) kernel: it ranks a large sequence of
+// integer keys with a bucket sort.
+package is
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/IS/params"
+	"github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/IS/types"
+	"github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/IS/verifier"
+	"github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/common"
+)
+
+const (
+	T_BENCHMARKING    = 0
+	T_INITIALIZATION  = 1
+	T_SORTING         = 2
+	T_TOTAL_EXECUTION = 3
+	MAX_ITERATIONS    = 10
+	USE_BUCKETS       = true
+	TEST_ARRAY_SIZE   = 5
+)
+
+// Config selects the problem a Run solves
+type Config struct {
+	Params   params.Params   // problem size, from params.Lookup or params.Custom
+	Workers  int             // goroutines; 0 means $GO_NUM_THREADS, or one per CPU
+	Schedule common.Schedule // of the loops over buckets and keys; none means $GO_SCHEDULE
+	Bind     common.Binding  // of the workers to cores; none means $GO_PROC_BIND
+	Out      io.Writer       // progress messages and the NPB banner, discarded when nil
+
+	// Input, from an upstream task of a workflow, is turned into the keys in
+	// place of the random sequence. The run is then of class U, with only
+	// the full verification.
+	Input  *common.Field
+	Output bool // return the sorted keys in Result.Output
+}
+
+// Result is the outcome of an IS run with its verification counts
+type Result struct {
+	common.Result
+	PartialPassed int           // partial verifications passed over the timed iterations
+	FullVerified  bool          // whether the final key sequence was sorted
+	Output        *common.Field // the sorted keys, when Config.Output is set
+}
+
+// Run ranks the keys of cfg.Params and returns the result. When ctx is done
+// between two iterations the run stops there and Run returns the partial result
+// with a *common.IncompleteError.
+func Run(ctx context.Context, cfg Config) (Result, error) {
+	if err := ctx.Err(); err != nil {
+		return Result{}, err
+	}
+	workers, err := common.ResolveWorkers(cfg.Workers)
+	if err != nil {
+		return Result{}, err
+	}
+	sched, err := common.ResolveSchedule(cfg.Schedule)
+	if err != nil {
+		return Result{}, err
+	}
+	bind, err := common.ResolveBinding(cfg.Bind)
+	if err != nil {
+		return Result{}, err
+	}
+	p := cfg.Params
+	if cfg.Input != nil {
+		p.CLASS = params.UserClass
+		p.Verifier = &verifier.EmptyVerifier{}
+	}
+	b := NewISBenchmark(p, workers)
+	b.schedule = sched
+	b.bind = bind
+	if cfg.Out != nil {
+		b.out = cfg.Out
+	}
+	b.input = cfg.Input
+	b.output = cfg.Output
+	return b.run(ctx)
+}
+
+// ISBenchmark represents the IS (Integer Sort) benchmark
+// This struct encapsulates all the state that was global in the C++ version
+type ISBenchmark struct {
+	// Problem size (derived from the class parameters)
+	params     params.Params
+	totalKeys  int
+	maxKey     int
+	numBuckets int
+	numKeys    int
+
+	// Main arrays (equivalent to global arrays in C++)
+	keyArray          []types.INT_TYPE
+	keyBuff1          []types.INT_TYPE
+	keyBuff2          []types.INT_TYPE
+	partialVerifyVals []types.INT_TYPE
+
+	// For USE_BUCKETS mode
+	bucketSize [][]types.INT_TYPE // [numProcs][numBuckets]
+	bucketPtrs []types.INT_TYPE   // [numBuckets]
+
+	// For !USE_BUCKETS mode
+	keyBuff1Aptr [][]types.INT_TYPE // [numProcs][maxKey]
+
+	// Global state (equivalent to global variables in C++)
+	keyBuffPtrGlobal   []types.INT_TYPE // Points to keyBuff1 (like pointer in C++)
+	passedVerification int
+
+	numProcs          int
+	schedule          common.Schedule
+	bind              common.Binding
+	team              *common.Team // started by run
+	verificationMutex sync.Mutex
+
+	input  *common.Field // data the keys are made of, nil for the random sequence
+	output bool
+
+	timers common.Timers
+	out    io.Writer
+}
+
+// NewISBenchmark creates a new IS benchmark instance running on numProcs
+// goroutines
+func NewISBenchmark(p params.Params, numProcs int) *ISBenchmark {
+	totalKeys := 1 << p.TOTAL_KEYS_LOG_2
+	maxKey := 1 << p.MAX_KEY_LOG_2
+
+	bench := &ISBenchmark{
+		params:            p,
+		totalKeys:         totalKeys,
+		maxKey:            maxKey,
+		numBuckets:        1 << p.NUM_BUCKETS_LOG_2,
+		numKeys:           totalKeys,
+		keyArray:          make([]types.INT_TYPE, totalKeys),
+		keyBuff1:          make([]types.INT_TYPE, maxKey),
+		keyBuff2:          make([]types.INT_TYPE, totalKeys),
+		partialVerifyVals: make([]types.INT_TYPE, TEST_ARRAY_SIZE),
+		keyBuffPtrGlobal:  make([]types.INT_TYPE, maxKey),
+		numProcs:          numProcs,
+		out:               io.Discard,
+	}
+
+	// Initialize bucketPtrs for USE_BUCKETS mode
+	if USE_BUCKETS {
+		bench.bucketPtrs = make([]types.INT_TYPE, bench.numBuckets)
+	}
+
+	return bench
+}
+
+// run performs the IS benchmark and returns its result, with an error when
+// ctx stopped its iterations early
+func (b *ISBenchmark) run(ctx context.Context) (Result, error) {
+	team, err := common.StartTeam(b.numProcs, b.schedule, b.bind)
+	if err != nil {
+		return Result{}, err
+	}
+	b.team = team
+	defer b.team.Close()
+
+	var timerOn bool
+	var timecounter float64
+
+	// Initialize timers
+	timerOn = false
+	if _, err := os.Stat("timer.flag"); err == nil {
+		timerOn = true
+	}
+
+	b.timers.Clear(T_BENCHMARKING)
+	if timerOn {
+		b.timers.Clear(T_INITIALIZATION)
+		b.timers.Clear(T_SORTING)
+		b.timers.Clear(T_TOTAL_EXECUTION)
+	}
+
+	if timerOn {
+		b.timers.Start(T_TOTAL_EXECUTION)
+	}
+
+	// Printout initial NPB info
+	fmt.Fprintf(b.out, "\n\n NAS Parallel Benchmarks 4.1 Serial Go version - IS Benchmark\n\n")
+	fmt.Fprintf(b.out, " Size:  %d  (class %s)\n", b.totalKeys, b.params.CLASS)
+	fmt.Fprintf(b.out, " Iterations:   %d\n", MAX_ITERATIONS)
+	fmt.Fprintf(b.out, "\n")
+
+	if timerOn {
+		b.timers.Start(T_INITIALIZATION)
+	}
+
+	// Generate random number sequence and subsequent keys
+	if b.input != nil {
+		b.inputSeq()
+	} else {
+		b.createSeq(314159265.00, 1220703125.00)
+	}
+
+	b.allocKeyBuff()
+
+	if timerOn {
+		b.timers.Stop(T_INITIALIZATION)
+	}
+
+	// Do one iteration for free (i.e., untimed) to guarantee initialization
+	b.rank(1)
+
+	// Start verification counter
+	b.passedVerification = 0
+
+	if b.params.CLASS != "S" {
+		fmt.Fprintln(b.out, "\n   iteration")
+	}
+
+	// Start timer
+	b.timers.Start(T_BENCHMARKING)
+
+	// This is the main iteration
+	iterations := MAX_ITERATIONS
+	for iteration := types.INT_TYPE(1); iteration <= MAX_ITERATIONS; iteration++ {
+		if ctx.Err() != nil {
+			iterations = int(iteration) - 1
+			break
+		}
+		if b.params.CLASS != "S" {
+			fmt.Fprintf(b.out, "        %d\n", iteration)
+		}
+		b.rank(iteration)
+	}
+
+	// End of timing
+	b.timers.Stop(T_BENCHMARKING)
+	timecounter = b.timers.Read(T_BENCHMARKING)
+	incomplete := iterations < MAX_ITERATIONS
+	if incomplete {
+		fmt.Fprintf(b.out, "\n Benchmark stopped after %d of %d iterations\n", iterations, MAX_ITERATIONS)
+	}
+
+	// This tests that keys are in sequence: sorting of last ranked key seq
+	if timerOn {
+		b.timers.Start(T_SORTING)
+	}
+	partialPassed := b.passedVerification
+	// The last iteration prepares the keys fullVerify checks
+	if !incomplete {
+		b.fullVerify()
+	}
+	fullVerified := b.passedVerification > partialPassed
+	if timerOn {
+		b.timers.Stop(T_SORTING)
+		b.timers.Stop(T_TOTAL_EXECUTION)
+	}
+
+	// The final printout
+	if b.passedVerification != 5*MAX_ITERATIONS+1 {
+		b.passedVerification = 0
+	}
+
+	mops := 0.0
+	if timecounter > 0 {
+		mops = float64(iterations*b.totalKeys) / timecounter / 1000000.0
+	}
+	result := common.Result{
+		Kernel:      "IS",
+		Class:       b.params.CLASS,
+		Size:        [3]int{b.totalKeys, 0, 0},
+		Iterations:  iterations,
+		Time:        timecounter,
+		Mops:        mops,
+		OpType:      "keys ranked",
+		Verified:    b.passedVerification > 0,
+		Incomplete:  incomplete,
+		Workers:     b.numProcs,
+		NPBVersion:  "4.1",
+		CompileTime: "Unknown",
+		Compiler:    "Go",
+	}
+	if timerOn {
+		result.Timers = []common.Timer{
+			{Name: "total execution", Seconds: b.timers.Read(T_TOTAL_EXECUTION)},
+			{Name: "initialization", Seconds: b.timers.Read(T_INITIALIZATION)},
+			{Name: "benchmarking", Seconds: b.timers.Read(T_BENCHMARKING)},
+			{Name: "sorting", Seconds: b.timers.Read(T_SORTING)},
+		}
+	}
+	b.team.Describe(&result)
+	common.Finish(&result, b.out)
+
+	// Print additional timers
+	if timerOn {
+		tTotal := b.timers.Read(T_TOTAL_EXECUTION)
+		fmt.Fprintf(b.out, "\nAdditional timers -\n")
+		fmt.Fprintf(b.out, " Total execution: %8.3f\n", tTotal)
+		if tTotal == 0.0 {
+			tTotal = 1.0
+		}
+		timecounter = b.timers.Read(T_INITIALIZATION)
+		tPercent := timecounter / tTotal * 100.0
+		fmt.Fprintf(b.out, " Initialization : %8.3f (%5.2f%%)\n", timecounter, tPercent)
+		timecounter = b.timers.Read(T_BENCHMARKING)
+		tPercent = timecounter / tTotal * 100.0
+		fmt.Fprintf(b.out, " Benchmarking   : %8.3f (%5.2f%%)\n", timecounter, tPercent)
+		timecounter = b.timers.Read(T_SORTING)
+		tPercent = timecounter / tTotal * 100.0
+		fmt.Fprintf(b.out, " Sorting        : %8.3f (%5.2f%%)\n", timecounter, tPercent)
+	}
+	res := Result{Result: result, PartialPassed: partialPassed, FullVerified: fullVerified}
+	if b.output {
+		res.Output = common.NewField(b.totalKeys, 1, 1)
+		for i, k := range b.keyArray {
+			res.Output.Data[i] = float64(k)
+		}
+	}
+	if incomplete {
+		return res, &common.IncompleteError{Completed: iterations, Planned: MAX_ITERATIONS, Err: ctx.Err()}
+	}
+	return res, nil
+}
+
+func (b *ISBenchmark) allocKeyBuff() {
+	numProcs := b.numProcs
+
+	if USE_BUCKETS {
+		b.bucketSize = make([][]types.INT_TYPE, numProcs)
+		for i := 0; i < numProcs; i++ {
+			b.bucketSize[i] = make([]types.INT_TYPE, b.numBuckets)
+		}
+
+		// Initialize keyBuff2 (parallel)
+		b.team.For(0, b.numKeys, func(start, end, _ int) {
+			for i := start; i < end; i++ {
+				b.keyBuff2[i] = 0
+			}
+		})
+	} else {
+		b.keyBuff1Aptr = make([][]types.INT_TYPE, numProcs)
+		b.keyBuff1Aptr[0] = b.keyBuff1
+		for i := 1; i < numProcs; i++ {
+			b.keyBuff1Aptr[i] = make([]types.INT_TYPE, b.maxKey)
+		}
+	}
+}
+
+// findMySeed returns parallel random number seq seed
+func findMySeed(kn int, np int, nn int64, s float64, a float64) float64 {
+	if kn == 0 {
+		return s
+	}
+
+	mq := (nn/4 + int64(np) - 1) / int64(np)
+	nq := mq * 4 * int64(kn) // number of rans to be skipped
+
+	t1 := s
+	t2 := a
+	kk := nq
+
+	for kk > 1 {
+		ik := kk / 2
+		if 2*ik == kk {
+			// CORREÇÃO: Ignorar o retorno, apenas atualizar t2 via ponteiro
+			common.Randlc(&t2, t2)
+			kk = ik
+		} else {
+			// CORREÇÃO: Ignorar o retorno, apenas atualizar t1 via ponteiro
+			common.Randlc(&t1, t2)
+			kk = kk - 1
+		}
+	}
+	// CORREÇÃO: Ignorar o retorno
+	common.Randlc(&t1, t2)
+
+	return t1
+}
+
+// createSeq generates random number sequence and subsequent keys
+func (b *ISBenchmark) createSeq(seed float64, a float64) {
+	// Parallel region - each worker makes a chunk of keys, from the seed
+	// findMySeed skips ahead to for the start of the chunk
+	b.team.Run(func(w *common.Worker) {
+		var x, s float64
+		var k types.INT_TYPE
+
+		mq := (b.numKeys + b.numProcs - 1) / b.numProcs
+		k1 := min(mq*w.ID, b.numKeys)
+		k2 := min(k1+mq, b.numKeys)
+
+		s = findMySeed(w.ID, b.numProcs, int64(4*b.numKeys), seed, a)
+		k = types.INT_TYPE(b.maxKey / 4)
+
+		for i := k1; i < k2; i++ {
+			x = common.Randlc(&s, a)
+			x += common.Randlc(&s, a)
+			x += common.Randlc(&s, a)
+			x += common.Randlc(&s, a)
+			b.keyArray[i] = types.INT_TYPE(float64(k) * x)
+		}
+	})
+}
+
+// inputSeq makes the keys of the input field, mapping its samples from
+// [-1, 1] onto the whole key range
+func (b *ISBenchmark) inputSeq() {
+	s := b.input.Sample(b.numKeys)
+	for i, v := range s {
+		b.keyArray[i] = types.INT_TYPE(min(float64(b.maxKey-1), float64(b.maxKey)*(v+1)/2))
+	}
+}
+
+// fullVerify verifies that all keys are correctly sorted
+func (b *ISBenchmark) fullVerify() {
+	if USE_BUCKETS {
+		// Buckets are already sorted. Sorting keys within each bucket
+		b.team.For(0, b.numBuckets, func(start, end, _ int) {
+			for bucketID := start; bucketID < end; bucketID++ {
+				var k, k1 types.INT_TYPE
+				if bucketID > 0 {
+					k1 = b.bucketPtrs[bucketID-1]
+				} else {
+					k1 = 0
+				}
+				k2 := b.bucketPtrs[bucketID]
+
+				for i := k1; i < k2; i++ {
+					// Need to use mutex for keyBuffPtrGlobal decrement
+					b.verificationMutex.Lock()
+					k = b.keyBuffPtrGlobal[b.keyBuff2[i]] - 1
+					b.keyBuffPtrGlobal[b.keyBuff2[i]] = k
+					// Keep mutex locked during write to keyArray to prevent races
+					b.keyArray[k] = b.keyBuff2[i]
+					b.verificationMutex.Unlock()
+				}
+			}
+		})
+	} else {
+		// Copy keyArray to keyBuff2
+		for i := 0; i < b.numKeys; i++ {
+			b.keyBuff2[i] = b.keyArray[i]
+		}
+		// This is actual sorting. Each thread is responsible for a subset of key values
+		j := b.numProcs
+		j = (b.maxKey + j - 1) / j
+		b.team.Run(func(w *common.Worker) {
+			var k, k1, k2 types.INT_TYPE
+			k1 = types.INT_TYPE(j * w.ID)
+			k2 = k1 + types.INT_TYPE(j)
+			if k2 > types.INT_TYPE(b.maxKey) {
+				k2 = types.INT_TYPE(b.maxKey)
+			}
+			for i := 0; i < b.numKeys; i++ {
+				if b.keyBuff2[i] >= k1 && b.keyBuff2[i] < k2 {
+					b.verificationMutex.Lock()
+					k = b.keyBuffPtrGlobal[b.keyBuff2[i]] - 1
+					b.keyBuffPtrGlobal[b.keyBuff2[i]] = k
+					// Keep mutex locked during write to keyArray
+					b.keyArray[k] = b.keyBuff2[i]
+					b.verificationMutex.Unlock()
+				}
+			}
+		})
+	}
+
+	// Confirm keys correctly sorted: count incorrectly sorted keys, if any
+	// Parallelize the verification loop
+	j := int(b.team.Sum(1, b.numKeys, func(start, end int) float64 {
+		localJ := 0
+		for i := start; i < end; i++ {
+			if b.keyArray[i-1] > b.keyArray[i] {
+				localJ++
+			}
+		}
+		return float64(localJ)
+	}))
+
+	if j != 0 {
+		fmt.Fprintf(b.out, "Full_verify: number of keys out of sort: %d\n", j)
+	} else {
+		b.verificationMutex.Lock()
+		b.passedVerification++
+		b.verificationMutex.Unlock()
+	}
+}
+
+// rank performs the main ranking/sorting operation for each iteration
+// Equivalent to rank in C++ - PARALLELIZED
+func (b *ISBenchmark) rank(iteration types.INT_TYPE) {
+	var shift int
+	var numBucketKeys types.INT_TYPE
+	var keyBuffPtr, keyBuffPtr2 []types.INT_TYPE
+
+	if USE_BUCKETS {
+		shift = b.params.MAX_KEY_LOG_2 - b.params.NUM_BUCKETS_LOG_2
+		numBucketKeys = types.INT_TYPE(1) << shift
+	}
+
+	// Set test values
+	b.keyArray[iteration] = iteration
+	b.keyArray[iteration+MAX_ITERATIONS] = types.INT_TYPE(b.maxKey) - iteration
+
+	// Determine where the partial verify test keys are, load into partial_verify_vals
+	for i := 0; i < TEST_ARRAY_SIZE; i++ {
+		b.partialVerifyVals[i] = b.keyArray[b.params.TEST_INDEX_ARRAY[i]]
+	}
+
+	// Setup pointers to key buffers
+	if USE_BUCKETS {
+		keyBuffPtr2 = b.keyBuff2
+	} else {
+		keyBuffPtr2 = b.keyArray
+	}
+	keyBuffPtr = b.keyBuff1
+
+	if USE_BUCKETS {
+		// Parallel region for bucket processing: the workers count the keys
+		// of their block per bucket, then scatter them into the buckets and
+		// rank the keys of their share of the buckets
+		b.team.Run(func(w *common.Worker) {
+			threadID := w.ID
+			workBuff := b.bucketSize[threadID]
+
+			// Initialize
+			for i := 0; i < b.numBuckets; i++ {
+				workBuff[i] = 0
+			}
+
+			// Determine the number of keys in each bucket
+			start, end := w.Range(0, b.numKeys)
+			for i := start; i < end; i++ {
+				workBuff[b.keyArray[i]>>shift]++
+			}
+			w.Barrier()
+
+			// Each worker calculates its own bucket pointers (threadprivate
+			// equivalent): the accumulated sizes of the lesser buckets and of
+			// the parts of this bucket that the workers before it count
+			localBucketPtrs := make([]types.INT_TYPE, b.numBuckets)
+			localBucketPtrs[0] = 0
+			for k := 0; k < threadID; k++ {
+				localBucketPtrs[0] += b.bucketSize[k][0]
+			}
+
+			for i := 1; i < b.numBuckets; i++ {
+				localBucketPtrs[i] = localBucketPtrs[i-1]
+				for k := 0; k < threadID; k++ {
+					localBucketPtrs[i] += b.bucketSize[k][i]
+				}
+				for k := threadID; k < b.numProcs; k++ {
+					localBucketPtrs[i] += b.bucketSize[k][i-1]
+				}
+			}
+
+			// Sort into appropriate bucket - each worker processes its block
+			for i := start; i < end; i++ {
+				k := b.keyArray[i]
+				bucketIdx := k >> shift
+				pos := localBucketPtrs[bucketIdx]
+				localBucketPtrs[bucketIdx]++
+				b.keyBuff2[pos] = k
+			}
+
+			// Global bucket pointers for the ranking phase
+			if threadID == 0 {
+				b.bucketPtrs[0] = 0
+				for k := 0; k < b.numProcs; k++ {
+					b.bucketPtrs[0] += b.bucketSize[k][0]
+				}
+
+				for i := 1; i < b.numBuckets; i++ {
+					b.bucketPtrs[i] = b.bucketPtrs[i-1]
+					for k := 0; k < b.numProcs; k++ {
+						b.bucketPtrs[i] += b.bucketSize[k][i]
+					}
+				}
+			}
+			w.Barrier()
+
+			// Now, buckets are sorted. Sort keys inside each bucket, which
+			// hold different numbers of keys, as the schedule deals them
+			w.For(0, b.numBuckets, func(first, last int) {
+				for bucketID := first; bucketID < last; bucketID++ {
+					var m, k1, k2 types.INT_TYPE
+					// Clear the work array section associated with each bucket
+					k1 = types.INT_TYPE(bucketID) * numBucketKeys
+					k2 = k1 + numBucketKeys
+					for k := k1; k < k2; k++ {
+						keyBuffPtr[k] = 0
+					}
+					// Ranking of all keys occurs in this section
+					if bucketID > 0 {
+						m = b.bucketPtrs[bucketID-1]
+					} else {
+						m = 0
+					}
+					for k := m; k < b.bucketPtrs[bucketID]; k++ {
+						keyBuffPtr[keyBuffPtr2[k]]++ // Now they have individual key population
+					}
+					// To obtain ranks of each key, successively add the individual key
+					// population, not forgetting to add m, the total of lesser keys
+					keyBuffPtr[k1] += m
+					for k := k1 + 1; k < k2; k++ {
+						keyBuffPtr[k] += keyBuffPtr[k-1]
+					}
+				}
+			})
+		})
+	} else {
+		// !USE_BUCKETS mode - parallelize work per worker
+		b.team.Run(func(w *common.Worker) {
+			workBuff := b.keyBuff1Aptr[w.ID]
+			// Clear the work array
+			for i := 0; i < b.maxKey; i++ {
+				workBuff[i] = 0
+			}
+			// Ranking of all keys occurs in this section
+			start, end := w.Range(0, b.numKeys)
+			for i := start; i < end; i++ {
+				workBuff[keyBuffPtr2[i]]++ // Now they have individual key population
+			}
+		})
+
+		// To obtain ranks of each key, successively add the individual key population
+		// (sequential - needs to be done per thread first, then accumulate)
+		for myid := 0; myid < b.numProcs; myid++ {
+			workBuff := b.keyBuff1Aptr[myid]
+			for i := 0; i < b.maxKey-1; i++ {
+				workBuff[i+1] += workBuff[i]
+			}
+		}
+
+		// Accumulate the global key population (sequential)
+		for k := 1; k < b.numProcs; k++ {
+			for i := 0; i < b.maxKey; i++ {
+				keyBuffPtr[i] += b.keyBuff1Aptr[k][i]
+			}
+		}
+	}
+
+	// This is the partial verify test section (sequential - as in C++)
+	// Observe that test_rank_array vals are shifted differently for different cases
+	for i := 0; i < TEST_ARRAY_SIZE; i++ {
+		k := b.partialVerifyVals[i] // test vals were put here
+		if 0 < k && k <= types.INT_TYPE(b.numKeys-1) {
+			keyRank := keyBuffPtr[k-1]
+			b.verificationMutex.Lock()
+			failed := b.params.Verifier.Do(i, iteration, keyRank, b.params.TEXT_RANK_ARRAY[:], &b.passedVerification)
+			b.verificationMutex.Unlock()
+			if failed {
+				fmt.Fprintf(b.out, "Failed partial verification: iteration %d, test key %d\n", iteration, i)
+			}
+		}
+	}
+
+	// Make copies of rank info for use by full_verify
+	if iteration == MAX_ITERATIONS {
+		copy(b.keyBuffPtrGlobal, keyBuffPtr)
+		// bucketPtrs is already a field, so it's preserved
+	}
+}
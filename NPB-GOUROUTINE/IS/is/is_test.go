@@ -0,0 +1,137 @@
+package is
+
+import (
+	"context"
+	"flag"
+	"runtime"
+	"testing"
+
+	"github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/IS/params"
+	"github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/common"
+	"github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/common/npbtest"
+)
+
+var long = flag.Bool("long", false, "also verify class A")
+
+// testClasses lists the classes the verification tests run
+func testClasses() []string {
+	if *long {
+		return []string{"S", "W", "A"}
+	}
+	return []string{"S", "W"}
+}
+
+func BenchmarkIS(b *testing.B) {
+	for _, class := range []string{"S", "W"} {
+		b.Run(class, func(b *testing.B) {
+			p, _ := params.Lookup(class)
+			mops := 0.0
+			for i := 0; i < b.N; i++ {
+				result, err := Run(context.Background(), Config{Params: p})
+				if err != nil {
+					b.Fatal(err)
+				}
+				if result.Failed() {
+					b.Fatalf("class %s failed verification", class)
+				}
+				mops += result.Mops
+			}
+			b.ReportMetric(mops/float64(b.N), "Mop/s")
+		})
+	}
+}
+
+func TestVerification(t *testing.T) {
+	for _, class := range testClasses() {
+		t.Run(class, func(t *testing.T) {
+			p, _ := params.Lookup(class)
+			result, err := Run(context.Background(), Config{Params: p})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := TEST_ARRAY_SIZE * MAX_ITERATIONS; result.PartialPassed != want {
+				t.Errorf("%d partial verifications passed, want %d", result.PartialPassed, want)
+			}
+			if !result.FullVerified {
+				t.Error("keys are not sorted after the last iteration")
+			}
+			if !result.Verified {
+				t.Error("kernel reported a failed verification")
+			}
+		})
+	}
+}
+
+// TestWorkers checks that the kernel verifies on teams of any size, including
+// one with more workers than the loops have iterations to share
+func TestWorkers(t *testing.T) {
+	p, _ := params.Lookup("S")
+	for _, workers := range []int{1, 3, 64} {
+		result, err := Run(context.Background(), Config{Params: p, Workers: workers})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Workers != workers || !result.Verified {
+			t.Errorf("%d workers: got %d workers, verified %v", workers, result.Workers, result.Verified)
+		}
+	}
+}
+
+// TestWorkersFromEnvironment checks that $GO_NUM_THREADS sets the number of
+// workers, leaves GOMAXPROCS alone, and must be a positive integer
+func TestWorkersFromEnvironment(t *testing.T) {
+	p, _ := params.Lookup("S")
+	maxProcs := runtime.GOMAXPROCS(0)
+	t.Setenv("GO_NUM_THREADS", "5")
+	result, err := Run(context.Background(), Config{Params: p})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Workers != 5 || runtime.GOMAXPROCS(0) != maxProcs {
+		t.Errorf("got %d workers, GOMAXPROCS %d, want 5 and %d", result.Workers, runtime.GOMAXPROCS(0), maxProcs)
+	}
+
+	for _, text := range []string{"0", "-2", "many"} {
+		t.Setenv("GO_NUM_THREADS", text)
+		if _, err := Run(context.Background(), Config{Params: p}); err == nil {
+			t.Errorf("GO_NUM_THREADS=%s was accepted", text)
+		}
+	}
+}
+
+// TestSchedules checks that the kernel verifies under every kind of schedule
+func TestSchedules(t *testing.T) {
+	p, _ := params.Lookup("S")
+	for _, text := range []string{"static", "static,3", "dynamic", "dynamic,7", "guided", "guided,2"} {
+		sched, err := common.ParseSchedule(text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result, err := Run(context.Background(), Config{Params: p, Workers: 3, Schedule: sched})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Schedule != text || !result.Verified {
+			t.Errorf("%s: got schedule %q, verified %v", text, result.Schedule, result.Verified)
+		}
+	}
+}
+
+// runClass runs the kernel at class for the checks every kernel shares
+func runClass(ctx context.Context, class string) (common.Result, error) {
+	p, _ := params.Lookup(class)
+	result, err := Run(ctx, Config{Params: p})
+	return result.Result, err
+}
+
+// TestConcurrentRuns checks that runs of two classes in one process do not
+// share state
+func TestConcurrentRuns(t *testing.T) {
+	npbtest.ConcurrentRuns(t, runClass, "S", "W")
+}
+
+// TestStoppedRun checks a run stopped by its context. Run checks once before
+// starting and then before every iteration
+func TestStoppedRun(t *testing.T) {
+	npbtest.StoppedRun(t, runClass, "S", "iterations")
+}
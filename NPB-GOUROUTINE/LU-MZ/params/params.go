@@ -0,0 +1,41 @@
+package params
+
+import "strings"
+
+// Params holds the zones, grid size, time step and reference values of one
+// LU-MZ class
+type Params struct {
+	CLASS                     string
+	X_ZONES, Y_ZONES          int // zones in x and y
+	GX_SIZE, GY_SIZE, GZ_SIZE int // grid points of the whole problem
+	NITER                     int // SSOR iterations
+	DT                        float64
+	XCR_REF                   [5]float64 // RMS norms of the residual
+	XCE_REF                   [5]float64 // RMS norms of the solution error
+}
+
+// Classes lists the problem classes in increasing size. The zone layouts and
+// grid sizes are those of NPB-MZ; the reference norms were computed with this
+// implementation, and the classes from C up are not provided.
+var Classes = []string{"S", "W", "A", "B"}
+
+var table = map[string]Params{
+	"S": {CLASS: "S", X_ZONES: 4, Y_ZONES: 4, GX_SIZE: 24, GY_SIZE: 24, GZ_SIZE: 6, NITER: 50, DT: 0.5,
+		XCR_REF: [5]float64{4.6780814177790e+02, 3.1754695987162e+01, 1.1590925641647e+02, 1.0369912873947e+02, 1.0835893035017e+03},
+		XCE_REF: [5]float64{1.0450228721067e+02, 6.4921706450885e+00, 2.5924245360132e+01, 2.2493956866775e+01, 2.4441225394269e+02}},
+	"W": {CLASS: "W", X_ZONES: 4, Y_ZONES: 4, GX_SIZE: 64, GY_SIZE: 64, GZ_SIZE: 8, NITER: 300, DT: 1.5e-3,
+		XCR_REF: [5]float64{8.1035820828807e+02, 5.5773911194544e+01, 2.0571977585385e+02, 1.8599438343382e+02, 1.9435256146159e+03},
+		XCE_REF: [5]float64{1.0564996848442e+02, 6.5488657119342e+00, 2.6731492234274e+01, 2.3330522828924e+01, 2.5560610389467e+02}},
+	"A": {CLASS: "A", X_ZONES: 4, Y_ZONES: 4, GX_SIZE: 128, GY_SIZE: 128, GZ_SIZE: 16, NITER: 250, DT: 2.0,
+		XCR_REF: [5]float64{3.3136009078459e+03, 2.8072326604384e+02, 8.4055047900559e+02, 6.8385915324596e+02, 5.9565771917764e+03},
+		XCE_REF: [5]float64{2.3670183616731e+02, 1.6668824116874e+01, 6.0594338794466e+01, 5.3947462845286e+01, 5.5524023216963e+02}},
+	"B": {CLASS: "B", X_ZONES: 4, Y_ZONES: 4, GX_SIZE: 304, GY_SIZE: 208, GZ_SIZE: 17, NITER: 250, DT: 2.0,
+		XCR_REF: [5]float64{1.2471048612297e+04, 1.2551643652607e+03, 3.2949830746464e+03, 2.6968769737335e+03, 1.8982179234737e+04},
+		XCE_REF: [5]float64{3.4385216301179e+02, 2.6136095169425e+01, 8.8068668627846e+01, 7.7923984884087e+01, 7.3608376842331e+02}},
+}
+
+// Lookup returns the parameters of the given class
+func Lookup(class string) (Params, bool) {
+	p, ok := table[strings.ToUpper(class)]
+	return p, ok
+}
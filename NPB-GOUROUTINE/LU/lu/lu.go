@@ -0,0 +1,1003 @@
+// Package lu is the LU (Lower-Upper Gauss-Seidel) pseudo-application: it
+// solves the 3D compressible Navier-Stokes equations with a symmetric
+// successive over-relaxation (SSOR) scheme, splitting each iteration into a
+// lower and an upper block triangular solve.
+package lu
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"os"
+
+	"github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/LU/params"
+	"github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/common"
+)
+
+// Timers of the timer.flag section breakdown
+const (
+	T_TOTAL = iota + 1
+	T_RHS
+	T_BLTS // lower sweep, with jacld
+	T_BUTS // upper sweep, with jacu
+	T_ADD
+	T_L2NORM
+	T_LAST = T_L2NORM
+)
+
+// Config selects the problem a Run solves
+type Config struct {
+	Params   params.Params   // problem size, from params.Lookup
+	Workers  int             // goroutines; 0 means $GO_NUM_THREADS, or one per CPU
+	Schedule common.Schedule // of the loops over grid planes; none means $GO_SCHEDULE
+	Bind     common.Binding  // of the workers to cores; none means $GO_PROC_BIND
+	Out      io.Writer       // progress messages and the NPB banner, discarded when nil
+}
+
+// Result is the outcome of an LU run with the values it is verified on
+type Result struct {
+	common.Result
+	XCR [5]float64 // RMS norms of the residual
+	XCE [5]float64 // RMS norms of the solution error
+	XCI float64    // surface integral
+}
+
+// Run runs the benchmark on the grid of cfg.Params and returns its result.
+// When ctx is done between two SSOR iterations the run stops there and Run
+// returns the partial result with a *common.IncompleteError.
+func Run(ctx context.Context, cfg Config) (Result, error) {
+	if err := ctx.Err(); err != nil {
+		return Result{}, err
+	}
+	workers, err := common.ResolveWorkers(cfg.Workers)
+	if err != nil {
+		return Result{}, err
+	}
+	out := cfg.Out
+	if out == nil {
+		out = io.Discard
+	}
+	sched, err := common.ResolveSchedule(cfg.Schedule)
+	if err != nil {
+		return Result{}, err
+	}
+	bind, err := common.ResolveBinding(cfg.Bind)
+	if err != nil {
+		return Result{}, err
+	}
+	lu := NewLUBenchmark(cfg.Params, workers, out)
+	lu.schedule = sched
+	lu.bind = bind
+	return lu.run(ctx)
+}
+
+// LUBenchmark represents the LU benchmark
+type LUBenchmark struct {
+	nx, ny, nz int
+	niter      int
+	dt, omega  float64
+	class      string
+	xcrRef     [5]float64
+	xceRef     [5]float64
+	xciRef     float64
+
+	// Size of the whole grid in x and y and position of this grid in it,
+	// which differ from nx, ny and 0 only for the zones of a multi-zone
+	// problem
+	gx, gy int
+	x0, y0 int
+
+	// Conserved variables, residual and forcing term at every point
+	u, rsd, frct [][5]float64
+	// Reciprocal of density and kinetic energy at every point
+	rhoI, qs []float64
+
+	// Blocks of one plane of the lower or upper triangular system: a, b and
+	// c couple a point to its neighbours in z, y and x (x, y and z for the
+	// upper one) and d is the diagonal
+	a, b, c, d [][5][5]float64
+	// Fluxes along one grid line, for each worker
+	flux [][][5]float64
+
+	// Coefficients of the exact solution
+	ce [5][13]float64
+
+	c1, c2, c3, c4, c5 float64
+	c1c5, c34, c1345   float64
+	r43                float64
+	dxi, deta, dzeta   float64
+	// Inverse squared, inverse doubled and inverse mesh width and the
+	// dissipation coefficients in the x, y and z directions
+	t1, t2, t3 [3]float64
+	dd         [3][5]float64
+	dssp       float64
+
+	numWorkers    int
+	schedule      common.Schedule
+	bind          common.Binding
+	team          *common.Team // started by run, or given to NewZone
+	timersEnabled bool
+	timers        common.Timers
+	out           io.Writer
+}
+
+// NewLUBenchmark creates an LU benchmark for the given class parameters
+// running on numWorkers goroutines
+func NewLUBenchmark(p params.Params, numWorkers int, out io.Writer) *LUBenchmark {
+	n := p.PROBLEM_SIZE
+	lu := &LUBenchmark{
+		nx:         n,
+		ny:         n,
+		nz:         n,
+		gx:         n,
+		gy:         n,
+		niter:      p.NITER,
+		dt:         p.DT,
+		omega:      1.2,
+		class:      p.CLASS,
+		xcrRef:     p.XCR_REF,
+		xceRef:     p.XCE_REF,
+		xciRef:     p.XCI_REF,
+		numWorkers: numWorkers,
+		out:        out,
+	}
+	lu.setConstants()
+	return lu
+}
+
+// wavefront runs plane(k, j0, j1) on every interior plane k for the band of
+// rows j0..j1-1 of each worker of the team. The triangular solves on a plane
+// depend on the rows below (forward) or above (backward) on the same plane,
+// so the workers form a pipeline: in a forward sweep, going up in k, a worker
+// starts on plane k once the worker of the band below has finished it, and a
+// backward sweep, going down in k, passes planes from the top band down. The
+// bands are fixed whatever the schedule of the team.
+func (lu *LUBenchmark) wavefront(forward bool, plane func(k, j0, j1 int)) {
+	rows := lu.ny - 2
+	band := (rows + lu.numWorkers - 1) / lu.numWorkers
+	workers := (rows + band - 1) / band
+	planes := lu.nz - 2
+
+	// done[w] receives one value for every plane the worker before w in
+	// the pipeline has finished
+	done := make([]chan struct{}, workers)
+	for w := range done {
+		done[w] = make(chan struct{}, planes)
+	}
+	lu.team.Run(func(worker *common.Worker) {
+		w := worker.ID
+		if w >= workers {
+			return
+		}
+		j0 := 1 + w*band
+		j1 := min(j0+band, lu.ny-1)
+		var wait, next chan struct{}
+		if forward {
+			if w > 0 {
+				wait = done[w]
+			}
+			if w < workers-1 {
+				next = done[w+1]
+			}
+		} else {
+			if w < workers-1 {
+				wait = done[w]
+			}
+			if w > 0 {
+				next = done[w-1]
+			}
+		}
+		for n := 0; n < planes; n++ {
+			k := 1 + n
+			if !forward {
+				k = planes - n
+			}
+			if wait != nil {
+				<-wait
+			}
+			plane(k, j0, j1)
+			if next != nil {
+				next <- struct{}{}
+			}
+		}
+	})
+}
+
+// idx returns the position of grid point (i, j, k) in the flat arrays
+func (lu *LUBenchmark) idx(k, j, i int) int {
+	return (k*lu.ny+j)*lu.nx + i
+}
+
+// setConstants computes the coefficients of the exact solution and the
+// constants of the discretization
+func (lu *LUBenchmark) setConstants() {
+	lu.ce = [5][13]float64{
+		{2.0, 0.0, 0.0, 4.0, 5.0, 3.0, 0.5, 0.02, 0.01, 0.03, 0.5, 0.4, 0.3},
+		{1.0, 0.0, 0.0, 0.0, 1.0, 2.0, 3.0, 0.01, 0.03, 0.02, 0.4, 0.3, 0.5},
+		{2.0, 2.0, 0.0, 0.0, 0.0, 2.0, 3.0, 0.04, 0.03, 0.05, 0.3, 0.5, 0.4},
+		{2.0, 2.0, 0.0, 0.0, 0.0, 2.0, 3.0, 0.03, 0.05, 0.04, 0.2, 0.1, 0.3},
+		{5.0, 4.0, 3.0, 2.0, 0.1, 0.4, 0.3, 0.05, 0.04, 0.03, 0.1, 0.3, 0.2},
+	}
+
+	lu.c1 = 1.4
+	lu.c2 = 0.4
+	lu.c3 = 0.1
+	lu.c4 = 1.0
+	lu.c5 = 1.4
+	lu.c1c5 = lu.c1 * lu.c5
+	lu.c34 = lu.c3 * lu.c4
+	lu.c1345 = lu.c1c5 * lu.c34
+	lu.r43 = 4.0 / 3.0
+
+	lu.dxi = 1.0 / float64(lu.gx-1)
+	lu.deta = 1.0 / float64(lu.gy-1)
+	lu.dzeta = 1.0 / float64(lu.nz-1)
+
+	for dir, h := range []float64{lu.dxi, lu.deta, lu.dzeta} {
+		lu.t1[dir] = 1.0 / (h * h)
+		lu.t2[dir] = 1.0 / (2.0 * h)
+		lu.t3[dir] = 1.0 / h
+	}
+
+	lu.dd[0] = [5]float64{0.75, 0.75, 0.75, 0.75, 0.75}
+	lu.dd[1] = [5]float64{0.75, 0.75, 0.75, 0.75, 0.75}
+	lu.dd[2] = [5]float64{1.0, 1.0, 1.0, 1.0, 1.0}
+
+	lu.dssp = math.Max(lu.dd[0][0], math.Max(lu.dd[1][0], lu.dd[2][0])) / 4.0
+}
+
+// exactSolution returns the exact solution at grid point (i, j, k)
+func (lu *LUBenchmark) exactSolution(i, j, k int) [5]float64 {
+	xi := float64(lu.x0+i) / float64(lu.gx-1)
+	eta := float64(lu.y0+j) / float64(lu.gy-1)
+	zeta := float64(k) / float64(lu.nz-1)
+	var dtemp [5]float64
+	for m := 0; m < 5; m++ {
+		ce := &lu.ce[m]
+		dtemp[m] = ce[0] +
+			xi*(ce[1]+xi*(ce[4]+xi*(ce[7]+xi*ce[10]))) +
+			eta*(ce[2]+eta*(ce[5]+eta*(ce[8]+eta*ce[11]))) +
+			zeta*(ce[3]+zeta*(ce[6]+zeta*(ce[9]+zeta*ce[12])))
+	}
+	return dtemp
+}
+
+// initialize sets u to the exact solution on the boundaries and to a
+// transfinite interpolation of the boundary values in the interior
+func (lu *LUBenchmark) initialize() {
+	nx, ny, nz := lu.nx, lu.ny, lu.nz
+
+	// Bottom and top faces
+	for j := 0; j < ny; j++ {
+		for i := 0; i < nx; i++ {
+			lu.u[lu.idx(0, j, i)] = lu.exactSolution(i, j, 0)
+			lu.u[lu.idx(nz-1, j, i)] = lu.exactSolution(i, j, nz-1)
+		}
+	}
+	// South and north faces
+	for k := 0; k < nz; k++ {
+		for i := 0; i < nx; i++ {
+			lu.u[lu.idx(k, 0, i)] = lu.exactSolution(i, 0, k)
+			lu.u[lu.idx(k, ny-1, i)] = lu.exactSolution(i, ny-1, k)
+		}
+	}
+	// West and east faces
+	for k := 0; k < nz; k++ {
+		for j := 0; j < ny; j++ {
+			lu.u[lu.idx(k, j, 0)] = lu.exactSolution(0, j, k)
+			lu.u[lu.idx(k, j, nx-1)] = lu.exactSolution(nx-1, j, k)
+		}
+	}
+
+	for k := 1; k < nz-1; k++ {
+		zeta := float64(k) / float64(nz-1)
+		for j := 1; j < ny-1; j++ {
+			eta := float64(j) / float64(ny-1)
+			for i := 1; i < nx-1; i++ {
+				xi := float64(i) / float64(nx-1)
+				west, east := lu.exactSolution(0, j, k), lu.exactSolution(nx-1, j, k)
+				south, north := lu.exactSolution(i, 0, k), lu.exactSolution(i, ny-1, k)
+				bottom, top := lu.exactSolution(i, j, 0), lu.exactSolution(i, j, nz-1)
+				u := &lu.u[lu.idx(k, j, i)]
+				for m := 0; m < 5; m++ {
+					pxi := (1.0-xi)*west[m] + xi*east[m]
+					peta := (1.0-eta)*south[m] + eta*north[m]
+					pzeta := (1.0-zeta)*bottom[m] + zeta*top[m]
+					u[m] = pxi + peta + pzeta - pxi*peta - peta*pzeta - pzeta*pxi + pxi*peta*pzeta
+				}
+			}
+		}
+	}
+}
+
+// dissipation subtracts the fourth-order dissipation of the n points of a
+// grid line of v, starting at v0 with stride vs, from the interior points of
+// the same line of dst, starting at d0 with stride ds
+func dissipation(dst [][5]float64, d0, ds int, v [][5]float64, v0, vs, n int, dssp float64) {
+	for m := 0; m < 5; m++ {
+		d := d0 + ds
+		i := v0 + vs
+		dst[d][m] -= dssp * (5.0*v[i][m] - 4.0*v[i+vs][m] + v[i+2*vs][m])
+		d += ds
+		i += vs
+		dst[d][m] -= dssp * (-4.0*v[i-vs][m] + 6.0*v[i][m] - 4.0*v[i+vs][m] + v[i+2*vs][m])
+	}
+	for l := 3; l < n-3; l++ {
+		d := d0 + l*ds
+		i := v0 + l*vs
+		for m := 0; m < 5; m++ {
+			dst[d][m] -= dssp * (v[i-2*vs][m] - 4.0*v[i-vs][m] + 6.0*v[i][m] - 4.0*v[i+vs][m] + v[i+2*vs][m])
+		}
+	}
+	for m := 0; m < 5; m++ {
+		d := d0 + (n-3)*ds
+		i := v0 + (n-3)*vs
+		dst[d][m] -= dssp * (v[i-2*vs][m] - 4.0*v[i-vs][m] + 6.0*v[i][m] - 4.0*v[i+vs][m])
+		d += ds
+		i += vs
+		dst[d][m] -= dssp * (v[i-2*vs][m] - 4.0*v[i-vs][m] + 5.0*v[i][m])
+	}
+}
+
+// setDerived computes rhoI and qs from the variables in v
+func (lu *LUBenchmark) setDerived(v [][5]float64) {
+	lu.team.For(0, len(v), func(p0, p1, _ int) {
+		for p := p0; p < p1; p++ {
+			tmp := 1.0 / v[p][0]
+			lu.rhoI[p] = tmp
+			lu.qs[p] = 0.5 * (v[p][1]*v[p][1] + v[p][2]*v[p][2] + v[p][3]*v[p][3]) * tmp
+		}
+	})
+}
+
+// lineFluxes adds the flux differences, viscous terms and dissipation of the
+// n points of a grid line of v, starting at p0 with stride s in direction
+// dir (0, 1 or 2 for x, y or z), to the interior points of the line of dst.
+// rhoI and qs must hold the derived quantities of v.
+func (lu *LUBenchmark) lineFluxes(dst, v, flux [][5]float64, p0, s, n, dir int) {
+	rhoI, qs := lu.rhoI, lu.qs
+	c1, c2 := lu.c1, lu.c2
+	t1, t2, t3 := lu.t1[dir], lu.t2[dir], lu.t3[dir]
+	dd := &lu.dd[dir]
+	vel := dir + 1
+
+	// Inviscid fluxes
+	for l := 0; l < n; l++ {
+		p := p0 + l*s
+		w := &v[p]
+		q := qs[p]
+		un := w[vel] * rhoI[p]
+		flux[l] = [5]float64{w[vel], w[1] * un, w[2] * un, w[3] * un, (c1*w[4] - c2*q) * un}
+		flux[l][vel] += c2 * (w[4] - q)
+	}
+	for l := 1; l < n-1; l++ {
+		r := &dst[p0+l*s]
+		for m := 0; m < 5; m++ {
+			r[m] -= t2 * (flux[l+1][m] - flux[l-1][m])
+		}
+	}
+
+	// Viscous fluxes
+	var scale [5]float64
+	for m := 1; m < 4; m++ {
+		scale[m] = t3
+	}
+	scale[vel] = lu.r43 * t3
+	for l := 1; l < n; l++ {
+		p, pm := p0+l*s, p0+(l-1)*s
+		var ui, uim1 [5]float64
+		for m := 1; m < 5; m++ {
+			ui[m] = rhoI[p] * v[p][m]
+			uim1[m] = rhoI[pm] * v[pm][m]
+		}
+		f := &flux[l]
+		for m := 1; m < 4; m++ {
+			f[m] = scale[m] * (ui[m] - uim1[m])
+		}
+		f[4] = 0.50*(1.0-lu.c1c5)*t3*((ui[1]*ui[1]+ui[2]*ui[2]+ui[3]*ui[3])-
+			(uim1[1]*uim1[1]+uim1[2]*uim1[2]+uim1[3]*uim1[3])) +
+			(1.0/6.0)*t3*(ui[vel]*ui[vel]-uim1[vel]*uim1[vel]) +
+			lu.c1c5*t3*(ui[4]-uim1[4])
+	}
+	for l := 1; l < n-1; l++ {
+		p := p0 + l*s
+		r := &dst[p]
+		r[0] += dd[0] * t1 * (v[p-s][0] - 2.0*v[p][0] + v[p+s][0])
+		for m := 1; m < 5; m++ {
+			r[m] += t3*lu.c34*(flux[l+1][m]-flux[l][m]) +
+				dd[m]*t1*(v[p-s][m]-2.0*v[p][m]+v[p+s][m])
+		}
+	}
+
+	dissipation(dst, p0, s, v, p0, s, n, lu.dssp)
+}
+
+// fluxes adds the flux differences of v in all three directions to the
+// interior of dst
+func (lu *LUBenchmark) fluxes(dst, v [][5]float64) {
+	nx, ny, nz := lu.nx, lu.ny, lu.nz
+	lu.team.For(1, nz-1, func(k0, k1, id int) {
+		for k := k0; k < k1; k++ {
+			for j := 1; j < ny-1; j++ {
+				lu.lineFluxes(dst, v, lu.flux[id], lu.idx(k, j, 0), 1, nx, 0)
+			}
+		}
+	})
+	lu.team.For(1, nz-1, func(k0, k1, id int) {
+		for k := k0; k < k1; k++ {
+			for i := 1; i < nx-1; i++ {
+				lu.lineFluxes(dst, v, lu.flux[id], lu.idx(k, 0, i), nx, ny, 1)
+			}
+		}
+	})
+	lu.team.For(1, ny-1, func(j0, j1, id int) {
+		for j := j0; j < j1; j++ {
+			for i := 1; i < nx-1; i++ {
+				lu.lineFluxes(dst, v, lu.flux[id], lu.idx(0, j, i), nx*ny, nz, 2)
+			}
+		}
+	})
+}
+
+// exactRHS computes the forcing term that makes the exact solution a
+// solution of the discretized equations
+func (lu *LUBenchmark) exactRHS() {
+	lu.team.For(0, lu.nz, func(k0, k1, _ int) {
+		for k := k0; k < k1; k++ {
+			for j := 0; j < lu.ny; j++ {
+				for i := 0; i < lu.nx; i++ {
+					p := lu.idx(k, j, i)
+					lu.rsd[p] = lu.exactSolution(i, j, k)
+					lu.frct[p] = [5]float64{}
+				}
+			}
+		}
+	})
+	lu.setDerived(lu.rsd)
+	lu.fluxes(lu.frct, lu.rsd)
+}
+
+// computeRHS computes the steady-state residual of u
+func (lu *LUBenchmark) computeRHS() {
+	if lu.timersEnabled {
+		lu.timers.Start(T_RHS)
+	}
+	lu.team.For(0, len(lu.rsd), func(p0, p1, _ int) {
+		for p := p0; p < p1; p++ {
+			for m := 0; m < 5; m++ {
+				lu.rsd[p][m] = -lu.frct[p][m]
+			}
+		}
+	})
+	lu.setDerived(lu.u)
+	lu.fluxes(lu.rsd, lu.u)
+	if lu.timersEnabled {
+		lu.timers.Stop(T_RHS)
+	}
+}
+
+// convective returns the Jacobian of the inviscid flux in direction dir at
+// the point p
+func (lu *LUBenchmark) convective(p, dir int) (a [5][5]float64) {
+	c1, c2 := lu.c1, lu.c2
+	u := &lu.u[p]
+	tmp1 := lu.rhoI[p]
+	tmp2 := tmp1 * tmp1
+	q := lu.qs[p]
+	n := dir + 1
+	un := u[n] * tmp1
+
+	a[0][n] = 1.0
+	for m := 1; m < 4; m++ {
+		if m == n {
+			a[m][0] = -un*un + c2*q*tmp1
+			for l := 1; l < 4; l++ {
+				a[m][l] = -c2 * (u[l] * tmp1)
+			}
+			a[m][m] = (2.0 - c2) * un
+			a[m][4] = c2
+		} else {
+			a[m][0] = -(u[m] * u[n]) * tmp2
+			a[m][m] = un
+			a[m][n] = u[m] * tmp1
+		}
+	}
+	a[4][0] = (c2*2.0*q - c1*u[4]) * (u[n] * tmp2)
+	for l := 1; l < 4; l++ {
+		a[4][l] = -c2 * (u[l] * u[n]) * tmp2
+	}
+	a[4][n] = c1*(u[4]*tmp1) - c2*(u[n]*u[n]*tmp2+q*tmp1)
+	a[4][4] = c1 * un
+	return a
+}
+
+// viscous returns the Jacobian of the viscous and dissipation terms in
+// direction dir at the point p
+func (lu *LUBenchmark) viscous(p, dir int) (v [5][5]float64) {
+	u := &lu.u[p]
+	tmp1 := lu.rhoI[p]
+	tmp2 := tmp1 * tmp1
+	tmp3 := tmp1 * tmp2
+	dd := &lu.dd[dir]
+	c1345 := lu.c1345
+
+	v[0][0] = dd[0]
+	v[4][0] = -c1345 * tmp2 * u[4]
+	for m := 1; m < 4; m++ {
+		cm := lu.c34
+		if m == dir+1 {
+			cm = lu.r43 * lu.c34
+		}
+		v[m][0] = -cm * tmp2 * u[m]
+		v[m][m] = cm*tmp1 + dd[m]
+		v[4][0] -= (cm - c1345) * tmp3 * (u[m] * u[m])
+		v[4][m] = (cm - c1345) * tmp2 * u[m]
+	}
+	v[4][4] = c1345*tmp1 + dd[4]
+	return v
+}
+
+// offDiagonal returns the block coupling a point to its neighbour p in
+// direction dir, with sign -1 for the lower and +1 for the upper neighbour
+func (lu *LUBenchmark) offDiagonal(p, dir int, sign float64) (blk [5][5]float64) {
+	a := lu.convective(p, dir)
+	v := lu.viscous(p, dir)
+	dt2 := sign * lu.dt * lu.t2[dir]
+	dt1 := lu.dt * lu.t1[dir]
+	for m := 0; m < 5; m++ {
+		for n := 0; n < 5; n++ {
+			blk[m][n] = dt2*a[m][n] - dt1*v[m][n]
+		}
+	}
+	return blk
+}
+
+// diagonal returns the diagonal block of the point p
+func (lu *LUBenchmark) diagonal(p int) (blk [5][5]float64) {
+	for m := 0; m < 5; m++ {
+		blk[m][m] = 1.0
+	}
+	for dir := 0; dir < 3; dir++ {
+		v := lu.viscous(p, dir)
+		dt1 := lu.dt * 2.0 * lu.t1[dir]
+		for m := 0; m < 5; m++ {
+			for n := 0; n < 5; n++ {
+				blk[m][n] += dt1 * v[m][n]
+			}
+		}
+	}
+	return blk
+}
+
+// jacld computes the blocks of the lower triangular part of the Jacobian on
+// rows j0..j1-1 of plane k
+func (lu *LUBenchmark) jacld(k, j0, j1 int) {
+	nx, sz := lu.nx, lu.nx*lu.ny
+	for j := j0; j < j1; j++ {
+		for i := 1; i < nx-1; i++ {
+			p, q := lu.idx(k, j, i), j*nx+i
+			lu.d[q] = lu.diagonal(p)
+			lu.a[q] = lu.offDiagonal(p-sz, 2, -1.0)
+			lu.b[q] = lu.offDiagonal(p-nx, 1, -1.0)
+			lu.c[q] = lu.offDiagonal(p-1, 0, -1.0)
+		}
+	}
+}
+
+// jacu computes the blocks of the upper triangular part of the Jacobian on
+// rows j0..j1-1 of plane k
+func (lu *LUBenchmark) jacu(k, j0, j1 int) {
+	nx, sz := lu.nx, lu.nx*lu.ny
+	for j := j0; j < j1; j++ {
+		for i := 1; i < nx-1; i++ {
+			p, q := lu.idx(k, j, i), j*nx+i
+			lu.d[q] = lu.diagonal(p)
+			lu.a[q] = lu.offDiagonal(p+1, 0, 1.0)
+			lu.b[q] = lu.offDiagonal(p+nx, 1, 1.0)
+			lu.c[q] = lu.offDiagonal(p+sz, 2, 1.0)
+		}
+	}
+}
+
+// mulAdd adds the product of blk and x to y
+func mulAdd(y *[5]float64, blk *[5][5]float64, x *[5]float64) {
+	for m := 0; m < 5; m++ {
+		y[m] += blk[m][0]*x[0] + blk[m][1]*x[1] + blk[m][2]*x[2] + blk[m][3]*x[3] + blk[m][4]*x[4]
+	}
+}
+
+// solveBlock overwrites v with the solution of blk x = v, by Gaussian
+// elimination without pivoting
+func solveBlock(blk *[5][5]float64, v *[5]float64) {
+	tmat := *blk
+	for c := 0; c < 4; c++ {
+		tmp1 := 1.0 / tmat[c][c]
+		for r := c + 1; r < 5; r++ {
+			tmp := tmp1 * tmat[r][c]
+			for n := c + 1; n < 5; n++ {
+				tmat[r][n] -= tmp * tmat[c][n]
+			}
+			v[r] -= v[c] * tmp
+		}
+	}
+	for r := 4; r >= 0; r-- {
+		for n := r + 1; n < 5; n++ {
+			v[r] -= tmat[r][n] * v[n]
+		}
+		v[r] /= tmat[r][r]
+	}
+}
+
+// blts performs the forward substitution of the lower triangular system on
+// rows j0..j1-1 of plane k, in place on rsd
+func (lu *LUBenchmark) blts(k, j0, j1 int) {
+	nx, sz := lu.nx, lu.nx*lu.ny
+	omega := lu.omega
+	v := lu.rsd
+	for j := j0; j < j1; j++ {
+		for i := 1; i < nx-1; i++ {
+			p, q := lu.idx(k, j, i), j*nx+i
+			var sum [5]float64
+			mulAdd(&sum, &lu.a[q], &v[p-sz])
+			for m := 0; m < 5; m++ {
+				v[p][m] -= omega * sum[m]
+			}
+			sum = [5]float64{}
+			mulAdd(&sum, &lu.b[q], &v[p-nx])
+			mulAdd(&sum, &lu.c[q], &v[p-1])
+			for m := 0; m < 5; m++ {
+				v[p][m] -= omega * sum[m]
+			}
+			solveBlock(&lu.d[q], &v[p])
+		}
+	}
+}
+
+// buts performs the backward substitution of the upper triangular system
+// on rows j0..j1-1 of plane k, in place on rsd
+func (lu *LUBenchmark) buts(k, j0, j1 int) {
+	nx, sz := lu.nx, lu.nx*lu.ny
+	omega := lu.omega
+	v := lu.rsd
+	for j := j1 - 1; j >= j0; j-- {
+		for i := nx - 2; i >= 1; i-- {
+			p, q := lu.idx(k, j, i), j*nx+i
+			var tv [5]float64
+			mulAdd(&tv, &lu.c[q], &v[p+sz])
+			mulAdd(&tv, &lu.b[q], &v[p+nx])
+			mulAdd(&tv, &lu.a[q], &v[p+1])
+			for m := 0; m < 5; m++ {
+				tv[m] *= omega
+			}
+			solveBlock(&lu.d[q], &tv)
+			for m := 0; m < 5; m++ {
+				v[p][m] -= tv[m]
+			}
+		}
+	}
+}
+
+// l2norm returns the RMS norms of the interior of v
+func (lu *LUBenchmark) l2norm(v [][5]float64) [5]float64 {
+	if lu.timersEnabled {
+		lu.timers.Start(T_L2NORM)
+	}
+	var sum [5]float64
+	for k := 1; k < lu.nz-1; k++ {
+		for j := 1; j < lu.ny-1; j++ {
+			for i := 1; i < lu.nx-1; i++ {
+				w := &v[lu.idx(k, j, i)]
+				for m := 0; m < 5; m++ {
+					sum[m] += w[m] * w[m]
+				}
+			}
+		}
+	}
+	for m := 0; m < 5; m++ {
+		sum[m] = math.Sqrt(sum[m] / float64((lu.nx-2)*(lu.ny-2)*(lu.nz-2)))
+	}
+	if lu.timersEnabled {
+		lu.timers.Stop(T_L2NORM)
+	}
+	return sum
+}
+
+// ssorStep performs one SSOR iteration, leaving the new residual in rsd
+func (lu *LUBenchmark) ssorStep() {
+	lu.sweep()
+	lu.computeRHS()
+}
+
+// sweep updates u with the lower and upper triangular solves of the
+// residual in rsd
+func (lu *LUBenchmark) sweep() {
+	nx, ny, nz := lu.nx, lu.ny, lu.nz
+	lu.team.For(1, nz-1, func(k0, k1, _ int) {
+		for k := k0; k < k1; k++ {
+			for j := 1; j < ny-1; j++ {
+				for i := 1; i < nx-1; i++ {
+					r := &lu.rsd[lu.idx(k, j, i)]
+					for m := 0; m < 5; m++ {
+						r[m] *= lu.dt
+					}
+				}
+			}
+		}
+	})
+
+	if lu.timersEnabled {
+		lu.timers.Start(T_BLTS)
+	}
+	lu.wavefront(true, func(k, j0, j1 int) {
+		lu.jacld(k, j0, j1)
+		lu.blts(k, j0, j1)
+	})
+	if lu.timersEnabled {
+		lu.timers.Stop(T_BLTS)
+		lu.timers.Start(T_BUTS)
+	}
+	lu.wavefront(false, func(k, j0, j1 int) {
+		lu.jacu(k, j0, j1)
+		lu.buts(k, j0, j1)
+	})
+	if lu.timersEnabled {
+		lu.timers.Stop(T_BUTS)
+		lu.timers.Start(T_ADD)
+	}
+
+	tmp := 1.0 / (lu.omega * (2.0 - lu.omega))
+	lu.team.For(1, nz-1, func(k0, k1, _ int) {
+		for k := k0; k < k1; k++ {
+			for j := 1; j < ny-1; j++ {
+				for i := 1; i < nx-1; i++ {
+					p := lu.idx(k, j, i)
+					for m := 0; m < 5; m++ {
+						lu.u[p][m] += tmp * lu.rsd[p][m]
+					}
+				}
+			}
+		}
+	})
+	if lu.timersEnabled {
+		lu.timers.Stop(T_ADD)
+	}
+}
+
+// ssor performs up to niter SSOR iterations, stopping early when ctx is
+// done, and returns the number it completed
+func (lu *LUBenchmark) ssor(ctx context.Context, niter int, verbose bool) int {
+	lu.computeRHS()
+	lu.l2norm(lu.rsd)
+
+	for i := 1; i <= T_LAST; i++ {
+		lu.timers.Clear(i)
+	}
+	lu.timers.Start(T_TOTAL)
+	defer lu.timers.Stop(T_TOTAL)
+
+	for step := 1; step <= niter; step++ {
+		if ctx.Err() != nil {
+			return step - 1
+		}
+		if verbose && (step%20 == 0 || step == niter || step == 1) {
+			fmt.Fprintf(lu.out, " Time step %4d\n", step)
+		}
+		lu.ssorStep()
+	}
+	return niter
+}
+
+// errorNorm returns the RMS norms of the difference between u and the exact
+// solution in the interior
+func (lu *LUBenchmark) errorNorm() [5]float64 {
+	var sum [5]float64
+	for k := 1; k < lu.nz-1; k++ {
+		for j := 1; j < lu.ny-1; j++ {
+			for i := 1; i < lu.nx-1; i++ {
+				uExact := lu.exactSolution(i, j, k)
+				u := &lu.u[lu.idx(k, j, i)]
+				for m := 0; m < 5; m++ {
+					tmp := uExact[m] - u[m]
+					sum[m] += tmp * tmp
+				}
+			}
+		}
+	}
+	for m := 0; m < 5; m++ {
+		sum[m] = math.Sqrt(sum[m] / float64((lu.nx-2)*(lu.ny-2)*(lu.nz-2)))
+	}
+	return sum
+}
+
+// surfaceIntegral returns the integral of the pressure over the surface of
+// the box the reference implementation integrates over
+func (lu *LUBenchmark) surfaceIntegral() float64 {
+	nx, sz := lu.nx, lu.nx*lu.ny
+	ibeg, ifin := 1, lu.nx-2
+	jbeg, jfin := 1, lu.ny-3
+	kbeg, kfin := 2, lu.nz-2
+
+	phi := func(p int) float64 {
+		u := &lu.u[p]
+		return lu.c2 * (u[4] - 0.50*(u[1]*u[1]+u[2]*u[2]+u[3]*u[3])/u[0])
+	}
+	// face sums phi over the corners of the n1 x n2 cells of a face whose
+	// first corner is p0, with strides s1 and s2 along its two sides
+	face := func(p0, s1, n1, s2, n2 int) float64 {
+		sum := 0.0
+		for b := 0; b < n2; b++ {
+			for a := 0; a < n1; a++ {
+				p := p0 + a*s1 + b*s2
+				sum += phi(p) + phi(p+s1) + phi(p+s2) + phi(p+s1+s2)
+			}
+		}
+		return sum
+	}
+
+	frc1 := lu.dxi * lu.deta *
+		(face(lu.idx(kbeg, jbeg, ibeg), 1, ifin-ibeg, nx, jfin-jbeg) +
+			face(lu.idx(kfin, jbeg, ibeg), 1, ifin-ibeg, nx, jfin-jbeg))
+	frc2 := lu.dxi * lu.dzeta *
+		(face(lu.idx(kbeg, jbeg, ibeg), 1, ifin-ibeg, sz, kfin-kbeg) +
+			face(lu.idx(kbeg, jfin, ibeg), 1, ifin-ibeg, sz, kfin-kbeg))
+	frc3 := lu.deta * lu.dzeta *
+		(face(lu.idx(kbeg, jbeg, ibeg), nx, jfin-jbeg, sz, kfin-kbeg) +
+			face(lu.idx(kbeg, jbeg, ifin), nx, jfin-jbeg, sz, kfin-kbeg))
+	return 0.25 * (frc1 + frc2 + frc3)
+}
+
+// verify computes the residual and error norms and the surface integral of
+// the solution and compares them with the reference values of the class
+func (lu *LUBenchmark) verify() (xcr, xce [5]float64, xci float64, verified bool) {
+	const epsilon = 1.0e-8
+
+	xcr = lu.l2norm(lu.rsd)
+	xce = lu.errorNorm()
+	xci = lu.surfaceIntegral()
+
+	fmt.Fprintf(lu.out, " Verification being performed for class %s\n", lu.class)
+	fmt.Fprintf(lu.out, " Accuracy setting for epsilon = %20.13E\n", epsilon)
+
+	verified = true
+	fmt.Fprintf(lu.out, " Comparison of RMS-norms of residual\n")
+	for m := 0; m < 5; m++ {
+		dif := math.Abs((xcr[m] - lu.xcrRef[m]) / lu.xcrRef[m])
+		if dif <= epsilon {
+			fmt.Fprintf(lu.out, "          %2d  %20.13E%20.13E%20.13E\n", m+1, xcr[m], lu.xcrRef[m], dif)
+		} else {
+			verified = false
+			fmt.Fprintf(lu.out, " FAILURE: %2d  %20.13E%20.13E%20.13E\n", m+1, xcr[m], lu.xcrRef[m], dif)
+		}
+	}
+	fmt.Fprintf(lu.out, " Comparison of RMS-norms of solution error\n")
+	for m := 0; m < 5; m++ {
+		dif := math.Abs((xce[m] - lu.xceRef[m]) / lu.xceRef[m])
+		if dif <= epsilon {
+			fmt.Fprintf(lu.out, "          %2d  %20.13E%20.13E%20.13E\n", m+1, xce[m], lu.xceRef[m], dif)
+		} else {
+			verified = false
+			fmt.Fprintf(lu.out, " FAILURE: %2d  %20.13E%20.13E%20.13E\n", m+1, xce[m], lu.xceRef[m], dif)
+		}
+	}
+	fmt.Fprintf(lu.out, " Comparison of surface integral\n")
+	dif := math.Abs((xci - lu.xciRef) / lu.xciRef)
+	if dif <= epsilon {
+		fmt.Fprintf(lu.out, "              %20.13E%20.13E%20.13E\n", xci, lu.xciRef, dif)
+	} else {
+		verified = false
+		fmt.Fprintf(lu.out, " FAILURE:     %20.13E%20.13E%20.13E\n", xci, lu.xciRef, dif)
+	}
+
+	if verified {
+		fmt.Fprintf(lu.out, " Verification Successful\n")
+	} else {
+		fmt.Fprintf(lu.out, " Verification failed\n")
+	}
+	return xcr, xce, xci, verified
+}
+
+// allocate creates the arrays of the grid and the scratch space of the
+// workers
+func (lu *LUBenchmark) allocate() {
+	points := lu.nx * lu.ny * lu.nz
+	lu.u = make([][5]float64, points)
+	lu.rsd = make([][5]float64, points)
+	lu.frct = make([][5]float64, points)
+	lu.rhoI = make([]float64, points)
+	lu.qs = make([]float64, points)
+	plane := lu.nx * lu.ny
+	lu.a = make([][5][5]float64, plane)
+	lu.b = make([][5][5]float64, plane)
+	lu.c = make([][5][5]float64, plane)
+	lu.d = make([][5][5]float64, plane)
+	lu.flux = make([][][5]float64, lu.numWorkers)
+	for w := range lu.flux {
+		lu.flux[w] = make([][5]float64, max(lu.nx, lu.ny, lu.nz))
+	}
+}
+
+// run performs the LU benchmark and returns its result, with an error when
+// ctx stopped its iterations early
+func (lu *LUBenchmark) run(ctx context.Context) (Result, error) {
+	if _, err := os.Stat("timer.flag"); err == nil {
+		lu.timersEnabled = true
+	}
+
+	team, err := common.StartTeam(lu.numWorkers, lu.schedule, lu.bind)
+	if err != nil {
+		return Result{}, err
+	}
+	lu.team = team
+	defer lu.team.Close()
+
+	lu.allocate()
+
+	fmt.Fprintf(lu.out, "\n\n NAS Parallel Benchmarks 4.1 Go Goroutine version - LU Benchmark\n\n")
+	fmt.Fprintf(lu.out, " Size: %4dx%4dx%4d\n", lu.nx, lu.ny, lu.nz)
+	fmt.Fprintf(lu.out, " Iterations: %4d\n", lu.niter)
+	fmt.Fprintf(lu.out, " Number of available workers: %d\n\n", lu.numWorkers)
+
+	lu.initialize()
+	lu.exactRHS()
+
+	// Do one iteration to touch all code, and reinitialize
+	lu.ssor(context.Background(), 1, false)
+	lu.initialize()
+
+	iterations := lu.ssor(ctx, lu.niter, true)
+	tmax := lu.timers.Read(T_TOTAL)
+
+	var xcr, xce [5]float64
+	var xci float64
+	verified := false
+	incomplete := iterations < lu.niter
+	if incomplete {
+		fmt.Fprintf(lu.out, "\n Benchmark stopped after %d of %d iterations\n", iterations, lu.niter)
+		fmt.Fprintf(lu.out, " NO VERIFICATION PERFORMED\n")
+	} else {
+		xcr, xce, xci, verified = lu.verify()
+	}
+
+	mflops := 0.0
+	if tmax != 0.0 {
+		navg := float64(lu.nx+lu.ny+lu.nz) / 3.0
+		mflops = 1.0e-6 * float64(iterations) *
+			(1984.77*float64(lu.nx*lu.ny*lu.nz) - 10923.3*navg*navg + 27770.9*navg - 144010.0) / tmax
+	}
+
+	result := common.Result{
+		Kernel:      "LU",
+		Class:       lu.class,
+		Size:        [3]int{lu.nx, lu.ny, lu.nz},
+		Iterations:  iterations,
+		Time:        tmax,
+		Mops:        mflops,
+		OpType:      "floating point",
+		Verified:    verified,
+		Incomplete:  incomplete,
+		Workers:     lu.numWorkers,
+		NPBVersion:  "4.1",
+		CompileTime: "Unknown",
+		Compiler:    "Go",
+	}
+	lu.team.Describe(&result)
+	if lu.timersEnabled {
+		names := []string{"", "total", "rhs", "blts", "buts", "add", "l2norm"}
+		for i := 1; i <= T_LAST; i++ {
+			result.Timers = append(result.Timers, common.Timer{Name: names[i], Seconds: lu.timers.Read(i)})
+		}
+	}
+	common.Finish(&result, lu.out)
+
+	if lu.timersEnabled {
+		fmt.Fprintln(lu.out, "  SECTION   Time (secs)")
+		for _, t := range result.Timers {
+			fmt.Fprintf(lu.out, "  %-8s:%9.3f  (%6.2f%%)\n", t.Name, t.Seconds, t.Seconds*100.0/tmax)
+		}
+	}
+	if incomplete {
+		return Result{result, xcr, xce, xci}, &common.IncompleteError{Completed: iterations, Planned: lu.niter, Err: ctx.Err()}
+	}
+	return Result{result, xcr, xce, xci}, nil
+}
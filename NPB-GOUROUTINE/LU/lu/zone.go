@@ -0,0 +1,86 @@
+package lu
+
+import (
+	"io"
+
+	"github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/common"
+)
+
+// Zone is one zone of a multi-zone LU problem (LU-MZ): a block of a larger
+// grid that is solved like the single-zone grid, except that its x and y
+// faces hold copies of the points next to them in the neighbouring zones
+type Zone struct {
+	lu *LUBenchmark
+}
+
+// NewZone creates the zone of nx x ny x nz points whose first point is at
+// (x0, y0, 0) in a grid of gx x gy x nz points, advanced with time step dt
+// and with its loops run on the workers of team
+func NewZone(nx, ny, nz, x0, y0, gx, gy int, dt float64, team *common.Team) *Zone {
+	lu := &LUBenchmark{
+		nx:         nx,
+		ny:         ny,
+		nz:         nz,
+		gx:         gx,
+		gy:         gy,
+		x0:         x0,
+		y0:         y0,
+		dt:         dt,
+		omega:      1.2,
+		numWorkers: team.Workers(),
+		team:       team,
+		out:        io.Discard,
+	}
+	lu.setConstants()
+	lu.allocate()
+	lu.initialize()
+	lu.exactRHS()
+	return &Zone{lu}
+}
+
+// Initialize sets u back to its initial values
+func (z *Zone) Initialize() {
+	z.lu.initialize()
+}
+
+// Step performs one SSOR iteration on the zone. The residual is computed
+// first because the faces of the zone change between iterations.
+func (z *Zone) Step() {
+	z.lu.computeRHS()
+	z.lu.sweep()
+}
+
+// U returns the conserved variables at every point of the zone, point
+// (i, j, k) at (k*ny+j)*nx+i
+func (z *Zone) U() [][5]float64 {
+	return z.lu.u
+}
+
+// Norms returns the sums over the interior points of the squared residual
+// and of the squared difference from the exact solution
+func (z *Zone) Norms() (rhs, err [5]float64) {
+	lu := z.lu
+	lu.computeRHS()
+	for k := 1; k < lu.nz-1; k++ {
+		for j := 1; j < lu.ny-1; j++ {
+			for i := 1; i < lu.nx-1; i++ {
+				uExact := lu.exactSolution(i, j, k)
+				p := lu.idx(k, j, i)
+				for m := 0; m < 5; m++ {
+					r := lu.rsd[p][m]
+					rhs[m] += r * r
+					e := uExact[m] - lu.u[p][m]
+					err[m] += e * e
+				}
+			}
+		}
+	}
+	return rhs, err
+}
+
+// Ops returns the floating point operations of one SSOR iteration
+func (z *Zone) Ops() float64 {
+	n3 := float64(z.lu.nx * z.lu.ny * z.lu.nz)
+	navg := float64(z.lu.nx+z.lu.ny+z.lu.nz) / 3.0
+	return 1984.77*n3 - 10923.3*navg*navg + 27770.9*navg - 144010.0
+}
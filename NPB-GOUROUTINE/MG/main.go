@@ -1,50 +1,75 @@
 package main
 
 import (
+	"cmp"
+	"flag"
 	"fmt"
+	"os"
+	"strings"
+
+	"github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/MG/mg"
 	"github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/MG/params"
+	"github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/common"
 )
 
 func main() {
-	if params.EmptyTag {
-		fmt.Println("To make a NAS benchmark type ")
-		fmt.Println("\t go build -o mg -tags=<CLASS>")
-		fmt.Println("where: <class> is \"S\", \"W\", \"A\", \"B\", \"C\", \"D\" or \"E\"")
-		return
-	}
-
-	// Create benchmark instance
-	mg := NewMGBenchmark()
-	mg.nit = params.NIT
-	mg.class = params.CLASS
-	mg.debug_vec[0] = 0 // Ativa os prints de rep_nrm
+	class := flag.String("class", "S", "problem class (S, W, A, B, C, D, E or U)")
+	n := flag.Int("n", 32, "class U: grid points in every dimension not set by -nx, -ny or -nz (even, at least 4)")
+	nx := flag.Int("nx", 0, "class U: grid points in x (0 means -n)")
+	ny := flag.Int("ny", 0, "class U: grid points in y (0 means -n)")
+	nz := flag.Int("nz", 0, "class U: grid points in z (0 means -n)")
+	nit := flag.Int("nit", 4, "class U: number of V-cycle iterations")
+	format := flag.String("format", "text", "result format: text or json")
+	results := flag.String("results", "", "append the result to this .csv or .jsonl file")
+	workers := flag.Int("workers", 0, "goroutines (0 means $GO_NUM_THREADS, or one per CPU)")
+	maxProcs := flag.Int("gomaxprocs", 0, "goroutines running at once (0 means $GOMAXPROCS, or one per CPU)")
+	timeout := flag.Duration("timeout", 0, "stop after this long and report the iterations completed (0 for no limit)")
+	flag.Parse()
 
-	// Calculate LM and LT_DEFAULT based on problem size
-	// LM is log2 of NX (assuming NX = NY = NZ for MG benchmark)
-	lm := 0
-	for n := params.NX; n > 1; n >>= 1 {
-		lm++
+	if err := common.SetFormat(*format); err != nil {
+		fmt.Fprintf(os.Stderr, "mg: %v\n", err)
+		os.Exit(1)
+	}
+	out := common.Progress()
+	if err := common.SetResultsFile(*results); err != nil {
+		fmt.Fprintf(os.Stderr, "mg: %v\n", err)
+		os.Exit(1)
+	}
+	if err := common.SetMaxProcs(*maxProcs); err != nil {
+		fmt.Fprintf(os.Stderr, "mg: %v\n", err)
+		os.Exit(1)
 	}
 
-	// Set lt and lt_default
-	mg.lt = lm
-	mg.lt_default = lm
-
-	// Initialize arrays with correct size
-	maxlevel := lm + 1
-	mg.nx = make([]int, maxlevel+1)
-	mg.ny = make([]int, maxlevel+1)
-	mg.nz = make([]int, maxlevel+1)
-	mg.m1 = make([]int, maxlevel+1)
-	mg.m2 = make([]int, maxlevel+1)
-	mg.m3 = make([]int, maxlevel+1)
-	mg.ir = make([]int, maxlevel+1)
-
-	// Store initial values at top level (will be set properly in setup())
-	mg.nx[lm] = params.NX
-	mg.ny[lm] = params.NY
-	mg.nz[lm] = params.NZ
+	var p params.Params
+	if strings.EqualFold(*class, params.UserClass) {
+		var err error
+		p, err = params.Custom(cmp.Or(*nx, *n), cmp.Or(*ny, *n), cmp.Or(*nz, *n), *nit)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "mg: class U: %v\n", err)
+			os.Exit(1)
+		}
+	} else {
+		var ok bool
+		p, ok = params.Lookup(*class)
+		if !ok {
+			common.PrintClassUsage(out, "mg", *class, append(params.Classes, params.UserClass))
+			os.Exit(1)
+		}
+	}
 
-	// Run benchmark
-	mg.run()
+	ctx, stop := common.RunContext(*timeout)
+	defer stop()
+	result, err := mg.Run(ctx, mg.Config{Params: p, Workers: *workers, Out: out})
+	if err != nil && !result.Incomplete {
+		fmt.Fprintf(os.Stderr, "mg: %v\n", err)
+		os.Exit(1)
+	}
+	common.Report(&result.Result)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "mg: %v\n", err)
+		os.Exit(1)
+	}
+	if result.Failed() {
+		os.Exit(1)
+	}
 }
@@ -1,12 +1,12 @@
-package main
+// Package mg is the MG (MultiGrid) kernel: it approximates the solution of a
+// 3D discrete Poisson equation with V-cycle multigrid.
+package mg
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"math"
-	"os"
-	"runtime"
-	"strconv"
-	"sync"
 	"time"
 
 	"github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/MG/params"
@@ -26,6 +26,101 @@ const (
 	T_LAST  = 10
 )
 
+// Config selects the problem a Run solves
+type Config struct {
+	Params   params.Params   // problem size, from params.Lookup or params.Custom
+	Workers  int             // goroutines; 0 means $GO_NUM_THREADS, or one per CPU
+	Schedule common.Schedule // of the loops over grid planes; none means $GO_SCHEDULE
+	Bind     common.Binding  // of the workers to cores; none means $GO_PROC_BIND
+	Out      io.Writer       // progress messages and the NPB banner, discarded when nil
+
+	// FirstTouch has each worker zero the planes of u, v and r it computes
+	// on, so that their pages land on its NUMA node; false means
+	// $GO_FIRST_TOUCH
+	FirstTouch bool
+
+	// Input, from an upstream task of a workflow, is the right hand side v
+	// in place of the random charges. The run is then of class U, with
+	// nothing to verify.
+	Input  *common.Field
+	Output bool // return the solution u in Result.Output
+}
+
+// Result is the outcome of an MG run with the L2 norm it is verified on
+type Result struct {
+	common.Result
+	Rnm2   float64
+	Output *common.Field // the solution on the NX x NY x NZ grid, when Config.Output is set
+}
+
+// Run sets up the grid hierarchy of cfg.Params, runs the benchmark on it and
+// returns its result. When ctx is done between two iterations the run stops
+// there and Run returns the partial result with a *common.IncompleteError.
+func Run(ctx context.Context, cfg Config) (Result, error) {
+	if err := ctx.Err(); err != nil {
+		return Result{}, err
+	}
+	workers, err := common.ResolveWorkers(cfg.Workers)
+	if err != nil {
+		return Result{}, err
+	}
+	sched, err := common.ResolveSchedule(cfg.Schedule)
+	if err != nil {
+		return Result{}, err
+	}
+	bind, err := common.ResolveBinding(cfg.Bind)
+	if err != nil {
+		return Result{}, err
+	}
+	firstTouch, err := common.ResolveFirstTouch(cfg.FirstTouch)
+	if err != nil {
+		return Result{}, err
+	}
+	p := cfg.Params
+
+	// Create benchmark instance
+	mg := NewMGBenchmark(workers)
+	mg.schedule = sched
+	mg.bind = bind
+	mg.firstTouch = firstTouch
+	mg.out = cfg.Out
+	if mg.out == nil {
+		mg.out = io.Discard
+	}
+	mg.nit = p.NIT
+	mg.class = p.CLASS
+	mg.verifyValue = p.VERIFY_VALUE
+	mg.input = cfg.Input
+	mg.output = cfg.Output
+	mg.debug_vec[0] = 0 // Ativa os prints de rep_nrm
+
+	// LM is the number of levels of the V-cycle, log2 of NX for the NPB
+	// classes
+	lm := params.Levels(p.NX, p.NY, p.NZ)
+
+	// Set lt and lt_default
+	mg.lt = lm
+	mg.lt_default = lm
+
+	// Initialize arrays with correct size
+	maxlevel := lm + 1
+	mg.nx = make([]int, maxlevel+1)
+	mg.ny = make([]int, maxlevel+1)
+	mg.nz = make([]int, maxlevel+1)
+	mg.m1 = make([]int, maxlevel+1)
+	mg.m2 = make([]int, maxlevel+1)
+	mg.m3 = make([]int, maxlevel+1)
+	mg.ir = make([]int, maxlevel+1)
+
+	// Store initial values at top level (will be set properly in setup())
+	mg.nx[lm] = p.NX
+	mg.ny[lm] = p.NY
+	mg.nz[lm] = p.NZ
+
+	// Run benchmark
+	return mg.run(ctx)
+}
+
 // MGBenchmark represents the MG (Multigrid) benchmark
 type MGBenchmark struct {
 	nx, ny, nz []int // Grid sizes for each level
@@ -45,31 +140,39 @@ type MGBenchmark struct {
 	n1, n2, n3    int // Actual array dimensions
 
 	// Problem size dependent constants
-	lm         int // log2 of NX (calculated from problem size)
+	lm         int // levels of the V-cycle, log2 of NX for the NPB classes
 	lt_default int // Same as lm
-	nm         int // 2 + (1 << lm)
+	nm         int // 2 + the largest grid size, 2 + (1 << lm) for the NPB classes
 	maxlevel   int // lt_default + 1
 	m          int // nm + 1
 
 	// Parallelism
-	numProcs int
+	numProcs   int
+	schedule   common.Schedule
+	bind       common.Binding
+	firstTouch bool
+	team       *common.Team   // started by run
+	scratch    [][3][]float64 // line buffers of the stencils of each worker, n1 points each
 
 	// Verification
-	verified  bool
-	rnm2      float64
-	rnmu      float64
-	debug_vec [8]int
+	verifyValue float64
+	verified    bool
+	rnm2        float64
+	rnmu        float64
+	debug_vec   [8]int
+
+	// Workflow data: the right hand side to solve for instead of zran3's,
+	// and whether to hand the solution on
+	input  *common.Field
+	output bool
+
+	timers common.Timers
+	out    io.Writer
 }
 
-// NewMGBenchmark creates a new MG benchmark instance
-func NewMGBenchmark() *MGBenchmark {
-	numWorkers := runtime.NumCPU()
-	if nw := os.Getenv("GO_NUM_THREADS"); nw != "" {
-		if n, err := strconv.Atoi(nw); err == nil && n > 0 {
-			numWorkers = n
-		}
-	}
-
+// NewMGBenchmark creates a new MG benchmark instance running on numWorkers
+// goroutines
+func NewMGBenchmark(numWorkers int) *MGBenchmark {
 	return &MGBenchmark{
 		lb:       1,
 		nx:       make([]int, 0), // Will be resized based on maxlevel
@@ -83,39 +186,10 @@ func NewMGBenchmark() *MGBenchmark {
 	}
 }
 
-// parallelFor helper to distribute loop iterations
+// parallelFor splits the iterations [start, end) among the workers of the
+// team by its schedule, each running task on the chunks it is dealt
 func (mg *MGBenchmark) parallelFor(start, end int, task func(s, e, goId int)) {
-	total := end - start
-	if total <= 0 {
-		return
-	}
-
-	// Se for pouco trabalho, faz sequencial para evitar overhead
-	if total < mg.numProcs {
-		task(start, end, 0)
-		return
-	}
-
-	chunkSize := (total + mg.numProcs - 1) / mg.numProcs
-	var wg sync.WaitGroup
-
-	for i := 0; i < mg.numProcs; i++ {
-		s := start + i*chunkSize
-		e := s + chunkSize
-		if s >= end {
-			break
-		}
-		if e > end {
-			e = end
-		}
-
-		wg.Add(1)
-		go func(sInt, eInt int) {
-			defer wg.Done()
-			task(sInt, eInt, i)
-		}(s, e)
-	}
-	wg.Wait()
+	mg.team.For(start, end, task)
 }
 
 // calculateIdx calculates 3D array index in a flat slice
@@ -141,6 +215,18 @@ func (mg *MGBenchmark) power(a float64, n int) float64 {
 	return power
 }
 
+// touch zeroes u, r and v plane by plane on the workers of the loops over the
+// planes of each level, the interior planes 1 to m3-2, which are those of
+// resid, psinv and comm3
+func (mg *MGBenchmark) touch() {
+	for k := mg.lt; k >= 1; k-- {
+		plane := mg.m1[k] * mg.m2[k]
+		common.Touch(mg.team, mg.u, mg.ir[k], plane, mg.m3[k], 1, mg.m3[k]-1)
+		common.Touch(mg.team, mg.r, mg.ir[k], plane, mg.m3[k], 1, mg.m3[k]-1)
+	}
+	common.Touch(mg.team, mg.v, 0, mg.n1*mg.n2, mg.n3, 1, mg.n3-1)
+}
+
 // zero3 zeros the first n elements of a slice
 func zero3(z []float64, n int) {
 	for i := 0; i < n; i++ {
@@ -311,6 +397,51 @@ func (mg *MGBenchmark) zran3(z []float64, n1, n2, n3 int, nx, ny int, k int) {
 	mg.comm3(z, n1, n2, n3, k)
 }
 
+// initRHS sets the right hand side v, from the input field when there is one
+func (mg *MGBenchmark) initRHS() {
+	if mg.input == nil {
+		mg.zran3(mg.v, mg.n1, mg.n2, mg.n3, mg.nx[mg.lt], mg.ny[mg.lt], mg.lt)
+		return
+	}
+	n1, n2, n3 := mg.n1, mg.n2, mg.n3
+	zero3(mg.v, n1*n2*n3)
+
+	// The charges of the periodic problem must add up to zero for it to
+	// have a solution, as zran3's +1s and -1s do
+	s := mg.input.Sample((n1 - 2) * (n2 - 2) * (n3 - 2))
+	mean := 0.0
+	for _, x := range s {
+		mean += x
+	}
+	mean /= float64(len(s))
+	i := 0
+	for i3 := 1; i3 < n3-1; i3++ {
+		for i2 := 1; i2 < n2-1; i2++ {
+			for i1 := 1; i1 < n1-1; i1++ {
+				mg.v[mg.calculateIdx(i1, i2, i3, n1, n2)] = s[i] - mean
+				i++
+			}
+		}
+	}
+	mg.comm3(mg.v, n1, n2, n3, mg.lt)
+}
+
+// solution returns the interior of u, the solution on the finest grid
+func (mg *MGBenchmark) solution() *common.Field {
+	n1, n2, n3 := mg.n1, mg.n2, mg.n3
+	f := common.NewField(n1-2, n2-2, n3-2)
+	i := 0
+	for i3 := 1; i3 < n3-1; i3++ {
+		for i2 := 1; i2 < n2-1; i2++ {
+			for i1 := 1; i1 < n1-1; i1++ {
+				f.Data[i] = mg.u[mg.calculateIdx(i1, i2, i3, n1, n2)]
+				i++
+			}
+		}
+	}
+	return f
+}
+
 func (mg *MGBenchmark) comm3(u []float64, n1, n2, n3 int, kk int) {
 	// Parallelize axis 1 loop over i3
 	mg.parallelFor(1, n3-1, func(start, end, goId int) {
@@ -358,33 +489,33 @@ func (mg *MGBenchmark) comm3(u []float64, n1, n2, n3 int, kk int) {
 func (mg *MGBenchmark) norm2u3(r []float64, n1, n2, n3 int, nx, ny, nz int) (float64, float64) {
 	dn := 1.0 * float64(nx*ny*nz)
 
-	// Reduction variables need explicit handling
+	// The partial sums are added in worker order, so that under a static
+	// schedule the norm does not depend on which worker finishes first
 	sumGlobal := 0.0
 	rnmuGlobal := 0.0
-	var mu sync.Mutex
 
-	mg.parallelFor(1, n3-1, func(start, end, goId int) {
+	mg.team.Run(func(w *common.Worker) {
 		sumLocal := 0.0
 		rnmuLocal := 0.0
-		for i3 := start; i3 < end; i3++ {
-			for i2 := 1; i2 < n2-1; i2++ {
-				for i1 := 1; i1 < n1-1; i1++ {
-					idx := mg.calculateIdx(i1, i2, i3, n1, n2)
-					val := r[idx]
-					sumLocal += val * val
-					a := math.Abs(val)
-					if a > rnmuLocal {
-						rnmuLocal = a
+		w.For(1, n3-1, func(start, end int) {
+			for i3 := start; i3 < end; i3++ {
+				for i2 := 1; i2 < n2-1; i2++ {
+					for i1 := 1; i1 < n1-1; i1++ {
+						idx := mg.calculateIdx(i1, i2, i3, n1, n2)
+						val := r[idx]
+						sumLocal += val * val
+						a := math.Abs(val)
+						if a > rnmuLocal {
+							rnmuLocal = a
+						}
 					}
 				}
 			}
+		})
+		sum, rnmu := w.Sum(sumLocal), w.Max(rnmuLocal)
+		if w.ID == 0 {
+			sumGlobal, rnmuGlobal = sum, rnmu
 		}
-		mu.Lock()
-		sumGlobal += sumLocal
-		if rnmuLocal > rnmuGlobal {
-			rnmuGlobal = rnmuLocal
-		}
-		mu.Unlock()
 	})
 
 	return math.Sqrt(sumGlobal / dn), rnmuGlobal
@@ -393,10 +524,7 @@ func (mg *MGBenchmark) norm2u3(r []float64, n1, n2, n3 int, nx, ny, nz int) (flo
 func (mg *MGBenchmark) resid(u, v, r []float64, n1, n2, n3 int, a []float64, k int) {
 	// Parallelizing outer loop i3
 	mg.parallelFor(1, n3-1, func(start, end, goId int) {
-		// PRIVATIZATION: Each thread gets its own scratch buffers
-		// Size M is sufficient (as defined in constants) or n1
-		u1 := make([]float64, n1)
-		u2 := make([]float64, n1)
+		u1, u2 := mg.scratch[goId][0][:n1], mg.scratch[goId][1][:n1]
 
 		for i3 := start; i3 < end; i3++ {
 			for i2 := 1; i2 < n2-1; i2++ {
@@ -432,9 +560,7 @@ func (mg *MGBenchmark) resid(u, v, r []float64, n1, n2, n3 int, a []float64, k i
 func (mg *MGBenchmark) psinv(r, u []float64, n1, n2, n3 int, c []float64, k int) {
 	// Parallelizing outer loop i3
 	mg.parallelFor(1, n3-1, func(start, end, goId int) {
-		// PRIVATIZATION: Local buffers
-		r1 := make([]float64, n1)
-		r2 := make([]float64, n1)
+		r1, r2 := mg.scratch[goId][0][:n1], mg.scratch[goId][1][:n1]
 
 		for i3 := start; i3 < end; i3++ {
 			for i2 := 1; i2 < n2-1; i2++ {
@@ -487,9 +613,7 @@ func (mg *MGBenchmark) rprj3(r []float64, m1k, m2k, m3k int, s []float64, m1j, m
 
 	// Parallelizing loop j3
 	mg.parallelFor(1, m3j-1, func(start, end, goId int) {
-		// PRIVATIZATION: Local buffers
-		x1 := make([]float64, m1k)
-		y1 := make([]float64, m1k)
+		x1, y1 := mg.scratch[goId][0][:m1k], mg.scratch[goId][1][:m1k]
 
 		for j3 := start; j3 < end; j3++ {
 			i3 := 2*j3 - d3
@@ -542,10 +666,7 @@ func (mg *MGBenchmark) interp(z []float64, mm1, mm2, mm3 int, u []float64, n1, n
 	if n1 != 3 && n2 != 3 && n3 != 3 {
 		// Parallelizing loop i3
 		mg.parallelFor(0, mm3-1, func(start, end, goId int) {
-			// PRIVATIZATION
-			z1 := make([]float64, mm1)
-			z2 := make([]float64, mm1)
-			z3 := make([]float64, mm1)
+			z1, z2, z3 := mg.scratch[goId][0][:mm1], mg.scratch[goId][1][:mm1], mg.scratch[goId][2][:mm1]
 
 			for i3 := start; i3 < end; i3++ {
 				for i2 := 0; i2 < mm2-1; i2++ {
@@ -721,18 +842,23 @@ func (mg *MGBenchmark) mg3P(u, v, r []float64, a, c []float64, n1, n2, n3 int, k
 // rep_nrm report on norm
 func (mg *MGBenchmark) rep_nrm(u []float64, n1, n2, n3 int, title string, kk int) {
 	rnm2, rmnmu := mg.norm2u3(u, n1, n2, n3, mg.nx[kk], mg.ny[kk], mg.nz[kk])
-	fmt.Printf(" Level%2d in %8s: norms =%21.14e%21.14e\n", kk, title, rnm2, rmnmu)
+	fmt.Fprintf(mg.out, " Level%2d in %8s: norms =%21.14e%21.14e\n", kk, title, rnm2, rmnmu)
 }
 
-func (mg *MGBenchmark) run() {
-	common.TimerStart(T_INIT)
-	mg.lm = int(math.Log2(float64(mg.nx[mg.lt])))
-	mg.lt_default = mg.lm
-	// Ensure lt matches lt_default
-	if mg.lt != mg.lt_default {
-		mg.lt = mg.lt_default
+// run performs the MG benchmark and returns its result, with an error when
+// ctx stopped its iterations early
+func (mg *MGBenchmark) run(ctx context.Context) (Result, error) {
+	team, err := common.StartTeam(mg.numProcs, mg.schedule, mg.bind)
+	if err != nil {
+		return Result{}, err
 	}
-	mg.nm = 2 + (1 << mg.lm)
+	mg.team = team
+	defer mg.team.Close()
+
+	mg.timers.Start(T_INIT)
+	mg.lm = mg.lt
+	mg.lt_default = mg.lm
+	mg.nm = 2 + max(mg.nx[mg.lt], mg.ny[mg.lt], mg.nz[mg.lt])
 	mg.maxlevel = mg.lt_default + 1
 	mg.m = mg.nm + 1
 
@@ -747,11 +873,11 @@ func (mg *MGBenchmark) run() {
 		mg.ir = make([]int, mg.maxlevel+1)
 	}
 
-	// Calculate NV and NR based on actual problem size (NX, NY, NZ)
-	// NDIM1, NDIM2, NDIM3 are log2 of NX, NY, NZ respectively
-	// So (1 << NDIM1) = NX, (1 << NDIM2) = NY, (1 << NDIM3) = NZ
+	// NV holds the finest grid and NR the grids of all levels: NPB's bound,
+	// or the sum of the levels when it is larger for a custom grid
 	NV := ONE * (2 + mg.nx[mg.lt]) * (2 + mg.ny[mg.lt]) * (2 + mg.nz[mg.lt])
-	NR := ((NV + mg.nm*mg.nm + 5*mg.nm + 7*mg.lm + 6) / 7) * 8
+	mg.setup()
+	NR := max(mg.ir[1]+mg.m1[1]*mg.m2[1]*mg.m3[1], ((NV+mg.nm*mg.nm+5*mg.nm+7*mg.lm+6)/7)*8)
 
 	// Allocations
 	if mg.u == nil {
@@ -790,15 +916,30 @@ func (mg *MGBenchmark) run() {
 	mg.setup()
 
 	// Initialize arrays. Using len(mg.u) here is safe as it's the first init.
-	zero3(mg.u, len(mg.u))
-	mg.zran3(mg.v, mg.n1, mg.n2, mg.n3, mg.nx[mg.lt], mg.ny[mg.lt], mg.lt)
+	if mg.firstTouch {
+		mg.touch()
+	} else {
+		zero3(mg.u, len(mg.u))
+	}
+	mg.initRHS()
 
 	mg.rnm2, mg.rnmu = mg.norm2u3(mg.v, mg.n1, mg.n2, mg.n3, mg.nx[mg.lt], mg.ny[mg.lt], mg.nz[mg.lt])
 
-	fmt.Printf("\n\n NAS Parallel Benchmarks 4.1 Parallel Go version - MG Benchmark\n\n")
-	fmt.Printf(" Size: %3dx%3dx%3d (class %s)\n", mg.nx[mg.lt], mg.ny[mg.lt], mg.nz[mg.lt], mg.class)
-	fmt.Printf(" Iterations: %3d\n", mg.nit)
-	fmt.Printf(" Workers:    %d\n", mg.numProcs)
+	fmt.Fprintf(mg.out, "\n\n NAS Parallel Benchmarks 4.1 Parallel Go version - MG Benchmark\n\n")
+	fmt.Fprintf(mg.out, " Size: %3dx%3dx%3d (class %s)\n", mg.nx[mg.lt], mg.ny[mg.lt], mg.nz[mg.lt], mg.class)
+	fmt.Fprintf(mg.out, " Iterations: %3d\n", mg.nit)
+	fmt.Fprintf(mg.out, " Workers:    %d\n", mg.numProcs)
+	fmt.Fprintf(mg.out, " Schedule:   %s\n", mg.schedule)
+	fmt.Fprintf(mg.out, " Placement:  %s\n", common.FormatPlacement(mg.team.Placement()))
+	fmt.Fprintf(mg.out, " First touch: %t\n", mg.firstTouch)
+
+	// The x lines of every level fit in those of the finest grid
+	mg.scratch = make([][3][]float64, mg.team.Workers())
+	mg.team.Run(func(w *common.Worker) {
+		for i := range mg.scratch[w.ID] {
+			mg.scratch[w.ID][i] = make([]float64, mg.n1)
+		}
+	})
 
 	mg.resid(mg.u, mg.v, mg.r, mg.n1, mg.n2, mg.n3, mg.a, mg.lt)
 	mg.rnm2, mg.rnmu = mg.norm2u3(mg.r, mg.n1, mg.n2, mg.n3, mg.nx[mg.lt], mg.ny[mg.lt], mg.nz[mg.lt])
@@ -810,50 +951,100 @@ func (mg *MGBenchmark) run() {
 	mg.setup()
 
 	zero3(mg.u, len(mg.u))
-	mg.zran3(mg.v, mg.n1, mg.n2, mg.n3, mg.nx[mg.lt], mg.ny[mg.lt], mg.lt)
+	mg.initRHS()
 
 	mg.resid(mg.u, mg.v, mg.r, mg.n1, mg.n2, mg.n3, mg.a, mg.lt)
 	mg.rnm2, mg.rnmu = mg.norm2u3(mg.r, mg.n1, mg.n2, mg.n3, mg.nx[mg.lt], mg.ny[mg.lt], mg.nz[mg.lt])
-	common.TimerStop(T_INIT)
+	mg.timers.Stop(T_INIT)
 
-	tinit := common.TimerRead(T_INIT)
-	fmt.Printf(" Initialization time: %15.3f seconds\n", tinit)
+	tinit := mg.timers.Read(T_INIT)
+	fmt.Fprintf(mg.out, " Initialization time: %15.3f seconds\n", tinit)
 	for i := T_BENCH; i < T_LAST; i++ {
-		common.TimerClear(i)
+		mg.timers.Clear(i)
 	}
 	startTime := time.Now()
+	iterations := mg.nit
 	for it := 1; it <= mg.nit; it++ {
+		if ctx.Err() != nil {
+			iterations = it - 1
+			break
+		}
 		if it == 1 || it == mg.nit || it%5 == 0 {
-			fmt.Printf("\t iter %3d\n", it)
+			fmt.Fprintf(mg.out, "\t iter %3d\n", it)
 		}
 		mg.mg3P(mg.u, mg.v, mg.r, mg.a, mg.c, mg.n1, mg.n2, mg.n3, mg.lt)
 		mg.resid(mg.u, mg.v, mg.r, mg.n1, mg.n2, mg.n3, mg.a, mg.lt)
 	}
 	elapsed := time.Since(startTime).Seconds()
+	numa := common.NUMABytes(common.SpanOf(mg.u), common.SpanOf(mg.v), common.SpanOf(mg.r))
+	fmt.Fprintf(mg.out, " NUMA pages: %s\n", common.FormatNUMABytes(numa))
 
 	mg.rnm2, mg.rnmu = mg.norm2u3(mg.r, mg.n1, mg.n2, mg.n3, mg.nx[mg.lt], mg.ny[mg.lt], mg.nz[mg.lt])
 
-	epsilon := 1.0e-8
-	verifyValue := params.VERIFY_VALUE
-	err := math.Abs(mg.rnm2-verifyValue) / verifyValue
-	mg.verified = err <= epsilon
-
-	fmt.Printf("\n Benchmark completed\n")
-	if mg.verified {
-		fmt.Printf(" VERIFICATION SUCCESSFUL\n")
-		fmt.Printf(" L2 Norm is %20.13e\n", mg.rnm2)
-		fmt.Printf(" Error is   %20.13e\n", err)
+	incomplete := iterations < mg.nit
+	if incomplete {
+		fmt.Fprintf(mg.out, "\n Benchmark stopped after %d of %d iterations\n", iterations, mg.nit)
+		fmt.Fprintf(mg.out, " NO VERIFICATION PERFORMED\n")
+		fmt.Fprintf(mg.out, " L2 Norm is %20.13e\n", mg.rnm2)
+	} else if mg.class == "U" || mg.input != nil {
+		fmt.Fprintf(mg.out, "\n Benchmark completed\n")
+		fmt.Fprintf(mg.out, " Problem size unknown\n")
+		fmt.Fprintf(mg.out, " NO VERIFICATION PERFORMED\n")
+		fmt.Fprintf(mg.out, " L2 Norm is %20.13e\n", mg.rnm2)
 	} else {
-		fmt.Printf(" VERIFICATION FAILED\n")
-		fmt.Printf(" L2 Norm is             %20.13e\n", mg.rnm2)
-		fmt.Printf(" The correct L2 Norm is %20.13e\n", verifyValue)
+		fmt.Fprintf(mg.out, "\n Benchmark completed\n")
+		epsilon := 1.0e-8
+		verifyValue := mg.verifyValue
+		err := math.Abs(mg.rnm2-verifyValue) / verifyValue
+		mg.verified = err <= epsilon
+
+		if mg.verified {
+			fmt.Fprintf(mg.out, " VERIFICATION SUCCESSFUL\n")
+			fmt.Fprintf(mg.out, " L2 Norm is %20.13e\n", mg.rnm2)
+			fmt.Fprintf(mg.out, " Error is   %20.13e\n", err)
+		} else {
+			fmt.Fprintf(mg.out, " VERIFICATION FAILED\n")
+			fmt.Fprintf(mg.out, " L2 Norm is             %20.13e\n", mg.rnm2)
+			fmt.Fprintf(mg.out, " The correct L2 Norm is %20.13e\n", verifyValue)
+		}
 	}
 
 	mops := 0.0
 	if elapsed > 0 {
 		nn := float64(mg.nx[mg.lt] * mg.ny[mg.lt] * mg.nz[mg.lt])
-		mops = 58.0 * float64(mg.nit) * nn * 1.0e-6 / elapsed
+		mops = 58.0 * float64(iterations) * nn * 1.0e-6 / elapsed
 	}
 
-	common.PrintResults("MG", mg.class, mg.nx[mg.lt], mg.ny[mg.lt], mg.nz[mg.lt], mg.nit, elapsed, mops, "floating point", mg.verified, "4.1", "Unknown", "Go", "")
+	class := mg.class
+	if mg.input != nil {
+		class = params.UserClass
+	}
+	result := common.Result{
+		Kernel:      "MG",
+		Class:       class,
+		Size:        [3]int{mg.nx[mg.lt], mg.ny[mg.lt], mg.nz[mg.lt]},
+		Iterations:  iterations,
+		Time:        elapsed,
+		Mops:        mops,
+		OpType:      "floating point",
+		Verified:    mg.verified,
+		Incomplete:  incomplete,
+		Workers:     mg.numProcs,
+		Timers:      []common.Timer{{Name: "init", Seconds: tinit}},
+		NPBVersion:  "4.1",
+		CompileTime: "Unknown",
+		Compiler:    "Go",
+	}
+	mg.team.Describe(&result)
+	result.FirstTouch = mg.firstTouch
+	result.NUMABytes = numa
+	common.Finish(&result, mg.out)
+	res := Result{Result: result, Rnm2: mg.rnm2}
+	if mg.output {
+		res.Output = mg.solution()
+	}
+	if incomplete {
+		return res, &common.IncompleteError{Completed: iterations, Planned: mg.nit, Err: ctx.Err()}
+	}
+	return res, nil
 }
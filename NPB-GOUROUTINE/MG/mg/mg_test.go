@@ -0,0 +1,189 @@
+package mg
+
+import (
+	"context"
+	"flag"
+	"math"
+	"testing"
+
+	"github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/MG/params"
+	"github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/common"
+	"github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/common/npbtest"
+)
+
+var long = flag.Bool("long", false, "also verify class A")
+
+// testClasses lists the classes the verification tests run
+func testClasses() []string {
+	if *long {
+		return []string{"S", "W", "A"}
+	}
+	return []string{"S", "W"}
+}
+
+func BenchmarkMG(b *testing.B) {
+	for _, class := range []string{"S", "W"} {
+		b.Run(class, func(b *testing.B) {
+			p, _ := params.Lookup(class)
+			mops := 0.0
+			for i := 0; i < b.N; i++ {
+				result, err := Run(context.Background(), Config{Params: p})
+				if err != nil {
+					b.Fatal(err)
+				}
+				if result.Failed() {
+					b.Fatalf("class %s failed verification", class)
+				}
+				mops += result.Mops
+			}
+			b.ReportMetric(mops/float64(b.N), "Mop/s")
+		})
+	}
+}
+
+func TestVerification(t *testing.T) {
+	for _, class := range testClasses() {
+		t.Run(class, func(t *testing.T) {
+			p, _ := params.Lookup(class)
+			result, err := Run(context.Background(), Config{Params: p})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := math.Abs(result.Rnm2-p.VERIFY_VALUE) / p.VERIFY_VALUE; err > 1.0e-8 {
+				t.Errorf("L2 norm = %.13e, want %.13e", result.Rnm2, p.VERIFY_VALUE)
+			}
+			if !result.Verified {
+				t.Error("kernel reported a failed verification")
+			}
+		})
+	}
+}
+
+// TestWorkers checks that the kernel verifies on teams of any size, including
+// one with more workers than the loops have iterations to share
+func TestWorkers(t *testing.T) {
+	p, _ := params.Lookup("S")
+	for _, workers := range []int{1, 3, 64} {
+		result, err := Run(context.Background(), Config{Params: p, Workers: workers})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Workers != workers || !result.Verified {
+			t.Errorf("%d workers: got %d workers, verified %v", workers, result.Workers, result.Verified)
+		}
+	}
+}
+
+// TestSchedules checks that the kernel verifies under every kind of schedule
+func TestSchedules(t *testing.T) {
+	p, _ := params.Lookup("S")
+	for _, text := range []string{"static", "static,3", "dynamic", "dynamic,7", "guided", "guided,2"} {
+		sched, err := common.ParseSchedule(text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result, err := Run(context.Background(), Config{Params: p, Workers: 3, Schedule: sched})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Schedule != text || !result.Verified {
+			t.Errorf("%s: got schedule %q, verified %v", text, result.Schedule, result.Verified)
+		}
+	}
+}
+
+// TestBinding checks that bound workers verify and report their placement,
+// and that a core that does not exist is an error
+func TestBinding(t *testing.T) {
+	p, _ := params.Lookup("S")
+	t.Setenv("GO_PROC_BIND", "spread")
+	result, err := Run(context.Background(), Config{Params: p, Workers: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ProcBind != "spread" || len(result.Placement) != 3 || !result.Verified {
+		t.Errorf("got binding %q, placement %v, verified %v", result.ProcBind, result.Placement, result.Verified)
+	}
+
+	bind := common.Binding{Policy: common.BIND_LIST, Cores: []int{1 << 20}}
+	if _, err := Run(context.Background(), Config{Params: p, Workers: 2, Bind: bind}); err == nil {
+		t.Error("binding to core 1<<20 was accepted")
+	}
+}
+
+// TestFirstTouch checks that grids zeroed by the workers verify, and that a
+// GO_FIRST_TOUCH that is not a boolean is an error
+func TestFirstTouch(t *testing.T) {
+	p, _ := params.Lookup("S")
+	for _, workers := range []int{1, 3, 64} {
+		result, err := Run(context.Background(), Config{Params: p, Workers: workers, FirstTouch: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !result.FirstTouch || !result.Verified {
+			t.Errorf("%d workers: got first touch %v, verified %v", workers, result.FirstTouch, result.Verified)
+		}
+	}
+
+	t.Setenv("GO_FIRST_TOUCH", "workers")
+	if _, err := Run(context.Background(), Config{Params: p, Workers: 2}); err == nil {
+		t.Error("GO_FIRST_TOUCH=workers was accepted")
+	}
+}
+
+// TestCustomGrids checks the depth of the V-cycle on grids that are not
+// cubes or powers of two, and that more iterations shrink the residual there
+func TestCustomGrids(t *testing.T) {
+	for _, tc := range []struct{ nx, ny, nz, levels int }{
+		{32, 32, 32, 5}, {256, 256, 256, 8}, {192, 192, 192, 7}, {64, 32, 48, 5}, {40, 24, 16, 4}, {6, 8, 8, 2},
+	} {
+		if got := params.Levels(tc.nx, tc.ny, tc.nz); got != tc.levels {
+			t.Errorf("Levels(%d, %d, %d) = %d, want %d", tc.nx, tc.ny, tc.nz, got, tc.levels)
+		}
+	}
+	for _, size := range [][3]int{{3, 8, 8}, {8, 2, 8}, {8, 8, 0}} {
+		if _, err := params.Custom(size[0], size[1], size[2], 4); err == nil {
+			t.Errorf("Custom accepted a %dx%dx%d grid", size[0], size[1], size[2])
+		}
+	}
+
+	for _, size := range [][3]int{{48, 32, 24}, {40, 24, 16}} {
+		norms := make([]float64, 2)
+		for i, nit := range []int{1, 4} {
+			p, err := params.Custom(size[0], size[1], size[2], nit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			result, err := Run(context.Background(), Config{Params: p})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Size != size || result.Verified || result.Failed() {
+				t.Errorf("%v: got size %v, verified %v, failed %v", size, result.Size, result.Verified, result.Failed())
+			}
+			norms[i] = result.Rnm2
+		}
+		if !(norms[1] > 0 && norms[1] < norms[0]/2) {
+			t.Errorf("%v: L2 norm %.6e after 1 iteration, %.6e after 4", size, norms[0], norms[1])
+		}
+	}
+}
+
+// runClass runs the kernel at class for the checks every kernel shares
+func runClass(ctx context.Context, class string) (common.Result, error) {
+	p, _ := params.Lookup(class)
+	result, err := Run(ctx, Config{Params: p})
+	return result.Result, err
+}
+
+// TestConcurrentRuns checks that runs of two classes in one process do not
+// share state
+func TestConcurrentRuns(t *testing.T) {
+	npbtest.ConcurrentRuns(t, runClass, "S", "W")
+}
+
+// TestStoppedRun checks a run stopped by its context. Run checks once before
+// starting and then before every iteration
+func TestStoppedRun(t *testing.T) {
+	npbtest.StoppedRun(t, runClass, "S", "iterations")
+}
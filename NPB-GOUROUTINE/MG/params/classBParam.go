@@ -1,14 +0,0 @@
-//go:build B
-
-package params
-
-const (
-	CLASS        = "B"
-	NX           = 256
-	NY           = 256
-	NZ           = 256
-	NIT          = 20
-	LM           = 5
-	EmptyTag     = false
-	VERIFY_VALUE = 0.1800564401355e-05
-)
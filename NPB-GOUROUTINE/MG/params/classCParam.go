@@ -1,14 +0,0 @@
-//go:build C
-
-package params
-
-const (
-	CLASS        = "C"
-	NX           = 512
-	NY           = 512
-	NZ           = 512
-	NIT          = 20
-	LM           = 5
-	EmptyTag     = false
-	VERIFY_VALUE = 0.5706732285740e-06
-)
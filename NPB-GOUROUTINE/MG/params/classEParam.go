@@ -1,14 +0,0 @@
-//go:build E
-
-package params
-
-const (
-	CLASS        = "E"
-	NX           = 2048
-	NY           = 2048
-	NZ           = 2048
-	NIT          = 50
-	LM           = 5
-	EmptyTag     = false
-	VERIFY_VALUE = 0.8157592357404e-10
-)
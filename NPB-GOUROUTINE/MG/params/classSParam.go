@@ -1,14 +0,0 @@
-//go:build S
-
-package params
-
-const (
-	CLASS        = "S"
-	NX           = 32
-	NY           = 32
-	NZ           = 32
-	NIT          = 4
-	LM           = 5
-	EmptyTag     = false
-	VERIFY_VALUE = 0.5307707005734e-04
-)
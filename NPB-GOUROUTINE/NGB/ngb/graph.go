@@ -0,0 +1,139 @@
+package ngb
+
+import (
+	"fmt"
+
+	"github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/NGB/params"
+	"github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/common"
+)
+
+// task is a vertex of a workflow: one run of a kernel, whose input is made of
+// the outputs of the tasks in in
+type task struct {
+	id     int
+	name   string
+	kernel string
+	class  string
+	in     []*task
+	out    []*task
+	launch *common.Field // input of a task with no predecessors; nil for the standard problem
+
+	// Set by the goroutine of the task before it closes done
+	done   chan struct{}
+	state  int
+	result common.Result
+	output *common.Field // released once every successor has taken it
+	norm   float64
+	start  float64 // seconds since the workflow started
+	end    float64
+
+	users int // successors that have not taken the output yet
+}
+
+// States of a task once done is closed
+const (
+	COMPLETED = iota
+	STOPPED   // its kernel was stopped before the end
+	SKIPPED   // it did not run, as the workflow stopped first
+)
+
+// graph is a workflow whose tasks are topologically sorted: every task comes
+// after those it takes its input from
+type graph struct {
+	name  string
+	tasks []*task
+}
+
+func (g *graph) addTask(kernel, class string, in ...*task) *task {
+	t := &task{
+		id:     len(g.tasks),
+		name:   fmt.Sprintf("%s.%d", kernel, len(g.tasks)),
+		kernel: kernel,
+		class:  class,
+		in:     in,
+		done:   make(chan struct{}),
+	}
+	for _, pred := range in {
+		pred.out = append(pred.out, t)
+		pred.users++
+	}
+	g.tasks = append(g.tasks, t)
+	return t
+}
+
+// newGraph builds the workflow of the given kind ("ED", "HC", "VP" or "MB")
+// whose tasks run the kernels at class
+func newGraph(kind, class string) (*graph, error) {
+	g := &graph{name: fmt.Sprintf("NGB_%s.%s", kind, class)}
+	switch kind {
+	case "ED":
+		g.buildED(class)
+	case "HC":
+		g.buildHC(class)
+	case "VP":
+		g.buildVP(class)
+	case "MB":
+		g.buildMB(class)
+	default:
+		return nil, fmt.Errorf("unknown graph %q: want ED, HC, VP or MB", kind)
+	}
+	return g, nil
+}
+
+// buildED builds the embarrassingly distributed workflow: independent EP
+// tasks, each launched with its own number, which seeds its random numbers
+func (g *graph) buildED(class string) {
+	for i := 0; i < params.NUM_TASKS; i++ {
+		t := g.addTask("EP", class)
+		t.launch = &common.Field{NX: 1, NY: 1, NZ: 1, Data: []float64{float64(i + 1)}}
+	}
+}
+
+// buildHC builds the helical chain: MG, FT and CG in turn, each task taking
+// the output of the one before it
+func (g *graph) buildHC(class string) {
+	kernels := []string{"MG", "FT", "CG"}
+	var prev *task
+	for i := 0; i < params.NUM_TASKS; i++ {
+		if prev == nil {
+			prev = g.addTask(kernels[i%3], class)
+		} else {
+			prev = g.addTask(kernels[i%3], class, prev)
+		}
+	}
+}
+
+// buildVP builds the visualization pipe: three rounds of a flow solver (MG),
+// a post-processor (FT) and a visualizer (IS). Each stage takes the output of
+// the stage before it in its round and of itself in the round before.
+func (g *graph) buildVP(class string) {
+	var mg, ft, is *task
+	for round := 0; round < params.NUM_TASKS/3; round++ {
+		if round == 0 {
+			mg = g.addTask("MG", class)
+			ft = g.addTask("FT", class, mg)
+			is = g.addTask("IS", class, ft)
+			continue
+		}
+		mg = g.addTask("MG", class, mg)
+		ft = g.addTask("FT", class, mg, ft)
+		is = g.addTask("IS", class, ft, is)
+	}
+}
+
+// buildMB builds the mixed bag: layers of CG, MG and FT tasks where every
+// task takes the outputs of the whole layer before it. The tasks of the
+// second and third column run at one and two classes below class, so the
+// data sent between the layers is of mixed sizes.
+func (g *graph) buildMB(class string) {
+	var prev []*task
+	for _, kernel := range []string{"CG", "MG", "FT"} {
+		var layer []*task
+		c := class
+		for col := 0; col < params.NUM_TASKS/3; col++ {
+			layer = append(layer, g.addTask(kernel, c, prev...))
+			c = params.Smaller(c)
+		}
+		prev = layer
+	}
+}
@@ -0,0 +1,360 @@
+// Package ngb is a workflow engine in the manner of the NAS Grid Benchmarks:
+// it composes the EP, IS, CG, MG and FT kernels into dataflow graphs whose
+// tasks run concurrently in one process, each as soon as the tasks it takes
+// its input from are done.
+//
+// A task hands its final data, such as the solution grid of MG or the sorted
+// keys of IS, to its successors as a common.Field; a successor samples it to
+// the size of its own problem in place of its generated initial data. A task
+// with several predecessors takes the average of their outputs, sampled to the
+// size of the largest. The tasks with no predecessors run the standard
+// problems and are verified by their kernels as well.
+//
+// Unlike NGB the tasks run the kernels of this repository instead of BT, SP
+// and LU, so the graphs keep the shapes of NGB's with other kernels in them,
+// and the reference values in params, the norms of the outputs of the tasks,
+// are those of this implementation.
+package ngb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/CG/cg"
+	cgparams "github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/CG/params"
+	"github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/EP/ep"
+	epparams "github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/EP/params"
+	"github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/FT/ft"
+	ftparams "github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/FT/params"
+	"github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/IS/is"
+	isparams "github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/IS/params"
+	"github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/MG/mg"
+	mgparams "github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/MG/params"
+	"github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/NGB/params"
+	"github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/common"
+)
+
+// EPSILON is the relative tolerance of the output norms
+const EPSILON = 1.0e-8
+
+// Config selects the workflow a Run executes
+type Config struct {
+	Params  params.Params // class of the tasks, from params.Lookup
+	Graph   string        // "ED", "HC", "VP" or "MB"
+	Workers int           // goroutines of every task; 0 means $GO_NUM_THREADS, or one per CPU
+	Out     io.Writer     // progress messages, the task table and the NPB banner, discarded when nil
+}
+
+// Task is the outcome of one task of a workflow
+type Task struct {
+	Name   string   `json:"name"`
+	Kernel string   `json:"kernel"`
+	Class  string   `json:"class"`
+	Inputs []string `json:"inputs,omitempty"`
+	State  string   `json:"state"` // completed, stopped or skipped
+
+	Start    float64 `json:"start_seconds"` // since the workflow started
+	End      float64 `json:"end_seconds"`
+	Time     float64 `json:"kernel_seconds"` // timed section of the kernel
+	Mops     float64 `json:"mops"`
+	Norm     float64 `json:"output_norm"`
+	Verified bool    `json:"verified"`
+}
+
+// Result is the outcome of a workflow with that of each of its tasks. Its
+// Time is the end-to-end time, its Mops the operations of all the kernels
+// over that time and its Timers the wall time of each task.
+type Result struct {
+	common.Result
+	Graph string
+	Tasks []Task
+}
+
+// Run executes the workflow cfg.Graph with the class of cfg.Params and returns
+// its result. When ctx is done the tasks that have not started are skipped,
+// the running ones stop between two of their iterations, and Run returns the
+// partial result with a *common.IncompleteError.
+func Run(ctx context.Context, cfg Config) (Result, error) {
+	if err := ctx.Err(); err != nil {
+		return Result{}, err
+	}
+	workers, err := common.ResolveWorkers(cfg.Workers)
+	if err != nil {
+		return Result{}, err
+	}
+	out := cfg.Out
+	if out == nil {
+		out = io.Discard
+	}
+	b, err := NewNGBBenchmark(cfg.Params, strings.ToUpper(cfg.Graph), workers, out)
+	if err != nil {
+		return Result{}, err
+	}
+	return b.run(ctx)
+}
+
+// NGBBenchmark represents one run of a workflow
+type NGBBenchmark struct {
+	class      string
+	graph      *graph
+	verify     [params.NUM_TASKS]float64
+	numWorkers int
+
+	mu    sync.Mutex // guards the outputs and their users, and out
+	begin time.Time
+	out   io.Writer
+}
+
+// NewNGBBenchmark creates the workflow of the given graph for the class of p,
+// whose tasks run on numWorkers goroutines each
+func NewNGBBenchmark(p params.Params, graph string, numWorkers int, out io.Writer) (*NGBBenchmark, error) {
+	verify, ok := p.VerifyValues(graph)
+	if !ok {
+		return nil, fmt.Errorf("unknown graph %q: want ED, HC, VP or MB", graph)
+	}
+	g, err := newGraph(graph, p.CLASS)
+	if err != nil {
+		return nil, err
+	}
+	return &NGBBenchmark{
+		class:      p.CLASS,
+		graph:      g,
+		verify:     verify,
+		numWorkers: numWorkers,
+		out:        out,
+	}, nil
+}
+
+// run executes the tasks and returns the result, with an error when ctx
+// stopped the workflow early or a kernel failed
+func (b *NGBBenchmark) run(ctx context.Context) (Result, error) {
+	fmt.Fprintf(b.out, "\n\n NAS Parallel Benchmarks 4.1 Parallel Go version - NGB Benchmark\n\n")
+	fmt.Fprintf(b.out, " Workflow: %s\n", b.graph.name)
+	fmt.Fprintf(b.out, " Tasks:    %d\n", len(b.graph.tasks))
+	fmt.Fprintf(b.out, " Workers:  %d per task\n\n", b.numWorkers)
+
+	// The kernels run under a context of their own, which is cancelled when
+	// the workflow stops or a task fails: ctx is only checked between tasks
+	kctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var errMu sync.Mutex
+	var firstErr error
+	b.begin = time.Now()
+	for _, t := range b.graph.tasks {
+		go func() {
+			defer close(t.done)
+			for _, pred := range t.in {
+				<-pred.done
+			}
+			if err := b.runTask(ctx, kctx, t); err != nil {
+				errMu.Lock()
+				if firstErr == nil {
+					firstErr = fmt.Errorf("%s: %w", t.name, err)
+				}
+				errMu.Unlock()
+				cancel()
+			}
+		}()
+	}
+	for _, t := range b.graph.tasks {
+		<-t.done
+	}
+	elapsed := time.Since(b.begin).Seconds()
+	if firstErr != nil {
+		return Result{}, firstErr
+	}
+
+	tasks := make([]Task, len(b.graph.tasks))
+	completed, ops := 0, 0.0
+	verified := true
+	var timers []common.Timer
+	for i, t := range b.graph.tasks {
+		tk := Task{
+			Name:   t.name,
+			Kernel: t.kernel,
+			Class:  t.class,
+			State:  [...]string{"completed", "stopped", "skipped"}[t.state],
+			Start:  t.start,
+			End:    t.end,
+			Time:   t.result.Time,
+			Mops:   t.result.Mops,
+			Norm:   t.norm,
+		}
+		for _, pred := range t.in {
+			tk.Inputs = append(tk.Inputs, pred.name)
+		}
+		if t.state == COMPLETED {
+			completed++
+			tk.Verified = !t.result.Failed() && math.Abs(t.norm-b.verify[i]) <= EPSILON*math.Abs(b.verify[i])
+		}
+		verified = verified && tk.Verified
+		ops += t.result.Mops * t.result.Time
+		if t.state != SKIPPED {
+			timers = append(timers, common.Timer{Name: t.name, Seconds: t.end - t.start})
+		}
+		tasks[i] = tk
+	}
+	incomplete := completed < len(tasks)
+	b.report(tasks, elapsed, incomplete)
+
+	mops := 0.0
+	if elapsed > 0 {
+		mops = ops / elapsed
+	}
+	result := common.Result{
+		Kernel:      "NGB",
+		Class:       b.class,
+		Size:        [3]int{len(tasks), 0, 0},
+		Iterations:  completed,
+		Time:        elapsed,
+		Mops:        mops,
+		OpType:      "kernel operations",
+		Verified:    verified && !incomplete,
+		Incomplete:  incomplete,
+		Workers:     b.numWorkers,
+		Timers:      timers,
+		NPBVersion:  "4.1",
+		CompileTime: "Unknown",
+		Compiler:    "Go",
+	}
+	common.Finish(&result, b.out)
+	res := Result{Result: result, Graph: b.graph.name, Tasks: tasks}
+	if incomplete {
+		return res, &common.IncompleteError{Completed: completed, Planned: len(tasks), Err: ctx.Err()}
+	}
+	return res, nil
+}
+
+// runTask runs the kernel of t, whose predecessors are done, on their
+// outputs. t is skipped when one of them did not complete or ctx is done.
+func (b *NGBBenchmark) runTask(ctx, kctx context.Context, t *task) error {
+	t.state = SKIPPED
+	for _, pred := range t.in {
+		if pred.state != COMPLETED {
+			return nil
+		}
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+	input := b.gather(t)
+	t.start = time.Since(b.begin).Seconds()
+	result, output, err := runKernel(kctx, t.kernel, t.class, b.numWorkers, input)
+	t.end = time.Since(b.begin).Seconds()
+	t.result = result
+
+	var incomplete *common.IncompleteError
+	switch {
+	case err == nil:
+		t.state = COMPLETED
+		t.norm = output.Norm()
+		if len(t.out) > 0 {
+			t.output = output
+		}
+	case errors.As(err, &incomplete):
+		t.state = STOPPED
+	case kctx.Err() != nil:
+		t.start, t.end = 0, 0 // stopped before the kernel began
+		return nil
+	default:
+		return err
+	}
+
+	b.mu.Lock()
+	fmt.Fprintf(b.out, " %-6s %-9s at %9.3f seconds\n", t.name, [...]string{"done", "stopped"}[t.state], t.end)
+	b.mu.Unlock()
+	return nil
+}
+
+// gather returns the input of t: its launch data when it has no
+// predecessors, the output of its only one, or the outputs of all of them
+// sampled to the size of the largest and averaged. Outputs no other task
+// needs are released.
+func (b *NGBBenchmark) gather(t *task) *common.Field {
+	if len(t.in) == 0 {
+		return t.launch
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	input := t.in[0].output
+	if len(t.in) > 1 {
+		largest := t.in[0].output
+		for _, pred := range t.in[1:] {
+			if len(pred.output.Data) > len(largest.Data) {
+				largest = pred.output
+			}
+		}
+		input = common.NewField(largest.NX, largest.NY, largest.NZ)
+		for _, pred := range t.in {
+			for i, v := range pred.output.Sample(len(input.Data)) {
+				input.Data[i] += v / float64(len(t.in))
+			}
+		}
+	}
+	for _, pred := range t.in {
+		if pred.users--; pred.users == 0 {
+			pred.output = nil
+		}
+	}
+	return input
+}
+
+// runKernel runs one kernel at class on input, nil for its standard problem,
+// and returns its result and output
+func runKernel(ctx context.Context, kernel, class string, workers int, input *common.Field) (common.Result, *common.Field, error) {
+	switch kernel {
+	case "EP":
+		p, _ := epparams.Lookup(class)
+		r, err := ep.Run(ctx, ep.Config{Params: p, Workers: workers, Input: input, Output: true})
+		return r.Result, r.Output, err
+	case "IS":
+		p, _ := isparams.Lookup(class)
+		r, err := is.Run(ctx, is.Config{Params: p, Workers: workers, Input: input, Output: true})
+		return r.Result, r.Output, err
+	case "CG":
+		p, _ := cgparams.Lookup(class)
+		r, err := cg.Run(ctx, cg.Config{Params: p, Workers: workers, Input: input, Output: true})
+		return r.Result, r.Output, err
+	case "MG":
+		p, _ := mgparams.Lookup(class)
+		r, err := mg.Run(ctx, mg.Config{Params: p, Workers: workers, Input: input, Output: true})
+		return r.Result, r.Output, err
+	case "FT":
+		p, _ := ftparams.Lookup(class)
+		r, err := ft.Run(ctx, ft.Config{Params: p, Workers: workers, Input: input, Output: true})
+		return r.Result, r.Output, err
+	}
+	return common.Result{}, nil, fmt.Errorf("unknown kernel %s", kernel)
+}
+
+// report prints the timings and output norms of the tasks and the end-to-end
+// time
+func (b *NGBBenchmark) report(tasks []Task, elapsed float64, incomplete bool) {
+	fmt.Fprintf(b.out, "\n Task   Kernel Class Inputs           Start      End   Kernel    Mop/s          Output norm  Verification\n")
+	for i, tk := range tasks {
+		inputs := strings.Join(tk.Inputs, ",")
+		if inputs == "" {
+			inputs = "-"
+		}
+		status := "SUCCESSFUL"
+		switch {
+		case tk.State != "completed":
+			status = strings.ToUpper(tk.State)
+		case !tk.Verified:
+			status = fmt.Sprintf("FAILED (want %.13e)", b.verify[i])
+		}
+		fmt.Fprintf(b.out, " %-6s %-6s %-5s %-14s %8.3f %8.3f %8.3f %8.2f %20.13e  %s\n",
+			tk.Name, tk.Kernel, tk.Class, inputs, tk.Start, tk.End, tk.Time, tk.Mops, tk.Norm, status)
+	}
+	fmt.Fprintf(b.out, "\n End-to-end time: %.3f seconds\n", elapsed)
+	if incomplete {
+		fmt.Fprintf(b.out, " Workflow stopped before all of its tasks completed\n")
+	}
+}
@@ -0,0 +1,150 @@
+package ngb
+
+import (
+	"context"
+	"flag"
+	"math"
+	"testing"
+
+	"github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/NGB/params"
+	"github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/common"
+	"github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/common/npbtest"
+)
+
+var long = flag.Bool("long", false, "also verify class A")
+
+// testClasses lists the classes the verification tests run
+func testClasses() []string {
+	if *long {
+		return []string{"S", "W", "A"}
+	}
+	return []string{"S", "W"}
+}
+
+func BenchmarkNGB(b *testing.B) {
+	for _, class := range []string{"S", "W"} {
+		for _, graph := range params.Graphs {
+			b.Run(class+"/"+graph, func(b *testing.B) {
+				p, _ := params.Lookup(class)
+				mops := 0.0
+				for i := 0; i < b.N; i++ {
+					result, err := Run(context.Background(), Config{Params: p, Graph: graph})
+					if err != nil {
+						b.Fatal(err)
+					}
+					if result.Failed() {
+						b.Fatalf("class %s graph %s failed verification", class, graph)
+					}
+					mops += result.Mops
+				}
+				b.ReportMetric(mops/float64(b.N), "Mop/s")
+			})
+		}
+	}
+}
+
+func TestVerification(t *testing.T) {
+	for _, class := range testClasses() {
+		for _, graph := range params.Graphs {
+			t.Run(class+"/"+graph, func(t *testing.T) {
+				p, _ := params.Lookup(class)
+				result, err := Run(context.Background(), Config{Params: p, Graph: graph})
+				if err != nil {
+					t.Fatal(err)
+				}
+				want, _ := p.VerifyValues(graph)
+				for i, tk := range result.Tasks {
+					if math.Abs(tk.Norm-want[i]) > EPSILON*want[i] {
+						t.Errorf("%s: output norm = %.13e, want %.13e", tk.Name, tk.Norm, want[i])
+					}
+				}
+				if !result.Verified {
+					t.Error("workflow reported a failed verification")
+				}
+			})
+		}
+	}
+}
+
+// TestGraphs checks the shape of the graphs and that their tasks are
+// topologically sorted
+func TestGraphs(t *testing.T) {
+	arcs := map[string]int{"ED": 0, "HC": 8, "VP": 12, "MB": 18}
+	for _, kind := range params.Graphs {
+		g, err := newGraph(kind, "W")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(g.tasks) != params.NUM_TASKS {
+			t.Errorf("%s: %d tasks, want %d", g.name, len(g.tasks), params.NUM_TASKS)
+		}
+		n := 0
+		for _, tk := range g.tasks {
+			for _, pred := range tk.in {
+				n++
+				if pred.id >= tk.id {
+					t.Errorf("%s: %s takes the output of %s", g.name, tk.name, pred.name)
+				}
+			}
+		}
+		if n != arcs[kind] {
+			t.Errorf("%s: %d arcs, want %d", g.name, n, arcs[kind])
+		}
+	}
+}
+
+func TestUnknownGraph(t *testing.T) {
+	p, _ := params.Lookup("S")
+	if _, err := Run(context.Background(), Config{Params: p, Graph: "XX"}); err == nil {
+		t.Error("graph XX was accepted")
+	}
+}
+
+// TestWorkers checks that the outputs do not depend on the number of
+// goroutines of the tasks
+func TestWorkers(t *testing.T) {
+	p, _ := params.Lookup("S")
+	want, _ := p.VerifyValues("MB")
+	for _, workers := range []int{1, 3} {
+		result, err := Run(context.Background(), Config{Params: p, Graph: "MB", Workers: workers})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, tk := range result.Tasks {
+			if math.Abs(tk.Norm-want[i]) > EPSILON*want[i] {
+				t.Errorf("%d workers: %s: output norm = %.13e, want %.13e", workers, tk.Name, tk.Norm, want[i])
+			}
+		}
+	}
+}
+
+// runClass runs the kernel at class for the checks every kernel shares
+func runClass(ctx context.Context, class string) (common.Result, error) {
+	p, _ := params.Lookup(class)
+	result, err := Run(ctx, Config{Params: p, Graph: "HC"})
+	return result.Result, err
+}
+
+// TestConcurrentRuns checks that runs of two classes in one process do not
+// share state
+func TestConcurrentRuns(t *testing.T) {
+	npbtest.ConcurrentRuns(t, runClass, "S", "W")
+}
+
+// TestStoppedRun checks a run stopped by its context. Run checks once before
+// starting and then before every task, which in the helical chain start one
+// after the other
+func TestStoppedRun(t *testing.T) {
+	var result Result
+	npbtest.StoppedRun(t, func(ctx context.Context, class string) (common.Result, error) {
+		p, _ := params.Lookup(class)
+		var err error
+		result, err = Run(ctx, Config{Params: p, Graph: "HC"})
+		return result.Result, err
+	}, "S", "tasks")
+	for i, tk := range result.Tasks {
+		if want := map[bool]string{true: "completed", false: "skipped"}[i < 2]; tk.State != want {
+			t.Errorf("%s is %s, want %s", tk.Name, tk.State, want)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package params
+
+import "strings"
+
+// NUM_TASKS is the number of tasks of every graph
+const NUM_TASKS = 9
+
+// Params holds the reference values of one NGB class, whose tasks run the
+// kernels at the class of the same name. The reference values are the norms
+// of the outputs of the tasks in task order, those of this implementation
+// (see package ngb).
+type Params struct {
+	CLASS string
+
+	ED_VERIFY_VALUES [NUM_TASKS]float64
+	HC_VERIFY_VALUES [NUM_TASKS]float64
+	VP_VERIFY_VALUES [NUM_TASKS]float64
+	MB_VERIFY_VALUES [NUM_TASKS]float64
+}
+
+// Classes lists the standard problem classes in increasing size
+var Classes = []string{"S", "W", "A"}
+
+// Graphs lists the workflows: embarrassingly distributed, helical chain,
+// visualization pipe and mixed bag
+var Graphs = []string{"ED", "HC", "VP", "MB"}
+
+var table = map[string]Params{
+	"S": {CLASS: "S",
+		ED_VERIFY_VALUES: [NUM_TASKS]float64{
+			1.0989630281824e+06, 1.0982198666479e+06, 1.0990661329880e+06,
+			1.0992900074293e+06, 1.0990374361157e+06, 1.0982751928127e+06,
+			1.0990539869736e+06, 1.0983811682151e+06, 1.0983811664130e+06},
+		HC_VERIFY_VALUES: [NUM_TASKS]float64{
+			9.7605651479298e-03, 4.7325429106738e+03, 2.1904854725514e-02,
+			2.4965693119002e-01, 9.3563329759999e+04, 2.1904854643607e-02,
+			2.4965691466380e-01, 9.3563323468756e+04, 2.1904854643607e-02},
+		VP_VERIFY_VALUES: [NUM_TASKS]float64{
+			9.7605651479298e-03, 4.7325429106738e+03, 1.0235062866211e+03,
+			4.6786759128081e-01, 5.1915881323529e+04, 1.3145150909424e+03,
+			6.1205658214049e+00, 2.8807586218545e+04, 1.4140566101074e+03},
+		MB_VERIFY_VALUES: [NUM_TASKS]float64{
+			2.1904854729392e-02, 2.1904854729392e-02, 2.1904854729392e-02,
+			2.4965693243558e-01, 2.4965693243558e-01, 2.4965693243558e-01,
+			9.3563330205918e+04, 9.3563330205918e+04, 9.3563330205918e+04},
+	},
+	"W": {CLASS: "W",
+		ED_VERIFY_VALUES: [NUM_TASKS]float64{
+			2.1970526528243e+06, 2.1963864498474e+06, 2.1973242757035e+06,
+			2.1979007663370e+06, 2.1969637062219e+06, 2.1968991155976e+06,
+			2.1968218254228e+06, 2.1968782945537e+06, 2.1961225600264e+06},
+		HC_VERIFY_VALUES: [NUM_TASKS]float64{
+			2.4267728668628e-03, 3.2803869529657e+03, 1.0224473621251e-02,
+			3.8745257697638e+00, 2.4121388315487e+05, 1.0224473621174e-02,
+			3.8745257689656e+00, 2.4121388313163e+05, 1.0224473621174e-02},
+		VP_VERIFY_VALUES: [NUM_TASKS]float64{
+			2.4267728668628e-03, 3.2803869529657e+03, 3.2767491350174e+04,
+			1.9077739224658e+00, 1.3199784390370e+05, 4.3572835348129e+04,
+			1.1493870215041e+02, 5.0484628416553e+04, 4.4261018460274e+04},
+		MB_VERIFY_VALUES: [NUM_TASKS]float64{
+			1.0224473621270e-02, 2.1904854729392e-02, 2.1904854729392e-02,
+			5.4250531148425e+00, 3.5771935661376e-01, 3.5771935661376e-01,
+			2.3829463727322e+05, 1.1860617618779e+05, 1.1860617618779e+05},
+	},
+	"A": {CLASS: "A",
+		ED_VERIFY_VALUES: [NUM_TASKS]float64{
+			1.7571094285635e+07, 1.7570724976258e+07, 1.7572338408045e+07,
+			1.7570299316646e+07, 1.7571336092372e+07, 1.7571907779234e+07,
+			1.7570188443778e+07, 1.7571337100414e+07, 1.7569751212585e+07},
+		HC_VERIFY_VALUES: [NUM_TASKS]float64{
+			9.5531628410391e-04, 1.9692360457636e+04, 7.6333471908790e-03,
+			1.4412049035556e+01, 4.4674834147958e+06, 7.6333471908762e-03,
+			1.4412049043274e+01, 4.4674834148398e+06, 7.6333471908762e-03},
+		VP_VERIFY_VALUES: [NUM_TASKS]float64{
+			9.5531628410391e-04, 1.9692360457636e+04, 2.6214342893898e+05,
+			2.9409996348500e+00, 1.5781685727558e+06, 3.5097125833714e+05,
+			3.7729064628044e+02, 9.9769275409309e+05, 3.9450397445667e+05},
+		MB_VERIFY_VALUES: [NUM_TASKS]float64{
+			7.6333471908795e-03, 1.0224473621270e-02, 2.1904854729392e-02,
+			2.2562669177285e+01, 5.6873015686054e+00, 3.8100995131075e-01,
+			4.4257576998463e+06, 2.7669022330907e+05, 1.3835023768320e+05},
+	},
+}
+
+// Lookup returns the parameters of the given class
+func Lookup(class string) (Params, bool) {
+	p, ok := table[strings.ToUpper(class)]
+	return p, ok
+}
+
+// Smaller returns the class below class, or class itself for the smallest
+func Smaller(class string) string {
+	for i, c := range Classes {
+		if c == class && i > 0 {
+			return Classes[i-1]
+		}
+	}
+	return class
+}
+
+// VerifyValues returns the reference output norms of the tasks of graph, one
+// of Graphs
+func (p Params) VerifyValues(graph string) ([NUM_TASKS]float64, bool) {
+	switch strings.ToUpper(graph) {
+	case "ED":
+		return p.ED_VERIFY_VALUES, true
+	case "HC":
+		return p.HC_VERIFY_VALUES, true
+	case "VP":
+		return p.VP_VERIFY_VALUES, true
+	case "MB":
+		return p.MB_VERIFY_VALUES, true
+	}
+	return [NUM_TASKS]float64{}, false
+}
@@ -0,0 +1,41 @@
+package params
+
+import "strings"
+
+// Params holds the zones, grid size, time step and reference values of one
+// SP-MZ class
+type Params struct {
+	CLASS                     string
+	X_ZONES, Y_ZONES          int // zones in x and y
+	GX_SIZE, GY_SIZE, GZ_SIZE int // grid points of the whole problem
+	NITER                     int // time steps
+	DT                        float64
+	XCR_REF                   [5]float64 // RMS norms of the residual
+	XCE_REF                   [5]float64 // RMS norms of the solution error
+}
+
+// Classes lists the problem classes in increasing size. The zone layouts and
+// grid sizes are those of NPB-MZ; the reference norms were computed with this
+// implementation, and the classes from C up are not provided.
+var Classes = []string{"S", "W", "A", "B"}
+
+var table = map[string]Params{
+	"S": {CLASS: "S", X_ZONES: 2, Y_ZONES: 2, GX_SIZE: 24, GY_SIZE: 24, GZ_SIZE: 6, NITER: 100, DT: 0.015,
+		XCR_REF: [5]float64{4.3959993543380e+02, 4.3133388082790e+01, 1.1377824025439e+02, 9.2617187203321e+01, 7.4312294906101e+02},
+		XCE_REF: [5]float64{1.9198836705940e+01, 1.3784858249494e+00, 4.5081090954955e+00, 3.4380279326240e+00, 3.6867783435538e+01}},
+	"W": {CLASS: "W", X_ZONES: 4, Y_ZONES: 4, GX_SIZE: 64, GY_SIZE: 64, GZ_SIZE: 8, NITER: 400, DT: 0.0015,
+		XCR_REF: [5]float64{4.1573499071861e+02, 2.9058718855707e+01, 9.9810348674732e+01, 8.3852235506130e+01, 8.3562426662593e+02},
+		XCE_REF: [5]float64{3.3048357819934e+01, 2.0868737317629e+00, 7.8543836459170e+00, 6.3372718814796e+00, 6.8598589343793e+01}},
+	"A": {CLASS: "A", X_ZONES: 4, Y_ZONES: 4, GX_SIZE: 128, GY_SIZE: 128, GZ_SIZE: 16, NITER: 400, DT: 0.0015,
+		XCR_REF: [5]float64{5.2308620254059e+03, 3.8243794778066e+02, 1.2564061035667e+03, 1.1136267601294e+03, 1.0224102317260e+04},
+		XCE_REF: [5]float64{1.0037795056896e+02, 6.5896365193661e+00, 2.3727454996788e+01, 1.9922717574786e+01, 2.0417644150099e+02}},
+	"B": {CLASS: "B", X_ZONES: 8, Y_ZONES: 8, GX_SIZE: 304, GY_SIZE: 208, GZ_SIZE: 17, NITER: 400, DT: 0.001,
+		XCR_REF: [5]float64{5.9754253064168e+04, 5.9231271578790e+03, 1.5427472727781e+04, 1.3056669798902e+04, 8.5420644724612e+04},
+		XCE_REF: [5]float64{2.9226608520569e+02, 2.0938503733899e+01, 7.0187602057439e+01, 6.1228277369567e+01, 5.8270958207166e+02}},
+}
+
+// Lookup returns the parameters of the given class
+func Lookup(class string) (Params, bool) {
+	p, ok := table[strings.ToUpper(class)]
+	return p, ok
+}
@@ -0,0 +1,105 @@
+package spmz
+
+import (
+	"context"
+	"flag"
+	"math"
+	"testing"
+
+	"github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/SP-MZ/params"
+	"github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/common"
+	"github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/common/npbtest"
+)
+
+var long = flag.Bool("long", false, "also verify class A")
+
+// testClasses lists the classes the verification tests run
+func testClasses() []string {
+	if *long {
+		return []string{"S", "W", "A"}
+	}
+	return []string{"S", "W"}
+}
+
+func BenchmarkSPMZ(b *testing.B) {
+	for _, class := range []string{"S", "W"} {
+		b.Run(class, func(b *testing.B) {
+			p, _ := params.Lookup(class)
+			mops := 0.0
+			for i := 0; i < b.N; i++ {
+				result, err := Run(context.Background(), Config{Params: p})
+				if err != nil {
+					b.Fatal(err)
+				}
+				if result.Failed() {
+					b.Fatalf("class %s failed verification", class)
+				}
+				mops += result.Mops
+			}
+			b.ReportMetric(mops/float64(b.N), "Mop/s")
+		})
+	}
+}
+
+func TestVerification(t *testing.T) {
+	for _, class := range testClasses() {
+		t.Run(class, func(t *testing.T) {
+			p, _ := params.Lookup(class)
+			result, err := Run(context.Background(), Config{Params: p})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for m := 0; m < 5; m++ {
+				if err := math.Abs(result.XCR[m]-p.XCR_REF[m]) / p.XCR_REF[m]; err > 1.0e-8 {
+					t.Errorf("xcr[%d] = %.13e, want %.13e", m, result.XCR[m], p.XCR_REF[m])
+				}
+				if err := math.Abs(result.XCE[m]-p.XCE_REF[m]) / p.XCE_REF[m]; err > 1.0e-8 {
+					t.Errorf("xce[%d] = %.13e, want %.13e", m, result.XCE[m], p.XCE_REF[m])
+				}
+			}
+			if !result.Verified {
+				t.Error("kernel reported a failed verification")
+			}
+		})
+	}
+}
+
+// TestZoneGroups checks that the norms do not depend on how the zones and
+// the loops are split across goroutines
+func TestZoneGroups(t *testing.T) {
+	p, _ := params.Lookup("S")
+	for _, cfg := range []Config{
+		{Params: p, Workers: 1, ZoneGroups: 1},
+		{Params: p, Workers: 4, ZoneGroups: 1},
+		{Params: p, Workers: 4, ZoneGroups: 4},
+		{Params: p, Workers: 6, ZoneGroups: 3},
+	} {
+		result, err := Run(context.Background(), cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.ZoneGroups != cfg.ZoneGroups || result.Workers != cfg.Workers || !result.Verified {
+			t.Errorf("%d workers in %d groups: got %d workers in %d groups, verified %v",
+				cfg.Workers, cfg.ZoneGroups, result.Workers, result.ZoneGroups, result.Verified)
+		}
+	}
+}
+
+// runClass runs the kernel at class for the checks every kernel shares
+func runClass(ctx context.Context, class string) (common.Result, error) {
+	p, _ := params.Lookup(class)
+	result, err := Run(ctx, Config{Params: p})
+	return result.Result, err
+}
+
+// TestConcurrentRuns checks that runs of two classes in one process do not
+// share state
+func TestConcurrentRuns(t *testing.T) {
+	npbtest.ConcurrentRuns(t, runClass, "S", "W")
+}
+
+// TestStoppedRun checks a run stopped by its context. Run checks once before
+// starting and then before every time step
+func TestStoppedRun(t *testing.T) {
+	npbtest.StoppedRun(t, runClass, "S", "time steps")
+}
@@ -0,0 +1,1275 @@
+// Package sp is the SP (Scalar Penta-diagonal) pseudo-application: it solves
+// the 3D compressible Navier-Stokes equations with an ADI scheme whose
+// factors are diagonalized into independent scalar pentadiagonal systems.
+package sp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"os"
+
+	"github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/SP/params"
+	"github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/common"
+)
+
+// Timers of the timer.flag section breakdown
+const (
+	T_TOTAL = iota + 1
+	T_RHS
+	T_TXINVR
+	T_XSOLVE
+	T_YSOLVE
+	T_ZSOLVE
+	T_ADD
+	T_LAST = T_ADD
+)
+
+// Config selects the problem a Run solves
+type Config struct {
+	Params   params.Params   // problem size, from params.Lookup
+	Workers  int             // goroutines; 0 means $GO_NUM_THREADS, or one per CPU
+	Schedule common.Schedule // of the loops over grid planes; none means $GO_SCHEDULE
+	Bind     common.Binding  // of the workers to cores; none means $GO_PROC_BIND
+	Out      io.Writer       // progress messages and the NPB banner, discarded when nil
+}
+
+// Result is the outcome of an SP run with the norms it is verified on
+type Result struct {
+	common.Result
+	XCR [5]float64 // RMS norms of the residual
+	XCE [5]float64 // RMS norms of the solution error
+}
+
+// Run runs the benchmark on the grid of cfg.Params and returns its result.
+// When ctx is done between two time steps the run stops there and Run returns
+// the partial result with a *common.IncompleteError.
+func Run(ctx context.Context, cfg Config) (Result, error) {
+	if err := ctx.Err(); err != nil {
+		return Result{}, err
+	}
+	workers, err := common.ResolveWorkers(cfg.Workers)
+	if err != nil {
+		return Result{}, err
+	}
+	out := cfg.Out
+	if out == nil {
+		out = io.Discard
+	}
+	sched, err := common.ResolveSchedule(cfg.Schedule)
+	if err != nil {
+		return Result{}, err
+	}
+	bind, err := common.ResolveBinding(cfg.Bind)
+	if err != nil {
+		return Result{}, err
+	}
+	sp := NewSPBenchmark(cfg.Params, workers, out)
+	sp.schedule = sched
+	sp.bind = bind
+	return sp.run(ctx)
+}
+
+// SPBenchmark represents the SP benchmark
+type SPBenchmark struct {
+	nx, ny, nz int
+	niter      int
+	dt         float64
+	class      string
+	xcrRef     [5]float64
+	xceRef     [5]float64
+
+	// Size of the whole grid in x and y and position of this grid in it,
+	// which differ from nx, ny and 0 only for the zones of a multi-zone
+	// problem
+	gx, gy int
+	x0, y0 int
+
+	// Conserved variables, right hand side and forcing term at every point
+	u, rhs, forcing []([5]float64)
+	// Quantities derived from u by computeRHS and used by the solvers
+	us, vs, ws, qs, rhoI, square, speed []float64
+
+	// Line solver scratch space of each worker
+	solvers []lineSolver
+
+	// Coefficients of the exact solution
+	ce [5][13]float64
+
+	c1, c2, c3, c4, c5         float64
+	dnxm1, dnym1, dnzm1        float64
+	c1c2, c1c5, c3c4, c1345    float64
+	conz1, con43, con16        float64
+	tx1, tx2, tx3              float64
+	ty1, ty2, ty3              float64
+	tz1, tz2, tz3              float64
+	dx, dy, dz                 [5]float64
+	dxtx1, dyty1, dztz1        [5]float64
+	dssp                       float64
+	c3c4tx3, c3c4ty3, c3c4tz3  float64
+	xxcon1, xxcon2, xxcon3     float64
+	xxcon4, xxcon5             float64
+	yycon1, yycon2, yycon3     float64
+	yycon4, yycon5             float64
+	zzcon1, zzcon2, zzcon3     float64
+	zzcon4, zzcon5             float64
+	bt, c2iv                   float64
+	comz1, comz4, comz5, comz6 float64
+
+	numWorkers    int
+	schedule      common.Schedule
+	bind          common.Binding
+	team          *common.Team // started by run, or given to NewZone
+	timersEnabled bool
+	timers        common.Timers
+	out           io.Writer
+}
+
+// lineSolver holds the pentadiagonal systems of the grid line a worker is
+// solving, with the velocity and dissipation coefficients they are formed
+// from
+type lineSolver struct {
+	lhs, lhsp, lhsm [][5]float64
+	cv, rho         []float64
+}
+
+// NewSPBenchmark creates an SP benchmark for the given class parameters
+// running on numWorkers goroutines
+func NewSPBenchmark(p params.Params, numWorkers int, out io.Writer) *SPBenchmark {
+	n := p.PROBLEM_SIZE
+	sp := &SPBenchmark{
+		nx:         n,
+		ny:         n,
+		nz:         n,
+		gx:         n,
+		gy:         n,
+		niter:      p.NITER,
+		dt:         p.DT,
+		class:      p.CLASS,
+		xcrRef:     p.XCR_REF,
+		xceRef:     p.XCE_REF,
+		numWorkers: numWorkers,
+		out:        out,
+	}
+	sp.setConstants()
+	return sp
+}
+
+// idx returns the position of grid point (i, j, k) in the flat arrays
+func (sp *SPBenchmark) idx(k, j, i int) int {
+	return (k*sp.ny+j)*sp.nx + i
+}
+
+// setConstants computes the coefficients of the exact solution and the
+// constants of the discretization
+func (sp *SPBenchmark) setConstants() {
+	sp.ce = [5][13]float64{
+		{2.0, 0.0, 0.0, 4.0, 5.0, 3.0, 0.5, 0.02, 0.01, 0.03, 0.5, 0.4, 0.3},
+		{1.0, 0.0, 0.0, 0.0, 1.0, 2.0, 3.0, 0.01, 0.03, 0.02, 0.4, 0.3, 0.5},
+		{2.0, 2.0, 0.0, 0.0, 0.0, 2.0, 3.0, 0.04, 0.03, 0.05, 0.3, 0.5, 0.4},
+		{2.0, 2.0, 0.0, 0.0, 0.0, 2.0, 3.0, 0.03, 0.05, 0.04, 0.2, 0.1, 0.3},
+		{5.0, 4.0, 3.0, 2.0, 0.1, 0.4, 0.3, 0.05, 0.04, 0.03, 0.1, 0.3, 0.2},
+	}
+
+	sp.c1 = 1.4
+	sp.c2 = 0.4
+	sp.c3 = 0.1
+	sp.c4 = 1.0
+	sp.c5 = 1.4
+
+	sp.dnxm1 = 1.0 / float64(sp.gx-1)
+	sp.dnym1 = 1.0 / float64(sp.gy-1)
+	sp.dnzm1 = 1.0 / float64(sp.nz-1)
+
+	sp.c1c2 = sp.c1 * sp.c2
+	sp.c1c5 = sp.c1 * sp.c5
+	sp.c3c4 = sp.c3 * sp.c4
+	sp.c1345 = sp.c1c5 * sp.c3c4
+
+	sp.conz1 = 1.0 - sp.c1c5
+
+	sp.tx1 = 1.0 / (sp.dnxm1 * sp.dnxm1)
+	sp.tx2 = 1.0 / (2.0 * sp.dnxm1)
+	sp.tx3 = 1.0 / sp.dnxm1
+
+	sp.ty1 = 1.0 / (sp.dnym1 * sp.dnym1)
+	sp.ty2 = 1.0 / (2.0 * sp.dnym1)
+	sp.ty3 = 1.0 / sp.dnym1
+
+	sp.tz1 = 1.0 / (sp.dnzm1 * sp.dnzm1)
+	sp.tz2 = 1.0 / (2.0 * sp.dnzm1)
+	sp.tz3 = 1.0 / sp.dnzm1
+
+	sp.dx = [5]float64{0.75, 0.75, 0.75, 0.75, 0.75}
+	sp.dy = [5]float64{0.75, 0.75, 0.75, 0.75, 0.75}
+	sp.dz = [5]float64{1.0, 1.0, 1.0, 1.0, 1.0}
+
+	sp.dssp = 0.25 * math.Max(sp.dx[0], math.Max(sp.dy[0], sp.dz[0]))
+
+	sp.c3c4tx3 = sp.c3c4 * sp.tx3
+	sp.c3c4ty3 = sp.c3c4 * sp.ty3
+	sp.c3c4tz3 = sp.c3c4 * sp.tz3
+
+	for m := 0; m < 5; m++ {
+		sp.dxtx1[m] = sp.dx[m] * sp.tx1
+		sp.dyty1[m] = sp.dy[m] * sp.ty1
+		sp.dztz1[m] = sp.dz[m] * sp.tz1
+	}
+
+	sp.con43 = 4.0 / 3.0
+	sp.con16 = 1.0 / 6.0
+
+	sp.xxcon1 = sp.c3c4tx3 * sp.con43 * sp.tx3
+	sp.xxcon2 = sp.c3c4tx3 * sp.tx3
+	sp.xxcon3 = sp.c3c4tx3 * sp.conz1 * sp.tx3
+	sp.xxcon4 = sp.c3c4tx3 * sp.con16 * sp.tx3
+	sp.xxcon5 = sp.c3c4tx3 * sp.c1c5 * sp.tx3
+
+	sp.yycon1 = sp.c3c4ty3 * sp.con43 * sp.ty3
+	sp.yycon2 = sp.c3c4ty3 * sp.ty3
+	sp.yycon3 = sp.c3c4ty3 * sp.conz1 * sp.ty3
+	sp.yycon4 = sp.c3c4ty3 * sp.con16 * sp.ty3
+	sp.yycon5 = sp.c3c4ty3 * sp.c1c5 * sp.ty3
+
+	sp.zzcon1 = sp.c3c4tz3 * sp.con43 * sp.tz3
+	sp.zzcon2 = sp.c3c4tz3 * sp.tz3
+	sp.zzcon3 = sp.c3c4tz3 * sp.conz1 * sp.tz3
+	sp.zzcon4 = sp.c3c4tz3 * sp.con16 * sp.tz3
+	sp.zzcon5 = sp.c3c4tz3 * sp.c1c5 * sp.tz3
+
+	sp.bt = math.Sqrt(0.5)
+	sp.c2iv = 2.5
+
+	dtdssp := sp.dt * sp.dssp
+	sp.comz1 = dtdssp
+	sp.comz4 = 4.0 * dtdssp
+	sp.comz5 = 5.0 * dtdssp
+	sp.comz6 = 6.0 * dtdssp
+}
+
+// exactSolution returns the exact solution at point (xi, eta, zeta)
+func (sp *SPBenchmark) exactSolution(xi, eta, zeta float64) [5]float64 {
+	var dtemp [5]float64
+	for m := 0; m < 5; m++ {
+		ce := &sp.ce[m]
+		dtemp[m] = ce[0] +
+			xi*(ce[1]+xi*(ce[4]+xi*(ce[7]+xi*ce[10]))) +
+			eta*(ce[2]+eta*(ce[5]+eta*(ce[8]+eta*ce[11]))) +
+			zeta*(ce[3]+zeta*(ce[6]+zeta*(ce[9]+zeta*ce[12])))
+	}
+	return dtemp
+}
+
+// initialize sets u to a transfinite interpolation of the boundary values of
+// the exact solution, and u to the exact solution on the boundaries
+func (sp *SPBenchmark) initialize() {
+	for p := range sp.u {
+		sp.u[p] = [5]float64{1.0, 1.0, 1.0, 1.0, 1.0}
+	}
+
+	var pface [2][3][5]float64
+	for k := 0; k < sp.nz; k++ {
+		zeta := float64(k) * sp.dnzm1
+		for j := 0; j < sp.ny; j++ {
+			eta := float64(sp.y0+j) * sp.dnym1
+			for i := 0; i < sp.nx; i++ {
+				xi := float64(sp.x0+i) * sp.dnxm1
+				for ix := 0; ix < 2; ix++ {
+					pface[ix][0] = sp.exactSolution(float64(ix), eta, zeta)
+				}
+				for iy := 0; iy < 2; iy++ {
+					pface[iy][1] = sp.exactSolution(xi, float64(iy), zeta)
+				}
+				for iz := 0; iz < 2; iz++ {
+					pface[iz][2] = sp.exactSolution(xi, eta, float64(iz))
+				}
+				u := &sp.u[sp.idx(k, j, i)]
+				for m := 0; m < 5; m++ {
+					pxi := xi*pface[1][0][m] + (1.0-xi)*pface[0][0][m]
+					peta := eta*pface[1][1][m] + (1.0-eta)*pface[0][1][m]
+					pzeta := zeta*pface[1][2][m] + (1.0-zeta)*pface[0][2][m]
+					u[m] = pxi + peta + pzeta - pxi*peta - pxi*pzeta - peta*pzeta + pxi*peta*pzeta
+				}
+			}
+		}
+	}
+
+	// West and east faces
+	for k := 0; k < sp.nz; k++ {
+		zeta := float64(k) * sp.dnzm1
+		for j := 0; j < sp.ny; j++ {
+			eta := float64(sp.y0+j) * sp.dnym1
+			sp.u[sp.idx(k, j, 0)] = sp.exactSolution(0.0, eta, zeta)
+			sp.u[sp.idx(k, j, sp.nx-1)] = sp.exactSolution(1.0, eta, zeta)
+		}
+	}
+
+	// South and north faces
+	for k := 0; k < sp.nz; k++ {
+		zeta := float64(k) * sp.dnzm1
+		for i := 0; i < sp.nx; i++ {
+			xi := float64(sp.x0+i) * sp.dnxm1
+			sp.u[sp.idx(k, 0, i)] = sp.exactSolution(xi, 0.0, zeta)
+			sp.u[sp.idx(k, sp.ny-1, i)] = sp.exactSolution(xi, 1.0, zeta)
+		}
+	}
+
+	// Bottom and top faces
+	for j := 0; j < sp.ny; j++ {
+		eta := float64(sp.y0+j) * sp.dnym1
+		for i := 0; i < sp.nx; i++ {
+			xi := float64(sp.x0+i) * sp.dnxm1
+			sp.u[sp.idx(0, j, i)] = sp.exactSolution(xi, eta, 0.0)
+			sp.u[sp.idx(sp.nz-1, j, i)] = sp.exactSolution(xi, eta, 1.0)
+		}
+	}
+}
+
+// dissipation subtracts the fourth-order dissipation of the n points of a
+// grid line of v, starting at v0 with stride vs, from the interior points of
+// the same line of dst, starting at d0 with stride ds
+func dissipation(dst [][5]float64, d0, ds int, v [][5]float64, v0, vs, n int, dssp float64) {
+	for m := 0; m < 5; m++ {
+		d := d0 + ds
+		i := v0 + vs
+		dst[d][m] -= dssp * (5.0*v[i][m] - 4.0*v[i+vs][m] + v[i+2*vs][m])
+		d += ds
+		i += vs
+		dst[d][m] -= dssp * (-4.0*v[i-vs][m] + 6.0*v[i][m] - 4.0*v[i+vs][m] + v[i+2*vs][m])
+	}
+	for l := 3; l < n-3; l++ {
+		d := d0 + l*ds
+		i := v0 + l*vs
+		for m := 0; m < 5; m++ {
+			dst[d][m] -= dssp * (v[i-2*vs][m] - 4.0*v[i-vs][m] + 6.0*v[i][m] - 4.0*v[i+vs][m] + v[i+2*vs][m])
+		}
+	}
+	for m := 0; m < 5; m++ {
+		d := d0 + (n-3)*ds
+		i := v0 + (n-3)*vs
+		dst[d][m] -= dssp * (v[i-2*vs][m] - 4.0*v[i-vs][m] + 6.0*v[i][m] - 4.0*v[i+vs][m])
+		d += ds
+		i += vs
+		dst[d][m] -= dssp * (v[i-2*vs][m] - 4.0*v[i-vs][m] + 5.0*v[i][m])
+	}
+}
+
+// exactRHS computes the forcing term that makes the exact solution a
+// solution of the discretized equations
+func (sp *SPBenchmark) exactRHS() {
+	maxdim := max(sp.nx, sp.ny, sp.nz)
+	ue := make([][5]float64, maxdim)
+	buf := make([][5]float64, maxdim)
+	cuf := make([]float64, maxdim)
+	q := make([]float64, maxdim)
+
+	for p := range sp.forcing {
+		sp.forcing[p] = [5]float64{}
+	}
+
+	// line fills ue, buf, cuf and q along one grid line, with dir the
+	// direction whose velocity goes to cuf
+	line := func(n, dir int, at func(l int) (float64, float64, float64)) {
+		for l := 0; l < n; l++ {
+			ue[l] = sp.exactSolution(at(l))
+			dtpp := 1.0 / ue[l][0]
+			for m := 1; m < 5; m++ {
+				buf[l][m] = dtpp * ue[l][m]
+			}
+			cuf[l] = buf[l][dir] * buf[l][dir]
+			buf[l][0] = buf[l][1]*buf[l][1] + buf[l][2]*buf[l][2] + buf[l][3]*buf[l][3]
+			q[l] = 0.5 * (buf[l][1]*ue[l][1] + buf[l][2]*ue[l][2] + buf[l][3]*ue[l][3])
+		}
+	}
+
+	// xi-direction flux differences
+	for k := 1; k < sp.nz-1; k++ {
+		zeta := float64(k) * sp.dnzm1
+		for j := 1; j < sp.ny-1; j++ {
+			eta := float64(sp.y0+j) * sp.dnym1
+			line(sp.nx, 1, func(i int) (float64, float64, float64) { return float64(sp.x0+i) * sp.dnxm1, eta, zeta })
+			for i := 1; i < sp.nx-1; i++ {
+				im1, ip1 := i-1, i+1
+				f := &sp.forcing[sp.idx(k, j, i)]
+				f[0] = f[0] - sp.tx2*(ue[ip1][1]-ue[im1][1]) +
+					sp.dxtx1[0]*(ue[ip1][0]-2.0*ue[i][0]+ue[im1][0])
+				f[1] = f[1] - sp.tx2*((ue[ip1][1]*buf[ip1][1]+sp.c2*(ue[ip1][4]-q[ip1]))-
+					(ue[im1][1]*buf[im1][1]+sp.c2*(ue[im1][4]-q[im1]))) +
+					sp.xxcon1*(buf[ip1][1]-2.0*buf[i][1]+buf[im1][1]) +
+					sp.dxtx1[1]*(ue[ip1][1]-2.0*ue[i][1]+ue[im1][1])
+				f[2] = f[2] - sp.tx2*(ue[ip1][2]*buf[ip1][1]-ue[im1][2]*buf[im1][1]) +
+					sp.xxcon2*(buf[ip1][2]-2.0*buf[i][2]+buf[im1][2]) +
+					sp.dxtx1[2]*(ue[ip1][2]-2.0*ue[i][2]+ue[im1][2])
+				f[3] = f[3] - sp.tx2*(ue[ip1][3]*buf[ip1][1]-ue[im1][3]*buf[im1][1]) +
+					sp.xxcon2*(buf[ip1][3]-2.0*buf[i][3]+buf[im1][3]) +
+					sp.dxtx1[3]*(ue[ip1][3]-2.0*ue[i][3]+ue[im1][3])
+				f[4] = f[4] - sp.tx2*(buf[ip1][1]*(sp.c1*ue[ip1][4]-sp.c2*q[ip1])-
+					buf[im1][1]*(sp.c1*ue[im1][4]-sp.c2*q[im1])) +
+					0.5*sp.xxcon3*(buf[ip1][0]-2.0*buf[i][0]+buf[im1][0]) +
+					sp.xxcon4*(cuf[ip1]-2.0*cuf[i]+cuf[im1]) +
+					sp.xxcon5*(buf[ip1][4]-2.0*buf[i][4]+buf[im1][4]) +
+					sp.dxtx1[4]*(ue[ip1][4]-2.0*ue[i][4]+ue[im1][4])
+			}
+			dissipation(sp.forcing, sp.idx(k, j, 0), 1, ue, 0, 1, sp.nx, sp.dssp)
+		}
+	}
+
+	// eta-direction flux differences
+	for k := 1; k < sp.nz-1; k++ {
+		zeta := float64(k) * sp.dnzm1
+		for i := 1; i < sp.nx-1; i++ {
+			xi := float64(sp.x0+i) * sp.dnxm1
+			line(sp.ny, 2, func(j int) (float64, float64, float64) { return xi, float64(sp.y0+j) * sp.dnym1, zeta })
+			for j := 1; j < sp.ny-1; j++ {
+				jm1, jp1 := j-1, j+1
+				f := &sp.forcing[sp.idx(k, j, i)]
+				f[0] = f[0] - sp.ty2*(ue[jp1][2]-ue[jm1][2]) +
+					sp.dyty1[0]*(ue[jp1][0]-2.0*ue[j][0]+ue[jm1][0])
+				f[1] = f[1] - sp.ty2*(ue[jp1][1]*buf[jp1][2]-ue[jm1][1]*buf[jm1][2]) +
+					sp.yycon2*(buf[jp1][1]-2.0*buf[j][1]+buf[jm1][1]) +
+					sp.dyty1[1]*(ue[jp1][1]-2.0*ue[j][1]+ue[jm1][1])
+				f[2] = f[2] - sp.ty2*((ue[jp1][2]*buf[jp1][2]+sp.c2*(ue[jp1][4]-q[jp1]))-
+					(ue[jm1][2]*buf[jm1][2]+sp.c2*(ue[jm1][4]-q[jm1]))) +
+					sp.yycon1*(buf[jp1][2]-2.0*buf[j][2]+buf[jm1][2]) +
+					sp.dyty1[2]*(ue[jp1][2]-2.0*ue[j][2]+ue[jm1][2])
+				f[3] = f[3] - sp.ty2*(ue[jp1][3]*buf[jp1][2]-ue[jm1][3]*buf[jm1][2]) +
+					sp.yycon2*(buf[jp1][3]-2.0*buf[j][3]+buf[jm1][3]) +
+					sp.dyty1[3]*(ue[jp1][3]-2.0*ue[j][3]+ue[jm1][3])
+				f[4] = f[4] - sp.ty2*(buf[jp1][2]*(sp.c1*ue[jp1][4]-sp.c2*q[jp1])-
+					buf[jm1][2]*(sp.c1*ue[jm1][4]-sp.c2*q[jm1])) +
+					0.5*sp.yycon3*(buf[jp1][0]-2.0*buf[j][0]+buf[jm1][0]) +
+					sp.yycon4*(cuf[jp1]-2.0*cuf[j]+cuf[jm1]) +
+					sp.yycon5*(buf[jp1][4]-2.0*buf[j][4]+buf[jm1][4]) +
+					sp.dyty1[4]*(ue[jp1][4]-2.0*ue[j][4]+ue[jm1][4])
+			}
+			dissipation(sp.forcing, sp.idx(k, 0, i), sp.nx, ue, 0, 1, sp.ny, sp.dssp)
+		}
+	}
+
+	// zeta-direction flux differences
+	for j := 1; j < sp.ny-1; j++ {
+		eta := float64(sp.y0+j) * sp.dnym1
+		for i := 1; i < sp.nx-1; i++ {
+			xi := float64(sp.x0+i) * sp.dnxm1
+			line(sp.nz, 3, func(k int) (float64, float64, float64) { return xi, eta, float64(k) * sp.dnzm1 })
+			for k := 1; k < sp.nz-1; k++ {
+				km1, kp1 := k-1, k+1
+				f := &sp.forcing[sp.idx(k, j, i)]
+				f[0] = f[0] - sp.tz2*(ue[kp1][3]-ue[km1][3]) +
+					sp.dztz1[0]*(ue[kp1][0]-2.0*ue[k][0]+ue[km1][0])
+				f[1] = f[1] - sp.tz2*(ue[kp1][1]*buf[kp1][3]-ue[km1][1]*buf[km1][3]) +
+					sp.zzcon2*(buf[kp1][1]-2.0*buf[k][1]+buf[km1][1]) +
+					sp.dztz1[1]*(ue[kp1][1]-2.0*ue[k][1]+ue[km1][1])
+				f[2] = f[2] - sp.tz2*(ue[kp1][2]*buf[kp1][3]-ue[km1][2]*buf[km1][3]) +
+					sp.zzcon2*(buf[kp1][2]-2.0*buf[k][2]+buf[km1][2]) +
+					sp.dztz1[2]*(ue[kp1][2]-2.0*ue[k][2]+ue[km1][2])
+				f[3] = f[3] - sp.tz2*((ue[kp1][3]*buf[kp1][3]+sp.c2*(ue[kp1][4]-q[kp1]))-
+					(ue[km1][3]*buf[km1][3]+sp.c2*(ue[km1][4]-q[km1]))) +
+					sp.zzcon1*(buf[kp1][3]-2.0*buf[k][3]+buf[km1][3]) +
+					sp.dztz1[3]*(ue[kp1][3]-2.0*ue[k][3]+ue[km1][3])
+				f[4] = f[4] - sp.tz2*(buf[kp1][3]*(sp.c1*ue[kp1][4]-sp.c2*q[kp1])-
+					buf[km1][3]*(sp.c1*ue[km1][4]-sp.c2*q[km1])) +
+					0.5*sp.zzcon3*(buf[kp1][0]-2.0*buf[k][0]+buf[km1][0]) +
+					sp.zzcon4*(cuf[kp1]-2.0*cuf[k]+cuf[km1]) +
+					sp.zzcon5*(buf[kp1][4]-2.0*buf[k][4]+buf[km1][4]) +
+					sp.dztz1[4]*(ue[kp1][4]-2.0*ue[k][4]+ue[km1][4])
+			}
+			dissipation(sp.forcing, sp.idx(0, j, i), sp.nx*sp.ny, ue, 0, 1, sp.nz, sp.dssp)
+		}
+	}
+
+	// Now change the sign of the forcing function
+	for k := 1; k < sp.nz-1; k++ {
+		for j := 1; j < sp.ny-1; j++ {
+			for i := 1; i < sp.nx-1; i++ {
+				f := &sp.forcing[sp.idx(k, j, i)]
+				for m := 0; m < 5; m++ {
+					f[m] = -1.0 * f[m]
+				}
+			}
+		}
+	}
+}
+
+// computeRHS computes the right hand side of the equations from u
+func (sp *SPBenchmark) computeRHS() {
+	if sp.timersEnabled {
+		sp.timers.Start(T_RHS)
+	}
+	u, rhs := sp.u, sp.rhs
+	us, vs, ws, qs, rhoI, square := sp.us, sp.vs, sp.ws, sp.qs, sp.rhoI, sp.square
+
+	// Compute the reciprocal of density, and the kinetic energy and the
+	// speed of sound
+	plane := sp.nx * sp.ny
+	sp.team.For(0, sp.nz, func(k0, k1, _ int) {
+		for p := k0 * plane; p < k1*plane; p++ {
+			rhoInv := 1.0 / u[p][0]
+			rhoI[p] = rhoInv
+			us[p] = u[p][1] * rhoInv
+			vs[p] = u[p][2] * rhoInv
+			ws[p] = u[p][3] * rhoInv
+			square[p] = 0.5 * (u[p][1]*u[p][1] + u[p][2]*u[p][2] + u[p][3]*u[p][3]) * rhoInv
+			qs[p] = square[p] * rhoInv
+			sp.speed[p] = math.Sqrt(sp.c1c2 * rhoInv * (u[p][4] - square[p]))
+		}
+
+		// Copy the exact forcing term to the right hand side; the
+		// boundaries keep it as is
+		copy(rhs[k0*plane:k1*plane], sp.forcing[k0*plane:k1*plane])
+	})
+
+	// xi-direction fluxes
+	sp.team.For(1, sp.nz-1, func(k0, k1, _ int) { sp.xFluxes(k0, k1) })
+	// eta-direction fluxes
+	sp.team.For(1, sp.nz-1, func(k0, k1, _ int) { sp.yFluxes(k0, k1) })
+	// zeta-direction fluxes
+	sp.team.For(1, sp.nz-1, func(k0, k1, _ int) { sp.zFluxes(k0, k1) })
+	sp.team.For(1, sp.ny-1, func(j0, j1, _ int) { sp.zDissipation(j0, j1) })
+
+	sp.team.For(1, sp.nz-1, func(k0, k1, _ int) {
+		for k := k0; k < k1; k++ {
+			for j := 1; j < sp.ny-1; j++ {
+				for i := 1; i < sp.nx-1; i++ {
+					r := &rhs[sp.idx(k, j, i)]
+					for m := 0; m < 5; m++ {
+						r[m] = r[m] * sp.dt
+					}
+				}
+			}
+		}
+	})
+	if sp.timersEnabled {
+		sp.timers.Stop(T_RHS)
+	}
+}
+
+// xFluxes adds the xi-direction fluxes and dissipation to rhs in the planes
+// k0..k1-1
+func (sp *SPBenchmark) xFluxes(k0, k1 int) {
+	u, rhs := sp.u, sp.rhs
+	us, vs, ws, qs, rhoI, square := sp.us, sp.vs, sp.ws, sp.qs, sp.rhoI, sp.square
+	c1, c2, dssp := sp.c1, sp.c2, sp.dssp
+	for k := k0; k < k1; k++ {
+		for j := 1; j < sp.ny-1; j++ {
+			for i := 1; i < sp.nx-1; i++ {
+				p := sp.idx(k, j, i)
+				pp, pm := p+1, p-1
+				uijk, up1, um1 := us[p], us[pp], us[pm]
+				r := &rhs[p]
+				r[0] = r[0] + sp.dxtx1[0]*(u[pp][0]-2.0*u[p][0]+u[pm][0]) -
+					sp.tx2*(u[pp][1]-u[pm][1])
+				r[1] = r[1] + sp.dxtx1[1]*(u[pp][1]-2.0*u[p][1]+u[pm][1]) +
+					sp.xxcon2*sp.con43*(up1-2.0*uijk+um1) -
+					sp.tx2*(u[pp][1]*up1-u[pm][1]*um1+(u[pp][4]-square[pp]-u[pm][4]+square[pm])*c2)
+				r[2] = r[2] + sp.dxtx1[2]*(u[pp][2]-2.0*u[p][2]+u[pm][2]) +
+					sp.xxcon2*(vs[pp]-2.0*vs[p]+vs[pm]) -
+					sp.tx2*(u[pp][2]*up1-u[pm][2]*um1)
+				r[3] = r[3] + sp.dxtx1[3]*(u[pp][3]-2.0*u[p][3]+u[pm][3]) +
+					sp.xxcon2*(ws[pp]-2.0*ws[p]+ws[pm]) -
+					sp.tx2*(u[pp][3]*up1-u[pm][3]*um1)
+				r[4] = r[4] + sp.dxtx1[4]*(u[pp][4]-2.0*u[p][4]+u[pm][4]) +
+					sp.xxcon3*(qs[pp]-2.0*qs[p]+qs[pm]) +
+					sp.xxcon4*(up1*up1-2.0*uijk*uijk+um1*um1) +
+					sp.xxcon5*(u[pp][4]*rhoI[pp]-2.0*u[p][4]*rhoI[p]+u[pm][4]*rhoI[pm]) -
+					sp.tx2*((c1*u[pp][4]-c2*square[pp])*up1-(c1*u[pm][4]-c2*square[pm])*um1)
+			}
+			p0 := sp.idx(k, j, 0)
+			dissipation(rhs, p0, 1, u, p0, 1, sp.nx, dssp)
+		}
+	}
+}
+
+// yFluxes adds the eta-direction fluxes and dissipation to rhs in the
+// planes k0..k1-1
+func (sp *SPBenchmark) yFluxes(k0, k1 int) {
+	u, rhs := sp.u, sp.rhs
+	us, vs, ws, qs, rhoI, square := sp.us, sp.vs, sp.ws, sp.qs, sp.rhoI, sp.square
+	c1, c2, dssp := sp.c1, sp.c2, sp.dssp
+	s := sp.nx
+	for k := k0; k < k1; k++ {
+		for j := 1; j < sp.ny-1; j++ {
+			for i := 1; i < sp.nx-1; i++ {
+				p := sp.idx(k, j, i)
+				pp, pm := p+s, p-s
+				vijk, vp1, vm1 := vs[p], vs[pp], vs[pm]
+				r := &rhs[p]
+				r[0] = r[0] + sp.dyty1[0]*(u[pp][0]-2.0*u[p][0]+u[pm][0]) -
+					sp.ty2*(u[pp][2]-u[pm][2])
+				r[1] = r[1] + sp.dyty1[1]*(u[pp][1]-2.0*u[p][1]+u[pm][1]) +
+					sp.yycon2*(us[pp]-2.0*us[p]+us[pm]) -
+					sp.ty2*(u[pp][1]*vp1-u[pm][1]*vm1)
+				r[2] = r[2] + sp.dyty1[2]*(u[pp][2]-2.0*u[p][2]+u[pm][2]) +
+					sp.yycon2*sp.con43*(vp1-2.0*vijk+vm1) -
+					sp.ty2*(u[pp][2]*vp1-u[pm][2]*vm1+(u[pp][4]-square[pp]-u[pm][4]+square[pm])*c2)
+				r[3] = r[3] + sp.dyty1[3]*(u[pp][3]-2.0*u[p][3]+u[pm][3]) +
+					sp.yycon2*(ws[pp]-2.0*ws[p]+ws[pm]) -
+					sp.ty2*(u[pp][3]*vp1-u[pm][3]*vm1)
+				r[4] = r[4] + sp.dyty1[4]*(u[pp][4]-2.0*u[p][4]+u[pm][4]) +
+					sp.yycon3*(qs[pp]-2.0*qs[p]+qs[pm]) +
+					sp.yycon4*(vp1*vp1-2.0*vijk*vijk+vm1*vm1) +
+					sp.yycon5*(u[pp][4]*rhoI[pp]-2.0*u[p][4]*rhoI[p]+u[pm][4]*rhoI[pm]) -
+					sp.ty2*((c1*u[pp][4]-c2*square[pp])*vp1-(c1*u[pm][4]-c2*square[pm])*vm1)
+			}
+		}
+		for i := 1; i < sp.nx-1; i++ {
+			p0 := sp.idx(k, 0, i)
+			dissipation(rhs, p0, s, u, p0, s, sp.ny, dssp)
+		}
+	}
+
+}
+
+// zFluxes adds the zeta-direction fluxes and dissipation to rhs in the
+// planes k0..k1-1. The dissipation spans whole zeta lines and is added by
+// zDissipation.
+func (sp *SPBenchmark) zFluxes(k0, k1 int) {
+	u, rhs := sp.u, sp.rhs
+	us, vs, ws, qs, rhoI, square := sp.us, sp.vs, sp.ws, sp.qs, sp.rhoI, sp.square
+	c1, c2 := sp.c1, sp.c2
+	s := sp.nx * sp.ny
+	for k := k0; k < k1; k++ {
+		for j := 1; j < sp.ny-1; j++ {
+			for i := 1; i < sp.nx-1; i++ {
+				p := sp.idx(k, j, i)
+				pp, pm := p+s, p-s
+				wijk, wp1, wm1 := ws[p], ws[pp], ws[pm]
+				r := &rhs[p]
+				r[0] = r[0] + sp.dztz1[0]*(u[pp][0]-2.0*u[p][0]+u[pm][0]) -
+					sp.tz2*(u[pp][3]-u[pm][3])
+				r[1] = r[1] + sp.dztz1[1]*(u[pp][1]-2.0*u[p][1]+u[pm][1]) +
+					sp.zzcon2*(us[pp]-2.0*us[p]+us[pm]) -
+					sp.tz2*(u[pp][1]*wp1-u[pm][1]*wm1)
+				r[2] = r[2] + sp.dztz1[2]*(u[pp][2]-2.0*u[p][2]+u[pm][2]) +
+					sp.zzcon2*(vs[pp]-2.0*vs[p]+vs[pm]) -
+					sp.tz2*(u[pp][2]*wp1-u[pm][2]*wm1)
+				r[3] = r[3] + sp.dztz1[3]*(u[pp][3]-2.0*u[p][3]+u[pm][3]) +
+					sp.zzcon2*sp.con43*(wp1-2.0*wijk+wm1) -
+					sp.tz2*(u[pp][3]*wp1-u[pm][3]*wm1+(u[pp][4]-square[pp]-u[pm][4]+square[pm])*c2)
+				r[4] = r[4] + sp.dztz1[4]*(u[pp][4]-2.0*u[p][4]+u[pm][4]) +
+					sp.zzcon3*(qs[pp]-2.0*qs[p]+qs[pm]) +
+					sp.zzcon4*(wp1*wp1-2.0*wijk*wijk+wm1*wm1) +
+					sp.zzcon5*(u[pp][4]*rhoI[pp]-2.0*u[p][4]*rhoI[p]+u[pm][4]*rhoI[pm]) -
+					sp.tz2*((c1*u[pp][4]-c2*square[pp])*wp1-(c1*u[pm][4]-c2*square[pm])*wm1)
+			}
+		}
+	}
+}
+
+// zDissipation adds the zeta-direction dissipation to rhs along the lines
+// j0..j1-1, which cross every plane
+func (sp *SPBenchmark) zDissipation(j0, j1 int) {
+	s := sp.nx * sp.ny
+	for j := j0; j < j1; j++ {
+		for i := 1; i < sp.nx-1; i++ {
+			p0 := sp.idx(0, j, i)
+			dissipation(sp.rhs, p0, s, sp.u, p0, s, sp.nz, sp.dssp)
+		}
+	}
+}
+
+// txinvr multiplies rhs by the inverse of the matrix of left eigenvectors
+// of the xi-direction flux Jacobian (block-diagonal matrix-vector product)
+func (sp *SPBenchmark) txinvr() {
+	if sp.timersEnabled {
+		sp.timers.Start(T_TXINVR)
+	}
+	c2, btc := sp.c2, sp.bt
+	sp.team.For(1, sp.nz-1, func(k0, k1, _ int) {
+		for k := k0; k < k1; k++ {
+			for j := 1; j < sp.ny-1; j++ {
+				for i := 1; i < sp.nx-1; i++ {
+					p := sp.idx(k, j, i)
+					ru1 := sp.rhoI[p]
+					uu, vv, ww := sp.us[p], sp.vs[p], sp.ws[p]
+					ac := sp.speed[p]
+					ac2inv := ac * ac
+
+					r := &sp.rhs[p]
+					r1, r2, r3, r4, r5 := r[0], r[1], r[2], r[3], r[4]
+
+					t1 := c2 / ac2inv * (sp.qs[p]*r1 - uu*r2 - vv*r3 - ww*r4 + r5)
+					t2 := btc * ru1 * (uu*r1 - r2)
+					t3 := (btc * ru1 * ac) * t1
+
+					r[0] = r1 - t1
+					r[1] = -ru1 * (ww*r1 - r4)
+					r[2] = ru1 * (vv*r1 - r3)
+					r[3] = -t2 + t3
+					r[4] = t2 + t3
+				}
+			}
+		}
+	})
+	if sp.timersEnabled {
+		sp.timers.Stop(T_TXINVR)
+	}
+}
+
+// solveLine forms the three scalar pentadiagonal systems of the grid line of
+// n points starting at p0 with stride s, and solves them in place of rhs: one
+// for the first three components with the velocity vel along the line as
+// eigenvalue, and one each for the last two with vel plus and minus the
+// speed of sound. dir is the line's direction (1 to 3), d and dmax its
+// dissipation coefficients, dtt1 and dtt2 dt times its t*1 and t*2 constants.
+// The systems are formed in the scratch space of ls.
+func (sp *SPBenchmark) solveLine(ls *lineSolver, p0, s, n int, vel []float64, dir int, d *[5]float64, dmax, dtt1, dtt2 float64) {
+	lhs, lhsp, lhsm := ls.lhs, ls.lhsp, ls.lhsm
+	cv, rho := ls.cv, ls.rho
+	rhs, speed := sp.rhs, sp.speed
+	c2dtt1 := 2.0 * dtt1
+	comz1, comz4, comz5, comz6 := sp.comz1, sp.comz4, sp.comz5, sp.comz6
+
+	// Fill the left hand side of the factor with the line velocity as
+	// eigenvalue
+	for l := 0; l < n; l++ {
+		p := p0 + l*s
+		ru1 := sp.c3c4 * sp.rhoI[p]
+		cv[l] = vel[p]
+		rho[l] = max(max(d[dir]+sp.con43*ru1, d[4]+sp.c1c5*ru1), max(dmax+ru1, d[0]))
+	}
+	lhsinit(lhs, lhsp, lhsm, n)
+	for l := 1; l < n-1; l++ {
+		lhs[l][0] = 0.0
+		lhs[l][1] = -dtt2*cv[l-1] - dtt1*rho[l-1]
+		lhs[l][2] = 1.0 + c2dtt1*rho[l]
+		lhs[l][3] = dtt2*cv[l+1] - dtt1*rho[l+1]
+		lhs[l][4] = 0.0
+	}
+
+	// Add the fourth order dissipation
+	l := 1
+	lhs[l][2] = lhs[l][2] + comz5
+	lhs[l][3] = lhs[l][3] - comz4
+	lhs[l][4] = lhs[l][4] + comz1
+	lhs[l+1][1] = lhs[l+1][1] - comz4
+	lhs[l+1][2] = lhs[l+1][2] + comz6
+	lhs[l+1][3] = lhs[l+1][3] - comz4
+	lhs[l+1][4] = lhs[l+1][4] + comz1
+	for l := 3; l < n-3; l++ {
+		lhs[l][0] = lhs[l][0] + comz1
+		lhs[l][1] = lhs[l][1] - comz4
+		lhs[l][2] = lhs[l][2] + comz6
+		lhs[l][3] = lhs[l][3] - comz4
+		lhs[l][4] = lhs[l][4] + comz1
+	}
+	l = n - 3
+	lhs[l][0] = lhs[l][0] + comz1
+	lhs[l][1] = lhs[l][1] - comz4
+	lhs[l][2] = lhs[l][2] + comz6
+	lhs[l][3] = lhs[l][3] - comz4
+	lhs[l+1][0] = lhs[l+1][0] + comz1
+	lhs[l+1][1] = lhs[l+1][1] - comz4
+	lhs[l+1][2] = lhs[l+1][2] + comz5
+
+	// The factors with the (u+c) and (u-c) eigenvalues differ from the
+	// first one by the speed of sound terms
+	for l := 1; l < n-1; l++ {
+		pm, pp := p0+(l-1)*s, p0+(l+1)*s
+		lhsp[l] = lhs[l]
+		lhsp[l][1] = lhs[l][1] - dtt2*speed[pm]
+		lhsp[l][3] = lhs[l][3] + dtt2*speed[pp]
+		lhsm[l] = lhs[l]
+		lhsm[l][1] = lhs[l][1] + dtt2*speed[pm]
+		lhsm[l][3] = lhs[l][3] - dtt2*speed[pp]
+	}
+
+	// Forward elimination of the first factor, on the first three
+	// components of rhs
+	for l := 0; l < n-2; l++ {
+		p, p1, p2 := p0+l*s, p0+(l+1)*s, p0+(l+2)*s
+		fac1 := 1.0 / lhs[l][2]
+		lhs[l][3] = fac1 * lhs[l][3]
+		lhs[l][4] = fac1 * lhs[l][4]
+		for m := 0; m < 3; m++ {
+			rhs[p][m] = fac1 * rhs[p][m]
+		}
+		lhs[l+1][2] = lhs[l+1][2] - lhs[l+1][1]*lhs[l][3]
+		lhs[l+1][3] = lhs[l+1][3] - lhs[l+1][1]*lhs[l][4]
+		for m := 0; m < 3; m++ {
+			rhs[p1][m] = rhs[p1][m] - lhs[l+1][1]*rhs[p][m]
+		}
+		lhs[l+2][1] = lhs[l+2][1] - lhs[l+2][0]*lhs[l][3]
+		lhs[l+2][2] = lhs[l+2][2] - lhs[l+2][0]*lhs[l][4]
+		for m := 0; m < 3; m++ {
+			rhs[p2][m] = rhs[p2][m] - lhs[l+2][0]*rhs[p][m]
+		}
+	}
+	// The last two rows are done apart, as they hold no fifth diagonal
+	l = n - 2
+	p, p1 := p0+l*s, p0+(l+1)*s
+	fac1 := 1.0 / lhs[l][2]
+	lhs[l][3] = fac1 * lhs[l][3]
+	lhs[l][4] = fac1 * lhs[l][4]
+	for m := 0; m < 3; m++ {
+		rhs[p][m] = fac1 * rhs[p][m]
+	}
+	lhs[l+1][2] = lhs[l+1][2] - lhs[l+1][1]*lhs[l][3]
+	lhs[l+1][3] = lhs[l+1][3] - lhs[l+1][1]*lhs[l][4]
+	for m := 0; m < 3; m++ {
+		rhs[p1][m] = rhs[p1][m] - lhs[l+1][1]*rhs[p][m]
+	}
+	fac2 := 1.0 / lhs[l+1][2]
+	for m := 0; m < 3; m++ {
+		rhs[p1][m] = fac2 * rhs[p1][m]
+	}
+
+	// Forward elimination of the (u+c) and (u-c) factors, on the fourth and
+	// fifth components of rhs
+	for _, f := range []struct {
+		lhs [][5]float64
+		m   int
+	}{{lhsp, 3}, {lhsm, 4}} {
+		lhs, m := f.lhs, f.m
+		for l := 0; l < n-2; l++ {
+			p, p1, p2 := p0+l*s, p0+(l+1)*s, p0+(l+2)*s
+			fac1 := 1.0 / lhs[l][2]
+			lhs[l][3] = fac1 * lhs[l][3]
+			lhs[l][4] = fac1 * lhs[l][4]
+			rhs[p][m] = fac1 * rhs[p][m]
+			lhs[l+1][2] = lhs[l+1][2] - lhs[l+1][1]*lhs[l][3]
+			lhs[l+1][3] = lhs[l+1][3] - lhs[l+1][1]*lhs[l][4]
+			rhs[p1][m] = rhs[p1][m] - lhs[l+1][1]*rhs[p][m]
+			lhs[l+2][1] = lhs[l+2][1] - lhs[l+2][0]*lhs[l][3]
+			lhs[l+2][2] = lhs[l+2][2] - lhs[l+2][0]*lhs[l][4]
+			rhs[p2][m] = rhs[p2][m] - lhs[l+2][0]*rhs[p][m]
+		}
+		l := n - 2
+		p, p1 := p0+l*s, p0+(l+1)*s
+		fac1 := 1.0 / lhs[l][2]
+		lhs[l][3] = fac1 * lhs[l][3]
+		lhs[l][4] = fac1 * lhs[l][4]
+		rhs[p][m] = fac1 * rhs[p][m]
+		lhs[l+1][2] = lhs[l+1][2] - lhs[l+1][1]*lhs[l][3]
+		lhs[l+1][3] = lhs[l+1][3] - lhs[l+1][1]*lhs[l][4]
+		rhs[p1][m] = rhs[p1][m] - lhs[l+1][1]*rhs[p][m]
+		rhs[p1][m] = rhs[p1][m] / lhs[l+1][2]
+	}
+
+	// Back substitution
+	l = n - 2
+	p, p1 = p0+l*s, p0+(l+1)*s
+	for m := 0; m < 3; m++ {
+		rhs[p][m] = rhs[p][m] - lhs[l][3]*rhs[p1][m]
+	}
+	rhs[p][3] = rhs[p][3] - lhsp[l][3]*rhs[p1][3]
+	rhs[p][4] = rhs[p][4] - lhsm[l][3]*rhs[p1][4]
+	for l := n - 3; l >= 0; l-- {
+		p, p1, p2 := p0+l*s, p0+(l+1)*s, p0+(l+2)*s
+		for m := 0; m < 3; m++ {
+			rhs[p][m] = rhs[p][m] - lhs[l][3]*rhs[p1][m] - lhs[l][4]*rhs[p2][m]
+		}
+		rhs[p][3] = rhs[p][3] - lhsp[l][3]*rhs[p1][3] - lhsp[l][4]*rhs[p2][3]
+		rhs[p][4] = rhs[p][4] - lhsm[l][3]*rhs[p1][4] - lhsm[l][4]*rhs[p2][4]
+	}
+}
+
+// xSolve solves the pentadiagonal systems of the xi-direction lines and
+// multiplies the result by the inverse of the matrix of right eigenvectors
+// of the xi-direction flux Jacobian
+func (sp *SPBenchmark) xSolve() {
+	if sp.timersEnabled {
+		sp.timers.Start(T_XSOLVE)
+	}
+	dmax := max(sp.dx[2], sp.dx[3])
+	sp.team.For(1, sp.nz-1, func(k0, k1, id int) {
+		for k := k0; k < k1; k++ {
+			for j := 1; j < sp.ny-1; j++ {
+				sp.solveLine(&sp.solvers[id], sp.idx(k, j, 0), 1, sp.nx, sp.us, 1, &sp.dx, dmax, sp.dt*sp.tx1, sp.dt*sp.tx2)
+			}
+		}
+	})
+	sp.ninvr()
+	if sp.timersEnabled {
+		sp.timers.Stop(T_XSOLVE)
+	}
+}
+
+// ySolve solves the pentadiagonal systems of the eta-direction lines and
+// multiplies the result by the product of the eta and xi eigenvector
+// matrices
+func (sp *SPBenchmark) ySolve() {
+	if sp.timersEnabled {
+		sp.timers.Start(T_YSOLVE)
+	}
+	dmax := max(sp.dy[1], sp.dy[3])
+	sp.team.For(1, sp.nz-1, func(k0, k1, id int) {
+		for k := k0; k < k1; k++ {
+			for i := 1; i < sp.nx-1; i++ {
+				sp.solveLine(&sp.solvers[id], sp.idx(k, 0, i), sp.nx, sp.ny, sp.vs, 2, &sp.dy, dmax, sp.dt*sp.ty1, sp.dt*sp.ty2)
+			}
+		}
+	})
+	sp.pinvr()
+	if sp.timersEnabled {
+		sp.timers.Stop(T_YSOLVE)
+	}
+}
+
+// zSolve solves the pentadiagonal systems of the zeta-direction lines and
+// multiplies the result by the matrix of right eigenvectors of the
+// zeta-direction flux Jacobian
+func (sp *SPBenchmark) zSolve() {
+	if sp.timersEnabled {
+		sp.timers.Start(T_ZSOLVE)
+	}
+	dmax := max(sp.dz[1], sp.dz[2])
+	sp.team.For(1, sp.ny-1, func(j0, j1, id int) {
+		for j := j0; j < j1; j++ {
+			for i := 1; i < sp.nx-1; i++ {
+				sp.solveLine(&sp.solvers[id], sp.idx(0, j, i), sp.nx*sp.ny, sp.nz, sp.ws, 3, &sp.dz, dmax, sp.dt*sp.tz1, sp.dt*sp.tz2)
+			}
+		}
+	})
+	sp.tzetar()
+	if sp.timersEnabled {
+		sp.timers.Stop(T_ZSOLVE)
+	}
+}
+
+// ninvr applies the block-diagonal inverse of the xi eigenvector matrix to
+// the interior of rhs
+func (sp *SPBenchmark) ninvr() {
+	sp.team.For(1, sp.nz-1, func(k0, k1, _ int) {
+		for k := k0; k < k1; k++ {
+			for j := 1; j < sp.ny-1; j++ {
+				for i := 1; i < sp.nx-1; i++ {
+					r := &sp.rhs[sp.idx(k, j, i)]
+					r1, r2, r3, r4, r5 := r[0], r[1], r[2], r[3], r[4]
+					t1 := sp.bt * r3
+					t2 := 0.5 * (r4 + r5)
+					r[0] = -r2
+					r[1] = r1
+					r[2] = sp.bt * (r4 - r5)
+					r[3] = -t1 + t2
+					r[4] = t1 + t2
+				}
+			}
+		}
+	})
+}
+
+// pinvr applies the block-diagonal inverse of the eta eigenvector matrix to
+// the interior of rhs
+func (sp *SPBenchmark) pinvr() {
+	sp.team.For(1, sp.nz-1, func(k0, k1, _ int) {
+		for k := k0; k < k1; k++ {
+			for j := 1; j < sp.ny-1; j++ {
+				for i := 1; i < sp.nx-1; i++ {
+					r := &sp.rhs[sp.idx(k, j, i)]
+					r1, r2, r3, r4, r5 := r[0], r[1], r[2], r[3], r[4]
+					t1 := sp.bt * r1
+					t2 := 0.5 * (r4 + r5)
+					r[0] = sp.bt * (r4 - r5)
+					r[1] = -r3
+					r[2] = r2
+					r[3] = -t1 + t2
+					r[4] = t1 + t2
+				}
+			}
+		}
+	})
+}
+
+// tzetar applies the block-diagonal matrix of right eigenvectors of the
+// zeta-direction flux Jacobian to the interior of rhs
+func (sp *SPBenchmark) tzetar() {
+	sp.team.For(1, sp.nz-1, func(k0, k1, _ int) {
+		for k := k0; k < k1; k++ {
+			for j := 1; j < sp.ny-1; j++ {
+				for i := 1; i < sp.nx-1; i++ {
+					p := sp.idx(k, j, i)
+					xvel, yvel, zvel := sp.us[p], sp.vs[p], sp.ws[p]
+					ac := sp.speed[p]
+					ac2u := ac * ac
+
+					r := &sp.rhs[p]
+					r1, r2, r3, r4, r5 := r[0], r[1], r[2], r[3], r[4]
+
+					uzik1 := sp.u[p][0]
+					btuz := sp.bt * uzik1
+
+					t1 := btuz / ac * (r4 + r5)
+					t2 := r3 + t1
+					t3 := btuz * (r4 - r5)
+
+					r[0] = t2
+					r[1] = -uzik1*r2 + xvel*t2
+					r[2] = uzik1*r1 + yvel*t2
+					r[3] = zvel*t2 + t3
+					r[4] = uzik1*(-xvel*r2+yvel*r1) + sp.qs[p]*t2 + sp.c2iv*ac2u*t1 + zvel*t3
+				}
+			}
+		}
+	})
+}
+
+// add adds the solved increments in rhs to u
+func (sp *SPBenchmark) add() {
+	if sp.timersEnabled {
+		sp.timers.Start(T_ADD)
+	}
+	sp.team.For(1, sp.nz-1, func(k0, k1, _ int) {
+		for k := k0; k < k1; k++ {
+			for j := 1; j < sp.ny-1; j++ {
+				for i := 1; i < sp.nx-1; i++ {
+					p := sp.idx(k, j, i)
+					for m := 0; m < 5; m++ {
+						sp.u[p][m] += sp.rhs[p][m]
+					}
+				}
+			}
+		}
+	})
+	if sp.timersEnabled {
+		sp.timers.Stop(T_ADD)
+	}
+}
+
+// adi performs one time step
+func (sp *SPBenchmark) adi() {
+	sp.computeRHS()
+	sp.txinvr()
+	sp.xSolve()
+	sp.ySolve()
+	sp.zSolve()
+	sp.add()
+}
+
+// errorNorm returns the RMS norms of the difference between u and the exact
+// solution
+func (sp *SPBenchmark) errorNorm() [5]float64 {
+	var rms [5]float64
+	for k := 0; k < sp.nz; k++ {
+		zeta := float64(k) * sp.dnzm1
+		for j := 0; j < sp.ny; j++ {
+			eta := float64(sp.y0+j) * sp.dnym1
+			for i := 0; i < sp.nx; i++ {
+				xi := float64(sp.x0+i) * sp.dnxm1
+				uExact := sp.exactSolution(xi, eta, zeta)
+				u := &sp.u[sp.idx(k, j, i)]
+				for m := 0; m < 5; m++ {
+					add := u[m] - uExact[m]
+					rms[m] += add * add
+				}
+			}
+		}
+	}
+	return sp.normalize(rms)
+}
+
+// rhsNorm returns the RMS norms of the interior of rhs
+func (sp *SPBenchmark) rhsNorm() [5]float64 {
+	var rms [5]float64
+	for k := 1; k < sp.nz-1; k++ {
+		for j := 1; j < sp.ny-1; j++ {
+			for i := 1; i < sp.nx-1; i++ {
+				r := &sp.rhs[sp.idx(k, j, i)]
+				for m := 0; m < 5; m++ {
+					rms[m] += r[m] * r[m]
+				}
+			}
+		}
+	}
+	return sp.normalize(rms)
+}
+
+// normalize turns sums of squares over the grid into RMS norms
+func (sp *SPBenchmark) normalize(rms [5]float64) [5]float64 {
+	for m := 0; m < 5; m++ {
+		for _, n := range []int{sp.nx, sp.ny, sp.nz} {
+			rms[m] /= float64(n - 2)
+		}
+		rms[m] = math.Sqrt(rms[m])
+	}
+	return rms
+}
+
+// verify computes the residual and error norms of the solution and compares
+// them with the reference values of the class
+func (sp *SPBenchmark) verify() (xcr, xce [5]float64, verified bool) {
+	const epsilon = 1.0e-8
+
+	xce = sp.errorNorm()
+	sp.computeRHS()
+	xcr = sp.rhsNorm()
+	for m := 0; m < 5; m++ {
+		xcr[m] = xcr[m] / sp.dt
+	}
+
+	fmt.Fprintf(sp.out, " Verification being performed for class %s\n", sp.class)
+	fmt.Fprintf(sp.out, " accuracy setting for epsilon = %20.13E\n", epsilon)
+
+	verified = true
+	fmt.Fprintf(sp.out, " Comparison of RMS-norms of residual\n")
+	for m := 0; m < 5; m++ {
+		dif := math.Abs((xcr[m] - sp.xcrRef[m]) / sp.xcrRef[m])
+		if dif <= epsilon {
+			fmt.Fprintf(sp.out, "          %2d%20.13E%20.13E%20.13E\n", m+1, xcr[m], sp.xcrRef[m], dif)
+		} else {
+			verified = false
+			fmt.Fprintf(sp.out, " FAILURE: %2d%20.13E%20.13E%20.13E\n", m+1, xcr[m], sp.xcrRef[m], dif)
+		}
+	}
+	fmt.Fprintf(sp.out, " Comparison of RMS-norms of solution error\n")
+	for m := 0; m < 5; m++ {
+		dif := math.Abs((xce[m] - sp.xceRef[m]) / sp.xceRef[m])
+		if dif <= epsilon {
+			fmt.Fprintf(sp.out, "          %2d%20.13E%20.13E%20.13E\n", m+1, xce[m], sp.xceRef[m], dif)
+		} else {
+			verified = false
+			fmt.Fprintf(sp.out, " FAILURE: %2d%20.13E%20.13E%20.13E\n", m+1, xce[m], sp.xceRef[m], dif)
+		}
+	}
+
+	if verified {
+		fmt.Fprintf(sp.out, " Verification Successful\n")
+	} else {
+		fmt.Fprintf(sp.out, " Verification failed\n")
+	}
+	return xcr, xce, verified
+}
+
+// allocate creates the arrays of the grid and the scratch space of the
+// workers
+func (sp *SPBenchmark) allocate() {
+	points := sp.nx * sp.ny * sp.nz
+	sp.u = make([][5]float64, points)
+	sp.rhs = make([][5]float64, points)
+	sp.forcing = make([][5]float64, points)
+	sp.us = make([]float64, points)
+	sp.vs = make([]float64, points)
+	sp.ws = make([]float64, points)
+	sp.qs = make([]float64, points)
+	sp.rhoI = make([]float64, points)
+	sp.square = make([]float64, points)
+	sp.speed = make([]float64, points)
+	maxdim := max(sp.nx, sp.ny, sp.nz)
+	sp.solvers = make([]lineSolver, sp.numWorkers)
+	for w := range sp.solvers {
+		sp.solvers[w] = lineSolver{
+			lhs:  make([][5]float64, maxdim),
+			lhsp: make([][5]float64, maxdim),
+			lhsm: make([][5]float64, maxdim),
+			cv:   make([]float64, maxdim),
+			rho:  make([]float64, maxdim),
+		}
+	}
+}
+
+// run performs the SP benchmark and returns its result, with an error when
+// ctx stopped its time steps early
+func (sp *SPBenchmark) run(ctx context.Context) (Result, error) {
+	if _, err := os.Stat("timer.flag"); err == nil {
+		sp.timersEnabled = true
+	}
+
+	team, err := common.StartTeam(sp.numWorkers, sp.schedule, sp.bind)
+	if err != nil {
+		return Result{}, err
+	}
+	sp.team = team
+	defer sp.team.Close()
+
+	sp.allocate()
+
+	fmt.Fprintf(sp.out, "\n\n NAS Parallel Benchmarks 4.1 Go Goroutine version - SP Benchmark\n\n")
+	fmt.Fprintf(sp.out, " Size: %4dx%4dx%4d\n", sp.nx, sp.ny, sp.nz)
+	fmt.Fprintf(sp.out, " Iterations: %4d    dt: %10.6f\n", sp.niter, sp.dt)
+	fmt.Fprintf(sp.out, " Number of available workers: %d\n\n", sp.numWorkers)
+
+	for i := 1; i <= T_LAST; i++ {
+		sp.timers.Clear(i)
+	}
+
+	sp.initialize()
+	sp.exactRHS()
+
+	// Do one time step to touch all code, and reinitialize
+	sp.adi()
+	sp.initialize()
+
+	for i := 1; i <= T_LAST; i++ {
+		sp.timers.Clear(i)
+	}
+	sp.timers.Start(T_TOTAL)
+
+	iterations := sp.niter
+	for step := 1; step <= sp.niter; step++ {
+		if ctx.Err() != nil {
+			iterations = step - 1
+			break
+		}
+		if step%20 == 0 || step == 1 {
+			fmt.Fprintf(sp.out, " Time step %4d\n", step)
+		}
+		sp.adi()
+	}
+
+	sp.timers.Stop(T_TOTAL)
+	tmax := sp.timers.Read(T_TOTAL)
+
+	var xcr, xce [5]float64
+	verified := false
+	incomplete := iterations < sp.niter
+	if incomplete {
+		fmt.Fprintf(sp.out, "\n Benchmark stopped after %d of %d time steps\n", iterations, sp.niter)
+		fmt.Fprintf(sp.out, " NO VERIFICATION PERFORMED\n")
+	} else {
+		xcr, xce, verified = sp.verify()
+	}
+
+	mflops := 0.0
+	if tmax != 0.0 {
+		n3 := float64(sp.nx * sp.ny * sp.nz)
+		navg := float64(sp.nx+sp.ny+sp.nz) / 3.0
+		mflops = 1.0e-6 * float64(iterations) *
+			(881.174*n3 - 4683.91*navg*navg + 11484.5*navg - 19272.4) / tmax
+	}
+
+	result := common.Result{
+		Kernel:      "SP",
+		Class:       sp.class,
+		Size:        [3]int{sp.nx, sp.ny, sp.nz},
+		Iterations:  iterations,
+		Time:        tmax,
+		Mops:        mflops,
+		OpType:      "floating point",
+		Verified:    verified,
+		Incomplete:  incomplete,
+		Workers:     sp.numWorkers,
+		NPBVersion:  "4.1",
+		CompileTime: "Unknown",
+		Compiler:    "Go",
+	}
+	sp.team.Describe(&result)
+	if sp.timersEnabled {
+		names := []string{"", "total", "rhs", "txinvr", "xsolve", "ysolve", "zsolve", "add"}
+		for i := 1; i <= T_LAST; i++ {
+			result.Timers = append(result.Timers, common.Timer{Name: names[i], Seconds: sp.timers.Read(i)})
+		}
+	}
+	common.Finish(&result, sp.out)
+
+	if sp.timersEnabled {
+		fmt.Fprintln(sp.out, "  SECTION   Time (secs)")
+		for _, t := range result.Timers {
+			fmt.Fprintf(sp.out, "  %-8s:%9.3f  (%6.2f%%)\n", t.Name, t.Seconds, t.Seconds*100.0/tmax)
+		}
+	}
+	if incomplete {
+		return Result{result, xcr, xce}, &common.IncompleteError{Completed: iterations, Planned: sp.niter, Err: ctx.Err()}
+	}
+	return Result{result, xcr, xce}, nil
+}
+
+// lhsinit sets the first and last rows of the three systems to identity
+// rows, which keep the boundary values of rhs
+func lhsinit(lhs, lhsp, lhsm [][5]float64, n int) {
+	for _, l := range []int{0, n - 1} {
+		lhs[l] = [5]float64{0.0, 0.0, 1.0, 0.0, 0.0}
+		lhsp[l] = lhs[l]
+		lhsm[l] = lhs[l]
+	}
+}
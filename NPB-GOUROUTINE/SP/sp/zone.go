@@ -0,0 +1,86 @@
+package sp
+
+import (
+	"io"
+
+	"github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/common"
+)
+
+// Zone is one zone of a multi-zone SP problem (SP-MZ): a block of a larger
+// grid that is solved like the single-zone grid, except that its x and y
+// faces hold copies of the points next to them in the neighbouring zones
+type Zone struct {
+	sp *SPBenchmark
+}
+
+// NewZone creates the zone of nx x ny x nz points whose first point is at
+// (x0, y0, 0) in a grid of gx x gy x nz points, advanced with time step dt
+// and with its loops run on the workers of team
+func NewZone(nx, ny, nz, x0, y0, gx, gy int, dt float64, team *common.Team) *Zone {
+	sp := &SPBenchmark{
+		nx:         nx,
+		ny:         ny,
+		nz:         nz,
+		gx:         gx,
+		gy:         gy,
+		x0:         x0,
+		y0:         y0,
+		dt:         dt,
+		numWorkers: team.Workers(),
+		team:       team,
+		out:        io.Discard,
+	}
+	sp.setConstants()
+	sp.allocate()
+	sp.initialize()
+	sp.exactRHS()
+	return &Zone{sp}
+}
+
+// Initialize sets u back to its initial values
+func (z *Zone) Initialize() {
+	z.sp.initialize()
+}
+
+// Step advances the zone by one time step
+func (z *Zone) Step() {
+	z.sp.adi()
+}
+
+// U returns the conserved variables at every point of the zone, point
+// (i, j, k) at (k*ny+j)*nx+i
+func (z *Zone) U() [][5]float64 {
+	return z.sp.u
+}
+
+// Norms returns the sums over the interior points of the squared residual,
+// divided by dt, and of the squared difference from the exact solution
+func (z *Zone) Norms() (rhs, err [5]float64) {
+	sp := z.sp
+	sp.computeRHS()
+	for k := 1; k < sp.nz-1; k++ {
+		zeta := float64(k) * sp.dnzm1
+		for j := 1; j < sp.ny-1; j++ {
+			eta := float64(sp.y0+j) * sp.dnym1
+			for i := 1; i < sp.nx-1; i++ {
+				xi := float64(sp.x0+i) * sp.dnxm1
+				uExact := sp.exactSolution(xi, eta, zeta)
+				p := sp.idx(k, j, i)
+				for m := 0; m < 5; m++ {
+					r := sp.rhs[p][m] / sp.dt
+					rhs[m] += r * r
+					e := sp.u[p][m] - uExact[m]
+					err[m] += e * e
+				}
+			}
+		}
+	}
+	return rhs, err
+}
+
+// Ops returns the floating point operations of one time step
+func (z *Zone) Ops() float64 {
+	n3 := float64(z.sp.nx * z.sp.ny * z.sp.nz)
+	navg := float64(z.sp.nx+z.sp.ny+z.sp.nz) / 3.0
+	return 881.174*n3 - 4683.91*navg*navg + 11484.5*navg - 19272.4
+}
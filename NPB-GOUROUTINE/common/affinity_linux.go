@@ -0,0 +1,44 @@
+package common
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+// CPU_SETSIZE is the number of cores an affinity mask holds, as in glibc
+const CPU_SETSIZE = 1024
+
+type cpuMask [CPU_SETSIZE / 64]uint64
+
+// threadAffinity returns the cores the calling OS thread may run on
+func threadAffinity() ([]int, error) {
+	var mask cpuMask
+	_, _, errno := syscall.RawSyscall(syscall.SYS_SCHED_GETAFFINITY, 0, unsafe.Sizeof(mask), uintptr(unsafe.Pointer(&mask)))
+	if errno != 0 {
+		return nil, fmt.Errorf("sched_getaffinity: %w", errno)
+	}
+	var cores []int
+	for c := 0; c < CPU_SETSIZE; c++ {
+		if mask[c/64]&(1<<(c%64)) != 0 {
+			cores = append(cores, c)
+		}
+	}
+	return cores, nil
+}
+
+// setThreadAffinity restricts the calling OS thread to cores
+func setThreadAffinity(cores []int) error {
+	var mask cpuMask
+	for _, c := range cores {
+		if c < 0 || c >= CPU_SETSIZE {
+			return fmt.Errorf("core %d is out of range 0-%d", c, CPU_SETSIZE-1)
+		}
+		mask[c/64] |= 1 << (c % 64)
+	}
+	_, _, errno := syscall.RawSyscall(syscall.SYS_SCHED_SETAFFINITY, 0, unsafe.Sizeof(mask), uintptr(unsafe.Pointer(&mask)))
+	if errno != 0 {
+		return fmt.Errorf("sched_setaffinity to cores %s: %w", FormatCores(cores), errno)
+	}
+	return nil
+}
@@ -0,0 +1,17 @@
+//go:build !linux
+
+package common
+
+import "errors"
+
+var errAffinity = errors.New("binding workers to cores is only supported on Linux")
+
+// threadAffinity returns the cores the calling OS thread may run on
+func threadAffinity() ([]int, error) {
+	return nil, errAffinity
+}
+
+// setThreadAffinity restricts the calling OS thread to cores
+func setThreadAffinity(cores []int) error {
+	return errAffinity
+}
@@ -0,0 +1,192 @@
+package common
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Binding policies, as in OMP_PROC_BIND. The zero Binding has no policy and
+// stands for the one of $GO_PROC_BIND.
+const (
+	BIND_NONE   = iota + 1 // workers run wherever the scheduler puts them
+	BIND_CLOSE             // worker i on the i-th usable core
+	BIND_SPREAD            // workers spaced evenly over the usable cores
+	BIND_LIST              // worker i on Cores[i], round robin
+)
+
+// Binding is how the workers of a team are pinned to cores. The usable cores
+// are those the process may run on, in increasing order.
+type Binding struct {
+	Policy int
+	Cores  []int // for BIND_LIST
+}
+
+// String returns b in the syntax of ParseBinding
+func (b Binding) String() string {
+	switch b.Policy {
+	case BIND_NONE:
+		return "false"
+	case BIND_CLOSE:
+		return "close"
+	case BIND_SPREAD:
+		return "spread"
+	case BIND_LIST:
+		return FormatCores(b.Cores)
+	}
+	return ""
+}
+
+// ParseBinding reads a binding written as GO_PROC_BIND takes it: "false",
+// "close", "spread", or a list of cores such as "0-3,8,10"
+func ParseBinding(text string) (Binding, error) {
+	text = strings.TrimSpace(text)
+	switch strings.ToLower(text) {
+	case "false", "none":
+		return Binding{Policy: BIND_NONE}, nil
+	case "close", "true":
+		return Binding{Policy: BIND_CLOSE}, nil
+	case "spread":
+		return Binding{Policy: BIND_SPREAD}, nil
+	}
+	var cores []int
+	for _, field := range strings.Split(text, ",") {
+		lo, hi, isRange := strings.Cut(strings.TrimSpace(field), "-")
+		first, err1 := strconv.Atoi(lo)
+		last, err2 := first, error(nil)
+		if isRange {
+			last, err2 = strconv.Atoi(hi)
+		}
+		if err1 != nil || err2 != nil || first < 0 || last < first {
+			return Binding{}, fmt.Errorf("unknown binding %q: want false, close, spread or a list of cores such as 0-3,8", text)
+		}
+		for c := first; c <= last; c++ {
+			cores = append(cores, c)
+		}
+	}
+	return Binding{Policy: BIND_LIST, Cores: cores}, nil
+}
+
+// ResolveBinding returns b, or when it has no policy the binding of
+// $GO_PROC_BIND, which is none when the variable is unset or empty
+func ResolveBinding(b Binding) (Binding, error) {
+	switch b.Policy {
+	case BIND_NONE, BIND_CLOSE, BIND_SPREAD:
+		return b, nil
+	case BIND_LIST:
+		if len(b.Cores) == 0 {
+			return Binding{}, fmt.Errorf("binding to a list of cores with an empty list")
+		}
+		return b, nil
+	case 0:
+	default:
+		return Binding{}, fmt.Errorf("invalid binding policy %d", b.Policy)
+	}
+	text := os.Getenv("GO_PROC_BIND")
+	if strings.TrimSpace(text) == "" {
+		return Binding{Policy: BIND_NONE}, nil
+	}
+	b, err := ParseBinding(text)
+	if err != nil {
+		return Binding{}, fmt.Errorf("GO_PROC_BIND: %w", err)
+	}
+	return b, nil
+}
+
+// placement returns the core of each of n workers under b, given the usable
+// cores, or nil when b binds no worker
+func (b Binding) placement(n int, usable []int) []int {
+	if b.Policy == BIND_NONE || b.Policy == 0 {
+		return nil
+	}
+	cores := make([]int, n)
+	m := len(usable)
+	for i := range cores {
+		switch b.Policy {
+		case BIND_CLOSE:
+			cores[i] = usable[i%m]
+		case BIND_SPREAD:
+			if n <= m {
+				cores[i] = usable[i*m/n]
+			} else {
+				cores[i] = usable[i%m]
+			}
+		case BIND_LIST:
+			cores[i] = b.Cores[i%len(b.Cores)]
+		}
+	}
+	return cores
+}
+
+// Split shares b out between teams of the given sizes that run side by side:
+// each team gets, as a list, the cores its workers have when the workers of
+// all teams are placed together under b, so that no two teams share a core
+// while there are enough of them. A binding of no worker is the same for
+// every team.
+func (b Binding) Split(sizes []int) ([]Binding, error) {
+	split := make([]Binding, len(sizes))
+	if b.Policy == BIND_NONE || b.Policy == 0 {
+		for i := range split {
+			split[i] = b
+		}
+		return split, nil
+	}
+	var usable []int
+	if b.Policy != BIND_LIST {
+		var err error
+		if usable, err = threadAffinity(); err != nil {
+			return nil, err
+		}
+	}
+	return b.split(sizes, usable), nil
+}
+
+// split is Split with the usable cores given
+func (b Binding) split(sizes []int, usable []int) []Binding {
+	total := 0
+	for _, n := range sizes {
+		total += n
+	}
+	cores := b.placement(total, usable)
+	split := make([]Binding, len(sizes))
+	for i, n := range sizes {
+		split[i] = Binding{Policy: BIND_LIST, Cores: cores[:n:n]}
+		cores = cores[n:]
+	}
+	return split
+}
+
+// FormatCores writes a set of cores as a list of ranges, such as "0-3,8"
+func FormatCores(cores []int) string {
+	sorted := append([]int(nil), cores...)
+	sort.Ints(sorted)
+	var parts []string
+	for i := 0; i < len(sorted); {
+		j := i
+		for j+1 < len(sorted) && sorted[j+1] <= sorted[j]+1 {
+			j++
+		}
+		if sorted[j] == sorted[i] {
+			parts = append(parts, strconv.Itoa(sorted[i]))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d-%d", sorted[i], sorted[j]))
+		}
+		i = j + 1
+	}
+	return strings.Join(parts, ",")
+}
+
+// FormatPlacement writes the core of each worker in worker order, such as
+// "0:0 1:2 2:4", or "unbound" for none
+func FormatPlacement(cores []int) string {
+	if len(cores) == 0 {
+		return "unbound"
+	}
+	parts := make([]string, len(cores))
+	for i, c := range cores {
+		parts[i] = fmt.Sprintf("%d:%d", i, c)
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,142 @@
+package common
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseBinding(t *testing.T) {
+	for _, tc := range []struct {
+		text string
+		want Binding
+		ok   bool
+	}{
+		{"false", Binding{Policy: BIND_NONE}, true},
+		{"none", Binding{Policy: BIND_NONE}, true},
+		{"close", Binding{Policy: BIND_CLOSE}, true},
+		{" TRUE ", Binding{Policy: BIND_CLOSE}, true},
+		{"spread", Binding{Policy: BIND_SPREAD}, true},
+		{"5", Binding{BIND_LIST, []int{5}}, true},
+		{"0-3,8", Binding{BIND_LIST, []int{0, 1, 2, 3, 8}}, true},
+		{"6, 2-3 ,2", Binding{BIND_LIST, []int{6, 2, 3, 2}}, true},
+		{"", Binding{}, false},
+		{"master", Binding{}, false},
+		{"3-1", Binding{}, false},
+		{"-1", Binding{}, false},
+		{"0-", Binding{}, false},
+		{"0,,2", Binding{}, false},
+		{"1-2-3", Binding{}, false},
+	} {
+		got, err := ParseBinding(tc.text)
+		if (err == nil) != tc.ok || got.Policy != tc.want.Policy || !slices.Equal(got.Cores, tc.want.Cores) {
+			t.Errorf("ParseBinding(%q) = %v, %v; want %v, ok %v", tc.text, got, err, tc.want, tc.ok)
+		}
+	}
+}
+
+func TestResolveBinding(t *testing.T) {
+	for _, tc := range []struct {
+		b    Binding
+		env  string
+		want Binding
+		ok   bool
+	}{
+		{Binding{}, "", Binding{Policy: BIND_NONE}, true},
+		{Binding{}, "spread", Binding{Policy: BIND_SPREAD}, true},
+		{Binding{}, "1,3", Binding{BIND_LIST, []int{1, 3}}, true},
+		{Binding{Policy: BIND_CLOSE}, "spread", Binding{Policy: BIND_CLOSE}, true},
+		{Binding{}, "sockets", Binding{}, false},
+		{Binding{Policy: BIND_LIST}, "", Binding{}, false},
+		{Binding{Policy: 7}, "", Binding{}, false},
+	} {
+		t.Setenv("GO_PROC_BIND", tc.env)
+		got, err := ResolveBinding(tc.b)
+		if (err == nil) != tc.ok || got.Policy != tc.want.Policy || !slices.Equal(got.Cores, tc.want.Cores) {
+			t.Errorf("ResolveBinding(%v) with GO_PROC_BIND=%q = %v, %v; want %v, ok %v", tc.b, tc.env, got, err, tc.want, tc.ok)
+		}
+	}
+}
+
+// TestPlacement checks where each policy puts the workers, on a made-up set
+// of usable cores so that it does not depend on the machine
+func TestPlacement(t *testing.T) {
+	usable := []int{0, 1, 2, 3, 8, 9, 10, 11}
+	for _, tc := range []struct {
+		b    Binding
+		n    int
+		want []int
+	}{
+		{Binding{Policy: BIND_NONE}, 4, nil},
+		{Binding{}, 4, nil},
+		{Binding{Policy: BIND_CLOSE}, 3, []int{0, 1, 2}},
+		{Binding{Policy: BIND_CLOSE}, 10, []int{0, 1, 2, 3, 8, 9, 10, 11, 0, 1}},
+		{Binding{Policy: BIND_SPREAD}, 2, []int{0, 8}},
+		{Binding{Policy: BIND_SPREAD}, 3, []int{0, 2, 9}},
+		{Binding{Policy: BIND_SPREAD}, 8, usable},
+		{Binding{Policy: BIND_SPREAD}, 9, []int{0, 1, 2, 3, 8, 9, 10, 11, 0}},
+		{Binding{BIND_LIST, []int{5, 7}}, 5, []int{5, 7, 5, 7, 5}},
+	} {
+		if got := tc.b.placement(tc.n, usable); !slices.Equal(got, tc.want) {
+			t.Errorf("%v with %d workers: placement %v, want %v", tc.b, tc.n, got, tc.want)
+		}
+	}
+}
+
+// TestSplit checks that teams running side by side get the cores of their
+// workers in the placement of all of them
+func TestSplit(t *testing.T) {
+	usable := []int{0, 1, 2, 3, 8, 9, 10, 11}
+	for _, tc := range []struct {
+		b     Binding
+		sizes []int
+		want  [][]int
+	}{
+		{Binding{Policy: BIND_CLOSE}, []int{3, 2, 2}, [][]int{{0, 1, 2}, {3, 8}, {9, 10}}},
+		{Binding{Policy: BIND_SPREAD}, []int{2, 2}, [][]int{{0, 2}, {8, 10}}},
+		{Binding{Policy: BIND_CLOSE}, []int{5, 5}, [][]int{{0, 1, 2, 3, 8}, {9, 10, 11, 0, 1}}},
+		{Binding{BIND_LIST, []int{4, 6, 7}}, []int{1, 3}, [][]int{{4}, {6, 7, 4}}},
+	} {
+		got := tc.b.split(tc.sizes, usable)
+		for i := range tc.want {
+			if got[i].Policy != BIND_LIST || !slices.Equal(got[i].Cores, tc.want[i]) {
+				t.Errorf("%v split %v: team %d got %v, want cores %v", tc.b, tc.sizes, i, got[i], tc.want[i])
+			}
+		}
+	}
+	got, err := Binding{Policy: BIND_NONE}.Split([]int{2, 3})
+	if err != nil || len(got) != 2 || got[0].Policy != BIND_NONE || got[1].Policy != BIND_NONE {
+		t.Errorf("no binding split in two = %v, %v; want two of no binding", got, err)
+	}
+}
+
+func TestFormatCores(t *testing.T) {
+	for _, tc := range []struct {
+		cores []int
+		want  string
+	}{
+		{nil, ""},
+		{[]int{4}, "4"},
+		{[]int{0, 1, 2, 3, 8}, "0-3,8"},
+		{[]int{11, 9, 10, 1, 3}, "1,3,9-11"},
+		{[]int{2, 2, 3, 5, 5}, "2-3,5"},
+	} {
+		if got := FormatCores(tc.cores); got != tc.want {
+			t.Errorf("FormatCores(%v) = %q, want %q", tc.cores, got, tc.want)
+		}
+		if len(tc.cores) > 0 {
+			b, err := ParseBinding(FormatCores(tc.cores))
+			if err != nil || FormatCores(b.Cores) != tc.want {
+				t.Errorf("ParseBinding(%q) = %v, %v; want the same cores back", tc.want, b, err)
+			}
+		}
+	}
+}
+
+func TestFormatPlacement(t *testing.T) {
+	if got := FormatPlacement(nil); got != "unbound" {
+		t.Errorf("FormatPlacement(nil) = %q, want unbound", got)
+	}
+	if got, want := FormatPlacement([]int{0, 8, 2}), "0:0 1:8 2:2"; got != want {
+		t.Errorf("FormatPlacement = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,66 @@
+package common
+
+import "math"
+
+// Field is the data a kernel leaves behind for the next task of a workflow:
+// values on an NX x NY x NZ grid stored with x varying fastest. A vector is a
+// field with NY = NZ = 1.
+type Field struct {
+	NX, NY, NZ int
+	Data       []float64
+}
+
+// NewField returns a zero field of the given size
+func NewField(nx, ny, nz int) *Field {
+	return &Field{NX: nx, NY: ny, NZ: nz, Data: make([]float64, nx*ny*nz)}
+}
+
+// Sample returns n values spread evenly over the data of f in storage order,
+// interpolated linearly between neighbours, and scaled so that the largest
+// magnitude is 1. A kernel reads its input through Sample, whatever the size
+// and shape of the field an upstream task produced.
+func (f *Field) Sample(n int) []float64 {
+	s := make([]float64, n)
+	m := len(f.Data)
+	if m == 0 {
+		return s
+	}
+	for i := range s {
+		if m == 1 || n == 1 {
+			s[i] = f.Data[0]
+			continue
+		}
+		pos := float64(i) * float64(m-1) / float64(n-1)
+		j := int(pos)
+		if j >= m-1 {
+			s[i] = f.Data[m-1]
+			continue
+		}
+		w := pos - float64(j)
+		s[i] = (1-w)*f.Data[j] + w*f.Data[j+1]
+	}
+	scale := 0.0
+	for _, v := range s {
+		scale = math.Max(scale, math.Abs(v))
+	}
+	if scale > 0 {
+		for i := range s {
+			s[i] /= scale
+		}
+	}
+	return s
+}
+
+// Norm returns the mean magnitude of the values of f, the checksum a
+// workflow verifies the output of a task on. Unlike a root mean square it
+// tells apart the normalized vectors of CG.
+func (f *Field) Norm() float64 {
+	if len(f.Data) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, v := range f.Data {
+		sum += math.Abs(v)
+	}
+	return sum / float64(len(f.Data))
+}
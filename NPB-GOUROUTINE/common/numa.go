@@ -0,0 +1,158 @@
+package common
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unsafe"
+)
+
+// ResolveFirstTouch returns whether the arrays of a run are first touched by
+// the workers that compute on them: when on is false, whether $GO_FIRST_TOUCH
+// is set to true, which it is not when the variable is unset or empty
+func ResolveFirstTouch(on bool) (bool, error) {
+	text := strings.TrimSpace(os.Getenv("GO_FIRST_TOUCH"))
+	if on || text == "" {
+		return on, nil
+	}
+	on, err := strconv.ParseBool(text)
+	if err != nil {
+		return false, fmt.Errorf("GO_FIRST_TOUCH: %q is not a boolean", text)
+	}
+	return on, nil
+}
+
+// Touch zeroes planes [0, n) of data, plane p being the stride elements from
+// base+p*stride, on the workers that the loops of t over planes [start, end)
+// deal them to: the planes before start go with the chunk of start, those
+// from end on with the chunk of end-1. The kernel places the memory pages of
+// a slice on the NUMA node of the thread that writes them first, so under a
+// static schedule every page ends up on the node of the worker that later
+// computes on it. Pages the process touched before, as of a slice reused from
+// an earlier run, keep their node.
+func Touch[T any](t *Team, data []T, base, stride, n, start, end int) {
+	t.For(start, end, func(lo, hi, _ int) {
+		if lo == start {
+			lo = 0
+		}
+		if hi == end {
+			hi = n
+		}
+		clear(data[base+lo*stride : base+hi*stride])
+	})
+}
+
+// Span is a range of memory, such as the elements of a slice
+type Span struct {
+	Addr, Len uintptr
+}
+
+// SpanOf returns the memory that holds the elements of s
+func SpanOf[T any](s []T) Span {
+	if len(s) == 0 {
+		return Span{}
+	}
+	return Span{uintptr(unsafe.Pointer(&s[0])), uintptr(len(s)) * unsafe.Sizeof(s[0])}
+}
+
+// NUMABytes returns the bytes of resident memory on each NUMA node of the
+// mappings that hold spans, as /proc/self/numa_maps tells. The Go heap maps
+// memory in large arenas, so the counts take in whatever else shares the
+// arenas of the spans; for the grids of a large class that is little. It
+// returns nil when the system does not report page placement.
+func NUMABytes(spans ...Span) map[int]int64 {
+	maps, err := os.ReadFile("/proc/self/maps")
+	if err != nil {
+		return nil
+	}
+	numaMaps, err := os.ReadFile("/proc/self/numa_maps")
+	if err != nil {
+		return nil
+	}
+	return numaBytes(string(maps), string(numaMaps), spans)
+}
+
+// numaBytes returns the bytes on each node of the mappings that hold spans,
+// given the text of /proc/self/maps and /proc/self/numa_maps
+func numaBytes(maps, numaMaps string, spans []Span) map[int]int64 {
+	// numa_maps only gives the start of each mapping; maps gives its end
+	ends := make(map[uint64]uint64)
+	for _, line := range strings.Split(maps, "\n") {
+		rng, _, _ := strings.Cut(line, " ")
+		lo, hi, ok := strings.Cut(rng, "-")
+		if !ok {
+			continue
+		}
+		start, err1 := strconv.ParseUint(lo, 16, 64)
+		end, err2 := strconv.ParseUint(hi, 16, 64)
+		if err1 == nil && err2 == nil {
+			ends[start] = end
+		}
+	}
+
+	pages := make(map[int]int64)
+	for _, line := range strings.Split(numaMaps, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		start, err := strconv.ParseUint(fields[0], 16, 64)
+		end, ok := ends[start]
+		if err != nil || !ok || !overlaps(spans, start, end) {
+			continue
+		}
+		pageSize := int64(4096)
+		counts := make(map[int]int64)
+		for _, field := range fields[1:] {
+			key, value, _ := strings.Cut(field, "=")
+			n, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				continue
+			}
+			if key == "kernelpagesize_kB" {
+				pageSize = n << 10
+			} else if digits, ok := strings.CutPrefix(key, "N"); ok {
+				if node, err := strconv.Atoi(digits); err == nil {
+					counts[node] += n
+				}
+			}
+		}
+		for node, n := range counts {
+			pages[node] += n * pageSize
+		}
+	}
+	return pages
+}
+
+// overlaps reports whether any of spans shares memory with [start, end)
+func overlaps(spans []Span, start, end uint64) bool {
+	for _, s := range spans {
+		if s.Len > 0 && uint64(s.Addr) < end && uint64(s.Addr+s.Len) > start {
+			return true
+		}
+	}
+	return false
+}
+
+// FormatNUMABytes writes the memory on each node in MiB, such as
+// "N0=812.0MiB N1=811.9MiB", or "not available" when they are not reported
+func FormatNUMABytes(pages map[int]int64) string {
+	if pages == nil {
+		return "not available"
+	}
+	if len(pages) == 0 {
+		return "none resident"
+	}
+	nodes := make([]int, 0, len(pages))
+	for node := range pages {
+		nodes = append(nodes, node)
+	}
+	sort.Ints(nodes)
+	parts := make([]string, len(nodes))
+	for i, node := range nodes {
+		parts[i] = fmt.Sprintf("N%d=%.1fMiB", node, float64(pages[node])/(1<<20))
+	}
+	return strings.Join(parts, " ")
+}
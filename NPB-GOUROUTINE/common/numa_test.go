@@ -0,0 +1,103 @@
+package common
+
+import (
+	"maps"
+	"testing"
+)
+
+const sampleMaps = `00400000-00452000 r-xp 00000000 08:02 173521                             /usr/bin/MG
+c000000000-c004000000 rw-p 00000000 00:00 0
+7f1c00000000-7f1c00400000 rw-p 00000000 00:00 0
+7ffd4a3e1000-7ffd4a402000 rw-p 00000000 00:00 0                          [stack]
+`
+
+const sampleNUMAMaps = `00400000 default file=/usr/bin/MG mapped=82 mapmax=2 N0=82 kernelpagesize_kB=4
+c000000000 default anon=16384 dirty=16384 active=0 N0=10000 N1=6384 kernelpagesize_kB=4
+7f1c00000000 interleave:0-1 anon=2 dirty=2 N0=1 N1=1 kernelpagesize_kB=2048
+7f1d00000000 default anon=3 dirty=3 N0=3 kernelpagesize_kB=4
+7ffd4a3e1000 default stack anon=5 dirty=5 N3=5 kernelpagesize_kB=4
+`
+
+// TestNUMABytes checks the parsing of /proc/self/numa_maps on fixed text:
+// only the mappings that share memory with a span count, with their own
+// page size, and a mapping missing from maps is skipped
+func TestNUMABytes(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		spans []Span
+		want  map[int]int64
+	}{
+		{"none", nil, map[int]int64{}},
+		{"heap arena", []Span{{0xc000100000, 1 << 20}}, map[int]int64{0: 10000 << 12, 1: 6384 << 12}},
+		{"huge pages", []Span{{0x7f1c00100000, 64}}, map[int]int64{0: 2 << 20, 1: 2 << 20}},
+		{"two mappings", []Span{{0xc000000000, 8}, {0x7ffd4a400000, 8}}, map[int]int64{0: 10000 << 12, 1: 6384 << 12, 3: 5 << 12}},
+		{"ends at a mapping", []Span{{0xbfff000000, 0x1000000}}, map[int]int64{}},
+		{"empty span", []Span{{0xc000100000, 0}}, map[int]int64{}},
+		{"not in maps", []Span{{0x7f1d00000000, 8}}, map[int]int64{}},
+	} {
+		if got := numaBytes(sampleMaps, sampleNUMAMaps, tc.spans); !maps.Equal(got, tc.want) {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestFormatNUMABytes(t *testing.T) {
+	for _, tc := range []struct {
+		pages map[int]int64
+		want  string
+	}{
+		{nil, "not available"},
+		{map[int]int64{}, "none resident"},
+		{map[int]int64{1: 3 << 19, 0: 1 << 20}, "N0=1.0MiB N1=1.5MiB"},
+	} {
+		if got := FormatNUMABytes(tc.pages); got != tc.want {
+			t.Errorf("FormatNUMABytes(%v) = %q, want %q", tc.pages, got, tc.want)
+		}
+	}
+}
+
+// TestTouch checks that Touch zeroes planes [0, n) at base and nothing else,
+// with the planes outside [start, end) going to the first and last chunks
+func TestTouch(t *testing.T) {
+	const base, stride, n = 5, 4, 12
+	for _, s := range testSchedules {
+		for _, workers := range []int{1, 3, 16} {
+			for _, r := range [][2]int{{0, n}, {1, n - 1}, {2, 5}} {
+				team := NewTeam(workers, s)
+				data := make([]int, base+n*stride+3)
+				for i := range data {
+					data[i] = 1
+				}
+				Touch(team, data, base, stride, n, r[0], r[1])
+				team.Close()
+				for i, v := range data {
+					inside := i >= base && i < base+n*stride
+					if inside != (v == 0) {
+						t.Fatalf("%v, %d workers, planes [%d, %d): element %d = %d", s, workers, r[0], r[1], i, v)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestResolveFirstTouch(t *testing.T) {
+	for _, tc := range []struct {
+		on       bool
+		env      string
+		want, ok bool
+	}{
+		{false, "", false, true},
+		{true, "", true, true},
+		{false, "true", true, true},
+		{false, "0", false, true},
+		{true, "false", true, true},
+		{false, "workers", false, false},
+	} {
+		t.Setenv("GO_FIRST_TOUCH", tc.env)
+		got, err := ResolveFirstTouch(tc.on)
+		if got != tc.want || (err == nil) != tc.ok {
+			t.Errorf("ResolveFirstTouch(%v) with GO_FIRST_TOUCH=%q = %v, %v; want %v, ok %v", tc.on, tc.env, got, err, tc.want, tc.ok)
+		}
+	}
+}
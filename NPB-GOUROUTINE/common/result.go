@@ -0,0 +1,142 @@
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"time"
+)
+
+// Variant names the implementation tree this package belongs to
+const Variant = "goroutine"
+
+// Result is the machine readable counterpart of the PrintResults banner
+type Result struct {
+	RunID        string        `json:"run_id"`
+	Timestamp    string        `json:"timestamp"` // RFC 3339, UTC
+	GitRevision  string        `json:"git_revision"`
+	Kernel       string        `json:"kernel"`
+	Variant      string        `json:"variant"`
+	Class        string        `json:"class"`
+	Size         [3]int        `json:"size"` // n1, n2, n3 as given to PrintResults (EP: log2 of the sample count)
+	Iterations   int           `json:"iterations"`
+	Time         float64       `json:"time_seconds"`
+	Mops         float64       `json:"mops"`
+	OpType       string        `json:"operation_type"`
+	Verified     bool          `json:"verified"`
+	Verification string        `json:"verification"`         // SUCCESSFUL, UNSUCCESSFUL, NOT PERFORMED or INCOMPLETE
+	Incomplete   bool          `json:"incomplete,omitempty"` // stopped early; Iterations counts the ones completed
+	Workers      int           `json:"workers"`
+	Schedule     string        `json:"schedule,omitempty"`    // loop schedule of the team, in GO_SCHEDULE syntax
+	ProcBind     string        `json:"proc_bind,omitempty"`   // binding of the workers, in GO_PROC_BIND syntax
+	Placement    []int         `json:"placement,omitempty"`   // core of each worker when they are bound
+	FirstTouch   bool          `json:"first_touch,omitempty"` // arrays zeroed by the workers that compute on them
+	NUMABytes    map[int]int64 `json:"numa_bytes,omitempty"`  // resident memory of the arrays per NUMA node
+	Timers       []Timer       `json:"timers,omitempty"`
+	NPBVersion   string        `json:"npb_version"`
+	CompileTime  string        `json:"compile_date"`
+	Compiler     string        `json:"compiler"`
+	Rand         string        `json:"rand,omitempty"`
+	GoVersion    string        `json:"go_version"`
+	Host         Host          `json:"host"`
+}
+
+// Timer is the time spent in one section of a benchmark (timer.flag runs)
+type Timer struct {
+	Name    string  `json:"name"`
+	Seconds float64 `json:"seconds"`
+}
+
+// Host describes the machine a benchmark ran on
+type Host struct {
+	Hostname   string `json:"hostname"`
+	OS         string `json:"os"`
+	Arch       string `json:"arch"`
+	NumCPU     int    `json:"num_cpu"`
+	GOMAXPROCS int    `json:"gomaxprocs"`
+}
+
+// resultOut receives the JSON document; nil means text output only
+var resultOut io.Writer
+
+// SetFormat selects how Report emits results: "text" prints the NPB banner
+// only, "json" also prints one JSON document on stdout. Progress gives the
+// writer the mains pass on for the banner and the other progress messages.
+func SetFormat(format string) error {
+	switch format {
+	case "text":
+		resultOut = nil
+	case "json":
+		resultOut = os.Stdout
+	default:
+		return fmt.Errorf("unknown result format %q (text or json)", format)
+	}
+	return nil
+}
+
+// Progress returns where the banner and progress messages go: stdout, or
+// stderr when stdout carries the JSON document
+func Progress() io.Writer {
+	if resultOut != nil {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+// VerificationStatus returns the banner wording of a verification outcome
+func VerificationStatus(class string, passed bool) string {
+	// Class U runs use user-supplied sizes with no reference values to check
+	switch {
+	case class == "U":
+		return "NOT PERFORMED"
+	case passed:
+		return "SUCCESSFUL"
+	default:
+		return "UNSUCCESSFUL"
+	}
+}
+
+// Failed reports whether the run did not pass its verification
+func (r Result) Failed() bool {
+	return !r.Incomplete && VerificationStatus(r.Class, r.Verified) == "UNSUCCESSFUL"
+}
+
+// Finish completes r with the run and host details and prints its NPB banner
+// to w
+func Finish(r *Result, w io.Writer) {
+	r.RunID = runID
+	r.Timestamp = time.Now().UTC().Format(time.RFC3339)
+	r.GitRevision = gitRevision()
+	r.Variant = Variant
+	r.Verification = VerificationStatus(r.Class, r.Verified)
+	if r.Incomplete {
+		r.Verification = "INCOMPLETE"
+	}
+	r.GoVersion = runtime.Version()
+	r.Host.Hostname, _ = os.Hostname()
+	r.Host.OS = runtime.GOOS
+	r.Host.Arch = runtime.GOARCH
+	r.Host.NumCPU = runtime.NumCPU()
+	r.Host.GOMAXPROCS = runtime.GOMAXPROCS(0)
+
+	PrintResults(w, r.Kernel, r.Class, r.Size[0], r.Size[1], r.Size[2], r.Iterations, r.Workers, r.Host.GOMAXPROCS, r.Time, r.Mops, r.OpType, r.Verification, r.NPBVersion, r.CompileTime, r.Compiler, r.Rand)
+}
+
+// Report prints the JSON document of a finished result in json format and
+// appends it to the results file when one is set
+func Report(r *Result) {
+	if resultOut != nil {
+		enc := json.NewEncoder(resultOut)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(r); err != nil {
+			fmt.Fprintf(os.Stderr, "writing JSON result: %v\n", err)
+		}
+	}
+	if resultsFile != "" {
+		if err := appendResult(*r); err != nil {
+			fmt.Fprintf(os.Stderr, "appending result to %s: %v\n", resultsFile, err)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package common
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Schedule kinds, as in OpenMP. The zero Schedule has no kind and stands for
+// the one of $GO_SCHEDULE.
+const (
+	STATIC  = iota + 1 // contiguous blocks, one per worker, or chunks dealt round robin
+	DYNAMIC            // chunks handed to whichever worker is free
+	GUIDED             // like DYNAMIC, with chunks shrinking as the loop runs out
+)
+
+// Schedule is how the loops of a team split their iterations among the
+// workers. Static schedules assign every iteration to the same worker on
+// every run, so reductions over them only depend on the number of workers.
+type Schedule struct {
+	Kind  int
+	Chunk int // iterations per chunk (the smallest chunk for GUIDED); 0 for the default
+}
+
+var scheduleNames = map[int]string{STATIC: "static", DYNAMIC: "dynamic", GUIDED: "guided"}
+
+// String returns s in the syntax of ParseSchedule
+func (s Schedule) String() string {
+	name, ok := scheduleNames[s.Kind]
+	if !ok {
+		return ""
+	}
+	if s.Chunk > 0 {
+		return name + "," + strconv.Itoa(s.Chunk)
+	}
+	return name
+}
+
+// ParseSchedule reads a schedule written as in OMP_SCHEDULE: "static",
+// "dynamic" or "guided", optionally followed by a comma and a chunk size
+func ParseSchedule(text string) (Schedule, error) {
+	name, chunk, hasChunk := strings.Cut(strings.TrimSpace(text), ",")
+	var s Schedule
+	for kind, n := range scheduleNames {
+		if strings.EqualFold(strings.TrimSpace(name), n) {
+			s.Kind = kind
+		}
+	}
+	if s.Kind == 0 {
+		return Schedule{}, fmt.Errorf("unknown schedule %q: want static, dynamic or guided, with an optional \",chunk\"", text)
+	}
+	if hasChunk {
+		n, err := strconv.Atoi(strings.TrimSpace(chunk))
+		if err != nil || n < 1 {
+			return Schedule{}, fmt.Errorf("schedule %q: chunk size must be a positive integer", text)
+		}
+		s.Chunk = n
+	}
+	return s, nil
+}
+
+// ResolveSchedule returns s, or when it has no kind the schedule of
+// $GO_SCHEDULE, which is static when the variable is unset or empty
+func ResolveSchedule(s Schedule) (Schedule, error) {
+	if s.Kind != 0 {
+		if _, ok := scheduleNames[s.Kind]; !ok || s.Chunk < 0 {
+			return Schedule{}, fmt.Errorf("invalid schedule kind %d, chunk %d", s.Kind, s.Chunk)
+		}
+		return s, nil
+	}
+	text := os.Getenv("GO_SCHEDULE")
+	if strings.TrimSpace(text) == "" {
+		return Schedule{Kind: STATIC}, nil
+	}
+	s, err := ParseSchedule(text)
+	if err != nil {
+		return Schedule{}, fmt.Errorf("GO_SCHEDULE: %w", err)
+	}
+	return s, nil
+}
@@ -0,0 +1,120 @@
+package common
+
+import (
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestParseSchedule(t *testing.T) {
+	for _, tc := range []struct {
+		text string
+		want Schedule
+		ok   bool
+	}{
+		{"static", Schedule{STATIC, 0}, true},
+		{"static,4", Schedule{STATIC, 4}, true},
+		{"dynamic", Schedule{DYNAMIC, 0}, true},
+		{" Dynamic , 16 ", Schedule{DYNAMIC, 16}, true},
+		{"guided", Schedule{GUIDED, 0}, true},
+		{"GUIDED,1", Schedule{GUIDED, 1}, true},
+		{"", Schedule{}, false},
+		{"auto", Schedule{}, false},
+		{"static,0", Schedule{}, false},
+		{"dynamic,-2", Schedule{}, false},
+		{"guided,", Schedule{}, false},
+		{"static,four", Schedule{}, false},
+		{",4", Schedule{}, false},
+	} {
+		got, err := ParseSchedule(tc.text)
+		if (err == nil) != tc.ok || got != tc.want {
+			t.Errorf("ParseSchedule(%q) = %v, %v; want %v, ok %v", tc.text, got, err, tc.want, tc.ok)
+		}
+		if tc.ok {
+			if back, err := ParseSchedule(got.String()); err != nil || back != got {
+				t.Errorf("ParseSchedule(%q) = %v, %v; want %v", got.String(), back, err, got)
+			}
+		}
+	}
+}
+
+func TestResolveSchedule(t *testing.T) {
+	for _, tc := range []struct {
+		s    Schedule
+		env  string
+		want Schedule
+		ok   bool
+	}{
+		{Schedule{}, "", Schedule{STATIC, 0}, true},
+		{Schedule{}, "  ", Schedule{STATIC, 0}, true},
+		{Schedule{}, "dynamic,2", Schedule{DYNAMIC, 2}, true},
+		{Schedule{GUIDED, 0}, "dynamic,2", Schedule{GUIDED, 0}, true},
+		{Schedule{}, "sometimes", Schedule{}, false},
+		{Schedule{Kind: 9}, "", Schedule{}, false},
+		{Schedule{STATIC, -1}, "", Schedule{}, false},
+	} {
+		t.Setenv("GO_SCHEDULE", tc.env)
+		got, err := ResolveSchedule(tc.s)
+		if (err == nil) != tc.ok || got != tc.want {
+			t.Errorf("ResolveSchedule(%v) with GO_SCHEDULE=%q = %v, %v; want %v, ok %v", tc.s, tc.env, got, err, tc.want, tc.ok)
+		}
+	}
+}
+
+// TestChunks checks the chunks each kind of schedule deals out. Static
+// schedules give every worker the same chunks on every run. Dynamic and
+// guided ones give whichever worker is free the next chunk, whose size only
+// depends on where it starts, so the chunks are checked in order of their
+// start.
+func TestChunks(t *testing.T) {
+	for _, tc := range []struct {
+		s          Schedule
+		workers    int
+		start, end int
+		perWorker  [][][2]int // static schedules: the chunks of each worker
+		chunks     [][2]int   // other schedules: all chunks by start
+	}{
+		{s: Schedule{STATIC, 0}, workers: 3, start: 0, end: 10,
+			perWorker: [][][2]int{{{0, 4}}, {{4, 7}}, {{7, 10}}}},
+		{s: Schedule{STATIC, 0}, workers: 4, start: 5, end: 13,
+			perWorker: [][][2]int{{{5, 7}}, {{7, 9}}, {{9, 11}}, {{11, 13}}}},
+		{s: Schedule{STATIC, 3}, workers: 2, start: 0, end: 10,
+			perWorker: [][][2]int{{{0, 3}, {6, 9}}, {{3, 6}, {9, 10}}}},
+		{s: Schedule{DYNAMIC, 0}, workers: 2, start: 2, end: 6,
+			chunks: [][2]int{{2, 3}, {3, 4}, {4, 5}, {5, 6}}},
+		{s: Schedule{DYNAMIC, 4}, workers: 3, start: 0, end: 10,
+			chunks: [][2]int{{0, 4}, {4, 8}, {8, 10}}},
+		{s: Schedule{GUIDED, 0}, workers: 2, start: 0, end: 10,
+			chunks: [][2]int{{0, 5}, {5, 8}, {8, 9}, {9, 10}}},
+		{s: Schedule{GUIDED, 3}, workers: 2, start: 100, end: 120,
+			chunks: [][2]int{{100, 110}, {110, 115}, {115, 118}, {118, 120}}},
+	} {
+		team := NewTeam(tc.workers, tc.s)
+		for run := 0; run < 5; run++ {
+			var mu sync.Mutex
+			perWorker := make([][][2]int, tc.workers)
+			var chunks [][2]int
+			team.For(tc.start, tc.end, func(lo, hi, id int) {
+				mu.Lock()
+				perWorker[id] = append(perWorker[id], [2]int{lo, hi})
+				chunks = append(chunks, [2]int{lo, hi})
+				mu.Unlock()
+			})
+			if tc.perWorker != nil {
+				for id := range perWorker {
+					if !slices.Equal(perWorker[id], tc.perWorker[id]) {
+						t.Errorf("%v, %d workers, [%d, %d): worker %d got %v, want %v",
+							tc.s, tc.workers, tc.start, tc.end, id, perWorker[id], tc.perWorker[id])
+					}
+				}
+				continue
+			}
+			slices.SortFunc(chunks, func(a, b [2]int) int { return a[0] - b[0] })
+			if !slices.Equal(chunks, tc.chunks) {
+				t.Errorf("%v, %d workers, [%d, %d): got chunks %v, want %v",
+					tc.s, tc.workers, tc.start, tc.end, chunks, tc.chunks)
+			}
+		}
+		team.Close()
+	}
+}
@@ -0,0 +1,397 @@
+package common
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// Team is a fixed set of goroutines that run the parallel loops of a kernel,
+// started once per run instead of once per loop. Worker 0 is the goroutine
+// that calls Run, For or Sum; the others wait between loops for the next
+// one. A team runs one loop at a time: its methods must not be called
+// concurrently, nor from within a loop of the same team.
+type Team struct {
+	workers  []*Worker
+	start    []chan func(*Worker) // one per worker but the first; closed by Close
+	barrier  *Barrier
+	schedule Schedule
+
+	// Iterations handed out by the current and the previous dynamic or
+	// guided loop, which the workers pick by the parity of their count of
+	// loops. Worker 0 resets one once every worker is past its loop.
+	next [2]paddedInt
+
+	// Per-worker slots of Worker.Sum and Worker.Max, padded so that two
+	// workers do not write to the same cache line
+	partial []paddedFloat
+
+	// Set by Bind: the core of each worker, and the cores the thread of
+	// worker 0 had before, which Close gives back
+	binding   Binding
+	placement []int
+	bound     bool
+	saved     []int
+}
+
+type paddedFloat struct {
+	v float64
+	_ [56]byte
+}
+
+type paddedInt struct {
+	v atomic.Int64
+	_ [56]byte
+}
+
+// Worker is the view a goroutine of a team has of a loop it runs
+type Worker struct {
+	ID   int // 0 to Workers()-1
+	team *Team
+	loop int // scheduled loops run so far, the same on every worker
+}
+
+// NewTeam starts a team of n goroutines, counting the caller's, whose loops
+// follow schedule s (static when s has no kind); n less than 1 is taken as 1.
+// Close stops them.
+func NewTeam(n int, s Schedule) *Team {
+	if n < 1 {
+		n = 1
+	}
+	if s.Kind == 0 {
+		s = Schedule{Kind: STATIC}
+	}
+	t := &Team{
+		workers:  make([]*Worker, n),
+		start:    make([]chan func(*Worker), n),
+		barrier:  NewBarrier(n),
+		schedule: s,
+		partial:  make([]paddedFloat, n),
+	}
+	for id := range t.workers {
+		t.workers[id] = &Worker{ID: id, team: t}
+	}
+	for id := 1; id < n; id++ {
+		t.start[id] = make(chan func(*Worker))
+		go t.serve(t.workers[id])
+	}
+	return t
+}
+
+// serve runs the loops handed to w until the team is closed
+func (t *Team) serve(w *Worker) {
+	for body := range t.start[w.ID] {
+		body(w)
+		t.barrier.Wait()
+	}
+}
+
+// Workers returns the number of goroutines of the team
+func (t *Team) Workers() int {
+	return len(t.workers)
+}
+
+// Schedule returns the schedule the loops of the team follow
+func (t *Team) Schedule() Schedule {
+	return t.schedule
+}
+
+// Close stops the goroutines of the team, which must be idle. After Bind it
+// must be called by the goroutine that called Bind.
+func (t *Team) Close() {
+	for _, c := range t.start[1:] {
+		close(c)
+	}
+	if t.bound {
+		if t.saved != nil {
+			setThreadAffinity(t.saved)
+		}
+		runtime.UnlockOSThread()
+	}
+}
+
+// Bind pins the workers of the team to cores under b. Every worker locks its
+// goroutine to its OS thread and restricts the thread to its core; the
+// goroutines of workers 1 and up keep their threads until Close, which hands
+// the caller's thread back with the cores it had. It does nothing when b
+// binds no worker.
+func (t *Team) Bind(b Binding) error {
+	t.binding = b
+	if b.Policy == BIND_NONE || b.Policy == 0 {
+		return nil
+	}
+	runtime.LockOSThread()
+	t.bound = true
+	usable, err := threadAffinity()
+	if err != nil {
+		return err
+	}
+	t.saved = usable
+	cores := b.placement(len(t.workers), usable)
+	placed := make([]int, len(t.workers))
+	errs := make([]error, len(t.workers))
+	t.Run(func(w *Worker) {
+		if w.ID != 0 {
+			runtime.LockOSThread()
+		}
+		if err := setThreadAffinity(cores[w.ID : w.ID+1]); err != nil {
+			errs[w.ID] = fmt.Errorf("worker %d: %w", w.ID, err)
+			return
+		}
+		got, err := threadAffinity()
+		if err != nil || len(got) != 1 {
+			errs[w.ID] = fmt.Errorf("worker %d: bound to core %d, runs on cores %s: %v", w.ID, cores[w.ID], FormatCores(got), err)
+			return
+		}
+		placed[w.ID] = got[0]
+	})
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	t.placement = placed
+	return nil
+}
+
+// Placement returns the core each worker is bound to, or nil when the
+// workers are not bound
+func (t *Team) Placement() []int {
+	return t.placement
+}
+
+// StartTeam starts a team of n workers whose loops follow schedule s and
+// binds them under b
+func StartTeam(n int, s Schedule, b Binding) (*Team, error) {
+	t := NewTeam(n, s)
+	if err := t.Bind(b); err != nil {
+		t.Close()
+		return nil, err
+	}
+	return t, nil
+}
+
+// Describe records the schedule, binding and placement of the team in r
+func (t *Team) Describe(r *Result) {
+	r.Schedule = t.schedule.String()
+	if t.binding.Policy != 0 {
+		r.ProcBind = t.binding.String()
+	}
+	r.Placement = t.placement
+}
+
+// Run runs body on every worker of the team, as a parallel region, and
+// returns once all of them have returned. Within body the workers split
+// loops with Range and synchronize with Barrier, Sum and Max.
+func (t *Team) Run(body func(w *Worker)) {
+	if len(t.workers) == 1 {
+		body(t.workers[0])
+		return
+	}
+	for _, c := range t.start[1:] {
+		c <- body
+	}
+	body(t.workers[0])
+	t.barrier.Wait()
+}
+
+// For runs body over the chunks of [start, end) that the schedule of the
+// team deals to each worker, and returns once every chunk is done. A range
+// with fewer iterations than workers runs on the caller alone.
+func (t *Team) For(start, end int, body func(lo, hi, id int)) {
+	if end-start < len(t.workers) {
+		if end > start {
+			body(start, end, 0)
+		}
+		return
+	}
+	t.Run(func(w *Worker) {
+		w.share(start, end, func(lo, hi int) { body(lo, hi, w.ID) })
+	})
+	t.resetLoop()
+}
+
+// Sum runs body over the chunks of [start, end) as For does and returns the
+// sum of the values it returned. The values of each worker are added up
+// first and the totals then in worker order, so under a static schedule the
+// result only depends on the number of workers.
+func (t *Team) Sum(start, end int, body func(lo, hi int) float64) float64 {
+	sum := 0.0
+	t.Run(func(w *Worker) {
+		local := 0.0
+		w.share(start, end, func(lo, hi int) { local += body(lo, hi) })
+		s := w.Sum(local)
+		if w.ID == 0 {
+			sum = s
+		}
+	})
+	t.resetLoop()
+	return sum
+}
+
+// resetLoop readies the counter of the last scheduled loop for the loop after
+// next. Worker 0 calls it once every worker is past that loop.
+func (t *Team) resetLoop() {
+	t.next[t.workers[0].loop%2].v.Store(0)
+}
+
+// For runs body over the chunks of [start, end) that the schedule of the
+// team deals to w, within a parallel region, like an OpenMP for: every
+// worker of the team must call it, and it ends with a barrier.
+func (w *Worker) For(start, end int, body func(lo, hi int)) {
+	w.share(start, end, body)
+	w.Barrier()
+	if w.ID == 0 {
+		w.team.resetLoop()
+	}
+}
+
+// share runs body over the chunks of [start, end) that the schedule deals to
+// w, without waiting for the other workers
+func (w *Worker) share(start, end int, body func(lo, hi int)) {
+	t := w.team
+	w.loop++
+	n := end - start
+	if n <= 0 {
+		return
+	}
+	nw := len(t.workers)
+	chunk := max(t.schedule.Chunk, 1)
+	switch t.schedule.Kind {
+	case STATIC:
+		if t.schedule.Chunk == 0 {
+			if lo, hi := w.Range(start, end); hi > lo {
+				body(lo, hi)
+			}
+			return
+		}
+		for lo := start + w.ID*chunk; lo < end; lo += nw * chunk {
+			body(lo, min(lo+chunk, end))
+		}
+	case DYNAMIC:
+		next := &t.next[w.loop%2].v
+		for {
+			lo := int(next.Add(int64(chunk))) - chunk
+			if lo >= n {
+				return
+			}
+			body(start+lo, start+min(lo+chunk, n))
+		}
+	case GUIDED:
+		next := &t.next[w.loop%2].v
+		for {
+			lo := int(next.Load())
+			if lo >= n {
+				return
+			}
+			size := max(chunk, (n-lo+nw-1)/nw)
+			if next.CompareAndSwap(int64(lo), int64(lo+size)) {
+				body(start+lo, start+min(lo+size, n))
+			}
+		}
+	}
+}
+
+// Range returns the block of [start, end) that w works on: the iterations
+// are split into contiguous blocks whose sizes differ by at most one
+func (w *Worker) Range(start, end int) (lo, hi int) {
+	n := end - start
+	if n <= 0 {
+		return start, start
+	}
+	nw := len(w.team.workers)
+	q, r := n/nw, n%nw
+	lo = start + w.ID*q + min(w.ID, r)
+	hi = lo + q
+	if w.ID < r {
+		hi++
+	}
+	return lo, hi
+}
+
+// Barrier waits for every worker of the team to reach it
+func (w *Worker) Barrier() {
+	w.team.barrier.Wait()
+}
+
+// Sum returns the sum of the values the workers of the team pass it, added
+// in worker order, to every one of them. It is a barrier.
+func (w *Worker) Sum(v float64) float64 {
+	t := w.team
+	if len(t.workers) == 1 {
+		return v
+	}
+	t.partial[w.ID].v = v
+	t.barrier.Wait()
+	sum := 0.0
+	for i := range t.partial {
+		sum += t.partial[i].v
+	}
+	t.barrier.Wait() // the slots are free once everyone has read them
+	return sum
+}
+
+// Max returns the largest of the values the workers of the team pass it to
+// every one of them. It is a barrier.
+func (w *Worker) Max(v float64) float64 {
+	t := w.team
+	if len(t.workers) == 1 {
+		return v
+	}
+	t.partial[w.ID].v = v
+	t.barrier.Wait()
+	m := t.partial[0].v
+	for i := range t.partial[1:] {
+		m = max(m, t.partial[i+1].v)
+	}
+	t.barrier.Wait()
+	return m
+}
+
+// BARRIER_SPINS is how many times a goroutine waiting at a barrier yields
+// before it blocks: a barrier is usually reached by all soon after the
+// first, and blocking costs a wakeup through the scheduler
+const BARRIER_SPINS = 64
+
+// Barrier is a reusable barrier for a fixed number of goroutines
+type Barrier struct {
+	n       int32
+	arrived atomic.Int32
+	phase   atomic.Uint32 // incremented each time all have arrived
+
+	mu   sync.Mutex
+	cond *sync.Cond
+}
+
+// NewBarrier returns a barrier for n goroutines
+func NewBarrier(n int) *Barrier {
+	b := &Barrier{n: int32(n)}
+	b.cond = sync.NewCond(&b.mu)
+	return b
+}
+
+// Wait blocks until all n goroutines have called it, after which the barrier
+// is ready for its next use
+func (b *Barrier) Wait() {
+	phase := b.phase.Load()
+	if b.arrived.Add(1) == b.n {
+		b.arrived.Store(0)
+		b.mu.Lock()
+		b.phase.Add(1)
+		b.mu.Unlock()
+		b.cond.Broadcast()
+		return
+	}
+	for i := 0; i < BARRIER_SPINS; i++ {
+		if b.phase.Load() != phase {
+			return
+		}
+		runtime.Gosched()
+	}
+	b.mu.Lock()
+	for b.phase.Load() == phase {
+		b.cond.Wait()
+	}
+	b.mu.Unlock()
+}
@@ -0,0 +1,197 @@
+package common
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// testSchedules lists a schedule of every kind, with and without a chunk
+var testSchedules = []Schedule{
+	{STATIC, 0}, {STATIC, 3}, {DYNAMIC, 0}, {DYNAMIC, 4}, {GUIDED, 0}, {GUIDED, 2},
+}
+
+// TestBarrier checks that a barrier can be reused phase after phase: no
+// goroutine gets past it before all have arrived
+func TestBarrier(t *testing.T) {
+	const n, phases = 5, 2000
+	b := NewBarrier(n)
+	var arrived [phases]atomic.Int32
+	var failures atomic.Int32
+	var wg sync.WaitGroup
+	for g := 0; g < n; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for p := range arrived {
+				arrived[p].Add(1)
+				b.Wait()
+				if arrived[p].Load() != n {
+					failures.Add(1)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if f := failures.Load(); f != 0 {
+		t.Errorf("%d goroutines got past the barrier before all had arrived", f)
+	}
+}
+
+// TestFor checks that every iteration of a loop runs exactly once, on a
+// worker of the team, under every schedule and for ranges with fewer
+// iterations than workers. Running many loops in a row also checks that
+// the counters of the dynamic and guided loops are readied for the loop
+// after next.
+func TestFor(t *testing.T) {
+	for _, s := range testSchedules {
+		for _, workers := range []int{1, 3, 8} {
+			team := NewTeam(workers, s)
+			for _, r := range [][2]int{{0, 0}, {4, 2}, {5, 7}, {0, 8}, {3, 100}, {0, 1000}} {
+				start, end := r[0], r[1]
+				for loop := 0; loop < 20; loop++ {
+					hits := make([]int, max(end, 0))
+					var badID atomic.Int32
+					var mu sync.Mutex
+					team.For(start, end, func(lo, hi, id int) {
+						if id < 0 || id >= workers {
+							badID.Add(1)
+						}
+						mu.Lock()
+						for i := lo; i < hi; i++ {
+							hits[i]++
+						}
+						mu.Unlock()
+					})
+					if badID.Load() != 0 {
+						t.Fatalf("%v, %d workers, [%d, %d): body got a worker id out of range", s, workers, start, end)
+					}
+					for i := start; i < end; i++ {
+						if hits[i] != 1 {
+							t.Fatalf("%v, %d workers, [%d, %d), loop %d: iteration %d ran %d times", s, workers, start, end, loop, i, hits[i])
+						}
+					}
+				}
+			}
+			team.Close()
+		}
+	}
+}
+
+// TestForFewIterations checks that a loop with fewer iterations than workers
+// runs on the caller alone
+func TestForFewIterations(t *testing.T) {
+	team := NewTeam(8, Schedule{DYNAMIC, 1})
+	defer team.Close()
+	calls := 0
+	team.For(10, 15, func(lo, hi, id int) {
+		calls++
+		if lo != 10 || hi != 15 || id != 0 {
+			t.Errorf("body(%d, %d, %d), want body(10, 15, 0)", lo, hi, id)
+		}
+	})
+	if calls != 1 {
+		t.Errorf("body ran %d times, want once", calls)
+	}
+}
+
+// TestSum checks Team.Sum under every schedule, and that under a static
+// schedule it adds up the same values in the same order on every run
+func TestSum(t *testing.T) {
+	for _, s := range testSchedules {
+		for _, workers := range []int{1, 3, 8} {
+			team := NewTeam(workers, s)
+			for _, n := range []int{0, 2, 1000} {
+				for loop := 0; loop < 10; loop++ {
+					got := team.Sum(0, n, func(lo, hi int) float64 {
+						sum := 0.0
+						for i := lo; i < hi; i++ {
+							sum += float64(i)
+						}
+						return sum
+					})
+					if want := float64(n * (n - 1) / 2); got != want {
+						t.Fatalf("%v, %d workers: sum of [0, %d) = %v, want %v", s, workers, n, got, want)
+					}
+				}
+			}
+			team.Close()
+		}
+	}
+
+	// Values whose sum depends on the order they are added in
+	team := NewTeam(7, Schedule{STATIC, 0})
+	defer team.Close()
+	sum := func() float64 {
+		return team.Sum(0, 1000, func(lo, hi int) float64 {
+			s := 0.0
+			for i := lo; i < hi; i++ {
+				s += 1.0 / float64(i+1)
+			}
+			return s
+		})
+	}
+	first := sum()
+	for loop := 0; loop < 50; loop++ {
+		if got := sum(); got != first {
+			t.Fatalf("run %d: sum = %v, first run gave %v", loop, got, first)
+		}
+	}
+}
+
+// TestWorkerLoops checks the loops and reductions of a parallel region:
+// Range splits a range into contiguous blocks whose sizes differ by at most
+// one, consecutive Worker.For loops each run every iteration once, and Sum
+// and Max give every worker the total and largest of the values passed in
+func TestWorkerLoops(t *testing.T) {
+	for _, s := range testSchedules {
+		for _, workers := range []int{1, 4, 9} {
+			team := NewTeam(workers, s)
+			const n, loops = 103, 50
+			blocks := make([][2]int, workers)
+			hits := make([][n]atomic.Int32, loops)
+			sums := make([]float64, workers)
+			maxes := make([]float64, workers)
+			team.Run(func(w *Worker) {
+				blocks[w.ID][0], blocks[w.ID][1] = w.Range(0, n)
+				for l := range hits {
+					w.For(0, n, func(lo, hi int) {
+						for i := lo; i < hi; i++ {
+							hits[l][i].Add(1)
+						}
+					})
+				}
+				sums[w.ID] = w.Sum(float64(w.ID + 1))
+				maxes[w.ID] = w.Max(float64(-w.ID * w.ID))
+				maxes[w.ID] += w.Max(float64(w.ID))
+			})
+			team.Close()
+
+			next := 0
+			for id, b := range blocks {
+				if b[0] != next || b[1]-b[0] < n/workers || b[1]-b[0] > n/workers+1 {
+					t.Errorf("%v, %d workers: worker %d got block [%d, %d)", s, workers, id, b[0], b[1])
+				}
+				next = b[1]
+			}
+			if next != n {
+				t.Errorf("%v, %d workers: blocks end at %d, want %d", s, workers, next, n)
+			}
+			for l := range hits {
+				for i := range hits[l] {
+					if c := hits[l][i].Load(); c != 1 {
+						t.Fatalf("%v, %d workers, loop %d: iteration %d ran %d times", s, workers, l, i, c)
+					}
+				}
+			}
+			for id := range sums {
+				if want := float64(workers * (workers + 1) / 2); sums[id] != want {
+					t.Errorf("%v, %d workers: worker %d got sum %v, want %v", s, workers, id, sums[id], want)
+				}
+				if want := float64(workers - 1); maxes[id] != want {
+					t.Errorf("%v, %d workers: worker %d got max %v, want %v", s, workers, id, maxes[id], want)
+				}
+			}
+		}
+	}
+}
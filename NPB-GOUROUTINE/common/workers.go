@@ -0,0 +1,45 @@
+package common
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// ResolveWorkers returns n, or when it is 0 the count of $GO_NUM_THREADS, or
+// one worker per CPU the process may run on when the variable is unset or
+// empty. The workers are goroutines; how many of them run at once is
+// GOMAXPROCS, which SetMaxProcs sets.
+func ResolveWorkers(n int) (int, error) {
+	if n < 0 {
+		return 0, fmt.Errorf("invalid number of workers %d", n)
+	}
+	if n > 0 {
+		return n, nil
+	}
+	text := strings.TrimSpace(os.Getenv("GO_NUM_THREADS"))
+	if text == "" {
+		return runtime.NumCPU(), nil
+	}
+	n, err := strconv.Atoi(text)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("GO_NUM_THREADS: %q is not a positive integer", text)
+	}
+	return n, nil
+}
+
+// SetMaxProcs sets GOMAXPROCS, the number of OS threads that run goroutines
+// at once, to n. When n is 0 it stays as the runtime set it from $GOMAXPROCS,
+// or to the number of CPUs. It is set once per process, unlike the number of
+// workers, which is per run.
+func SetMaxProcs(n int) error {
+	if n < 0 {
+		return fmt.Errorf("invalid GOMAXPROCS %d", n)
+	}
+	if n > 0 {
+		runtime.GOMAXPROCS(n)
+	}
+	return nil
+}
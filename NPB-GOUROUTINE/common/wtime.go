@@ -4,27 +4,31 @@ import (
 	"time"
 )
 
-var start [64]float64
-var elapsed [64]float64
+// Timers holds the stopwatches of one benchmark run, indexed by the kernel's
+// timer numbers
+type Timers struct {
+	start   [64]float64
+	elapsed [64]float64
+}
 
 func elapsedTime() float64 {
 	return float64(time.Now().UnixNano()) / 1e9
 }
 
-func TimerClear(n int) {
-	elapsed[n] = 0.0
+func (t *Timers) Clear(n int) {
+	t.elapsed[n] = 0.0
 }
 
-func TimerStart(n int) {
-	start[n] = elapsedTime()
+func (t *Timers) Start(n int) {
+	t.start[n] = elapsedTime()
 }
 
-func TimerStop(n int) {
+func (t *Timers) Stop(n int) {
 	now := elapsedTime()
-	t := now - start[n]
-	elapsed[n] += t
+	e := now - t.start[n]
+	t.elapsed[n] += e
 }
 
-func TimerRead(n int) float64 {
-	return elapsed[n]
+func (t *Timers) Read(n int) float64 {
+	return t.elapsed[n]
 }
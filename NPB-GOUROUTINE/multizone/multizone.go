@@ -0,0 +1,528 @@
+// Package multizone runs the NPB multi-zone benchmarks (BT-MZ, SP-MZ and
+// LU-MZ). The grid is split in x and y into zones that are advanced by the
+// solver of the single-zone benchmark, and before every time step each zone
+// copies into its x and y faces the points next to them in the neighbouring
+// zones, with periodic wrap-around in both directions.
+//
+// Parallelism is nested: the zones are distributed over zone groups, each
+// advancing its zones in one goroutine, and the loops of every zone are
+// split across the team of loop workers of its group. The zones are placed
+// largest first on the group with the fewest points, so that groups get
+// similar amounts of work when the zones differ in size.
+package multizone
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/common"
+)
+
+// Timers of the timer.flag section breakdown
+const (
+	T_TOTAL = iota + 1
+	T_EXCHANGE
+	T_ZONES
+	T_LAST = T_ZONES
+)
+
+// Solver advances the points of one zone
+type Solver interface {
+	// Initialize sets the zone back to its initial values
+	Initialize()
+	// Step advances the zone by one time step
+	Step()
+	// U returns the five conserved variables at every point of the zone,
+	// point (i, j, k) at (k*ny+j)*nx+i
+	U() [][5]float64
+	// Norms returns the sums over the interior points of the squared
+	// residual and of the squared error of the solution
+	Norms() (rhs, err [5]float64)
+	// Ops returns the floating point operations of one time step
+	Ops() float64
+}
+
+// Problem describes one class of a multi-zone benchmark
+type Problem struct {
+	Kernel         string // name in the banner and the result, e.g. "BT-MZ"
+	Class          string
+	XZones, YZones int     // zones in x and y
+	GX, GY, GZ     int     // points of the whole grid
+	Ratio          float64 // width of the widest zone over the narrowest, in x and in y; 1 for equal zones
+	Niter          int
+	DT             float64
+	XCRRef, XCERef [5]float64 // reference RMS norms of the residual and the error
+	// NewSolver creates the solver of a zone of nx x ny x GZ points whose
+	// first point is at (x0, y0) in the whole grid, with its loops run on
+	// the workers of team
+	NewSolver func(nx, ny, x0, y0 int, team *common.Team) Solver
+}
+
+// Config selects the goroutines of a run and how they are scheduled and bound
+type Config struct {
+	Workers    int             // goroutines; 0 means $GO_NUM_THREADS, or one per CPU
+	ZoneGroups int             // goroutines the zones are distributed over; 0 means $GO_ZONE_GROUPS, or as many as the zones and workers allow
+	Schedule   common.Schedule // of the loops of the zones; none means $GO_SCHEDULE
+	Bind       common.Binding  // of the workers to cores; none means $GO_PROC_BIND
+	Out        io.Writer       // progress messages and the NPB banner, discarded when nil
+}
+
+// Result is the outcome of a multi-zone run with the values it is verified on
+type Result struct {
+	common.Result
+	XCR        [5]float64 // RMS norms of the residual
+	XCE        [5]float64 // RMS norms of the solution error
+	ZoneGroups int        // goroutines the zones were distributed over
+}
+
+// zone is one block of the grid with the solver that advances it. Its first
+// and last points in x and y are copies of points of its neighbours.
+type zone struct {
+	nx, ny, nz int
+	x0, y0     int // position of the first point in the whole grid
+	points     int
+	solver     Solver
+
+	west, east, south, north *zone
+}
+
+// benchmark is one run of a multi-zone problem
+type benchmark struct {
+	Problem
+	zones       []*zone
+	groups      [][]*zone // zones of each group
+	loopWorkers []int     // loop workers of each group
+	numWorkers  int
+	schedule    common.Schedule
+	bind        common.Binding
+
+	// Started by run: the goroutines of the groups, worker g advancing the
+	// zones of group g, and the team of loop workers of each group, which
+	// worker g started and binds
+	groupTeam *common.Team
+	teams     []*common.Team
+
+	timersEnabled bool
+	timers        common.Timers
+	out           io.Writer
+}
+
+// Run runs the multi-zone problem p with the goroutines of cfg, split into
+// zone groups that each bind their loop workers to their share of the cores
+// of cfg.Bind. When ctx is done between two time steps the run stops there
+// and Run returns the partial result with a *common.IncompleteError.
+func Run(ctx context.Context, p Problem, cfg Config) (Result, error) {
+	if err := ctx.Err(); err != nil {
+		return Result{}, err
+	}
+	out := cfg.Out
+	if out == nil {
+		out = io.Discard
+	}
+	numWorkers, err := common.ResolveWorkers(cfg.Workers)
+	if err != nil {
+		return Result{}, err
+	}
+	numGroups, err := resolveGroups(cfg.ZoneGroups, p.XZones*p.YZones)
+	if err != nil {
+		return Result{}, err
+	}
+	sched, err := common.ResolveSchedule(cfg.Schedule)
+	if err != nil {
+		return Result{}, err
+	}
+	bind, err := common.ResolveBinding(cfg.Bind)
+	if err != nil {
+		return Result{}, err
+	}
+	mz := newBenchmark(p, numWorkers, numGroups, out)
+	mz.schedule = sched
+	mz.bind = bind
+	return mz.run(ctx)
+}
+
+// resolveGroups returns n, or when it is 0 the count of $GO_ZONE_GROUPS, or
+// one group per zone when the variable is unset or empty
+func resolveGroups(n, numZones int) (int, error) {
+	if n < 0 {
+		return 0, fmt.Errorf("invalid number of zone groups %d", n)
+	}
+	if n > 0 {
+		return n, nil
+	}
+	text := strings.TrimSpace(os.Getenv("GO_ZONE_GROUPS"))
+	if text == "" {
+		return numZones, nil
+	}
+	n, err := strconv.Atoi(text)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("GO_ZONE_GROUPS: %q is not a positive integer", text)
+	}
+	return n, nil
+}
+
+// widths splits n points into zones whose widths grow geometrically, the
+// last one ratio times the first
+func widths(n, zones int, ratio float64) []int {
+	w := make([]int, zones)
+	if zones == 1 || math.Abs(ratio-1.0) < 1.0e-10 {
+		for i := range w {
+			w[i] = (i+1)*n/zones - i*n/zones
+		}
+		return w
+	}
+
+	// The ends of the zones are rounded slightly down, which keeps the
+	// widths increasing
+	r := math.Exp(math.Log(ratio) / float64(zones-1))
+	smallest := float64(n) * (r - 1.0) / (math.Pow(r, float64(zones)) - 1.0)
+	start := 0
+	for i := range w {
+		end := n
+		if i < zones-1 {
+			end = int(smallest*(math.Pow(r, float64(i+1))-1.0)/(r-1.0) + 0.45)
+		}
+		w[i] = end - start
+		start = end
+	}
+	return w
+}
+
+// newBenchmark lays out the zones of p and distributes them and the workers
+// over the groups. start creates the solvers of the zones.
+func newBenchmark(p Problem, numWorkers, numGroups int, out io.Writer) *benchmark {
+	numZones := p.XZones * p.YZones
+	numGroups = min(numGroups, numZones, numWorkers)
+
+	mz := &benchmark{
+		Problem:    p,
+		zones:      make([]*zone, numZones),
+		groups:     make([][]*zone, numGroups),
+		numWorkers: numWorkers,
+		out:        out,
+	}
+
+	// Every zone has one more point on each x and y face, the neighbour's
+	wx, wy := widths(p.GX, p.XZones, p.Ratio), widths(p.GY, p.YZones, p.Ratio)
+	x0, y0 := make([]int, p.XZones), make([]int, p.YZones)
+	for i := 1; i < p.XZones; i++ {
+		x0[i] = x0[i-1] + wx[i-1]
+	}
+	for j := 1; j < p.YZones; j++ {
+		y0[j] = y0[j-1] + wy[j-1]
+	}
+	for j := 0; j < p.YZones; j++ {
+		for i := 0; i < p.XZones; i++ {
+			z := &zone{nx: wx[i] + 2, ny: wy[j] + 2, nz: p.GZ, x0: x0[i] - 1, y0: y0[j] - 1}
+			z.points = z.nx * z.ny * z.nz
+			mz.zones[j*p.XZones+i] = z
+		}
+	}
+	for j := 0; j < p.YZones; j++ {
+		for i := 0; i < p.XZones; i++ {
+			z := mz.zones[j*p.XZones+i]
+			z.west = mz.zones[j*p.XZones+(i+p.XZones-1)%p.XZones]
+			z.east = mz.zones[j*p.XZones+(i+1)%p.XZones]
+			z.south = mz.zones[(j+p.YZones-1)%p.YZones*p.XZones+i]
+			z.north = mz.zones[(j+1)%p.YZones*p.XZones+i]
+		}
+	}
+
+	// Largest zones first, each on the group with the fewest points so far
+	order := slices.Clone(mz.zones)
+	slices.SortStableFunc(order, func(a, b *zone) int { return b.points - a.points })
+	load := make([]int, numGroups)
+	for _, z := range order {
+		g := 0
+		for h := range load {
+			if load[h] < load[g] {
+				g = h
+			}
+		}
+		mz.groups[g] = append(mz.groups[g], z)
+		load[g] += z.points
+	}
+
+	// The workers are shared out evenly, the first groups (which hold the
+	// largest zones) getting the ones left over
+	mz.loopWorkers = make([]int, numGroups)
+	for g := range mz.loopWorkers {
+		mz.loopWorkers[g] = numWorkers / numGroups
+		if g < numWorkers%numGroups {
+			mz.loopWorkers[g]++
+		}
+	}
+	return mz
+}
+
+// start starts the goroutines of the groups, and in each of them the team of
+// its loop workers, bound to the cores the group gets of mz.bind, and
+// creates the solvers of its zones. close stops them.
+func (mz *benchmark) start() error {
+	binds, err := mz.bind.Split(mz.loopWorkers)
+	if err != nil {
+		return err
+	}
+	mz.groupTeam = common.NewTeam(len(mz.groups), common.Schedule{})
+	mz.teams = make([]*common.Team, len(mz.groups))
+	errs := make([]error, len(mz.groups))
+	mz.groupTeam.Run(func(w *common.Worker) {
+		g := w.ID
+		mz.teams[g], errs[g] = common.StartTeam(mz.loopWorkers[g], mz.schedule, binds[g])
+		if errs[g] != nil {
+			return
+		}
+		for _, z := range mz.groups[g] {
+			z.solver = mz.NewSolver(z.nx, z.ny, z.x0, z.y0, mz.teams[g])
+		}
+	})
+	if err := errors.Join(errs...); err != nil {
+		mz.close()
+		return err
+	}
+	return nil
+}
+
+// close stops the teams of the groups, each from the goroutine that started
+// it, and the goroutines of the groups
+func (mz *benchmark) close() {
+	mz.groupTeam.Run(func(w *common.Worker) {
+		if t := mz.teams[w.ID]; t != nil {
+			t.Close()
+		}
+	})
+	mz.groupTeam.Close()
+}
+
+// eachGroup runs task on the zones of every group, each group on its own
+// goroutine, and waits for all of them
+func (mz *benchmark) eachGroup(task func(zones []*zone)) {
+	mz.groupTeam.Run(func(w *common.Worker) {
+		task(mz.groups[w.ID])
+	})
+}
+
+// exchange copies into the x and y faces of every zone the points next to
+// them in its neighbours. Only the interior of the faces is copied, so a
+// zone reads nothing that another zone writes.
+func (mz *benchmark) exchange() {
+	if mz.timersEnabled {
+		mz.timers.Start(T_EXCHANGE)
+	}
+	mz.eachGroup(func(zones []*zone) {
+		for _, z := range zones {
+			u := z.solver.U()
+			w, e, s, n := z.west, z.east, z.south, z.north
+			wu, eu, su, nu := w.solver.U(), e.solver.U(), s.solver.U(), n.solver.U()
+			for k := 0; k < z.nz; k++ {
+				for j := 1; j < z.ny-1; j++ {
+					u[(k*z.ny+j)*z.nx] = wu[(k*w.ny+j)*w.nx+w.nx-2]
+					u[(k*z.ny+j)*z.nx+z.nx-1] = eu[(k*e.ny+j)*e.nx+1]
+				}
+				for i := 1; i < z.nx-1; i++ {
+					u[k*z.ny*z.nx+i] = su[(k*s.ny+s.ny-2)*s.nx+i]
+					u[(k*z.ny+z.ny-1)*z.nx+i] = nu[(k*n.ny+1)*n.nx+i]
+				}
+			}
+		}
+	})
+	if mz.timersEnabled {
+		mz.timers.Stop(T_EXCHANGE)
+	}
+}
+
+// step exchanges the zone faces and advances every zone by one time step
+func (mz *benchmark) step() {
+	mz.exchange()
+	if mz.timersEnabled {
+		mz.timers.Start(T_ZONES)
+	}
+	mz.eachGroup(func(zones []*zone) {
+		for _, z := range zones {
+			z.solver.Step()
+		}
+	})
+	if mz.timersEnabled {
+		mz.timers.Stop(T_ZONES)
+	}
+}
+
+// verify computes the residual and error norms over all zones and compares
+// them with the reference values of the class
+func (mz *benchmark) verify() (xcr, xce [5]float64, verified bool) {
+	const epsilon = 1.0e-8
+
+	for _, z := range mz.zones {
+		rhs, err := z.solver.Norms()
+		for m := 0; m < 5; m++ {
+			xcr[m] += rhs[m]
+			xce[m] += err[m]
+		}
+	}
+	interior := float64(mz.GX * mz.GY * (mz.GZ - 2))
+	for m := 0; m < 5; m++ {
+		xcr[m] = math.Sqrt(xcr[m] / interior)
+		xce[m] = math.Sqrt(xce[m] / interior)
+	}
+
+	fmt.Fprintf(mz.out, " Verification being performed for class %s\n", mz.Class)
+	fmt.Fprintf(mz.out, " accuracy setting for epsilon = %20.13E\n", epsilon)
+
+	verified = true
+	for _, norms := range []struct {
+		title     string
+		got, want [5]float64
+	}{
+		{"residual", xcr, mz.XCRRef},
+		{"solution error", xce, mz.XCERef},
+	} {
+		fmt.Fprintf(mz.out, " Comparison of RMS-norms of %s\n", norms.title)
+		for m := 0; m < 5; m++ {
+			dif := math.Abs((norms.got[m] - norms.want[m]) / norms.want[m])
+			if dif <= epsilon {
+				fmt.Fprintf(mz.out, "          %2d%20.13E%20.13E%20.13E\n", m+1, norms.got[m], norms.want[m], dif)
+			} else {
+				verified = false
+				fmt.Fprintf(mz.out, " FAILURE: %2d%20.13E%20.13E%20.13E\n", m+1, norms.got[m], norms.want[m], dif)
+			}
+		}
+	}
+
+	if verified {
+		fmt.Fprintf(mz.out, " Verification Successful\n")
+	} else {
+		fmt.Fprintf(mz.out, " Verification failed\n")
+	}
+	return xcr, xce, verified
+}
+
+// run performs the benchmark and returns its result, with an error when ctx
+// stopped its time steps early
+func (mz *benchmark) run(ctx context.Context) (Result, error) {
+	if _, err := os.Stat("timer.flag"); err == nil {
+		mz.timersEnabled = true
+	}
+	if err := mz.start(); err != nil {
+		return Result{}, err
+	}
+	defer mz.close()
+
+	smallest, largest := mz.zones[0].points, mz.zones[0].points
+	ops := 0.0
+	for _, z := range mz.zones {
+		smallest = min(smallest, z.points)
+		largest = max(largest, z.points)
+		ops += z.solver.Ops()
+	}
+	heaviest, total := 0, 0
+	for _, zs := range mz.groups {
+		load := 0
+		for _, z := range zs {
+			load += z.points
+		}
+		heaviest = max(heaviest, load)
+		total += load
+	}
+
+	fmt.Fprintf(mz.out, "\n\n NAS Parallel Benchmarks 4.1 Go Goroutine version - %s Benchmark\n\n", mz.Kernel)
+	fmt.Fprintf(mz.out, " Number of zones: %3d x %3d\n", mz.XZones, mz.YZones)
+	fmt.Fprintf(mz.out, " Total mesh size: %4dx%4dx%4d\n", mz.GX, mz.GY, mz.GZ)
+	fmt.Fprintf(mz.out, " Iterations: %4d    dt: %10.6f\n", mz.Niter, mz.DT)
+	fmt.Fprintf(mz.out, " Zone sizes: %d to %d points\n", smallest, largest)
+	fmt.Fprintf(mz.out, " Number of available workers: %d\n", mz.numWorkers)
+	fmt.Fprintf(mz.out, " Zone groups: %d, loop workers per group: %d to %d\n",
+		len(mz.groups), slices.Min(mz.loopWorkers), slices.Max(mz.loopWorkers))
+	fmt.Fprintf(mz.out, " Load imbalance: %.3f (heaviest group over the mean)\n\n",
+		float64(heaviest)*float64(len(mz.groups))/float64(total))
+
+	// Do one time step to touch all code, and reinitialize
+	mz.step()
+	for _, z := range mz.zones {
+		z.solver.Initialize()
+	}
+
+	for i := 1; i <= T_LAST; i++ {
+		mz.timers.Clear(i)
+	}
+	mz.timers.Start(T_TOTAL)
+
+	iterations := mz.Niter
+	for step := 1; step <= mz.Niter; step++ {
+		if ctx.Err() != nil {
+			iterations = step - 1
+			break
+		}
+		if step%20 == 0 || step == 1 {
+			fmt.Fprintf(mz.out, " Time step %4d\n", step)
+		}
+		mz.step()
+	}
+
+	mz.timers.Stop(T_TOTAL)
+	tmax := mz.timers.Read(T_TOTAL)
+
+	var xcr, xce [5]float64
+	verified := false
+	incomplete := iterations < mz.Niter
+	if incomplete {
+		fmt.Fprintf(mz.out, "\n Benchmark stopped after %d of %d time steps\n", iterations, mz.Niter)
+		fmt.Fprintf(mz.out, " NO VERIFICATION PERFORMED\n")
+	} else {
+		xcr, xce, verified = mz.verify()
+	}
+
+	mflops := 0.0
+	if tmax != 0.0 {
+		mflops = 1.0e-6 * float64(iterations) * ops / tmax
+	}
+
+	result := common.Result{
+		Kernel:      mz.Kernel,
+		Class:       mz.Class,
+		Size:        [3]int{mz.GX, mz.GY, mz.GZ},
+		Iterations:  iterations,
+		Time:        tmax,
+		Mops:        mflops,
+		OpType:      "floating point",
+		Verified:    verified,
+		Incomplete:  incomplete,
+		Workers:     mz.numWorkers,
+		NPBVersion:  "4.1",
+		CompileTime: "Unknown",
+		Compiler:    "Go",
+	}
+	// The teams bind to lists of cores; the result holds the binding asked
+	// for and the cores of the workers of every group in turn
+	mz.teams[0].Describe(&result)
+	result.ProcBind = mz.bind.String()
+	result.Placement = nil
+	for _, t := range mz.teams {
+		result.Placement = append(result.Placement, t.Placement()...)
+	}
+	if mz.timersEnabled {
+		names := []string{"", "total", "exchange", "zones"}
+		for i := 1; i <= T_LAST; i++ {
+			result.Timers = append(result.Timers, common.Timer{Name: names[i], Seconds: mz.timers.Read(i)})
+		}
+	}
+	common.Finish(&result, mz.out)
+
+	if mz.timersEnabled {
+		fmt.Fprintln(mz.out, "  SECTION   Time (secs)")
+		for _, t := range result.Timers {
+			fmt.Fprintf(mz.out, "  %-8s:%9.3f  (%6.2f%%)\n", t.Name, t.Seconds, t.Seconds*100.0/tmax)
+		}
+	}
+	res := Result{result, xcr, xce, len(mz.groups)}
+	if incomplete {
+		return res, &common.IncompleteError{Completed: iterations, Planned: mz.Niter, Err: ctx.Err()}
+	}
+	return res, nil
+}
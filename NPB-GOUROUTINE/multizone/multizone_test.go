@@ -0,0 +1,99 @@
+package multizone
+
+import (
+	"io"
+	"testing"
+
+	"github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/common"
+)
+
+// position is a Solver whose points hold their position in the whole grid,
+// x and y wrapped around, so that an exchange can be checked against the
+// layout of the zones alone
+type position struct {
+	u [][5]float64
+}
+
+func (s *position) Initialize()                  {}
+func (s *position) Step()                        {}
+func (s *position) U() [][5]float64              { return s.u }
+func (s *position) Norms() (rhs, err [5]float64) { return rhs, err }
+func (s *position) Ops() float64                 { return 0 }
+
+// wrap returns x in [0, n)
+func wrap(x, n int) int {
+	return (x%n + n) % n
+}
+
+func TestWidths(t *testing.T) {
+	for _, tc := range []struct {
+		n, zones int
+		ratio    float64
+	}{
+		{24, 2, 4.5}, {64, 4, 4.5}, {304, 8, 4.5}, {64, 4, 1.0}, {10, 3, 1.0}, {7, 1, 4.5},
+	} {
+		w := widths(tc.n, tc.zones, tc.ratio)
+		sum := 0
+		for i, x := range w {
+			sum += x
+			if x < 1 || (i > 0 && x < w[i-1]) {
+				t.Errorf("widths(%d, %d, %g) = %v, want positive and increasing", tc.n, tc.zones, tc.ratio, w)
+				break
+			}
+		}
+		if sum != tc.n {
+			t.Errorf("widths(%d, %d, %g) = %v, sum %d", tc.n, tc.zones, tc.ratio, w, sum)
+		}
+		if tc.zones > 1 && tc.ratio == 1.0 && w[len(w)-1]-w[0] > 1 {
+			t.Errorf("widths(%d, %d, 1) = %v, want equal widths", tc.n, tc.zones, w)
+		}
+	}
+}
+
+// TestExchange checks that every zone gets on its x and y faces the points
+// of the whole grid next to it, wrapped around at the edges of the grid
+func TestExchange(t *testing.T) {
+	p := Problem{XZones: 3, YZones: 2, GX: 20, GY: 14, GZ: 4, Ratio: 2.5}
+	p.NewSolver = func(nx, ny, x0, y0 int, _ *common.Team) Solver {
+		s := &position{u: make([][5]float64, nx*ny*p.GZ)}
+		for k := 0; k < p.GZ; k++ {
+			for j := 0; j < ny; j++ {
+				for i := 0; i < nx; i++ {
+					pt := &s.u[(k*ny+j)*nx+i]
+					if i == 0 || j == 0 || i == nx-1 || j == ny-1 {
+						*pt = [5]float64{-1, -1, -1, -1, -1}
+					} else {
+						*pt = [5]float64{float64(wrap(x0+i, p.GX)), float64(wrap(y0+j, p.GY)), float64(k)}
+					}
+				}
+			}
+		}
+		return s
+	}
+
+	for _, groups := range []int{1, 2, 6} {
+		mz := newBenchmark(p, 6, groups, io.Discard)
+		mz.bind = common.Binding{Policy: common.BIND_NONE}
+		if err := mz.start(); err != nil {
+			t.Fatal(err)
+		}
+		mz.exchange()
+		mz.close()
+		for n, z := range mz.zones {
+			u := z.solver.U()
+			for k := 0; k < z.nz; k++ {
+				for j := 0; j < z.ny; j++ {
+					for i := 0; i < z.nx; i++ {
+						if (i == 0 || i == z.nx-1) == (j == 0 || j == z.ny-1) {
+							continue // interior points, and the corners no stencil reads
+						}
+						want := [5]float64{float64(wrap(z.x0+i, p.GX)), float64(wrap(z.y0+j, p.GY)), float64(k)}
+						if got := u[(k*z.ny+j)*z.nx+i]; got != want {
+							t.Fatalf("%d groups: zone %d point (%d, %d, %d) = %v, want %v", groups, n, i, j, k, got, want)
+						}
+					}
+				}
+			}
+		}
+	}
+}
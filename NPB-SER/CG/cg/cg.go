@@ -1,11 +1,15 @@
-package main
+// Package cg is the CG (Conjugate Gradient) kernel: it estimates the smallest
+// eigenvalue of a large sparse symmetric positive definite matrix.
+package cg
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"math"
-	"os"
 	"time"
 
+	"github.com/iyisakuma/NPB-GO/NPB-SER/CG/params"
 	"github.com/iyisakuma/NPB-GO/NPB-SER/common"
 )
 
@@ -17,8 +21,34 @@ const (
 	MAX_NONZER     = 26
 )
 
-// Global variables
-var (
+// Config selects the problem a Run solves
+type Config struct {
+	Params params.Params // problem size, from params.Lookup or params.Custom
+	Out    io.Writer     // progress messages and the NPB banner, discarded when nil
+}
+
+// Result is the outcome of a CG run with the zeta it is verified on
+type Result struct {
+	common.Result
+	Zeta float64
+}
+
+// Run generates the matrix of cfg.Params, runs the benchmark on it and returns
+// its result. When ctx is done between two iterations the run stops there and
+// Run returns the partial result with a *common.IncompleteError.
+func Run(ctx context.Context, cfg Config) (Result, error) {
+	if err := ctx.Err(); err != nil {
+		return Result{}, err
+	}
+	out := cfg.Out
+	if out == nil {
+		out = io.Discard
+	}
+	return NewCGBenchmark(cfg.Params, out).run(ctx)
+}
+
+// CGBenchmark represents the CG benchmark
+type CGBenchmark struct {
 	// Problem size parameters
 	NA     int
 	NZ     int
@@ -26,40 +56,38 @@ var (
 	SHIFT  float64
 	NONZER int
 
-	// Arrays
-	a      []float64
-	colidx []int
-	rowstr []int
-	x      []float64
-	z      []float64
-	p      []float64
-	q      []float64
-	r      []float64
-
-	// Verification
-	zeta            float64
-	zetaVerifyValue float64
-	classNPB        string
-	verified        bool
-)
-
-// CGBenchmark represents the CG benchmark
-type CGBenchmark struct {
 	naa      int
 	nzz      int
 	firstrow int
 	lastrow  int
 	firstcol int
 	lastcol  int
+
+	// Verification
+	zetaVerifyValue float64
+	classNPB        string
+
+	out io.Writer
 }
 
-// NewCGBenchmark creates a new CG benchmark instance
-func NewCGBenchmark() *CGBenchmark {
+// NewCGBenchmark creates a CG benchmark instance for one problem size
+func NewCGBenchmark(prob params.Params, out io.Writer) *CGBenchmark {
+	nz := prob.NA * (prob.NONZER + 1) * (prob.NONZER + 1)
 	return &CGBenchmark{
-		firstrow: 0,
-		lastrow:  NA - 1,
-		firstcol: 0,
-		lastcol:  NA - 1,
+		NA:              prob.NA,
+		NZ:              nz,
+		NITER:           prob.NITER,
+		SHIFT:           prob.SHIFT,
+		NONZER:          prob.NONZER,
+		naa:             prob.NA,
+		nzz:             nz,
+		firstrow:        0,
+		lastrow:         prob.NA - 1,
+		firstcol:        0,
+		lastcol:         prob.NA - 1,
+		zetaVerifyValue: prob.ZETA_VERIFY_VALUE,
+		classNPB:        prob.CLASS,
+		out:             out,
 	}
 }
 
@@ -114,9 +142,29 @@ func vecset(n int, v []float64, iv []int, nzv *int, i int, val float64) {
 	}
 }
 
+// SparseSpaceError reports that the matrix generated by sparse needs more
+// nonzeros than were allocated for it
+type SparseSpaceError struct {
+	NZA, NZMax int
+}
+
+func (e *SparseSpaceError) Error() string {
+	return fmt.Sprintf("space for matrix elements exceeded in sparse: nza, nzmax = %d, %d", e.NZA, e.NZMax)
+}
+
+// SparseInternalError reports an element of row Row that sparse found no
+// slot for
+type SparseInternalError struct {
+	Row int
+}
+
+func (e *SparseInternalError) Error() string {
+	return fmt.Sprintf("internal error in sparse: i=%d", e.Row)
+}
+
 // sparse generates a sparse matrix from a list of [col, row, element] triples
 func sparse(a []float64, colidx []int, rowstr []int, n int, nz int, nozer int,
-	arow []int, acol [][]int, aelt [][]float64, firstrow, lastrow int, nzloc []int, rcond, shift float64) {
+	arow []int, acol [][]int, aelt [][]float64, firstrow, lastrow int, nzloc []int, rcond, shift float64) error {
 
 	nrows := lastrow - firstrow + 1
 
@@ -137,9 +185,7 @@ func sparse(a []float64, colidx []int, rowstr []int, n int, nz int, nozer int,
 	nza := rowstr[nrows] - 1
 
 	if nza > nz {
-		fmt.Printf("Space for matrix elements exceeded in sparse\n")
-		fmt.Printf("nza, nzmax = %d, %d\n", nza, nz)
-		os.Exit(1)
+		return &SparseSpaceError{NZA: nza, NZMax: nz}
 	}
 
 	// Preload data pages
@@ -194,8 +240,7 @@ func sparse(a []float64, colidx []int, rowstr []int, n int, nz int, nozer int,
 					}
 				}
 				if !goto40 {
-					fmt.Printf("internal error in sparse: i=%d\n", i)
-					os.Exit(1)
+					return &SparseInternalError{Row: i}
 				}
 				a[k] += va
 			}
@@ -224,11 +269,12 @@ func sparse(a []float64, colidx []int, rowstr []int, n int, nz int, nozer int,
 	for j := 1; j < nrows+1; j++ {
 		rowstr[j] -= nzloc[j-1]
 	}
+	return nil
 }
 
 // makea generates the sparse matrix A - complete implementation
 func (cg *CGBenchmark) makea(naa, nzz int, a []float64, colidx []int, rowstr []int,
-	firstrow, lastrow, firstcol, lastcol int) {
+	firstrow, lastrow, firstcol, lastcol int) error {
 
 	// Initialize random number generator
 	tran := 314159265.0
@@ -240,8 +286,8 @@ func (cg *CGBenchmark) makea(naa, nzz int, a []float64, colidx []int, rowstr []i
 	acol := make([][]int, naa)
 	aelt := make([][]float64, naa)
 	for i := 0; i < naa; i++ {
-		acol[i] = make([]int, NONZER+1)
-		aelt[i] = make([]float64, NONZER+1)
+		acol[i] = make([]int, cg.NONZER+1)
+		aelt[i] = make([]float64, cg.NONZER+1)
 	}
 	nzloc := make([]int, lastrow-firstrow+1)
 
@@ -253,9 +299,9 @@ func (cg *CGBenchmark) makea(naa, nzz int, a []float64, colidx []int, rowstr []i
 
 	// Generate nonzero positions and save for the use in sparse
 	for iouter := 0; iouter < naa; iouter++ {
-		nzv := NONZER
-		ivc := make([]int, NONZER+1)
-		vc := make([]float64, NONZER+1)
+		nzv := cg.NONZER
+		ivc := make([]int, cg.NONZER+1)
+		vc := make([]float64, cg.NONZER+1)
 		sprnvc(naa, nzv, nn1, vc, ivc, &tran)
 		vecset(naa, vc, ivc, &nzv, iouter+1, 0.5)
 		arow[iouter] = nzv
@@ -266,7 +312,7 @@ func (cg *CGBenchmark) makea(naa, nzz int, a []float64, colidx []int, rowstr []i
 	}
 
 	// Make the sparse matrix from list of elements with duplicates
-	sparse(a, colidx, rowstr, naa, nzz, NONZER, arow, acol, aelt, firstrow, lastrow, nzloc, 0.1, SHIFT)
+	return sparse(a, colidx, rowstr, naa, nzz, cg.NONZER, arow, acol, aelt, firstrow, lastrow, nzloc, 0.1, cg.SHIFT)
 }
 
 // conj_grad performs conjugate gradient algorithm
@@ -277,7 +323,7 @@ func (cg *CGBenchmark) conj_grad(colidx []int, rowstr []int, x []float64, z []fl
 	var d, rho, rho0, alpha, beta float64
 
 	// Initialize the CG algorithm
-	for i := 0; i < NA+1; i++ {
+	for i := 0; i < cg.NA+1; i++ {
 		q[i] = 0.0
 		z[i] = 0.0
 		r[i] = x[i]
@@ -293,7 +339,7 @@ func (cg *CGBenchmark) conj_grad(colidx []int, rowstr []int, x []float64, z []fl
 	// The conjugate gradient iteration loop
 	for cgit := 1; cgit <= cgitmax; cgit++ {
 		// q = A.p (matrix-vector multiply) - following C++ implementation
-		for i := 0; i < NA; i++ {
+		for i := 0; i < cg.NA; i++ {
 			sum := 0.0
 			for j := rowstr[i]; j < rowstr[i+1]; j++ {
 				sum += a[j] * p[colidx[j]]
@@ -346,7 +392,7 @@ func (cg *CGBenchmark) conj_grad(colidx []int, rowstr []int, x []float64, z []fl
 	// Compute residual norm explicitly: ||r|| = ||x - A.z||
 	// First, form A.z
 	sum := 0.0
-	for i := 0; i < NA; i++ {
+	for i := 0; i < cg.NA; i++ {
 		d := 0.0
 		for j := rowstr[i]; j < rowstr[i+1]; j++ {
 			d += a[j] * z[colidx[j]]
@@ -362,8 +408,19 @@ func (cg *CGBenchmark) conj_grad(colidx []int, rowstr []int, x []float64, z []fl
 	*rnorm = math.Sqrt(sum)
 }
 
-// run performs the CG benchmark
-func (cg *CGBenchmark) run() {
+// run performs the CG benchmark and returns its result, or the error that
+// kept the matrix from being generated or stopped the iterations early
+func (cg *CGBenchmark) run(ctx context.Context) (Result, error) {
+	// Allocate arrays
+	a := make([]float64, cg.NZ)
+	colidx := make([]int, cg.NZ)
+	rowstr := make([]int, cg.NA+1)
+	x := make([]float64, cg.NA+1)
+	z := make([]float64, cg.NA+1)
+	p := make([]float64, cg.NA+1)
+	q := make([]float64, cg.NA+1)
+	r := make([]float64, cg.NA+1)
+
 	// Initialize arrays
 	naa := cg.naa
 	nzz := cg.nzz
@@ -374,7 +431,9 @@ func (cg *CGBenchmark) run() {
 	common.Randlc(&tran, amult)
 
 	// Generate matrix
-	cg.makea(naa, nzz, a, colidx, rowstr, cg.firstrow, cg.lastrow, cg.firstcol, cg.lastcol)
+	if err := cg.makea(naa, nzz, a, colidx, rowstr, cg.firstrow, cg.lastrow, cg.firstcol, cg.lastcol); err != nil {
+		return Result{}, err
+	}
 
 	// Shift column indices
 	for j := 0; j < cg.lastrow-cg.firstrow+1; j++ {
@@ -384,19 +443,20 @@ func (cg *CGBenchmark) run() {
 	}
 
 	// Set starting vector to (1, 1, ..., 1)
-	for i := 0; i < NA+1; i++ {
+	for i := 0; i < cg.NA+1; i++ {
 		x[i] = 1.0
 	}
 
 	// Initialize vectors
-	for j := 0; j < NA; j++ {
+	for j := 0; j < cg.NA; j++ {
 		q[j] = 0.0
 		z[j] = 0.0
 		r[j] = 0.0
 		p[j] = 0.0
 	}
 
-	zeta = 0.0
+	zeta := 0.0
+	verified := false
 
 	// Do one iteration untimed to init all code and data page tables
 	for it := 1; it <= 1; it++ {
@@ -420,7 +480,7 @@ func (cg *CGBenchmark) run() {
 	}
 
 	// Set starting vector to (1, 1, ..., 1) again
-	for i := 0; i < NA+1; i++ {
+	for i := 0; i < cg.NA+1; i++ {
 		x[i] = 1.0
 	}
 	zeta = 0.0
@@ -428,7 +488,13 @@ func (cg *CGBenchmark) run() {
 	// Main CG loop
 	startTime := time.Now()
 
-	for it := 1; it <= NITER; it++ {
+	iterations := cg.NITER
+	for it := 1; it <= cg.NITER; it++ {
+		if ctx.Err() != nil {
+			iterations = it - 1
+			break
+		}
+
 		// Perform conjugate gradient
 		var rnorm float64
 		cg.conj_grad(colidx, rowstr, x, z, a, p, q, r, &rnorm)
@@ -441,12 +507,12 @@ func (cg *CGBenchmark) run() {
 			norm_temp2 += z[j] * z[j]
 		}
 		norm_temp2 = 1.0 / math.Sqrt(norm_temp2)
-		zeta = SHIFT + 1.0/norm_temp1
+		zeta = cg.SHIFT + 1.0/norm_temp1
 
 		if it == 1 {
-			fmt.Printf("\n   iteration           ||r||                 zeta\n")
+			fmt.Fprintf(cg.out, "\n   iteration           ||r||                 zeta\n")
 		}
-		fmt.Printf("    %5d       %20.14e%20.13e\n", it, rnorm, zeta)
+		fmt.Fprintf(cg.out, "    %5d       %20.14e%20.13e\n", it, rnorm, zeta)
 
 		// Normalize z to obtain x
 		for j := 0; j < cg.lastcol-cg.firstcol+1; j++ {
@@ -458,24 +524,55 @@ func (cg *CGBenchmark) run() {
 	elapsed := endTime.Sub(startTime).Seconds()
 
 	// Calculate Mop/s using the same formula as C++
-	mops := float64(2*NITER*NA) * (3.0 + float64(NONZER*(NONZER+1)) + 25.0*(5.0+float64(NONZER*(NONZER+1))) + 3.0) / elapsed / 1e6
+	mops := float64(2*iterations*cg.NA) * (3.0 + float64(cg.NONZER*(cg.NONZER+1)) + 25.0*(5.0+float64(cg.NONZER*(cg.NONZER+1))) + 3.0) / elapsed / 1e6
 
 	// Verify result
-	verified = math.Abs(zeta-zetaVerifyValue) < 1e-10
-	err := math.Abs(zeta-zetaVerifyValue) / zetaVerifyValue
-
-	// Print detailed verification results
-	fmt.Printf("\n Benchmark completed\n")
-	if verified {
-		fmt.Printf(" VERIFICATION SUCCESSFUL\n")
-		fmt.Printf(" Zeta is    %20.13e\n", zeta)
-		fmt.Printf(" Error is   %20.13e\n", err)
+	incomplete := iterations < cg.NITER
+	if incomplete {
+		fmt.Fprintf(cg.out, "\n Benchmark stopped after %d of %d iterations\n", iterations, cg.NITER)
+		fmt.Fprintf(cg.out, " NO VERIFICATION PERFORMED\n")
+		fmt.Fprintf(cg.out, " Zeta is    %20.13e\n", zeta)
+	} else if cg.classNPB == "U" {
+		fmt.Fprintf(cg.out, "\n Benchmark completed\n")
+		fmt.Fprintf(cg.out, " Problem size unknown\n")
+		fmt.Fprintf(cg.out, " NO VERIFICATION PERFORMED\n")
+		fmt.Fprintf(cg.out, " Zeta is    %20.13e\n", zeta)
 	} else {
-		fmt.Printf(" VERIFICATION FAILED\n")
-		fmt.Printf(" Zeta                %20.13e\n", zeta)
-		fmt.Printf(" The correct zeta is %20.13e\n", zetaVerifyValue)
+		fmt.Fprintf(cg.out, "\n Benchmark completed\n")
+		verified = math.Abs(zeta-cg.zetaVerifyValue) < 1e-10
+		err := math.Abs(zeta-cg.zetaVerifyValue) / cg.zetaVerifyValue
+
+		// Print detailed verification results
+		if verified {
+			fmt.Fprintf(cg.out, " VERIFICATION SUCCESSFUL\n")
+			fmt.Fprintf(cg.out, " Zeta is    %20.13e\n", zeta)
+			fmt.Fprintf(cg.out, " Error is   %20.13e\n", err)
+		} else {
+			fmt.Fprintf(cg.out, " VERIFICATION FAILED\n")
+			fmt.Fprintf(cg.out, " Zeta                %20.13e\n", zeta)
+			fmt.Fprintf(cg.out, " The correct zeta is %20.13e\n", cg.zetaVerifyValue)
+		}
 	}
 
 	// Print results
-	common.PrintResults("CG", classNPB, NA, 0, 0, NITER, elapsed, mops, "conjugate gradient", verified, "4.1", "Unknown", "Go", "")
+	result := common.Result{
+		Kernel:      "CG",
+		Class:       cg.classNPB,
+		Size:        [3]int{cg.NA, 0, 0},
+		Iterations:  iterations,
+		Time:        elapsed,
+		Mops:        mops,
+		OpType:      "conjugate gradient",
+		Verified:    verified,
+		Incomplete:  incomplete,
+		Workers:     1,
+		NPBVersion:  "4.1",
+		CompileTime: "Unknown",
+		Compiler:    "Go",
+	}
+	common.Finish(&result, cg.out)
+	if incomplete {
+		return Result{result, zeta}, &common.IncompleteError{Completed: iterations, Planned: cg.NITER, Err: ctx.Err()}
+	}
+	return Result{result, zeta}, nil
 }
@@ -1,43 +1,67 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"os"
+	"strings"
 
+	"github.com/iyisakuma/NPB-GO/NPB-SER/CG/cg"
 	"github.com/iyisakuma/NPB-GO/NPB-SER/CG/params"
+	"github.com/iyisakuma/NPB-GO/NPB-SER/common"
 )
 
 func main() {
-	if params.EmptyTag {
-		fmt.Println("To make a NAS benchmark type ")
-		fmt.Println("\t go build -o cg -tags=<CLASS>")
-		fmt.Println("where: <class> is \"S\", \"W\", \"A\", \"B\", \"C\", \"D\" or \"E\"")
-		return
-	}
-
-	// Set global variables from params
-	NA = params.NA
-	NITER = params.NITER
-	SHIFT = params.SHIFT
-	NONZER = params.NONZER
-	NZ = NA * (NONZER + 1) * (NONZER + 1)
-	zetaVerifyValue = params.ZETA_VERIFY_VALUE
-	classNPB = params.CLASS
+	class := flag.String("class", "S", "problem class (S, W, A, B, C, D, E or U)")
+	na := flag.Int("na", 1400, "class U: order of the sparse matrix")
+	nonzer := flag.Int("nonzer", 7, "class U: nonzeros per generating vector")
+	niter := flag.Int("niter", 15, "class U: number of iterations")
+	shift := flag.Float64("shift", 10.0, "class U: shift of the main diagonal")
+	format := flag.String("format", "text", "result format: text or json")
+	results := flag.String("results", "", "append the result to this .csv or .jsonl file")
+	timeout := flag.Duration("timeout", 0, "stop after this long and report the iterations completed (0 for no limit)")
+	flag.Parse()
 
-	// Allocate arrays
-	a = make([]float64, NZ)
-	colidx = make([]int, NZ)
-	rowstr = make([]int, NA+1)
-	x = make([]float64, NA+1)
-	z = make([]float64, NA+1)
-	p = make([]float64, NA+1)
-	q = make([]float64, NA+1)
-	r = make([]float64, NA+1)
+	if err := common.SetFormat(*format); err != nil {
+		fmt.Fprintf(os.Stderr, "cg: %v\n", err)
+		os.Exit(1)
+	}
+	out := common.Progress()
+	if err := common.SetResultsFile(*results); err != nil {
+		fmt.Fprintf(os.Stderr, "cg: %v\n", err)
+		os.Exit(1)
+	}
 
-	// Create benchmark instance
-	cg := NewCGBenchmark()
-	cg.naa = NA
-	cg.nzz = NZ
+	var prob params.Params
+	if strings.EqualFold(*class, params.UserClass) {
+		var err error
+		prob, err = params.Custom(*na, *nonzer, *niter, *shift)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "cg: class U: %v\n", err)
+			os.Exit(1)
+		}
+	} else {
+		var ok bool
+		prob, ok = params.Lookup(*class)
+		if !ok {
+			common.PrintClassUsage(out, "cg", *class, append(params.Classes, params.UserClass))
+			os.Exit(1)
+		}
+	}
 
-	// Run benchmark
-	cg.run()
+	ctx, stop := common.RunContext(*timeout)
+	defer stop()
+	result, err := cg.Run(ctx, cg.Config{Params: prob, Out: out})
+	if err != nil && !result.Incomplete {
+		fmt.Fprintf(os.Stderr, "cg: %v\n", err)
+		os.Exit(1)
+	}
+	common.Report(&result.Result)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cg: %v\n", err)
+		os.Exit(1)
+	}
+	if result.Failed() {
+		os.Exit(1)
+	}
 }
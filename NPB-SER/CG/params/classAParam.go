@@ -1,14 +0,0 @@
-//go:build A
-
-package params
-
-const (
-	CLASS           = "A"
-	NA              = 14000
-	NITER           = 15
-	SHIFT           = 20.0
-	NONZER          = 11
-	EmptyTag        = false
-	ZETA_VERIFY_VALUE = 17.130235054029
-)
-
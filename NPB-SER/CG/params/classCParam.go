@@ -1,14 +0,0 @@
-//go:build C
-
-package params
-
-const (
-	CLASS           = "C"
-	NA              = 150000
-	NITER           = 75
-	SHIFT           = 110.0
-	NONZER          = 15
-	EmptyTag        = false
-	ZETA_VERIFY_VALUE = 28.973605592845
-)
-
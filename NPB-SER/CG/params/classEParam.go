@@ -1,14 +0,0 @@
-//go:build E
-
-package params
-
-const (
-	CLASS           = "E"
-	NA              = 9000000
-	NITER           = 100
-	SHIFT           = 1500.0
-	NONZER          = 26
-	EmptyTag        = false
-	ZETA_VERIFY_VALUE = 77.522164599383
-)
-
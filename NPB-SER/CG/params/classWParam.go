@@ -1,13 +0,0 @@
-//go:build W
-
-package params
-
-const (
-	CLASS             = "W"
-	NA                = 7000
-	NITER             = 15
-	SHIFT             = 12.0
-	NONZER            = 8
-	EmptyTag          = false
-	ZETA_VERIFY_VALUE = 10.362595087124
-)
@@ -1,14 +0,0 @@
-//go:build !S && !W && !A && !B && !C && !D && !E
-
-package params
-
-const (
-	CLASS           = ""
-	NA              = 1400
-	NITER           = 15
-	SHIFT           = 10.0
-	NONZER          = 7
-	EmptyTag        = true
-	ZETA_VERIFY_VALUE = 0.0
-)
-
@@ -0,0 +1,52 @@
+package params
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Params holds the problem size and reference zeta of one CG class
+type Params struct {
+	CLASS             string
+	NA                int
+	NITER             int
+	SHIFT             float64
+	NONZER            int
+	ZETA_VERIFY_VALUE float64
+}
+
+// Classes lists the standard problem classes in increasing size
+var Classes = []string{"S", "W", "A", "B", "C", "D", "E"}
+
+var table = map[string]Params{
+	"S": {CLASS: "S", NA: 1400, NITER: 15, SHIFT: 10.0, NONZER: 7, ZETA_VERIFY_VALUE: 8.5971775078648},
+	"W": {CLASS: "W", NA: 7000, NITER: 15, SHIFT: 12.0, NONZER: 8, ZETA_VERIFY_VALUE: 10.362595087124},
+	"A": {CLASS: "A", NA: 14000, NITER: 15, SHIFT: 20.0, NONZER: 11, ZETA_VERIFY_VALUE: 17.130235054029},
+	"B": {CLASS: "B", NA: 75000, NITER: 75, SHIFT: 60.0, NONZER: 13, ZETA_VERIFY_VALUE: 22.712745482631},
+	"C": {CLASS: "C", NA: 150000, NITER: 75, SHIFT: 110.0, NONZER: 15, ZETA_VERIFY_VALUE: 28.973605592845},
+	"D": {CLASS: "D", NA: 1500000, NITER: 100, SHIFT: 500.0, NONZER: 21, ZETA_VERIFY_VALUE: 52.514532105794},
+	"E": {CLASS: "E", NA: 9000000, NITER: 100, SHIFT: 1500.0, NONZER: 26, ZETA_VERIFY_VALUE: 77.522164599383},
+}
+
+// UserClass selects a problem size supplied on the command line
+const UserClass = "U"
+
+// Lookup returns the parameters of the given class
+func Lookup(class string) (Params, bool) {
+	p, ok := table[strings.ToUpper(class)]
+	return p, ok
+}
+
+// Custom returns class U parameters for a matrix of order na built from
+// nonzer nonzeros per generating vector. There is no reference zeta.
+func Custom(na, nonzer, niter int, shift float64) (Params, error) {
+	switch {
+	case na < 2:
+		return Params{}, fmt.Errorf("na must be at least 2, got %d", na)
+	case nonzer < 1 || nonzer >= na:
+		return Params{}, fmt.Errorf("nonzer must be between 1 and na-1, got %d", nonzer)
+	case niter < 1:
+		return Params{}, fmt.Errorf("niter must be positive, got %d", niter)
+	}
+	return Params{CLASS: UserClass, NA: na, NITER: niter, SHIFT: shift, NONZER: nonzer}, nil
+}
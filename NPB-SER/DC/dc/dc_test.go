@@ -0,0 +1,103 @@
+package dc
+
+import (
+	"context"
+	"flag"
+	"os"
+	"testing"
+
+	"github.com/iyisakuma/NPB-GO/NPB-SER/DC/params"
+	"github.com/iyisakuma/NPB-GO/NPB-SER/common"
+	"github.com/iyisakuma/NPB-GO/NPB-SER/common/npbtest"
+)
+
+var long = flag.Bool("long", false, "also verify class 1M")
+
+// testClasses lists the classes the verification tests run
+func testClasses() []string {
+	if *long {
+		return []string{"1K", "100K", "1M"}
+	}
+	return []string{"1K", "100K"}
+}
+
+func BenchmarkDC(b *testing.B) {
+	for _, class := range []string{"1K", "100K"} {
+		b.Run(class, func(b *testing.B) {
+			p, _ := params.Lookup(class)
+			mops := 0.0
+			for i := 0; i < b.N; i++ {
+				result, err := Run(context.Background(), Config{Params: p})
+				if err != nil {
+					b.Fatal(err)
+				}
+				if result.Failed() {
+					b.Fatalf("class %s failed verification", class)
+				}
+				mops += result.Mops
+			}
+			b.ReportMetric(mops/float64(b.N), "Mop/s")
+		})
+	}
+}
+
+func TestVerification(t *testing.T) {
+	for _, class := range testClasses() {
+		t.Run(class, func(t *testing.T) {
+			p, _ := params.Lookup(class)
+			result, err := Run(context.Background(), Config{Params: p})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Rows != p.ROWS_REF {
+				t.Errorf("rows = %d, want %d", result.Rows, p.ROWS_REF)
+			}
+			if result.Checksum != p.CHECKSUM_REF {
+				t.Errorf("checksum = %d, want %d", result.Checksum, p.CHECKSUM_REF)
+			}
+			if !result.Verified {
+				t.Error("kernel reported a failed verification")
+			}
+		})
+	}
+}
+
+// TestOnDisk checks that keeping the tuples and views in files gives the
+// views computed in memory
+func TestOnDisk(t *testing.T) {
+	p, _ := params.Lookup("1K")
+	dir := t.TempDir()
+	result, err := Run(context.Background(), Config{Params: p, Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Verified {
+		t.Errorf("rows %d checksum %d, want %d and %d", result.Rows, result.Checksum, p.ROWS_REF, p.CHECKSUM_REF)
+	}
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := 1<<p.NUM_ATTRS + 1; len(files) != want {
+		t.Errorf("%d files in the directory, want a tuple file and %d view files", len(files), want-1)
+	}
+}
+
+// runClass runs the kernel at class for the checks every kernel shares
+func runClass(ctx context.Context, class string) (common.Result, error) {
+	p, _ := params.Lookup(class)
+	result, err := Run(ctx, Config{Params: p})
+	return result.Result, err
+}
+
+// TestConcurrentRuns checks that runs of two classes in one process do not
+// share state
+func TestConcurrentRuns(t *testing.T) {
+	npbtest.ConcurrentRuns(t, runClass, "1K", "100K")
+}
+
+// TestStoppedRun checks a run stopped by its context. Run checks once before
+// starting and then before every view
+func TestStoppedRun(t *testing.T) {
+	npbtest.StoppedRun(t, runClass, "1K", "views")
+}
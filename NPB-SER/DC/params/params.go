@@ -0,0 +1,35 @@
+package params
+
+import "strings"
+
+// Params holds the input size and reference values of one DC class. The
+// reference values are those of this implementation (see package dc).
+type Params struct {
+	CLASS        string
+	NUM_TUPLES   int    // input tuples
+	NUM_ATTRS    int    // dimension attributes of every tuple
+	ROWS_REF     int64  // tuples of all views together
+	CHECKSUM_REF uint64 // checksum of all views
+}
+
+// Classes lists the problem classes in increasing size. They are named after
+// their input tuples rather than NPB's letters: NPB's DC classes have more
+// attributes, and their reference checksums are not those of these views.
+var Classes = []string{"1K", "100K", "1M", "10M"}
+
+var table = map[string]Params{
+	"1K": {CLASS: "1K", NUM_TUPLES: 1000, NUM_ATTRS: 5,
+		ROWS_REF: 1078, CHECKSUM_REF: 6346146872},
+	"100K": {CLASS: "100K", NUM_TUPLES: 100000, NUM_ATTRS: 8,
+		ROWS_REF: 129586, CHECKSUM_REF: 665394699408512},
+	"1M": {CLASS: "1M", NUM_TUPLES: 1000000, NUM_ATTRS: 10,
+		ROWS_REF: 1555199, CHECKSUM_REF: 295998197721716992},
+	"10M": {CLASS: "10M", NUM_TUPLES: 10000000, NUM_ATTRS: 12,
+		ROWS_REF: 46650647, CHECKSUM_REF: 14980389759231902464},
+}
+
+// Lookup returns the parameters of the given class
+func Lookup(class string) (Params, bool) {
+	p, ok := table[strings.ToUpper(class)]
+	return p, ok
+}
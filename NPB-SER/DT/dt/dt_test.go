@@ -0,0 +1,117 @@
+package dt
+
+import (
+	"context"
+	"flag"
+	"testing"
+
+	"github.com/iyisakuma/NPB-GO/NPB-SER/DT/params"
+	"github.com/iyisakuma/NPB-GO/NPB-SER/common"
+	"github.com/iyisakuma/NPB-GO/NPB-SER/common/npbtest"
+)
+
+var long = flag.Bool("long", false, "also verify class A")
+
+// testClasses lists the classes the verification tests run
+func testClasses() []string {
+	if *long {
+		return []string{"S", "W", "A"}
+	}
+	return []string{"S", "W"}
+}
+
+func BenchmarkDT(b *testing.B) {
+	for _, class := range []string{"S", "W"} {
+		for _, graph := range params.Graphs {
+			b.Run(class+"/"+graph, func(b *testing.B) {
+				p, _ := params.Lookup(class)
+				mops := 0.0
+				for i := 0; i < b.N; i++ {
+					result, err := Run(context.Background(), Config{Params: p, Graph: graph})
+					if err != nil {
+						b.Fatal(err)
+					}
+					if result.Failed() {
+						b.Fatalf("class %s graph %s failed verification", class, graph)
+					}
+					mops += result.Mops
+				}
+				b.ReportMetric(mops/float64(b.N), "Mop/s")
+			})
+		}
+	}
+}
+
+func TestVerification(t *testing.T) {
+	for _, class := range testClasses() {
+		for _, graph := range params.Graphs {
+			t.Run(class+"/"+graph, func(t *testing.T) {
+				p, _ := params.Lookup(class)
+				result, err := Run(context.Background(), Config{Params: p, Graph: graph})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if want, _ := p.VerifyValue(graph); result.Checksum != want {
+					t.Errorf("checksum = %.1f, want %.1f", result.Checksum, want)
+				}
+				if !result.Verified {
+					t.Error("kernel reported a failed verification")
+				}
+			})
+		}
+	}
+}
+
+// TestGraphs checks the shape of the graphs of every class and that their
+// nodes are topologically sorted
+func TestGraphs(t *testing.T) {
+	sizes := map[string][3][2]int{ // nodes and arcs of BH, WH and SH
+		"S": {{5, 4}, {5, 4}, {12, 16}},
+		"W": {{11, 10}, {11, 10}, {32, 48}},
+		"A": {{21, 20}, {21, 20}, {80, 128}},
+		"B": {{43, 42}, {43, 42}, {192, 320}},
+	}
+	for _, class := range params.Classes {
+		p, _ := params.Lookup(class)
+		for i, kind := range params.Graphs {
+			g, err := newGraph(kind, class, p.NUM_SOURCES)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := [2]int{len(g.nodes), len(g.arcs)}; got != sizes[class][i] {
+				t.Errorf("%s: %d nodes and %d arcs, want %d and %d", g.name, got[0], got[1], sizes[class][i][0], sizes[class][i][1])
+			}
+			for _, ar := range g.arcs {
+				if ar.tail.id >= ar.head.id {
+					t.Errorf("%s: arc from node %d to node %d", g.name, ar.tail.id, ar.head.id)
+				}
+			}
+		}
+	}
+}
+
+func TestUnknownGraph(t *testing.T) {
+	p, _ := params.Lookup("S")
+	if _, err := Run(context.Background(), Config{Params: p, Graph: "XH"}); err == nil {
+		t.Error("graph XH was accepted")
+	}
+}
+
+// runClass runs the kernel at class for the checks every kernel shares
+func runClass(ctx context.Context, class string) (common.Result, error) {
+	p, _ := params.Lookup(class)
+	result, err := Run(ctx, Config{Params: p, Graph: "SH"})
+	return result.Result, err
+}
+
+// TestConcurrentRuns checks that runs of two classes in one process do not
+// share state
+func TestConcurrentRuns(t *testing.T) {
+	npbtest.ConcurrentRuns(t, runClass, "S", "W")
+}
+
+// TestStoppedRun checks a run stopped by its context. Run checks once before
+// starting and then before every node
+func TestStoppedRun(t *testing.T) {
+	npbtest.StoppedRun(t, runClass, "S", "nodes")
+}
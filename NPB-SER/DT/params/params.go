@@ -0,0 +1,51 @@
+package params
+
+import "strings"
+
+// Params holds the sizes and reference checksums of one DT class
+type Params struct {
+	CLASS         string
+	NUM_SAMPLES   int // mean number of samples of a feature
+	STD_DEVIATION int // spread of the number of samples of the features
+	NUM_SOURCES   int // sources of the BH and SH graphs, sinks of WH and SH
+
+	BH_VERIFY_VALUE float64
+	WH_VERIFY_VALUE float64
+	SH_VERIFY_VALUE float64
+}
+
+// Classes lists the standard problem classes in increasing size
+var Classes = []string{"S", "W", "A", "B"}
+
+// Graphs lists the communication graphs: black hole, white hole and shuffle
+var Graphs = []string{"BH", "WH", "SH"}
+
+var table = map[string]Params{
+	"S": {CLASS: "S", NUM_SAMPLES: 1728, STD_DEVIATION: 128, NUM_SOURCES: 4,
+		BH_VERIFY_VALUE: 30892725.0, WH_VERIFY_VALUE: 67349758.0, SH_VERIFY_VALUE: 58875767.0},
+	"W": {CLASS: "W", NUM_SAMPLES: 1728 * 8, STD_DEVIATION: 128 * 2, NUM_SOURCES: 4 * 2,
+		BH_VERIFY_VALUE: 4102461.0, WH_VERIFY_VALUE: 204280762.0, SH_VERIFY_VALUE: 186944764.0},
+	"A": {CLASS: "A", NUM_SAMPLES: 1728 * 64, STD_DEVIATION: 128 * 4, NUM_SOURCES: 4 * 4,
+		BH_VERIFY_VALUE: 17809491.0, WH_VERIFY_VALUE: 1289925229.0, SH_VERIFY_VALUE: 610856482.0},
+	"B": {CLASS: "B", NUM_SAMPLES: 1728 * 512, STD_DEVIATION: 128 * 8, NUM_SOURCES: 4 * 8,
+		BH_VERIFY_VALUE: 4317114.0, WH_VERIFY_VALUE: 7877279917.0, SH_VERIFY_VALUE: 1836863082.0},
+}
+
+// Lookup returns the parameters of the given class
+func Lookup(class string) (Params, bool) {
+	p, ok := table[strings.ToUpper(class)]
+	return p, ok
+}
+
+// VerifyValue returns the reference checksum of graph, one of Graphs
+func (p Params) VerifyValue(graph string) (float64, bool) {
+	switch strings.ToUpper(graph) {
+	case "BH":
+		return p.BH_VERIFY_VALUE, true
+	case "WH":
+		return p.WH_VERIFY_VALUE, true
+	case "SH":
+		return p.SH_VERIFY_VALUE, true
+	}
+	return 0, false
+}
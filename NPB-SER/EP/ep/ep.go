@@ -0,0 +1,262 @@
+// Package ep is the EP (Embarrassingly Parallel) kernel: it tallies Gaussian
+// deviates generated from a stream of pseudorandom numbers.
+package ep
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/iyisakuma/NPB-GO/NPB-SER/EP/params"
+	"github.com/iyisakuma/NPB-GO/NPB-SER/common"
+)
+
+const (
+	MK      = 16
+	NK      = 1 << MK
+	NQ      = 10
+	EPSILON = 1.0e-8
+	A       = 1220703125.0
+	S       = 271828183.0
+	NK_PLUS = ((2 * NK) + 1)
+)
+
+// Config selects the problem a Run solves
+type Config struct {
+	Params params.Params // problem size, from params.Lookup
+	Out    io.Writer     // progress messages and the NPB banner, discarded when nil
+}
+
+// Result is the outcome of an EP run with the sums it is verified on
+type Result struct {
+	common.Result
+	SX, SY float64
+}
+
+// Run runs the EP benchmark and returns its result. When ctx is done between
+// two batches of random numbers the run stops there and Run returns the
+// partial result with a *common.IncompleteError.
+func Run(ctx context.Context, cfg Config) (Result, error) {
+	if err := ctx.Err(); err != nil {
+		return Result{}, err
+	}
+	p := cfg.Params
+	out := cfg.Out
+	if out == nil {
+		out = io.Discard
+	}
+	x := make([]float64, NK_PLUS)
+	q := make([]float64, NQ)
+	var timers common.Timers
+
+	var Mops, t1, t2, t3, t4, x1, x2 float64
+	var sx, sy, tm, an, tt, gc float64
+	var sx_err, sy_err float64
+	var np int
+	var i, ik, kk, l, k, nit int
+	var k_offset int
+	var verified, timers_enabled bool
+	var dum = []float64{1.0, 1.0, 1.0}
+	var size string
+
+	timers_enabled = checkTimeFlag()
+	size = fmt.Sprintf("%15.0f", math.Pow(2.0, float64(p.M+1)))
+
+	size = strings.TrimRight(size, ".")
+
+	fmt.Fprintf(out, "\n\n NAS Parallel Benchmarks 4.1 Serial Go version - EP Benchmark\n\n")
+	fmt.Fprintf(out, " Number of random numbers generated: %15s\n", size)
+	verified = false
+
+	/*
+	 * --------------------------------------------------------------------
+	 * compute the number of "batches" of random number pairs generated
+	 * per processor. Adjust if the number of processors does not evenly
+	 * divide the total number
+	 * --------------------------------------------------------------------
+	 */
+	np = 1 << (p.M - MK)
+
+	/*
+	 * call the random number generator functions and initialize
+	 * the x-array to reduce the effects of paging on the timings.
+	 * also, call all mathematical functions that are used. make
+	 * sure these initializations cannot be eliminated as dead code.
+	 */
+	common.Vranlc(0, &dum[0], dum[1], dum)
+	dum[0] = common.Randlc(&dum[1], dum[2])
+
+	for i = 0; i < NK_PLUS; i++ {
+		x[i] = -1.0e99
+	}
+
+	Mops = math.Log(math.Sqrt(math.Abs(math.Max(1.0, 1.0))))
+
+	timers.Clear(0)
+	timers.Clear(1)
+	timers.Clear(2)
+	timers.Start(0)
+
+	t1 = A
+	common.Vranlc(0, &t1, A, x)
+	for i = 0; i < MK+1; i++ {
+		t2 = common.Randlc(&t1, t1)
+	}
+
+	an = t1
+	tt = S
+	gc = 0.0
+	sx = 0.0
+	sy = 0.0
+
+	for i = 0; i <= NQ-1; i++ {
+		q[i] = 0.0
+	}
+
+	/*
+	 * each instance of this loop may be performed independently. we compute
+	 * the k offsets separately to take into account the fact that some nodes
+	 * have more numbers to generate than others
+	 */
+	k_offset = -1
+
+	batches := np
+	for k = 1; k <= np; k++ {
+		if ctx.Err() != nil {
+			batches = k - 1
+			break
+		}
+		kk = k_offset + k
+		t1 = S
+		t2 = an
+
+		/* find starting seed t1 for this kk */
+		for i = 1; i <= 100; i++ {
+			ik = kk / 2
+			if (2 * ik) != kk {
+				t3 = common.Randlc(&t1, t2)
+			}
+			if ik == 0 {
+				break
+			}
+			t3 = common.Randlc(&t2, t2)
+			kk = ik
+		}
+		/* compute uniform pseudorandom numbers */
+		if timers_enabled {
+			timers.Start(2)
+		}
+		common.Vranlc(2*NK, &t1, A, x)
+		if timers_enabled {
+			timers.Stop(2)
+		}
+
+		/*
+		 * compute gaussian deviates by acceptance-rejection method and
+		 * tally counts in concentric square annuli. this loop is not
+		 * vectorizable.
+		 */
+
+		if timers_enabled {
+			timers.Start(1)
+		}
+
+		for i = 0; i < NK; i++ {
+			x1 = 2.0*x[2*i] - 1.0
+			x2 = 2.0*x[2*i+1] - 1.0
+			t1 = x1*x1 + x2*x2
+			if t1 <= 1.0 {
+				t2 = math.Sqrt(-2.0 * math.Log(t1) / t1)
+				t3 = (x1 * t2)
+				t4 = (x2 * t2)
+				l = int(math.Max(math.Abs(t3), math.Abs(t4)))
+				q[l] += 1.0
+				sx = sx + t3
+				sy = sy + t4
+			}
+		}
+		if timers_enabled {
+			timers.Stop(1)
+		}
+	}
+
+	for i = 0; i <= NQ-1; i++ {
+		gc = gc + q[i]
+	}
+	incomplete := batches < np
+	timers.Stop(0)
+	tm = timers.Read(0)
+
+	nit = 0
+
+	sx_err = math.Abs((sx - p.SX_VERIFY_VALUE) / p.SX_VERIFY_VALUE)
+	sy_err = math.Abs((sy - p.SY_VERIFY_VALUE) / p.SY_VERIFY_VALUE)
+	verified = !incomplete && (sx_err <= EPSILON) && (sy_err <= EPSILON)
+
+	Mops = math.Pow(2.0, float64(p.M+1)) * float64(batches) / float64(np) / tm / 1000000.0
+
+	if incomplete {
+		fmt.Fprintf(out, "\n Benchmark stopped after %d of %d batches\n", batches, np)
+	}
+	fmt.Fprintf(out, "\n EP Benchmark Results:\n\n")
+	fmt.Fprintf(out, " CPU Time =%10.4f\n", tm)
+	fmt.Fprintf(out, " N = 2^%5d\n", p.M)
+	fmt.Fprintf(out, " No. Gaussian Pairs = %15.0f\n", gc)
+	fmt.Fprintf(out, " Sums = %25.15e %25.15e\n", sx, sy)
+	fmt.Fprintln(out, " Counts:")
+
+	for i = 0; i < NQ-1; i++ {
+		fmt.Fprintf(out, "%3d%15.0f\n", i, q[i])
+	}
+
+	result := common.Result{
+		Kernel:      "EP",
+		Class:       p.CLASS,
+		Size:        [3]int{p.M + 1, 0, 0},
+		Iterations:  nit,
+		Time:        tm,
+		Mops:        Mops,
+		OpType:      "Random numbers generated",
+		Verified:    verified,
+		Incomplete:  incomplete,
+		Workers:     1,
+		NPBVersion:  "4.1",
+		CompileTime: time.Now().Format("03 Jun 2006"),
+		Compiler:    "go1.24.2 linux/amd64",
+		Rand:        "randdp",
+	}
+	if timers_enabled {
+		result.Timers = []common.Timer{
+			{Name: "total", Seconds: timers.Read(0)},
+			{Name: "gaussian pairs", Seconds: timers.Read(1)},
+			{Name: "random numbers", Seconds: timers.Read(2)},
+		}
+	}
+	common.Finish(&result, out)
+	if timers_enabled {
+		if tm <= 0.0 {
+			tm = 1.0
+		}
+		tt = timers.Read(0)
+		fmt.Fprintf(out, "\nTotal time:     %9.3f (%6.2f)\n", tt, tt*100.0/tm)
+
+		tt = timers.Read(1)
+		fmt.Fprintf(out, "Gaussian pairs: %9.3f (%6.2f)\n", tt, tt*100.0/tm)
+
+		tt = timers.Read(2)
+		fmt.Fprintf(out, "Random numbers: %9.3f (%6.2f)\n", tt, tt*100.0/tm)
+	}
+	if incomplete {
+		return Result{result, sx, sy}, &common.IncompleteError{Completed: batches, Planned: np, Err: ctx.Err()}
+	}
+	return Result{result, sx, sy}, nil
+}
+
+func checkTimeFlag() bool {
+	_, err := os.Stat("timer.flag")
+	return os.IsNotExist(err)
+}
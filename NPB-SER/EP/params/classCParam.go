@@ -1,11 +0,0 @@
-//go:build C
-
-package params
-
-const (
-	CLASS           = "C"
-	M               = 32
-	EmptyTag        = false
-	SX_VERIFY_VALUE = 4.764367927995374e+4
-	SY_VERIFY_VALUE = -8.084072988043731e+4
-)
@@ -1,11 +0,0 @@
-//go:build D
-
-package params
-
-const (
-	CLASS           = "D"
-	M               = 36
-	EmptyTag        = false
-	SX_VERIFY_VALUE = 1.982481200946593e+5
-	SY_VERIFY_VALUE = -1.020596636361769e+5
-)
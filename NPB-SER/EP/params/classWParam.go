@@ -1,11 +0,0 @@
-//go:build W
-
-package params
-
-const (
-	CLASS           = "W"
-	M               = 25
-	EmptyTag        = false
-	SX_VERIFY_VALUE = -2.863319731645753e+3
-	SY_VERIFY_VALUE = -6.320053679109499e+3
-)
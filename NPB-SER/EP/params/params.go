@@ -0,0 +1,30 @@
+package params
+
+import "strings"
+
+// Params holds the problem size and reference sums of one EP class
+type Params struct {
+	CLASS           string
+	M               int
+	SX_VERIFY_VALUE float64
+	SY_VERIFY_VALUE float64
+}
+
+// Classes lists the standard problem classes in increasing size
+var Classes = []string{"S", "W", "A", "B", "C", "D", "E"}
+
+var table = map[string]Params{
+	"S": {CLASS: "S", M: 24, SX_VERIFY_VALUE: -3.247834652034740e+3, SY_VERIFY_VALUE: -6.958407078382297e+3},
+	"W": {CLASS: "W", M: 25, SX_VERIFY_VALUE: -2.863319731645753e+3, SY_VERIFY_VALUE: -6.320053679109499e+3},
+	"A": {CLASS: "A", M: 28, SX_VERIFY_VALUE: -4.295875165629892e+3, SY_VERIFY_VALUE: -1.580732573678431e+4},
+	"B": {CLASS: "B", M: 30, SX_VERIFY_VALUE: 4.033815542441498e+4, SY_VERIFY_VALUE: -2.660669192809235e+4},
+	"C": {CLASS: "C", M: 32, SX_VERIFY_VALUE: 4.764367927995374e+4, SY_VERIFY_VALUE: -8.084072988043731e+4},
+	"D": {CLASS: "D", M: 36, SX_VERIFY_VALUE: 1.982481200946593e+5, SY_VERIFY_VALUE: -1.020596636361769e+5},
+	"E": {CLASS: "E", M: 40, SX_VERIFY_VALUE: -5.319717441530e+05, SY_VERIFY_VALUE: -3.688834557731e+05},
+}
+
+// Lookup returns the parameters of the given class
+func Lookup(class string) (Params, bool) {
+	p, ok := table[strings.ToUpper(class)]
+	return p, ok
+}
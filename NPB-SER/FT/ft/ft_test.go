@@ -0,0 +1,100 @@
+package ft
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"math/cmplx"
+	"testing"
+
+	"github.com/iyisakuma/NPB-GO/NPB-SER/FT/params"
+	"github.com/iyisakuma/NPB-GO/NPB-SER/common"
+	"github.com/iyisakuma/NPB-GO/NPB-SER/common/npbtest"
+)
+
+var long = flag.Bool("long", false, "also verify class A")
+
+// testClasses lists the classes the verification tests run
+func testClasses() []string {
+	if *long {
+		return []string{"S", "W", "A"}
+	}
+	return []string{"S", "W"}
+}
+
+func BenchmarkFT(b *testing.B) {
+	for _, class := range []string{"S", "W"} {
+		b.Run(class, func(b *testing.B) {
+			p, _ := params.Lookup(class)
+			mops := 0.0
+			for i := 0; i < b.N; i++ {
+				result, err := Run(context.Background(), Config{Params: p})
+				if err != nil {
+					b.Fatal(err)
+				}
+				if result.Failed() {
+					b.Fatalf("class %s failed verification", class)
+				}
+				mops += result.Mops
+			}
+			b.ReportMetric(mops/float64(b.N), "Mop/s")
+		})
+	}
+}
+
+func TestVerification(t *testing.T) {
+	for _, class := range testClasses() {
+		t.Run(class, func(t *testing.T) {
+			p, _ := params.Lookup(class)
+			result, err := Run(context.Background(), Config{Params: p})
+			if err != nil {
+				t.Fatal(err)
+			}
+			refClass, ref := checksumReference(p.NX, p.NY, p.NZ, p.NITER)
+			if refClass != class {
+				t.Fatalf("sizes of class %s match reference class %s", class, refClass)
+			}
+			for i := 1; i <= p.NITER; i++ {
+				if ref[i] == 0 {
+					continue
+				}
+				if err := cmplx.Abs(result.Sums[i]-ref[i]) / cmplx.Abs(ref[i]); err > EPSILON {
+					t.Errorf("checksum %d = %.12e, want %.12e", i, result.Sums[i], ref[i])
+				}
+			}
+			if !result.Verified {
+				t.Error("kernel reported a failed verification")
+			}
+		})
+	}
+}
+
+func TestCfftzInvalidParameters(t *testing.T) {
+	ft := NewFTBenchmark(params.Params{}, nil)
+	ft.u = []Dcomplex{4}
+	for _, tc := range []struct{ is, m int }{{0, 2}, {1, 0}, {-1, 5}} {
+		var cfftzErr *CfftzError
+		if err := ft.cfftz(tc.is, tc.m, 1<<tc.m, nil, nil); !errors.As(err, &cfftzErr) {
+			t.Errorf("cfftz(is=%d, m=%d) returned %v, want a *CfftzError", tc.is, tc.m, err)
+		}
+	}
+}
+
+// runClass runs the kernel at class for the checks every kernel shares
+func runClass(ctx context.Context, class string) (common.Result, error) {
+	p, _ := params.Lookup(class)
+	result, err := Run(ctx, Config{Params: p})
+	return result.Result, err
+}
+
+// TestConcurrentRuns checks that runs of two classes in one process do not
+// share state
+func TestConcurrentRuns(t *testing.T) {
+	npbtest.ConcurrentRuns(t, runClass, "S", "W")
+}
+
+// TestStoppedRun checks a run stopped by its context. Run checks once before
+// starting and then before every iteration
+func TestStoppedRun(t *testing.T) {
+	npbtest.StoppedRun(t, runClass, "S", "iterations")
+}
@@ -1,14 +0,0 @@
-//go:build B
-
-package params
-
-const (
-	CLASS     = "B"
-	NX        = 512
-	NY        = 256
-	NZ        = 256
-	NITER     = 20
-	MAXDIM    = 512
-	EmptyTag  = false
-)
-
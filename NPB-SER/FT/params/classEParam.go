@@ -1,14 +0,0 @@
-//go:build E
-
-package params
-
-const (
-	CLASS     = "E"
-	NX        = 4096
-	NY        = 2048
-	NZ        = 2048
-	NITER     = 25
-	MAXDIM    = 4096
-	EmptyTag  = false
-)
-
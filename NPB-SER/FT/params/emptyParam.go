@@ -1,14 +0,0 @@
-//go:build !S && !W && !A && !B && !C && !D && !E
-
-package params
-
-const (
-	CLASS     = ""
-	NX        = 64
-	NY        = 64
-	NZ        = 64
-	NITER     = 6
-	MAXDIM    = 64
-	EmptyTag  = true
-)
-
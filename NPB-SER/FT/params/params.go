@@ -0,0 +1,59 @@
+package params
+
+import (
+	"fmt"
+	"math/bits"
+	"strings"
+)
+
+// Params holds the grid size and iteration count of one FT class.
+// The reference checksums live in verify(), keyed by the same sizes.
+type Params struct {
+	CLASS  string
+	NX     int
+	NY     int
+	NZ     int
+	NITER  int
+	MAXDIM int
+}
+
+// Classes lists the standard problem classes in increasing size
+var Classes = []string{"S", "W", "A", "B", "C", "D", "E"}
+
+var table = map[string]Params{
+	"S": {CLASS: "S", NX: 64, NY: 64, NZ: 64, NITER: 6, MAXDIM: 64},
+	"W": {CLASS: "W", NX: 128, NY: 128, NZ: 32, NITER: 6, MAXDIM: 128},
+	"A": {CLASS: "A", NX: 256, NY: 256, NZ: 128, NITER: 6, MAXDIM: 256},
+	"B": {CLASS: "B", NX: 512, NY: 256, NZ: 256, NITER: 20, MAXDIM: 512},
+	"C": {CLASS: "C", NX: 512, NY: 512, NZ: 512, NITER: 20, MAXDIM: 512},
+	"D": {CLASS: "D", NX: 2048, NY: 1024, NZ: 1024, NITER: 25, MAXDIM: 2048},
+	"E": {CLASS: "E", NX: 4096, NY: 2048, NZ: 2048, NITER: 25, MAXDIM: 4096},
+}
+
+// UserClass selects a problem size supplied on the command line
+const UserClass = "U"
+
+// Lookup returns the parameters of the given class
+func Lookup(class string) (Params, bool) {
+	p, ok := table[strings.ToUpper(class)]
+	return p, ok
+}
+
+// Custom returns class U parameters for an nx x ny x nz grid. Every
+// dimension must be a power of two, and nx and ny at least one FFT block
+// (16 points) wide.
+func Custom(nx, ny, nz, niter int) (Params, error) {
+	for _, d := range []struct {
+		name string
+		n    int
+		min  int
+	}{{"nx", nx, 16}, {"ny", ny, 16}, {"nz", nz, 2}} {
+		if d.n < d.min || bits.OnesCount(uint(d.n)) != 1 {
+			return Params{}, fmt.Errorf("%s must be a power of two of at least %d, got %d", d.name, d.min, d.n)
+		}
+	}
+	if niter < 1 {
+		return Params{}, fmt.Errorf("niter must be positive, got %d", niter)
+	}
+	return Params{CLASS: UserClass, NX: nx, NY: ny, NZ: nz, NITER: niter, MAXDIM: max(nx, ny, nz)}, nil
+}
@@ -0,0 +1,574 @@
+// Package is is the IS (Integer Sort) kernel: it ranks a large sequence of
+// integer keys with a bucket sort.
+package is
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/iyisakuma/NPB-GO/NPB-SER/IS/params"
+	"github.com/iyisakuma/NPB-GO/NPB-SER/IS/types"
+	"github.com/iyisakuma/NPB-GO/NPB-SER/common"
+)
+
+const (
+	T_BENCHMARKING    = 0
+	T_INITIALIZATION  = 1
+	T_SORTING         = 2
+	T_TOTAL_EXECUTION = 3
+	MAX_ITERATIONS    = 10
+	USE_BUCKETS       = true
+	TEST_ARRAY_SIZE   = 5
+)
+
+// Config selects the problem a Run solves
+type Config struct {
+	Params params.Params // problem size, from params.Lookup or params.Custom
+	Out    io.Writer     // progress messages and the NPB banner, discarded when nil
+}
+
+// Result is the outcome of an IS run with its verification counts
+type Result struct {
+	common.Result
+	PartialPassed int  // partial verifications passed over the timed iterations
+	FullVerified  bool // whether the final key sequence was sorted
+}
+
+// Run ranks the keys of cfg.Params and returns the result. When ctx is done
+// between two iterations the run stops there and Run returns the partial result
+// with a *common.IncompleteError.
+func Run(ctx context.Context, cfg Config) (Result, error) {
+	if err := ctx.Err(); err != nil {
+		return Result{}, err
+	}
+	b := NewISBenchmark(cfg.Params)
+	if cfg.Out != nil {
+		b.out = cfg.Out
+	}
+	return b.run(ctx)
+}
+
+// ISBenchmark represents the IS (Integer Sort) benchmark
+// This struct encapsulates all the state that was global in the C++ version
+type ISBenchmark struct {
+	// Problem size (derived from the class parameters)
+	params     params.Params
+	totalKeys  int
+	maxKey     int
+	numBuckets int
+	numKeys    int
+
+	// Main arrays (equivalent to global arrays in C++)
+	keyArray          []types.INT_TYPE
+	keyBuff1          []types.INT_TYPE
+	keyBuff2          []types.INT_TYPE
+	partialVerifyVals []types.INT_TYPE
+
+	// For USE_BUCKETS mode
+	bucketSize [][]types.INT_TYPE // [numProcs][numBuckets]
+	bucketPtrs []types.INT_TYPE   // [numBuckets]
+
+	// For !USE_BUCKETS mode
+	keyBuff1Aptr [][]types.INT_TYPE // [numProcs][maxKey]
+
+	// Global state (equivalent to global variables in C++)
+	keyBuffPtrGlobal   []types.INT_TYPE // Points to keyBuff1 (like pointer in C++)
+	passedVerification int
+
+	timers common.Timers
+	out    io.Writer
+}
+
+// NewISBenchmark creates a new IS benchmark instance
+func NewISBenchmark(p params.Params) *ISBenchmark {
+	totalKeys := 1 << p.TOTAL_KEYS_LOG_2
+	maxKey := 1 << p.MAX_KEY_LOG_2
+
+	bench := &ISBenchmark{
+		params:            p,
+		totalKeys:         totalKeys,
+		maxKey:            maxKey,
+		numBuckets:        1 << p.NUM_BUCKETS_LOG_2,
+		numKeys:           totalKeys,
+		keyArray:          make([]types.INT_TYPE, totalKeys),
+		keyBuff1:          make([]types.INT_TYPE, maxKey),
+		keyBuff2:          make([]types.INT_TYPE, totalKeys),
+		partialVerifyVals: make([]types.INT_TYPE, TEST_ARRAY_SIZE),
+		keyBuffPtrGlobal:  make([]types.INT_TYPE, maxKey),
+		out:               io.Discard,
+	}
+
+	// Initialize bucketPtrs for USE_BUCKETS mode
+	if USE_BUCKETS {
+		bench.bucketPtrs = make([]types.INT_TYPE, bench.numBuckets)
+	}
+
+	return bench
+}
+
+// run performs the IS benchmark and returns its result, with an error when
+// ctx stopped its iterations early
+func (b *ISBenchmark) run(ctx context.Context) (Result, error) {
+	var timerOn bool
+	var timecounter float64
+
+	// Initialize timers
+	timerOn = false
+	if _, err := os.Stat("timer.flag"); err == nil {
+		timerOn = true
+	}
+
+	b.timers.Clear(T_BENCHMARKING)
+	if timerOn {
+		b.timers.Clear(T_INITIALIZATION)
+		b.timers.Clear(T_SORTING)
+		b.timers.Clear(T_TOTAL_EXECUTION)
+	}
+
+	if timerOn {
+		b.timers.Start(T_TOTAL_EXECUTION)
+	}
+
+	// Printout initial NPB info
+	fmt.Fprintf(b.out, "\n\n NAS Parallel Benchmarks 4.1 Serial Go version - IS Benchmark\n\n")
+	fmt.Fprintf(b.out, " Size:  %d  (class %s)\n", b.totalKeys, b.params.CLASS)
+	fmt.Fprintf(b.out, " Iterations:   %d\n", MAX_ITERATIONS)
+	fmt.Fprintf(b.out, "\n")
+
+	if timerOn {
+		b.timers.Start(T_INITIALIZATION)
+	}
+
+	// Generate random number sequence and subsequent keys
+	b.createSeq(314159265.00, 1220703125.00)
+
+	b.allocKeyBuff()
+
+	if timerOn {
+		b.timers.Stop(T_INITIALIZATION)
+	}
+
+	// Do one iteration for free (i.e., untimed) to guarantee initialization
+	b.rank(1)
+
+	// Start verification counter
+	b.passedVerification = 0
+
+	if b.params.CLASS != "S" {
+		fmt.Fprintln(b.out, "\n   iteration")
+	}
+
+	// Start timer
+	b.timers.Start(T_BENCHMARKING)
+
+	// This is the main iteration
+	iterations := MAX_ITERATIONS
+	for iteration := types.INT_TYPE(1); iteration <= MAX_ITERATIONS; iteration++ {
+		if ctx.Err() != nil {
+			iterations = int(iteration) - 1
+			break
+		}
+		if b.params.CLASS != "S" {
+			fmt.Fprintf(b.out, "        %d\n", iteration)
+		}
+		b.rank(iteration)
+	}
+
+	// End of timing
+	b.timers.Stop(T_BENCHMARKING)
+	timecounter = b.timers.Read(T_BENCHMARKING)
+	incomplete := iterations < MAX_ITERATIONS
+	if incomplete {
+		fmt.Fprintf(b.out, "\n Benchmark stopped after %d of %d iterations\n", iterations, MAX_ITERATIONS)
+	}
+
+	// This tests that keys are in sequence: sorting of last ranked key seq
+	if timerOn {
+		b.timers.Start(T_SORTING)
+	}
+	partialPassed := b.passedVerification
+	// The last iteration prepares the keys fullVerify checks
+	if !incomplete {
+		b.fullVerify()
+	}
+	fullVerified := b.passedVerification > partialPassed
+	if timerOn {
+		b.timers.Stop(T_SORTING)
+		b.timers.Stop(T_TOTAL_EXECUTION)
+	}
+
+	// The final printout
+	if b.passedVerification != 5*MAX_ITERATIONS+1 {
+		b.passedVerification = 0
+	}
+
+	mops := 0.0
+	if timecounter > 0 {
+		mops = float64(iterations*b.totalKeys) / timecounter / 1000000.0
+	}
+	result := common.Result{
+		Kernel:      "IS",
+		Class:       b.params.CLASS,
+		Size:        [3]int{b.totalKeys, 0, 0},
+		Iterations:  iterations,
+		Time:        timecounter,
+		Mops:        mops,
+		OpType:      "keys ranked",
+		Verified:    b.passedVerification > 0,
+		Incomplete:  incomplete,
+		Workers:     1,
+		NPBVersion:  "4.1",
+		CompileTime: "Unknown",
+		Compiler:    "Go",
+	}
+	if timerOn {
+		result.Timers = []common.Timer{
+			{Name: "total execution", Seconds: b.timers.Read(T_TOTAL_EXECUTION)},
+			{Name: "initialization", Seconds: b.timers.Read(T_INITIALIZATION)},
+			{Name: "benchmarking", Seconds: b.timers.Read(T_BENCHMARKING)},
+			{Name: "sorting", Seconds: b.timers.Read(T_SORTING)},
+		}
+	}
+	common.Finish(&result, b.out)
+
+	// Print additional timers
+	if timerOn {
+		tTotal := b.timers.Read(T_TOTAL_EXECUTION)
+		fmt.Fprintf(b.out, "\nAdditional timers -\n")
+		fmt.Fprintf(b.out, " Total execution: %8.3f\n", tTotal)
+		if tTotal == 0.0 {
+			tTotal = 1.0
+		}
+		timecounter = b.timers.Read(T_INITIALIZATION)
+		tPercent := timecounter / tTotal * 100.0
+		fmt.Fprintf(b.out, " Initialization : %8.3f (%5.2f%%)\n", timecounter, tPercent)
+		timecounter = b.timers.Read(T_BENCHMARKING)
+		tPercent = timecounter / tTotal * 100.0
+		fmt.Fprintf(b.out, " Benchmarking   : %8.3f (%5.2f%%)\n", timecounter, tPercent)
+		timecounter = b.timers.Read(T_SORTING)
+		tPercent = timecounter / tTotal * 100.0
+		fmt.Fprintf(b.out, " Sorting        : %8.3f (%5.2f%%)\n", timecounter, tPercent)
+	}
+	if incomplete {
+		return Result{result, partialPassed, fullVerified}, &common.IncompleteError{Completed: iterations, Planned: MAX_ITERATIONS, Err: ctx.Err()}
+	}
+	return Result{result, partialPassed, fullVerified}, nil
+}
+
+func (b *ISBenchmark) allocKeyBuff() {
+	numProcs := 1
+
+	if USE_BUCKETS {
+		b.bucketSize = make([][]types.INT_TYPE, numProcs)
+		for i := 0; i < numProcs; i++ {
+			b.bucketSize[i] = make([]types.INT_TYPE, b.numBuckets)
+		}
+
+		// Initialize keyBuff2
+		for i := 0; i < b.numKeys; i++ {
+			b.keyBuff2[i] = 0
+		}
+	} else {
+		b.keyBuff1Aptr = make([][]types.INT_TYPE, numProcs)
+		b.keyBuff1Aptr[0] = b.keyBuff1
+		for i := 1; i < numProcs; i++ {
+			b.keyBuff1Aptr[i] = make([]types.INT_TYPE, b.maxKey)
+		}
+	}
+}
+
+// findMySeed returns parallel random number seq seed
+// Equivalent to find_my_seed in C++
+func findMySeed(kn int, np int, nn int64, s float64, a float64) float64 {
+	/*
+	 * Create a random number sequence of total length nn residing
+	 * on np number of processors.  Each processor will therefore have a
+	 * subsequence of length nn/np.  This routine returns that random
+	 * number which is the first random number for the subsequence belonging
+	 * to processor rank kn, and which is used as seed for proc kn ran # gen.
+	 */
+	if kn == 0 {
+		return s
+	}
+
+	mq := (nn/4 + int64(np) - 1) / int64(np)
+	nq := mq * 4 * int64(kn) // number of rans to be skipped
+
+	t1 := s
+	t2 := a
+	kk := nq
+	for kk > 1 {
+		ik := kk / 2
+		if 2*ik == kk {
+			t2 = common.Randlc(&t2, t2)
+			kk = ik
+		} else {
+			t1 = common.Randlc(&t1, t2)
+			kk = kk - 1
+		}
+	}
+	t1 = common.Randlc(&t1, t2)
+
+	return t1
+}
+
+// createSeq generates random number sequence and subsequent keys
+// Equivalent to create_seq in C++
+func (b *ISBenchmark) createSeq(seed float64, a float64) {
+	var x, s float64
+	var k types.INT_TYPE
+
+	myid := 0
+	numProcs := 1
+
+	mq := (b.numKeys + numProcs - 1) / numProcs
+	k1 := mq * myid
+	k2 := k1 + mq
+	if k2 > b.numKeys {
+		k2 = b.numKeys
+	}
+
+	s = findMySeed(myid, numProcs, int64(4*b.numKeys), seed, a)
+
+	k = types.INT_TYPE(b.maxKey / 4)
+
+	for i := k1; i < k2; i++ {
+		x = common.Randlc(&s, a)
+		x += common.Randlc(&s, a)
+		x += common.Randlc(&s, a)
+		x += common.Randlc(&s, a)
+		b.keyArray[i] = types.INT_TYPE(float64(k) * x)
+	}
+}
+
+// fullVerify verifies that all keys are correctly sorted
+// Equivalent to full_verify in C++
+func (b *ISBenchmark) fullVerify() {
+	var k, k1, k2 types.INT_TYPE
+
+	myid := 0
+	numProcs := 1
+
+	// Now, finally, sort the keys:
+	// Copy keys into work array; keys in key_array will be reassigned.
+
+	if USE_BUCKETS {
+		// Buckets are already sorted. Sorting keys within each bucket
+		for j := 0; j < b.numBuckets; j++ {
+			if j > 0 {
+				k1 = b.bucketPtrs[j-1]
+			} else {
+				k1 = 0
+			}
+			for i := k1; i < b.bucketPtrs[j]; i++ {
+				// Equivalent to: k = --key_buff_ptr_global[key_buff2[i]];
+				// In C++, key_buff_ptr_global points to key_buff1, so we use keyBuffPtrGlobal
+				// which is a copy of keyBuff1 from the last iteration
+				k = b.keyBuffPtrGlobal[b.keyBuff2[i]] - 1
+				b.keyBuffPtrGlobal[b.keyBuff2[i]] = k
+				b.keyArray[k] = b.keyBuff2[i]
+			}
+		}
+	} else {
+		// Copy keyArray to keyBuff2
+		for i := 0; i < b.numKeys; i++ {
+			b.keyBuff2[i] = b.keyArray[i]
+		}
+		// This is actual sorting. Each thread is responsible for a subset of key values
+		j := numProcs
+		j = (b.maxKey + j - 1) / j
+		k1 = types.INT_TYPE(j * myid)
+		k2 = k1 + types.INT_TYPE(j)
+		if k2 > types.INT_TYPE(b.maxKey) {
+			k2 = types.INT_TYPE(b.maxKey)
+		}
+		for i := 0; i < b.numKeys; i++ {
+			if b.keyBuff2[i] >= k1 && b.keyBuff2[i] < k2 {
+				// Equivalent to: k = --key_buff_ptr_global[key_buff2[i]];
+				k = b.keyBuffPtrGlobal[b.keyBuff2[i]] - 1
+				b.keyBuffPtrGlobal[b.keyBuff2[i]] = k
+				b.keyArray[k] = b.keyBuff2[i]
+			}
+		}
+	}
+
+	// Confirm keys correctly sorted: count incorrectly sorted keys, if any
+	j := 0
+	for i := 1; i < b.numKeys; i++ {
+		if b.keyArray[i-1] > b.keyArray[i] {
+			j++
+		}
+	}
+	if j != 0 {
+		fmt.Fprintf(b.out, "Full_verify: number of keys out of sort: %d\n", j)
+	} else {
+		b.passedVerification++
+	}
+}
+
+// rank performs the main ranking/sorting operation for each iteration
+// Equivalent to rank in C++
+func (b *ISBenchmark) rank(iteration types.INT_TYPE) {
+	var shift int
+	var numBucketKeys types.INT_TYPE
+	var keyBuffPtr, keyBuffPtr2 []types.INT_TYPE
+	var workBuff []types.INT_TYPE
+	var m, k1, k2 types.INT_TYPE
+
+	myid := 0
+	numProcs := 1
+
+	if USE_BUCKETS {
+		shift = b.params.MAX_KEY_LOG_2 - b.params.NUM_BUCKETS_LOG_2
+		numBucketKeys = types.INT_TYPE(1) << shift
+	}
+
+	// Set test values
+	b.keyArray[iteration] = iteration
+	b.keyArray[iteration+MAX_ITERATIONS] = types.INT_TYPE(b.maxKey) - iteration
+
+	// Determine where the partial verify test keys are, load into partial_verify_vals
+	for i := 0; i < TEST_ARRAY_SIZE; i++ {
+		b.partialVerifyVals[i] = b.keyArray[b.params.TEST_INDEX_ARRAY[i]]
+	}
+
+	// Setup pointers to key buffers
+	if USE_BUCKETS {
+		keyBuffPtr2 = b.keyBuff2
+	} else {
+		keyBuffPtr2 = b.keyArray
+	}
+	keyBuffPtr = b.keyBuff1
+
+	if USE_BUCKETS {
+		workBuff = b.bucketSize[myid]
+
+		// Initialize
+		for i := 0; i < b.numBuckets; i++ {
+			workBuff[i] = 0
+		}
+
+		// Determine the number of keys in each bucket
+		for i := 0; i < b.numKeys; i++ {
+			workBuff[b.keyArray[i]>>shift]++
+		}
+
+		// Accumulative bucket sizes are the bucket pointers.
+		// These are global sizes accumulated upon to each bucket
+		b.bucketPtrs[0] = 0
+		for k := 0; k < myid; k++ {
+			b.bucketPtrs[0] += b.bucketSize[k][0]
+		}
+
+		for i := 1; i < b.numBuckets; i++ {
+			b.bucketPtrs[i] = b.bucketPtrs[i-1]
+			for k := 0; k < myid; k++ {
+				b.bucketPtrs[i] += b.bucketSize[k][i]
+			}
+			for k := myid; k < numProcs; k++ {
+				b.bucketPtrs[i] += b.bucketSize[k][i-1]
+			}
+		}
+
+		// Sort into appropriate bucket
+		for i := 0; i < b.numKeys; i++ {
+			k := b.keyArray[i]
+			b.keyBuff2[b.bucketPtrs[k>>shift]] = k
+			b.bucketPtrs[k>>shift]++
+		}
+
+		// The bucket pointers now point to the final accumulated sizes
+		if myid < numProcs-1 {
+			for i := 0; i < b.numBuckets; i++ {
+				for k := myid + 1; k < numProcs; k++ {
+					b.bucketPtrs[i] += b.bucketSize[k][i]
+				}
+			}
+		}
+
+		// Now, buckets are sorted.  We only need to sort keys inside
+		// each bucket, which can be done in parallel.  Because the distribution
+		// of the number of keys in the buckets is Gaussian, the use of
+		// a dynamic schedule should improve load balance, thus, performance
+		for i := 0; i < b.numBuckets; i++ {
+			// Clear the work array section associated with each bucket
+			k1 = types.INT_TYPE(i) * numBucketKeys
+			k2 = k1 + numBucketKeys
+			for k := k1; k < k2; k++ {
+				keyBuffPtr[k] = 0
+			}
+			// Ranking of all keys occurs in this section:
+			// In this section, the keys themselves are used as their
+			// own indexes to determine how many of each there are: their
+			// individual population
+			if i > 0 {
+				m = b.bucketPtrs[i-1]
+			} else {
+				m = 0
+			}
+			for k := m; k < b.bucketPtrs[i]; k++ {
+				keyBuffPtr[keyBuffPtr2[k]]++ // Now they have individual key population
+			}
+			// To obtain ranks of each key, successively add the individual key
+			// population, not forgetting to add m, the total of lesser keys,
+			// to the first key population
+			keyBuffPtr[k1] += m
+			for k := k1 + 1; k < k2; k++ {
+				keyBuffPtr[k] += keyBuffPtr[k-1]
+			}
+		}
+	} else {
+		workBuff = b.keyBuff1Aptr[myid]
+		// Clear the work array
+		for i := 0; i < b.maxKey; i++ {
+			workBuff[i] = 0
+		}
+		// Ranking of all keys occurs in this section:
+		// In this section, the keys themselves are used as their
+		// own indexes to determine how many of each there are: their
+		// individual population
+		for i := 0; i < b.numKeys; i++ {
+			workBuff[keyBuffPtr2[i]]++ // Now they have individual key population
+		}
+		// To obtain ranks of each key, successively add the individual key population
+		for i := 0; i < b.maxKey-1; i++ {
+			workBuff[i+1] += workBuff[i]
+		}
+		// Accumulate the global key population
+		for k := 1; k < numProcs; k++ {
+			for i := 0; i < b.maxKey; i++ {
+				keyBuffPtr[i] += b.keyBuff1Aptr[k][i]
+			}
+		}
+	}
+
+	// This is the partial verify test section
+	// Observe that test_rank_array vals are
+	// shifted differently for different cases
+	for i := 0; i < TEST_ARRAY_SIZE; i++ {
+		k := b.partialVerifyVals[i] // test vals were put here
+		if 0 < k && k <= types.INT_TYPE(b.numKeys-1) {
+			keyRank := keyBuffPtr[k-1]
+			failed := b.params.Verifier.Do(i, iteration, keyRank, b.params.TEXT_RANK_ARRAY[:], &b.passedVerification)
+			if failed {
+				fmt.Fprintf(b.out, "Failed partial verification: iteration %d, test key %d\n", iteration, i)
+			}
+		}
+	}
+
+	// Make copies of rank info for use by full_verify: these variables
+	// in rank are local; making them global slows down the code, probably
+	// since they cannot be made register by compiler
+	// In C++, key_buff_ptr_global = key_buff_ptr just copies the pointer,
+	// and both point to key_buff1. In Go, we need to copy the data.
+	if iteration == MAX_ITERATIONS {
+		copy(b.keyBuffPtrGlobal, keyBuffPtr)
+		// Also copy bucketPtrs for USE_BUCKETS mode
+		if USE_BUCKETS {
+			// bucketPtrs is already a field, so it's preserved
+			// But we need to make sure it's not modified after this
+		}
+	}
+}
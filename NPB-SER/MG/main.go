@@ -1,41 +1,69 @@
 package main
 
 import (
+	"cmp"
+	"flag"
 	"fmt"
+	"os"
+	"strings"
 
+	"github.com/iyisakuma/NPB-GO/NPB-SER/MG/mg"
 	"github.com/iyisakuma/NPB-GO/NPB-SER/MG/params"
+	"github.com/iyisakuma/NPB-GO/NPB-SER/common"
 )
 
 func main() {
-	if params.EmptyTag {
-		fmt.Println("To make a NAS benchmark type ")
-		fmt.Println("\t go build -o mg -tags=<CLASS>")
-		fmt.Println("where: <class> is \"S\", \"W\", \"A\", \"B\", \"C\", \"D\" or \"E\"")
-		return
-	}
-	mg := NewMGBenchmark()
-	mg.nit = params.NIT
-	mg.class = params.CLASS
+	class := flag.String("class", "S", "problem class (S, W, A, B, C, D, E or U)")
+	n := flag.Int("n", 32, "class U: grid points in every dimension not set by -nx, -ny or -nz (even, at least 4)")
+	nx := flag.Int("nx", 0, "class U: grid points in x (0 means -n)")
+	ny := flag.Int("ny", 0, "class U: grid points in y (0 means -n)")
+	nz := flag.Int("nz", 0, "class U: grid points in z (0 means -n)")
+	nit := flag.Int("nit", 4, "class U: number of V-cycle iterations")
+	format := flag.String("format", "text", "result format: text or json")
+	results := flag.String("results", "", "append the result to this .csv or .jsonl file")
+	timeout := flag.Duration("timeout", 0, "stop after this long and report the iterations completed (0 for no limit)")
+	flag.Parse()
 
-	lm := 0
-	for n := params.NX; n > 1; n >>= 1 {
-		lm++
+	if err := common.SetFormat(*format); err != nil {
+		fmt.Fprintf(os.Stderr, "mg: %v\n", err)
+		os.Exit(1)
+	}
+	out := common.Progress()
+	if err := common.SetResultsFile(*results); err != nil {
+		fmt.Fprintf(os.Stderr, "mg: %v\n", err)
+		os.Exit(1)
 	}
 
-	mg.lt = lm
-	mg.lt_default = lm
-
-	maxlevel := lm + 1
-	mg.nx = make([]int, maxlevel+1)
-	mg.ny = make([]int, maxlevel+1)
-	mg.nz = make([]int, maxlevel+1)
-	mg.m1 = make([]int, maxlevel+1)
-	mg.m2 = make([]int, maxlevel+1)
-	mg.m3 = make([]int, maxlevel+1)
-	mg.ir = make([]int, maxlevel+1)
+	var p params.Params
+	if strings.EqualFold(*class, params.UserClass) {
+		var err error
+		p, err = params.Custom(cmp.Or(*nx, *n), cmp.Or(*ny, *n), cmp.Or(*nz, *n), *nit)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "mg: class U: %v\n", err)
+			os.Exit(1)
+		}
+	} else {
+		var ok bool
+		p, ok = params.Lookup(*class)
+		if !ok {
+			common.PrintClassUsage(out, "mg", *class, append(params.Classes, params.UserClass))
+			os.Exit(1)
+		}
+	}
 
-	mg.nx[lm] = params.NX
-	mg.ny[lm] = params.NY
-	mg.nz[lm] = params.NZ
-	mg.run()
+	ctx, stop := common.RunContext(*timeout)
+	defer stop()
+	result, err := mg.Run(ctx, mg.Config{Params: p, Out: out})
+	if err != nil && !result.Incomplete {
+		fmt.Fprintf(os.Stderr, "mg: %v\n", err)
+		os.Exit(1)
+	}
+	common.Report(&result.Result)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "mg: %v\n", err)
+		os.Exit(1)
+	}
+	if result.Failed() {
+		os.Exit(1)
+	}
 }
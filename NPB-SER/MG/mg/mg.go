@@ -1,7 +1,11 @@
-package main
+// Package mg is the MG (MultiGrid) kernel: it approximates the solution of a
+// 3D discrete Poisson equation with V-cycle multigrid.
+package mg
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"math"
 	"time"
 
@@ -20,6 +24,55 @@ const (
 	T_LAST  = 10
 )
 
+// Config selects the problem a Run solves
+type Config struct {
+	Params params.Params // problem size, from params.Lookup or params.Custom
+	Out    io.Writer     // progress messages and the NPB banner, discarded when nil
+}
+
+// Result is the outcome of an MG run with the L2 norm it is verified on
+type Result struct {
+	common.Result
+	Rnm2 float64
+}
+
+// Run sets up the grid hierarchy of cfg.Params, runs the benchmark on it and
+// returns its result. When ctx is done between two iterations the run stops
+// there and Run returns the partial result with a *common.IncompleteError.
+func Run(ctx context.Context, cfg Config) (Result, error) {
+	if err := ctx.Err(); err != nil {
+		return Result{}, err
+	}
+	p := cfg.Params
+	mg := NewMGBenchmark()
+	mg.out = cfg.Out
+	if mg.out == nil {
+		mg.out = io.Discard
+	}
+	mg.nit = p.NIT
+	mg.class = p.CLASS
+	mg.verifyValue = p.VERIFY_VALUE
+
+	lm := params.Levels(p.NX, p.NY, p.NZ)
+
+	mg.lt = lm
+	mg.lt_default = lm
+
+	maxlevel := lm + 1
+	mg.nx = make([]int, maxlevel+1)
+	mg.ny = make([]int, maxlevel+1)
+	mg.nz = make([]int, maxlevel+1)
+	mg.m1 = make([]int, maxlevel+1)
+	mg.m2 = make([]int, maxlevel+1)
+	mg.m3 = make([]int, maxlevel+1)
+	mg.ir = make([]int, maxlevel+1)
+
+	mg.nx[lm] = p.NX
+	mg.ny[lm] = p.NY
+	mg.nz[lm] = p.NZ
+	return mg.run(ctx)
+}
+
 // MGBenchmark represents the MG (Multigrid) benchmark
 type MGBenchmark struct {
 	nx, ny, nz []int // Grid sizes for each level
@@ -46,17 +99,21 @@ type MGBenchmark struct {
 	n1, n2, n3    int // Actual array dimensions
 
 	// Problem size dependent constants
-	lm         int // log2 of NX
+	lm         int // levels of the V-cycle, log2 of NX for the NPB classes
 	lt_default int // Same as lm
-	nm         int // 2 + (1 << lm)
+	nm         int // 2 + the largest grid size, 2 + (1 << lm) for the NPB classes
 	maxlevel   int // lt_default + 1
 	m          int // nm + 1
 
 	// Verification
-	verified  bool
-	rnm2      float64
-	rnmu      float64
-	debug_vec [8]int
+	verifyValue float64
+	verified    bool
+	rnm2        float64
+	rnmu        float64
+	debug_vec   [8]int
+
+	timers common.Timers
+	out    io.Writer
 }
 
 // NewMGBenchmark creates a new MG benchmark instance
@@ -654,19 +711,18 @@ func (mg *MGBenchmark) mg3P(u, v, r []float64, a, c []float64, n1, n2, n3 int, k
 // rep_nrm report on norm
 func (mg *MGBenchmark) rep_nrm(u []float64, n1, n2, n3 int, title string, kk int) {
 	rnm2, rmnmu := mg.norm2u3(u, n1, n2, n3, mg.nx[kk], mg.ny[kk], mg.nz[kk])
-	fmt.Printf(" Level%2d in %8s: norms =%21.14e%21.14e\n", kk, title, rnm2, rmnmu)
+	fmt.Fprintf(mg.out, " Level%2d in %8s: norms =%21.14e%21.14e\n", kk, title, rnm2, rmnmu)
 }
 
-func (mg *MGBenchmark) run() {
+// run performs the MG benchmark and returns its result, with an error when
+// ctx stopped its iterations early
+func (mg *MGBenchmark) run(ctx context.Context) (Result, error) {
 	// Calculate problem size dependent constants
-	common.TimerStart(T_INIT)
+	mg.timers.Start(T_INIT)
 
-	mg.lm = int(math.Log2(float64(mg.nx[mg.lt])))
+	mg.lm = mg.lt
 	mg.lt_default = mg.lm
-	if mg.lt != mg.lt_default {
-		mg.lt = mg.lt_default
-	}
-	mg.nm = 2 + (1 << mg.lm)
+	mg.nm = 2 + max(mg.nx[mg.lt], mg.ny[mg.lt], mg.nz[mg.lt])
 	mg.maxlevel = mg.lt_default + 1
 	mg.m = mg.nm + 1
 
@@ -731,9 +787,9 @@ func (mg *MGBenchmark) run() {
 	mg.zran3(mg.v, mg.n1, mg.n2, mg.n3, mg.nx[mg.lt], mg.ny[mg.lt], mg.lt)
 
 	mg.rnm2, mg.rnmu = mg.norm2u3(mg.v, mg.n1, mg.n2, mg.n3, mg.nx[mg.lt], mg.ny[mg.lt], mg.nz[mg.lt])
-	fmt.Printf("\n\n NAS Parallel Benchmarks 4.1 Serial Go version - MG Benchmark\n\n")
-	fmt.Printf(" Size: %3dx%3dx%3d (class %s)\n", mg.nx[mg.lt], mg.ny[mg.lt], mg.nz[mg.lt], mg.class)
-	fmt.Printf(" Iterations: %3d\n", mg.nit)
+	fmt.Fprintf(mg.out, "\n\n NAS Parallel Benchmarks 4.1 Serial Go version - MG Benchmark\n\n")
+	fmt.Fprintf(mg.out, " Size: %3dx%3dx%3d (class %s)\n", mg.nx[mg.lt], mg.ny[mg.lt], mg.nz[mg.lt], mg.class)
+	fmt.Fprintf(mg.out, " Iterations: %3d\n", mg.nit)
 
 	mg.resid(mg.u, mg.v, mg.r, mg.n1, mg.n2, mg.n3, mg.a, mg.lt)
 	mg.rnm2, mg.rnmu = mg.norm2u3(mg.r, mg.n1, mg.n2, mg.n3, mg.nx[mg.lt], mg.ny[mg.lt], mg.nz[mg.lt])
@@ -749,15 +805,20 @@ func (mg *MGBenchmark) run() {
 
 	mg.resid(mg.u, mg.v, mg.r, mg.n1, mg.n2, mg.n3, mg.a, mg.lt)
 	mg.rnm2, mg.rnmu = mg.norm2u3(mg.r, mg.n1, mg.n2, mg.n3, mg.nx[mg.lt], mg.ny[mg.lt], mg.nz[mg.lt])
-	common.TimerStop(T_INIT)
+	mg.timers.Stop(T_INIT)
 
-	tinit := common.TimerRead(T_INIT)
-	fmt.Printf(" Initialization time: %15.3f seconds\n", tinit)
+	tinit := mg.timers.Read(T_INIT)
+	fmt.Fprintf(mg.out, " Initialization time: %15.3f seconds\n", tinit)
 
 	startTime := time.Now()
+	iterations := mg.nit
 	for it := 1; it <= mg.nit; it++ {
+		if ctx.Err() != nil {
+			iterations = it - 1
+			break
+		}
 		if it == 1 || it == mg.nit || it%5 == 0 {
-			fmt.Printf("\t iter %3d\n", it)
+			fmt.Fprintf(mg.out, "\t iter %3d\n", it)
 		}
 		mg.mg3P(mg.u, mg.v, mg.r, mg.a, mg.c, mg.n1, mg.n2, mg.n3, mg.lt)
 		mg.resid(mg.u, mg.v, mg.r, mg.n1, mg.n2, mg.n3, mg.a, mg.lt)
@@ -766,27 +827,59 @@ func (mg *MGBenchmark) run() {
 
 	mg.rnm2, mg.rnmu = mg.norm2u3(mg.r, mg.n1, mg.n2, mg.n3, mg.nx[mg.lt], mg.ny[mg.lt], mg.nz[mg.lt])
 
-	epsilon := 1.0e-8
-	verifyValue := params.VERIFY_VALUE
-	err := math.Abs(mg.rnm2-verifyValue) / verifyValue
-	mg.verified = err <= epsilon
-
-	fmt.Printf("\n Benchmark completed\n")
-	if mg.verified {
-		fmt.Printf(" VERIFICATION SUCCESSFUL\n")
-		fmt.Printf(" L2 Norm is %20.13e\n", mg.rnm2)
-		fmt.Printf(" Error is   %20.13e\n", err)
+	incomplete := iterations < mg.nit
+	if incomplete {
+		fmt.Fprintf(mg.out, "\n Benchmark stopped after %d of %d iterations\n", iterations, mg.nit)
+		fmt.Fprintf(mg.out, " NO VERIFICATION PERFORMED\n")
+		fmt.Fprintf(mg.out, " L2 Norm is %20.13e\n", mg.rnm2)
+	} else if mg.class == "U" {
+		fmt.Fprintf(mg.out, "\n Benchmark completed\n")
+		fmt.Fprintf(mg.out, " Problem size unknown\n")
+		fmt.Fprintf(mg.out, " NO VERIFICATION PERFORMED\n")
+		fmt.Fprintf(mg.out, " L2 Norm is %20.13e\n", mg.rnm2)
 	} else {
-		fmt.Printf(" VERIFICATION FAILED\n")
-		fmt.Printf(" L2 Norm is             %20.13e\n", mg.rnm2)
-		fmt.Printf(" The correct L2 Norm is %20.13e\n", verifyValue)
+		fmt.Fprintf(mg.out, "\n Benchmark completed\n")
+		epsilon := 1.0e-8
+		verifyValue := mg.verifyValue
+		err := math.Abs(mg.rnm2-verifyValue) / verifyValue
+		mg.verified = err <= epsilon
+
+		if mg.verified {
+			fmt.Fprintf(mg.out, " VERIFICATION SUCCESSFUL\n")
+			fmt.Fprintf(mg.out, " L2 Norm is %20.13e\n", mg.rnm2)
+			fmt.Fprintf(mg.out, " Error is   %20.13e\n", err)
+		} else {
+			fmt.Fprintf(mg.out, " VERIFICATION FAILED\n")
+			fmt.Fprintf(mg.out, " L2 Norm is             %20.13e\n", mg.rnm2)
+			fmt.Fprintf(mg.out, " The correct L2 Norm is %20.13e\n", verifyValue)
+		}
 	}
 
 	mops := 0.0
 	if elapsed > 0 {
 		nn := float64(mg.nx[mg.lt] * mg.ny[mg.lt] * mg.nz[mg.lt])
-		mops = 58.0 * float64(mg.nit) * nn * 1.0e-6 / elapsed
-	}
-
-	common.PrintResults("MG", mg.class, mg.nx[mg.lt], mg.ny[mg.lt], mg.nz[mg.lt], mg.nit, elapsed, mops, "floating point", mg.verified, "4.1", "Serial", "Go", "")
+		mops = 58.0 * float64(iterations) * nn * 1.0e-6 / elapsed
+	}
+
+	result := common.Result{
+		Kernel:      "MG",
+		Class:       mg.class,
+		Size:        [3]int{mg.nx[mg.lt], mg.ny[mg.lt], mg.nz[mg.lt]},
+		Iterations:  iterations,
+		Time:        elapsed,
+		Mops:        mops,
+		OpType:      "floating point",
+		Verified:    mg.verified,
+		Incomplete:  incomplete,
+		Workers:     1,
+		Timers:      []common.Timer{{Name: "init", Seconds: tinit}},
+		NPBVersion:  "4.1",
+		CompileTime: "Serial",
+		Compiler:    "Go",
+	}
+	common.Finish(&result, mg.out)
+	if incomplete {
+		return Result{result, mg.rnm2}, &common.IncompleteError{Completed: iterations, Planned: mg.nit, Err: ctx.Err()}
+	}
+	return Result{result, mg.rnm2}, nil
 }
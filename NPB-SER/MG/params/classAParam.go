@@ -1,14 +0,0 @@
-//go:build A
-
-package params
-
-const (
-	CLASS        = "A"
-	NX           = 256
-	NY           = 256
-	NZ           = 256
-	NIT          = 4
-	LM           = 5
-	EmptyTag     = false
-	VERIFY_VALUE = 0.2433365309069e-05
-)
@@ -1,14 +0,0 @@
-//go:build D
-
-package params
-
-const (
-	CLASS        = "D"
-	NX           = 1024
-	NY           = 1024
-	NZ           = 1024
-	NIT          = 50
-	LM           = 5
-	EmptyTag     = false
-	VERIFY_VALUE = 0.1583275060440e-09
-)
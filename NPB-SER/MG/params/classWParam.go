@@ -1,14 +0,0 @@
-//go:build W
-
-package params
-
-const (
-	CLASS        = "W"
-	NX           = 64
-	NY           = 64
-	NZ           = 64
-	NIT          = 4
-	LM           = 5
-	EmptyTag     = false
-	VERIFY_VALUE = 0.6467329375339e-05
-)
@@ -1,14 +0,0 @@
-//go:build !S && !W && !A && !B && !C && !D && !E
-
-package params
-
-const (
-	CLASS        = ""
-	NX           = 32
-	NY           = 32
-	NZ           = 32
-	NIT          = 4
-	LM           = 5
-	EmptyTag     = true
-	VERIFY_VALUE = 0.0
-)
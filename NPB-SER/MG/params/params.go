@@ -0,0 +1,66 @@
+package params
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Params holds the grid size and reference L2 norm of one MG class
+type Params struct {
+	CLASS        string
+	NX           int
+	NY           int
+	NZ           int
+	NIT          int
+	VERIFY_VALUE float64
+}
+
+// Classes lists the standard problem classes in increasing size
+var Classes = []string{"S", "W", "A", "B", "C", "D", "E"}
+
+var table = map[string]Params{
+	"S": {CLASS: "S", NX: 32, NY: 32, NZ: 32, NIT: 4, VERIFY_VALUE: 0.5307707005734e-04},
+	"W": {CLASS: "W", NX: 128, NY: 128, NZ: 128, NIT: 4, VERIFY_VALUE: 0.6467329375339e-05},
+	"A": {CLASS: "A", NX: 256, NY: 256, NZ: 256, NIT: 4, VERIFY_VALUE: 0.2433365309069e-05},
+	"B": {CLASS: "B", NX: 256, NY: 256, NZ: 256, NIT: 20, VERIFY_VALUE: 0.1800564401355e-05},
+	"C": {CLASS: "C", NX: 512, NY: 512, NZ: 512, NIT: 20, VERIFY_VALUE: 0.5706732285740e-06},
+	"D": {CLASS: "D", NX: 1024, NY: 1024, NZ: 1024, NIT: 50, VERIFY_VALUE: 0.1583275060440e-09},
+	"E": {CLASS: "E", NX: 2048, NY: 2048, NZ: 2048, NIT: 50, VERIFY_VALUE: 0.8157592357404e-10},
+}
+
+// UserClass selects a problem size supplied on the command line
+const UserClass = "U"
+
+// Lookup returns the parameters of the given class
+func Lookup(class string) (Params, bool) {
+	p, ok := table[strings.ToUpper(class)]
+	return p, ok
+}
+
+// Custom returns class U parameters for an nx x ny x nz grid. Each size
+// must be even and at least 4, so that the V-cycle has a coarser grid than
+// the finest; see Levels for how deep it goes.
+func Custom(nx, ny, nz, nit int) (Params, error) {
+	for _, n := range []int{nx, ny, nz} {
+		if n < 4 || n%2 != 0 {
+			return Params{}, fmt.Errorf("grid sizes must be even and at least 4, got %dx%dx%d", nx, ny, nz)
+		}
+	}
+	if nit < 1 {
+		return Params{}, fmt.Errorf("nit must be positive, got %d", nit)
+	}
+	return Params{CLASS: UserClass, NX: nx, NY: ny, NZ: nz, NIT: nit}, nil
+}
+
+// Levels returns the number of grids of the V-cycle on an nx x ny x nz
+// grid: every coarser grid halves the sizes of the one above, for as long as
+// they all stay even and at least 2. A grid of n = 2^k points a side has k
+// levels, down to 2 points as in NPB; one of 192 has 7, down to 3.
+func Levels(nx, ny, nz int) int {
+	levels := 1
+	for nx%2 == 0 && ny%2 == 0 && nz%2 == 0 && min(nx, ny, nz) >= 4 {
+		nx, ny, nz = nx/2, ny/2, nz/2
+		levels++
+	}
+	return levels
+}
@@ -0,0 +1,25 @@
+package common
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// PrintClassUsage explains on w how to select a problem class when an unknown
+// one was given
+func PrintClassUsage(w io.Writer, program, class string, classes []string) {
+	quoted := make([]string, len(classes))
+	for i, c := range classes {
+		quoted[i] = fmt.Sprintf("%q", c)
+	}
+	list := quoted[0]
+	if len(quoted) > 1 {
+		list = strings.Join(quoted[:len(quoted)-1], ", ") + " or " + quoted[len(quoted)-1]
+	}
+
+	fmt.Fprintf(w, "Unknown class %q\n", class)
+	fmt.Fprintln(w, "To run a NAS benchmark type ")
+	fmt.Fprintf(w, "\t %s -class=<CLASS>\n", program)
+	fmt.Fprintf(w, "where: <class> is %s\n", list)
+}
@@ -0,0 +1,38 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+)
+
+// IncompleteError reports a run that its context stopped after Completed of
+// its Planned iterations. The partial result is returned along with it.
+type IncompleteError struct {
+	Completed, Planned int
+	Err                error // the context's error
+}
+
+func (e *IncompleteError) Error() string {
+	return fmt.Sprintf("stopped after %d of %d iterations: %v", e.Completed, e.Planned, e.Err)
+}
+
+func (e *IncompleteError) Unwrap() error {
+	return e.Err
+}
+
+// RunContext returns the context a command runs its benchmark in: it is
+// cancelled by SIGINT and, when timeout is positive, once timeout has passed
+func RunContext(timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	if timeout <= 0 {
+		return ctx, stop
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	return ctx, func() {
+		cancel()
+		stop()
+	}
+}
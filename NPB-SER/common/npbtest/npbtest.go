@@ -0,0 +1,91 @@
+// Package npbtest holds the checks the tests of every kernel share: runs of
+// several classes at once, and runs that their context stops early.
+package npbtest
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/iyisakuma/NPB-GO/NPB-SER/common"
+)
+
+// RunFunc runs a kernel at a problem class under ctx and returns the part of
+// its result every kernel has
+type RunFunc func(ctx context.Context, class string) (common.Result, error)
+
+// stopAfter is a context that turns cancelled after n checks of Err, which
+// the workers or ranks of a run may make concurrently
+type stopAfter struct {
+	context.Context
+	mu sync.Mutex
+	n  int
+}
+
+func (c *stopAfter) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.n == 0 {
+		return context.Canceled
+	}
+	c.n--
+	return nil
+}
+
+// StopAfter returns a context whose Err reports nothing for the first n
+// checks and context.Canceled from then on, so that a run stops at a known
+// point
+func StopAfter(n int) context.Context {
+	return &stopAfter{Context: context.Background(), n: n}
+}
+
+// ConcurrentRuns checks that runs of the given classes at the same time in
+// one process do not share state: each verifies and reports its own class
+func ConcurrentRuns(t testing.TB, run RunFunc, classes ...string) {
+	t.Helper()
+	results := make([]common.Result, len(classes))
+	errs := make([]error, len(classes))
+	var wg sync.WaitGroup
+	for i, class := range classes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = run(context.Background(), class)
+		}()
+	}
+	wg.Wait()
+	for i, class := range classes {
+		if errs[i] != nil {
+			t.Fatalf("class %s: %v", class, errs[i])
+		}
+		if results[i].Class != class || !results[i].Verified {
+			t.Errorf("class %s: got class %s, verified %v", class, results[i].Class, results[i].Verified)
+		}
+	}
+}
+
+// StoppedRun checks a run of class whose context is done from its third
+// check of Err on. A kernel checks once before starting and then before
+// each of its units of work (iterations, time steps, nodes...), so the run
+// must stop after two of them with a *common.IncompleteError wrapping
+// context.Canceled, and report a result that is incomplete, unverified and
+// not failed.
+func StoppedRun(t testing.TB, run RunFunc, class, units string) {
+	t.Helper()
+	result, err := run(StopAfter(3), class)
+
+	var incomplete *common.IncompleteError
+	if !errors.As(err, &incomplete) {
+		t.Fatalf("err = %v, want an *IncompleteError", err)
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want it to wrap context.Canceled", err)
+	}
+	if incomplete.Completed != 2 {
+		t.Errorf("completed %d %s, want 2", incomplete.Completed, units)
+	}
+	if !result.Incomplete || result.Verified || result.Failed() {
+		t.Errorf("result incomplete=%v verified=%v failed=%v, want true false false", result.Incomplete, result.Verified, result.Failed())
+	}
+}
@@ -0,0 +1,128 @@
+package common
+
+import (
+	"crypto/rand"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"strconv"
+	"strings"
+)
+
+// resultsFile is where Report appends one record per run, "" for none
+var resultsFile string
+
+// resultsCSV selects CSV records instead of JSON lines
+var resultsCSV bool
+
+// runID identifies every record written by this process
+var runID = newRunID()
+
+// csvHeader is the column schema of CSV results files. Columns are only ever
+// appended to it so that files from older runs stay readable.
+var csvHeader = []string{
+	"run_id", "timestamp", "git_revision", "kernel", "variant", "class",
+	"n1", "n2", "n3", "iterations", "time_seconds", "mops", "operation_type",
+	"verified", "verification", "workers", "go_version",
+	"hostname", "os", "arch", "num_cpu", "gomaxprocs", "incomplete",
+}
+
+// SetResultsFile makes Report append every result to path: CSV rows when the
+// name ends in .csv, JSON lines when it ends in .jsonl or .ndjson. An empty
+// path disables it.
+func SetResultsFile(path string) error {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case "":
+		if path != "" {
+			return fmt.Errorf("results file %q needs a .csv, .jsonl or .ndjson extension", path)
+		}
+	case ".csv":
+		resultsCSV = true
+	case ".jsonl", ".ndjson":
+		resultsCSV = false
+	default:
+		return fmt.Errorf("results file %q needs a .csv, .jsonl or .ndjson extension", path)
+	}
+	resultsFile = path
+	return nil
+}
+
+// appendResult adds r to the results file, writing the CSV header first when
+// the file is new or empty
+func appendResult(r Result) error {
+	f, err := os.OpenFile(resultsFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if !resultsCSV {
+		line, err := json.Marshal(r)
+		if err != nil {
+			return err
+		}
+		_, err = f.Write(append(line, '\n'))
+		return err
+	}
+
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	w := csv.NewWriter(f)
+	if fi.Size() == 0 {
+		w.Write(csvHeader)
+	}
+	w.Write(csvRecord(r))
+	w.Flush()
+	return w.Error()
+}
+
+// csvRecord lays r out in csvHeader order
+func csvRecord(r Result) []string {
+	itoa := strconv.Itoa
+	ftoa := func(f float64) string { return strconv.FormatFloat(f, 'g', -1, 64) }
+	return []string{
+		r.RunID, r.Timestamp, r.GitRevision, r.Kernel, r.Variant, r.Class,
+		itoa(r.Size[0]), itoa(r.Size[1]), itoa(r.Size[2]), itoa(r.Iterations),
+		ftoa(r.Time), ftoa(r.Mops), r.OpType,
+		strconv.FormatBool(r.Verified), r.Verification, itoa(r.Workers), r.GoVersion,
+		r.Host.Hostname, r.Host.OS, r.Host.Arch, itoa(r.Host.NumCPU), itoa(r.Host.GOMAXPROCS),
+		strconv.FormatBool(r.Incomplete),
+	}
+}
+
+// newRunID returns a random 16 hex digit identifier
+func newRunID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// gitRevision returns the commit the binary was built from, suffixed with
+// "-dirty" for uncommitted changes. It is empty when the build carries no VCS
+// information (go run, or builds outside a checkout).
+func gitRevision() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	var rev string
+	var dirty bool
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			rev = s.Value
+		case "vcs.modified":
+			dirty = s.Value == "true"
+		}
+	}
+	if rev != "" && dirty {
+		rev += "-dirty"
+	}
+	return rev
+}
@@ -0,0 +1,13 @@
+module github.com/iyisakuma/NPB-GO/npb
+
+go 1.24
+
+require (
+	github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE v0.0.0
+	github.com/iyisakuma/NPB-GO/NPB-SER v0.0.0
+)
+
+replace (
+	github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE => ../NPB-GOUROUTINE
+	github.com/iyisakuma/NPB-GO/NPB-SER => ../NPB-SER
+)
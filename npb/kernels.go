@@ -0,0 +1,93 @@
+package main
+
+import (
+	"slices"
+	"strings"
+
+	btparams "github.com/iyisakuma/NPB-GO/NPB-SER/BT/params"
+	cgparams "github.com/iyisakuma/NPB-GO/NPB-SER/CG/params"
+	dcparams "github.com/iyisakuma/NPB-GO/NPB-SER/DC/params"
+	dtparams "github.com/iyisakuma/NPB-GO/NPB-SER/DT/params"
+	epparams "github.com/iyisakuma/NPB-GO/NPB-SER/EP/params"
+	ftparams "github.com/iyisakuma/NPB-GO/NPB-SER/FT/params"
+	isparams "github.com/iyisakuma/NPB-GO/NPB-SER/IS/params"
+	luparams "github.com/iyisakuma/NPB-GO/NPB-SER/LU/params"
+	mgparams "github.com/iyisakuma/NPB-GO/NPB-SER/MG/params"
+	spparams "github.com/iyisakuma/NPB-GO/NPB-SER/SP/params"
+
+	btmzparams "github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/BT-MZ/params"
+	lumzparams "github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/LU-MZ/params"
+	ngbparams "github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/NGB/params"
+	spmzparams "github.com/iyisakuma/NPB-GO/NPB-GOUROUTINE/SP-MZ/params"
+)
+
+// Kernel describes one benchmark the driver can dispatch to
+type Kernel struct {
+	Name        string   // command line name, e.g. "cg"
+	Dir         string   // directory of the kernel inside each variant tree
+	Description string   // one line summary shown by "npb list"
+	Classes     []string // accepted problem classes, class U included when supported
+	Variants    []string // variants that implement the kernel, all of them when empty
+}
+
+// Variant is one implementation tree of the kernels
+type Variant struct {
+	Name    string // command line name, e.g. "goroutine"
+	Dir     string // tree directory relative to the repository root
+	Workers bool   // whether the -workers option has any effect
+	Launch  string // command of the tree that starts one process per rank, none when empty
+}
+
+var kernels = []Kernel{
+	{"ep", "EP", "Embarrassingly Parallel, floating-point operation capacity", epparams.Classes, nil},
+	{"is", "IS", "Integer Sort, random memory access", append(isparams.Classes, isparams.UserClass), nil},
+	{"cg", "CG", "Conjugate Gradient, irregular memory access and communication", append(cgparams.Classes, cgparams.UserClass), nil},
+	{"mg", "MG", "Multi-Grid on a sequence of meshes, memory intensive", append(mgparams.Classes, mgparams.UserClass), []string{"serial", "goroutine"}},
+	{"ft", "FT", "discrete 3D fast Fourier Transform, all-to-all communication", append(ftparams.Classes, ftparams.UserClass), []string{"serial", "goroutine"}},
+	{"bt", "BT", "Block Tri-diagonal solver pseudo-application", btparams.Classes, []string{"serial", "goroutine"}},
+	{"sp", "SP", "Scalar Penta-diagonal solver pseudo-application", spparams.Classes, []string{"serial", "goroutine"}},
+	{"lu", "LU", "Lower-Upper Gauss-Seidel solver pseudo-application", luparams.Classes, []string{"serial", "goroutine"}},
+	{"dc", "DC", "Data Cube, data movement through memory or local files", dcparams.Classes, []string{"serial", "goroutine"}},
+	{"dt", "DT", "Data Traffic, features streamed through a graph of nodes", dtparams.Classes, []string{"serial", "channel", "tcp"}},
+	{"bt-mz", "BT-MZ", "multi-zone BT with uneven zones, zone and loop parallelism", btmzparams.Classes, []string{"goroutine"}},
+	{"sp-mz", "SP-MZ", "multi-zone SP with even zones, zone and loop parallelism", spmzparams.Classes, []string{"goroutine"}},
+	{"lu-mz", "LU-MZ", "multi-zone LU with 4 x 4 zones, zone and loop parallelism", lumzparams.Classes, []string{"goroutine"}},
+	{"ngb", "NGB", "grid workflows of EP, IS, CG, MG and FT tasks that feed one another", ngbparams.Classes, []string{"goroutine"}},
+}
+
+var variants = []Variant{
+	{"serial", "NPB-SER", false, ""},
+	{"goroutine", "NPB-GOUROUTINE", true, ""},
+	{"channel", "NPB-CHANNEL", true, ""},
+	{"tcp", "NPB-CHANNEL", true, "npbrun"},
+}
+
+// lookupKernel finds a kernel by its command line name
+func lookupKernel(name string) (Kernel, bool) {
+	for _, k := range kernels {
+		if strings.EqualFold(k.Name, name) {
+			return k, true
+		}
+	}
+	return Kernel{}, false
+}
+
+// lookupVariant finds a variant by its command line name
+func lookupVariant(name string) (Variant, bool) {
+	for _, v := range variants {
+		if strings.EqualFold(v.Name, name) {
+			return v, true
+		}
+	}
+	return Variant{}, false
+}
+
+// hasClass reports whether the kernel accepts the given problem class
+func (k Kernel) hasClass(class string) bool {
+	return slices.Contains(k.Classes, strings.ToUpper(class))
+}
+
+// hasVariant reports whether the kernel is implemented in the given variant
+func (k Kernel) hasVariant(variant string) bool {
+	return len(k.Variants) == 0 || slices.Contains(k.Variants, variant)
+}
@@ -0,0 +1,234 @@
+// Command npb runs any NPB-GO kernel in any of its implementations.
+//
+//	npb list
+//	npb run cg -class B -variant goroutine -workers 16 -gomaxprocs 8
+//	npb run mg -class U -- -n 256 -nit 10
+//	npb run ft -class A -format json > ft.json
+//	npb run cg -class D -timeout 10m
+//	npb run bt-mz -class B -workers 16 -- -groups 4
+//	npb run is -class B -variant tcp -workers 8
+//
+// Arguments after "--" are handed to the kernel unchanged. The exit status is
+// the kernel's: 0 when the run verified (or had nothing to verify), 1 when
+// verification failed, the kernel stopped on an error or the run was cut short
+// by -timeout or SIGINT. Usage errors exit with 2.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	switch os.Args[1] {
+	case "run":
+		os.Exit(run(os.Args[2:]))
+	case "list":
+		list()
+	case "help", "-h", "-help", "--help":
+		usage()
+	default:
+		fmt.Fprintf(os.Stderr, "npb: unknown command %q\n", os.Args[1])
+		usage()
+		os.Exit(2)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage:")
+	fmt.Fprintln(os.Stderr, "\t npb list")
+	fmt.Fprintln(os.Stderr, "\t npb run <kernel> [-class <CLASS>] [-variant <VARIANT>] [-workers <N>] [-gomaxprocs <N>] [-format text|json] [-results <file>] [-timeout <duration>] [-- <kernel flags>]")
+	fmt.Fprintln(os.Stderr, "Run \"npb list\" to see the available kernels, classes and variants.")
+}
+
+// list prints the kernels, their classes and the variants
+func list() {
+	fmt.Println("Kernels:")
+	for _, k := range kernels {
+		fmt.Printf("  %-5s %s\n", k.Name, k.Description)
+		fmt.Printf("        classes: %s\n", strings.Join(k.Classes, " "))
+		if len(k.Variants) > 0 {
+			fmt.Printf("        variants: %s\n", strings.Join(k.Variants, " "))
+		}
+	}
+	fmt.Println("Variants:")
+	for _, v := range variants {
+		if v.Launch != "" {
+			fmt.Printf("  %-10s (%s, one process per rank started by %s)\n", v.Name, v.Dir, v.Launch)
+			continue
+		}
+		fmt.Printf("  %-10s (%s)\n", v.Name, v.Dir)
+	}
+}
+
+// run executes one kernel and returns the exit status of the driver
+func run(args []string) int {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		fmt.Fprintln(os.Stderr, "npb run: missing kernel name")
+		usage()
+		return 2
+	}
+	kernel, ok := lookupKernel(args[0])
+	if !ok {
+		fmt.Fprintf(os.Stderr, "npb run: unknown kernel %q\n", args[0])
+		return 2
+	}
+
+	fs := flag.NewFlagSet("npb run "+kernel.Name, flag.ContinueOnError)
+	class := fs.String("class", kernel.Classes[0], "problem class")
+	variantName := fs.String("variant", variants[0].Name, "implementation: serial, goroutine, channel or tcp")
+	if len(kernel.Variants) > 0 {
+		*variantName = kernel.Variants[0]
+	}
+	workers := fs.Int("workers", 0, "number of goroutines (goroutine variant) or ranks (channel and tcp variants), 0 means one per CPU")
+	maxProcs := fs.Int("gomaxprocs", 0, "goroutines running at once in the kernel (0 means $GOMAXPROCS, or one per CPU)")
+	format := fs.String("format", "text", "result format: text or json")
+	results := fs.String("results", "", "append the result to this .csv or .jsonl file")
+	timeout := fs.Duration("timeout", 0, "stop the kernel after this long and report the iterations completed (0 for no limit)")
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	variant, ok := lookupVariant(*variantName)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "npb run: unknown variant %q\n", *variantName)
+		return 2
+	}
+	if !kernel.hasVariant(variant.Name) {
+		fmt.Fprintf(os.Stderr, "npb run: %s is not implemented by the %s variant (variants: %s)\n", kernel.Name, variant.Name, strings.Join(kernel.Variants, " "))
+		return 2
+	}
+	if !kernel.hasClass(*class) {
+		fmt.Fprintf(os.Stderr, "npb run: %s has no class %q (classes: %s)\n", kernel.Name, *class, strings.Join(kernel.Classes, " "))
+		return 2
+	}
+	if *workers < 0 || (*workers > 1 && !variant.Workers) {
+		fmt.Fprintf(os.Stderr, "npb run: -workers %d is not supported by the %s variant\n", *workers, variant.Name)
+		return 2
+	}
+	if *maxProcs < 0 || (*maxProcs > 0 && !variant.Workers) {
+		fmt.Fprintf(os.Stderr, "npb run: -gomaxprocs %d is not supported by the %s variant\n", *maxProcs, variant.Name)
+		return 2
+	}
+
+	exe, err := executable(variant, kernel)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "npb run: %v\n", err)
+		return 2
+	}
+
+	kernelArgs := []string{"-class=" + strings.ToUpper(*class), "-format=" + *format}
+	if *workers > 0 && variant.Workers && variant.Launch == "" {
+		kernelArgs = append(kernelArgs, "-workers="+strconv.Itoa(*workers))
+	}
+	if *maxProcs > 0 {
+		kernelArgs = append(kernelArgs, "-gomaxprocs="+strconv.Itoa(*maxProcs))
+	}
+	if *results != "" {
+		kernelArgs = append(kernelArgs, "-results="+*results)
+	}
+	if *timeout > 0 {
+		kernelArgs = append(kernelArgs, "-timeout="+timeout.String())
+	}
+	kernelArgs = append(kernelArgs, fs.Args()...)
+	if variant.Launch != "" {
+		launcher, err := executable(variant, Kernel{Dir: variant.Launch})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "npb run: %v\n", err)
+			return 2
+		}
+		// The launcher starts the ranks, which ignore -workers
+		launchArgs := []string{exe}
+		if *workers > 0 {
+			launchArgs = []string{"-np=" + strconv.Itoa(*workers), exe}
+		}
+		kernelArgs = append(launchArgs, kernelArgs...)
+		exe = launcher
+	}
+	cmd := exec.Command(exe, kernelArgs...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	// SIGINT stops the kernel, which still reports its partial result; the
+	// driver waits for it instead of dying first, and forwards the signal when
+	// it was sent to the driver alone
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	defer signal.Stop(interrupts)
+
+	if err := cmd.Start(); err != nil {
+		fmt.Fprintf(os.Stderr, "npb run: %v\n", err)
+		return 2
+	}
+	go func() {
+		for sig := range interrupts {
+			cmd.Process.Signal(sig)
+		}
+	}()
+	if err := cmd.Wait(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return exitErr.ExitCode()
+		}
+		fmt.Fprintf(os.Stderr, "npb run: %v\n", err)
+		return 2
+	}
+	return 0
+}
+
+// executable returns the path of the kernel binary of a variant, building it
+// into the tree's bin/ directory first. The build runs every time, so that a
+// run never uses a binary older than the sources; the build cache makes it
+// quick when nothing changed.
+func executable(v Variant, k Kernel) (string, error) {
+	root, err := repoRoot()
+	if err != nil {
+		return "", err
+	}
+	tree := filepath.Join(root, v.Dir)
+	exe := filepath.Join(tree, "bin", k.Dir)
+
+	build := exec.Command("go", "build", "-o", exe, "./"+k.Dir)
+	build.Dir = tree
+	build.Stdout = os.Stderr
+	build.Stderr = os.Stderr
+	if err := build.Run(); err != nil {
+		return "", fmt.Errorf("building %s/%s: %v", v.Dir, k.Dir, err)
+	}
+	return exe, nil
+}
+
+// repoRoot locates the NPB-GO checkout: $NPB_ROOT if set, otherwise the
+// nearest parent of the working directory holding the variant trees
+func repoRoot() (string, error) {
+	if root := os.Getenv("NPB_ROOT"); root != "" {
+		return root, nil
+	}
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	for {
+		if fi, err := os.Stat(filepath.Join(dir, variants[0].Dir)); err == nil && fi.IsDir() {
+			return dir, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", errors.New("cannot find the NPB-GO checkout; run inside it or set NPB_ROOT")
+		}
+		dir = parent
+	}
+}